@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This interface renders the dest of a SET validation into the update of a storage backend
+// the filter is the lookup of the updated documents, e.g. {"_id": id}: the version check of dest is added to it – see VersionFilter
+type UpdateAdapter interface {
+	Render(filter map[string]interface{}, dest map[string]interface{}) (interface{}, error)
+}
+
+// This struct is the update MongoAdapter renders: the selector and the update document of collection.Update
+type MongoUpdate struct {
+	Filter map[string]interface{}
+	Update map[string]interface{}
+}
+
+// This struct renders the updates as MongoDB update documents, with the dot-notation paths – see Update
+type MongoAdapter struct{}
+
+// This struct renders the updates as SQL UPDATE statements with bound parameters, e.g. for Postgres
+// the paths of dest are the columns, unless Columns maps them: a dotted path with no column is an error, the sub-documents and arrays being bound as JSON
+type SQLAdapter struct {
+	Table       string              // the updated table
+	Columns     map[string]string   // the columns by dest or filter path, when they differ
+	Placeholder func(n int) string  // the placeholder of the nth parameter, from 1, "$n" if nil – e.g. "?" for MySQL
+	Quote       func(string) string // the identifiers quoting, between double quotes if nil
+}
+
+// This struct is the statement SQLAdapter renders, to be run as db.Exec(statement.Query, statement.Args...)
+type SQLStatement struct {
+	Query string
+	Args  []interface{}
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the *MongoUpdate of dest
+func (MongoAdapter) Render(filter map[string]interface{}, dest map[string]interface{}) (interface{}, error) {
+	selector := make(map[string]interface{}, len(filter)+1)
+	for path, value := range filter {
+		selector[path] = value
+	}
+	for path, value := range VersionFilter(dest) {
+		selector[path] = value
+	}
+	update := Update(dest)
+	if len(update) == 0 {
+		return nil, fmt.Errorf("validation: empty update")
+	}
+	return &MongoUpdate{Filter: selector, Update: update}, nil
+}
+
+// This method returns the *SQLStatement of dest: the removed fields are set to NULL, the version one incremented, and a nil filter value is tested with IS NULL
+// the columns are written in the order of the paths, so the same update always renders the same statement
+func (a *SQLAdapter) Render(filter map[string]interface{}, dest map[string]interface{}) (interface{}, error) {
+	if a.Table == "" {
+		return nil, fmt.Errorf("validation: no table to update")
+	}
+	if len(dest) == 0 {
+		return nil, fmt.Errorf("validation: empty update")
+	}
+	statement := &SQLStatement{}
+	bind := func(value interface{}) (string, error) {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			value = string(encoded)
+		}
+		statement.Args = append(statement.Args, value)
+		return a.placeholder(len(statement.Args)), nil
+	}
+
+	assignments := make([]string, 0, len(dest))
+	for _, path := range sortedKeys(dest) {
+		column, err := a.column(path)
+		if err != nil {
+			return nil, err
+		}
+		switch value := dest[path].(type) {
+		case unset:
+			assignments = append(assignments, column+" = NULL")
+		case VersionCheck:
+			assignments = append(assignments, column+" = "+column+" + 1")
+		default:
+			placeholder, err := bind(value)
+			if err != nil {
+				return nil, fmt.Errorf("validation: %s: %v", path, err)
+			}
+			assignments = append(assignments, column+" = "+placeholder)
+		}
+	}
+
+	lookup := make(map[string]interface{}, len(filter)+1)
+	for path, value := range filter {
+		lookup[path] = value
+	}
+	for path, value := range VersionFilter(dest) {
+		lookup[path] = value
+	}
+	conditions := make([]string, 0, len(lookup))
+	for _, path := range sortedKeys(lookup) {
+		column, err := a.column(path)
+		if err != nil {
+			return nil, err
+		}
+		if lookup[path] == nil {
+			conditions = append(conditions, column+" IS NULL")
+			continue
+		}
+		placeholder, err := bind(lookup[path])
+		if err != nil {
+			return nil, fmt.Errorf("validation: %s: %v", path, err)
+		}
+		conditions = append(conditions, column+" = "+placeholder)
+	}
+
+	statement.Query = "UPDATE " + a.quote(a.Table) + " SET " + strings.Join(assignments, ", ")
+	if len(conditions) > 0 {
+		statement.Query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return statement, nil
+}
+
+// This method returns the quoted column of the path
+func (a *SQLAdapter) column(path string) (string, error) {
+	column, ok := a.Columns[path]
+	if !ok {
+		if strings.Contains(path, ".") {
+			return "", fmt.Errorf("validation: no column for the path %q", path)
+		}
+		column = path
+	}
+	return a.quote(column), nil
+}
+
+// This method returns the placeholder of the nth parameter
+func (a *SQLAdapter) placeholder(n int) string {
+	if a.Placeholder != nil {
+		return a.Placeholder(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// This method returns the quoted identifier
+func (a *SQLAdapter) quote(identifier string) string {
+	if a.Quote != nil {
+		return a.Quote(identifier)
+	}
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSQLAdapter(t *testing.T) {
+	dest := map[string]interface{}{"name": "bob", "bio": Unset, "version": VersionCheck{Expected: 3}, "address.city": "Paris", "tags": []interface{}{"a"}}
+	if _, err := (&SQLAdapter{Table: "users"}).Render(map[string]interface{}{"id": 7}, dest); err == nil {
+		t.Errorf("expected the dotted path with no column to be refused")
+	}
+	if _, err := (&SQLAdapter{}).Render(nil, dest); err == nil {
+		t.Errorf("expected the missing table to be refused")
+	}
+
+	var adapter UpdateAdapter = &SQLAdapter{Table: "users", Columns: map[string]string{"address.city": "city"}}
+	rendered, err := adapter.Render(map[string]interface{}{"id": 7, "deleted_at": nil}, dest)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	statement := rendered.(*SQLStatement)
+	expected := `UPDATE "users" SET "city" = $1, "bio" = NULL, "name" = $2, "tags" = $3, "version" = "version" + 1 WHERE "deleted_at" IS NULL AND "id" = $4 AND "version" = $5`
+	if statement.Query != expected {
+		t.Errorf("expected %s, got %s", expected, statement.Query)
+	}
+	if args := []interface{}{"Paris", "bob", `["a"]`, 7, int64(3)}; !reflect.DeepEqual(statement.Args, args) {
+		t.Errorf("expected %v, got %v", args, statement.Args)
+	}
+
+	mysql := &SQLAdapter{Table: "users", Placeholder: func(int) string { return "?" }, Quote: func(s string) string { return "`" + s + "`" }}
+	rendered, _ = mysql.Render(map[string]interface{}{"id": 7}, map[string]interface{}{"name": "bob"})
+	if query := rendered.(*SQLStatement).Query; query != "UPDATE `users` SET `name` = ? WHERE `id` = ?" {
+		t.Errorf("unexpected MySQL statement %s", query)
+	}
+}
+
+func TestMongoAdapter(t *testing.T) {
+	dest := map[string]interface{}{"name": "bob", "bio": Unset, "version": VersionCheck{Expected: 3}}
+	rendered, err := MongoAdapter{}.Render(map[string]interface{}{"_id": 1}, dest)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	update := rendered.(*MongoUpdate)
+	if !reflect.DeepEqual(update.Filter, map[string]interface{}{"_id": 1, "version": int64(3)}) || !reflect.DeepEqual(update.Update, Update(dest)) {
+		t.Errorf("unexpected update %+v", update)
+	}
+	if _, err := (MongoAdapter{}).Render(nil, map[string]interface{}{}); err == nil {
+		t.Errorf("expected the empty update to be refused")
+	}
+}
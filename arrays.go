@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct requires the items of an array to be ordered, e.g. the points of a time series
+// the items are compared as numbers (exactly), strings or times, and all of them must be of the same kind
+type Ordered struct {
+	Descending bool   // the items are in decreasing order, increasing otherwise
+	Key        string // the path of the compared value in the sub-documents, the items themselves being compared if empty
+	Strict     bool   // two consecutive items may not be equal
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function checks no two sub-documents of the array hold the same value at the UniqueBy path, the elements without one aside
+// the duplicate is reported at its index, along with the index of the first element holding the value
+func checkUnique(validator *Validator, in string, value interface{}, errors *[]*DataError) bool {
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return true
+	}
+	keys := make([]interface{}, slice.Len())
+	valid := true
+	for i := range keys {
+		document, ok := slice.Index(i).Interface().(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if keys[i] = readExisting(document, validator.UniqueBy); keys[i] == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if keys[j] != nil && sameValue(keys[i], keys[j]) {
+				err := NewError("duplicate_item", in+"."+strconv.Itoa(i), keys[i], map[string]interface{}{"key": validator.UniqueBy, "first": j})
+				err.Path = append(ParsePath(in), PathSegment{Index: i, IsIndex: true})
+				*errors = append(*errors, err)
+				valid = false
+				break
+			}
+		}
+	}
+	return valid
+}
+
+// this private function checks the items of the array are ordered, reporting the first one out of order at its index
+func checkOrdered(validator *Validator, in string, value interface{}, errors *[]*DataError) bool {
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return true
+	}
+	order := validator.Ordered
+	var previous interface{}
+	for i := 0; i < slice.Len(); i++ {
+		current := slice.Index(i).Interface()
+		if order.Key != "" {
+			document, _ := current.(map[string]interface{})
+			current = readExisting(document, order.Key)
+		}
+		if i > 0 {
+			cmp, ok := compareValues(previous, current)
+			if order.Descending {
+				cmp = -cmp
+			}
+			if !ok || cmp > 0 || (cmp == 0 && order.Strict) {
+				direction := "asc"
+				if order.Descending {
+					direction = "desc"
+				}
+				err := NewError("not_ordered", in+"."+strconv.Itoa(i), current, map[string]interface{}{"order": direction, "key": order.Key})
+				err.Path = append(ParsePath(in), PathSegment{Index: i, IsIndex: true})
+				*errors = append(*errors, err)
+				return false
+			}
+		}
+		previous = current
+	}
+	return true
+}
+
+// this private function compares two numbers, strings or times, and tells whether they can be compared
+func compareValues(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case time.Time:
+		b, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a.Before(b):
+			return -1, true
+		case a.After(b):
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	case nil:
+		return 0, false
+	}
+	x, okA := numberRat(a)
+	y, okB := numberRat(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+// this private function returns the exact value of a number, of any numeric type
+func numberRat(value interface{}) (*big.Rat, bool) {
+	if _, isString := value.(string); isString {
+		return nil, false
+	}
+	if signed, unsigned, isUnsigned, ok := integerValue(value); ok {
+		if isUnsigned {
+			return new(big.Rat).SetInt(new(big.Int).SetUint64(unsigned)), true
+		}
+		return new(big.Rat).SetInt64(signed), true
+	}
+	rat, ok := parseDecimal(value)
+	return rat, ok && rat != nil
+}
@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUniqueBy(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"addresses": {Type: "[]interface {}", UniqueBy: "label"}}}
+	_, errors := schema.Validate(map[string]interface{}{"addresses": []interface{}{
+		map[string]interface{}{"label": "home"},
+		map[string]interface{}{"label": "work"},
+		map[string]interface{}{},
+		"flat",
+		map[string]interface{}{"label": "home"},
+	}}, Options{Usage: INIT})
+	if len(errors) != 1 {
+		t.Fatalf("expected a single duplicate, got %v", errors)
+	}
+	err := errors[0]
+	if err.Code != "duplicate_item" || err.Field != "addresses.4" || err.Params["first"] != 0 || err.Params["key"] != "label" {
+		t.Errorf("expected the duplicate at 4 of the element 0, got %v %v", err, err.Params)
+	}
+	if len(err.Path) != 2 || !err.Path[1].IsIndex || err.Path[1].Index != 4 {
+		t.Errorf("expected the addresses[4] path, got %v", err.Path)
+	}
+
+	if errors := CheckSchema(&Schema{Validators: map[string]*Validator{"label": {Type: "string", UniqueBy: "a"}}}); len(errors) != 1 {
+		t.Errorf("expected the non-array type to be reported, got %v", errors)
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"points": {Type: "[]interface {}", Ordered: &Ordered{Key: "t", Strict: true}},
+		"ranks":  {Type: "[]interface {}", Ordered: &Ordered{Descending: true}},
+		"dates":  {Type: "[]time.Time", Ordered: &Ordered{}},
+	}}
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, errors := schema.Validate(map[string]interface{}{
+		"points": []interface{}{map[string]interface{}{"t": json.Number("1")}, map[string]interface{}{"t": json.Number("2.5")}},
+		"ranks":  []interface{}{"c", "b", "b", "a"},
+		"dates":  []time.Time{day, day.Add(time.Hour)},
+	}, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+
+	tests := []struct {
+		doc    map[string]interface{}
+		field  string
+		params map[string]interface{}
+	}{
+		{map[string]interface{}{"points": []interface{}{map[string]interface{}{"t": json.Number("2.5")}, map[string]interface{}{"t": 2.5}}}, "points.1", map[string]interface{}{"order": "asc", "key": "t"}},
+		{map[string]interface{}{"ranks": []interface{}{json.Number("10"), json.Number("9"), json.Number("9.5")}}, "ranks.2", map[string]interface{}{"order": "desc", "key": ""}},
+		{map[string]interface{}{"ranks": []interface{}{json.Number("10"), "x"}}, "ranks.1", map[string]interface{}{"order": "desc", "key": ""}},
+		{map[string]interface{}{"dates": []time.Time{day, day.Add(-time.Hour)}}, "dates.1", map[string]interface{}{"order": "asc", "key": ""}},
+	}
+	for _, test := range tests {
+		_, errors := schema.Validate(test.doc, Options{Usage: INIT})
+		if len(errors) != 1 || errors[0].Code != "not_ordered" || errors[0].Field != test.field || !reflect.DeepEqual(errors[0].Params, test.params) {
+			t.Errorf("%v: expected not_ordered on %s, got %v", test.doc, test.field, errors)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+// Package avro converts the Avro record schemas into validation schemas and back, so the Kafka producers and consumers validate the events with the same rules as the HTTP handlers
+// the nested records become "map[string]interface {}" validators whose fields are validated under dotted paths, e.g. "address.city"
+// the records inside the arrays become the Items validators of their elements, and the numbers inside the arrays and maps are expected as json.Number, as decoded with UseNumber or validation.Normalize
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is the object form of an Avro type – a primitive type name or a union is read into Name or Union
+type avroType struct {
+	Type        string            `json:"type"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	LogicalType string            `json:"logicalType"`
+	Fields      []avroField       `json:"fields"`
+	Symbols     []string          `json:"symbols"`
+	Items       json.RawMessage   `json:"items"`
+	Values      json.RawMessage   `json:"values"`
+	Precision   int               `json:"precision"`
+	Scale       int               `json:"scale"`
+	Union       []json.RawMessage `json:"-"`
+}
+
+// This inner struct is a record field – Default is nil when the field has no default, "null" when the default is null
+type avroField struct {
+	Name    string          `json:"name"`
+	Type    json.RawMessage `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// This struct resolves the named types while a schema is imported
+type importer struct {
+	named      map[string]*avroType
+	validators map[string]*validation.Validator
+	visiting   map[*avroType]bool // the records whose fields are being imported, a recursive one being refused
+}
+
+// the Avro names: fields, records and enums
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// the regexp of the uuid logical type
+const uuidRegexp = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+// the date and time logical types, by the type they annotate: the values are the integers of the wire format, e.g. the milliseconds since the epoch
+var timeLogicalTypes = map[string]string{
+	"date": "int", "time-millis": "int", "time-micros": "long",
+	"timestamp-millis": "long", "timestamp-micros": "long", "local-timestamp-millis": "long", "local-timestamp-micros": "long",
+}
+
+// the boundaries of the float type, a float64 validator being bounded by them
+var float32Boundaries = validation.Boundaries{Min: -math.MaxFloat32, Max: math.MaxFloat32}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method reads an Avro type, registering the named ones and replacing the references by the types they name
+func (i *importer) resolve(raw json.RawMessage) (*avroType, error) {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0:
+		return nil, fmt.Errorf("missing type")
+	case raw[0] == '"':
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return nil, err
+		}
+		if named, ok := i.named[name]; ok {
+			return named, nil
+		}
+		return &avroType{Type: name}, nil
+	case raw[0] == '[':
+		t := &avroType{Type: "union"}
+		return t, json.Unmarshal(raw, &t.Union)
+	}
+
+	t := &avroType{}
+	if err := json.Unmarshal(raw, t); err != nil {
+		return nil, err
+	}
+	if t.Name != "" {
+		i.named[t.Name] = t
+		if t.Namespace != "" {
+			i.named[t.Namespace+"."+t.Name] = t
+		}
+	}
+	return t, nil
+}
+
+// This method writes the validators of the record fields, under the path prefix
+// the fields of an optional record are neither required nor defaulted, since the record itself may be missing
+func (i *importer) record(t *avroType, prefix string, optional bool) error {
+	if i.visiting[t] {
+		return fmt.Errorf("recursive record %q is not supported", t.Name)
+	}
+	i.visiting[t] = true
+	defer delete(i.visiting, t)
+	for _, field := range t.Fields {
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+		if err := i.field(path, field, optional); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// This method writes the validator of a record field – and the ones of its fields if it is a record
+func (i *importer) field(path string, field avroField, optional bool) error {
+	t, err := i.resolve(field.Type)
+	if err != nil {
+		return err
+	}
+	if t.Type == "union" {
+		if t, err = i.nullable(t); err != nil {
+			return err
+		}
+		optional = true
+	}
+
+	validator, err := i.validator(t)
+	if err != nil {
+		return err
+	}
+	validator.IsRequired = !optional && field.Default == nil
+	if !optional && field.Default != nil && string(field.Default) != "null" {
+		if _, err := decodeDefault(validator.Type, field.Default); err != nil {
+			return fmt.Errorf("invalid default: %v", err)
+		}
+		// decoded at each call, so the documents never share a default map or array
+		raw := field.Default
+		validator.Default = func(interface{}) interface{} {
+			value, _ := decodeDefault(validator.Type, raw)
+			return value
+		}
+	}
+	i.validators[path] = validator
+
+	if t.Type == "record" {
+		return i.record(t, path, optional)
+	}
+	return nil
+}
+
+// This method returns the type of a union made of null and another type, the only unions a validator can hold
+func (i *importer) nullable(t *avroType) (*avroType, error) {
+	var types []*avroType
+	for _, raw := range t.Union {
+		branch, err := i.resolve(raw)
+		if err != nil {
+			return nil, err
+		}
+		if branch.Type != "null" {
+			types = append(types, branch)
+		}
+	}
+	if len(types) != 1 {
+		return nil, fmt.Errorf("unions are only supported with null and one other type")
+	}
+	return types[0], nil
+}
+
+// This method returns the validator of a type, without its requirement nor its default
+func (i *importer) validator(t *avroType) (*validation.Validator, error) {
+	_type, err := i.typeString(t, false)
+	if err != nil {
+		return nil, err
+	}
+	validator := &validation.Validator{Type: _type}
+	switch {
+	case t.Type == "enum":
+		symbols := make([]string, len(t.Symbols))
+		for n, symbol := range t.Symbols {
+			symbols[n] = regexp.QuoteMeta(symbol)
+		}
+		validator.Regexp = "^(" + strings.Join(symbols, "|") + ")$"
+	case t.LogicalType == "uuid":
+		validator.Regexp = uuidRegexp
+	case t.LogicalType == "decimal":
+		validator.Decimal = decimal(t.Precision, t.Scale)
+	case t.Type == "float":
+		validator.Boundaries = float32Boundaries
+	case _type == "[]map[string]interface {}":
+		// the elements are validated against the fields of the record, as documents
+		item, err := i.resolve(t.Items)
+		if err != nil {
+			return nil, err
+		}
+		items := &importer{named: i.named, validators: make(map[string]*validation.Validator), visiting: i.visiting}
+		if err := items.record(item, "", false); err != nil {
+			return nil, err
+		}
+		validator.Items = items.validators
+	case _type == "int32":
+		validator.IntBoundaries = &validation.IntBoundaries{Min: math.MinInt32, Max: math.MaxInt32}
+	case _type == "int64":
+		validator.IntBoundaries = &validation.IntBoundaries{Min: math.MinInt64, Max: math.MaxInt64}
+	}
+	return validator, nil
+}
+
+// This method returns the validator type string of an Avro type
+// inside the arrays and maps, the numbers are json.Number and the nullable values interface {}; the records are only supported as fields or as the items of the arrays of fields
+func (i *importer) typeString(t *avroType, nested bool) (string, error) {
+	switch t.Type {
+	case "boolean":
+		return "bool", nil
+	case "int", "long", "float", "double":
+		if annotated, ok := timeLogicalTypes[t.LogicalType]; ok && annotated != t.Type {
+			return "", fmt.Errorf("logical type %q annotating %q instead of %q", t.LogicalType, t.Type, annotated)
+		}
+		if nested {
+			return "json.Number", nil
+		}
+		return map[string]string{"int": "int32", "long": "int64", "float": "float64", "double": "float64"}[t.Type], nil
+	case "string", "enum":
+		return "string", nil
+	case "bytes", "fixed":
+		if t.LogicalType == "decimal" {
+			return "json.Number", nil
+		}
+		return "string", nil
+	case "record":
+		if nested {
+			return "", fmt.Errorf("records are only supported as fields or as the items of the arrays of fields")
+		}
+		return "map[string]interface {}", nil
+	case "union":
+		branch, err := i.nullable(t)
+		if err != nil {
+			return "", err
+		}
+		if _, err := i.typeString(branch, true); err != nil {
+			return "", err
+		}
+		return "interface {}", nil
+	case "array", "map":
+		raw := t.Items
+		if t.Type == "map" {
+			raw = t.Values
+		}
+		item, err := i.resolve(raw)
+		if err != nil {
+			return "", err
+		}
+		if item.Type == "record" && t.Type == "array" && !nested {
+			return "[]map[string]interface {}", nil
+		}
+		itemType, err := i.typeString(item, true)
+		if err != nil {
+			return "", err
+		}
+		if t.Type == "map" {
+			return "map[string]" + itemType, nil
+		}
+		return "[]" + itemType, nil
+	}
+	return "", fmt.Errorf("unsupported type %q", t.Type)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function converts an Avro record schema into a validation schema
+// the fields with no default that are not a union with null are required, the defaults become Default functions
+// the enums and the uuid logical type become regexps, the decimal logical type a Decimal whose bounds follow the precision, and the floats float64 values bounded as float32 ones
+// the date and time logical types (timestamp-millis, ...) keep the int or long type they annotate, as the integers of the wire format – and are refused on another type
+// the unions of several non-null types, the recursive records and the records inside maps are not supported
+func Import(data []byte) (*validation.Schema, error) {
+	i := &importer{named: make(map[string]*avroType), validators: make(map[string]*validation.Validator), visiting: make(map[*avroType]bool)}
+	t, err := i.resolve(data)
+	if err != nil {
+		return nil, fmt.Errorf("avro: %v", err)
+	}
+	if t.Type != "record" {
+		return nil, fmt.Errorf("avro: the schema is a %s, not a record", t.Type)
+	}
+	if err := i.record(t, "", false); err != nil {
+		return nil, fmt.Errorf("avro: %v", err)
+	}
+	return &validation.Schema{Validators: i.validators}, nil
+}
+
+// This function converts a validation schema into an Avro record schema of the given name
+// the validators that are not required become unions with null, the Default functions are called with nil to get the default values
+// the dotted paths must go through "map[string]interface {}" validators, which become records, and the Items validators of an array become the record of its items
+// the logical types are lost but for decimal, whose precision is read from the Decimal bounds
+func Export(schema *validation.Schema, name string) ([]byte, error) {
+	record, err := exportRecord(schema.Validators, name)
+	if err != nil {
+		return nil, fmt.Errorf("avro: %v", err)
+	}
+	return json.Marshal(record)
+}
+
+// this private function returns the record of the validators, the array of sub-documents with Items validators being arrays of records
+func exportRecord(validators map[string]*validation.Validator, name string) (map[string]interface{}, error) {
+	paths := make([]string, 0, len(validators))
+	for path := range validators {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if i := strings.LastIndex(path, "."); i >= 0 {
+			if parent := validators[path[:i]]; parent == nil || parent.Type != "map[string]interface {}" {
+				return nil, fmt.Errorf("%s is not inside a record", path)
+			}
+		}
+	}
+	return exportFields(validators, paths, "", name)
+}
+
+// this private function returns the record holding the validators directly under the path
+func exportFields(validators map[string]*validation.Validator, paths []string, path string, name string) (map[string]interface{}, error) {
+	if !avroName.MatchString(name) {
+		return nil, fmt.Errorf("invalid record name %q", name)
+	}
+	fields := []avroField{}
+	for _, full := range paths {
+		key := full
+		if path != "" {
+			if !strings.HasPrefix(full, path+".") {
+				continue
+			}
+			key = full[len(path)+1:]
+		}
+		if strings.Contains(key, ".") {
+			continue
+		}
+		if !avroName.MatchString(key) {
+			return nil, fmt.Errorf("%s: invalid field name", full)
+		}
+
+		validator := validators[full]
+		var _type interface{}
+		var err error
+		switch {
+		case validator.Type == "map[string]interface {}":
+			_type, err = exportFields(validators, paths, full, name+"_"+key)
+		case validator.Items != nil:
+			var items map[string]interface{}
+			items, err = exportRecord(validator.Items, name+"_"+key)
+			_type = map[string]interface{}{"type": "array", "items": items}
+		case validator.Type == "float64" && validator.Boundaries == float32Boundaries:
+			_type = "float"
+		default:
+			_type, err = exportType(validator.Type, validator.Decimal)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", full, err)
+		}
+
+		field := avroField{Name: key}
+		if validator.Default != nil && len(validator.Defaults) == 0 && validator.DefaultFromDoc == nil {
+			if field.Default, err = json.Marshal(validator.Default(nil)); err != nil {
+				return nil, fmt.Errorf("%s: invalid default: %v", full, err)
+			}
+		} else if !validator.IsRequired {
+			// a union default is of its first type
+			_type, field.Default = []interface{}{"null", _type}, json.RawMessage("null")
+		}
+		field.Type, err = json.Marshal(_type)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return map[string]interface{}{"type": "record", "name": name, "fields": fields}, nil
+}
+
+// this private function returns the Avro type of a validator type string
+func exportType(_type string, dec *validation.Decimal) (interface{}, error) {
+	if dec != nil && dec.Scale >= 0 {
+		return map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": precision(dec), "scale": dec.Scale}, nil
+	}
+	switch _type {
+	case "bool":
+		return "boolean", nil
+	case "string", "bson.ObjectId", "json.RawMessage":
+		return "string", nil
+	case "int8", "int16", "int32", "uint8", "uint16":
+		return "int", nil
+	case "int", "int64", "uint", "uint32", "uint64":
+		return "long", nil
+	case "float32":
+		return "float", nil
+	case "float64", "json.Number":
+		return "double", nil
+	}
+	if _type == "time.Time" {
+		return nil, fmt.Errorf("type %q cannot be exported: the Avro times are integers, e.g. timestamp-millis, whereas the unix times of the time.Time validators are in seconds", _type)
+	}
+	if strings.HasPrefix(_type, "[]") {
+		items, err := exportType(_type[2:], nil)
+		return map[string]interface{}{"type": "array", "items": items}, err
+	}
+	if strings.HasPrefix(_type, "map[string]") && _type != "map[string]interface {}" {
+		values, err := exportType(_type[len("map[string]"):], nil)
+		return map[string]interface{}{"type": "map", "values": values}, err
+	}
+	return nil, fmt.Errorf("type %q cannot be exported", _type)
+}
+
+// this private function returns the Decimal of the decimal logical type: its scale, and the bounds its precision allows
+func decimal(precision int, scale int) *validation.Decimal {
+	max := strings.Repeat("9", precision-scale)
+	if max == "" {
+		max = "0"
+	}
+	if scale > 0 {
+		max += "." + strings.Repeat("9", scale)
+	}
+	return &validation.Decimal{Scale: scale, Min: "-" + max, Max: max}
+}
+
+// this private function returns the precision of a Decimal, read from its bounds – 38 digits if it has none
+func precision(dec *validation.Decimal) int {
+	digits := 0
+	for _, bound := range []string{dec.Min, dec.Max} {
+		integer := strings.TrimLeft(strings.SplitN(bound, ".", 2)[0], "-+0")
+		if len(integer) > digits {
+			digits = len(integer)
+		}
+	}
+	if dec.Min == "" && dec.Max == "" {
+		return 38
+	}
+	return digits + dec.Scale
+}
+
+// this private function decodes a default value into the validator type
+func decodeDefault(_type string, raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return value, nil
+	}
+	switch _type {
+	case "int32":
+		var n int32
+		return n, json.Unmarshal([]byte(number), &n)
+	case "int64":
+		return number.Int64()
+	case "float64":
+		return number.Float64()
+	}
+	return value, nil
+}
@@ -0,0 +1,216 @@
+package avro
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/grebett/validation"
+)
+
+const userSchema = `{
+	"type": "record", "name": "User", "namespace": "com.example",
+	"fields": [
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "age", "type": "int"},
+		{"name": "score", "type": "double", "default": 1.5},
+		{"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["ADMIN", "USER"]}, "default": "USER"},
+		{"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 6, "scale": 2}},
+		{"name": "nick", "type": ["null", "string"], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "address", "type": {"type": "record", "name": "Address", "fields": [
+			{"name": "city", "type": "string"},
+			{"name": "zip", "type": ["null", "long"]}
+		]}},
+		{"name": "previous", "type": ["null", "Address"]}
+	]
+}`
+
+func TestImport(t *testing.T) {
+	schema, err := Import([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]validation.Validator{
+		"id":            {Type: "string", Regexp: uuidRegexp, IsRequired: true},
+		"age":           {Type: "int32", IntBoundaries: &validation.IntBoundaries{Min: math.MinInt32, Max: math.MaxInt32}, IsRequired: true},
+		"score":         {Type: "float64"},
+		"role":          {Type: "string", Regexp: "^(ADMIN|USER)$"},
+		"balance":       {Type: "json.Number", Decimal: &validation.Decimal{Scale: 2, Min: "-9999.99", Max: "9999.99"}, IsRequired: true},
+		"nick":          {Type: "string"},
+		"tags":          {Type: "[]string"},
+		"address":       {Type: "map[string]interface {}", IsRequired: true},
+		"address.city":  {Type: "string", IsRequired: true},
+		"address.zip":   {Type: "int64", IntBoundaries: &validation.IntBoundaries{Min: math.MinInt64, Max: math.MaxInt64}},
+		"previous":      {Type: "map[string]interface {}"},
+		"previous.city": {Type: "string"},
+		"previous.zip":  {Type: "int64", IntBoundaries: &validation.IntBoundaries{Min: math.MinInt64, Max: math.MaxInt64}},
+	}
+	if len(schema.Validators) != len(expected) {
+		t.Errorf("expected %d validators, got %d", len(expected), len(schema.Validators))
+	}
+	for path, validator := range schema.Validators {
+		imported := *validator
+		imported.Default = nil
+		if want, ok := expected[path]; !ok || !reflect.DeepEqual(imported, want) {
+			t.Errorf("%s: expected %+v, got %+v", path, want, imported)
+		}
+	}
+
+	dest, errors := schema.Validate(map[string]interface{}{
+		"id":      "123e4567-e89b-12d3-a456-426614174000",
+		"age":     json.Number("36"),
+		"balance": json.Number("12.50"),
+		"address": map[string]interface{}{"city": "London"},
+	}, validation.Options{Usage: validation.INIT})
+	if len(errors) > 0 {
+		t.Fatal(errors)
+	}
+	if dest["score"] != 1.5 || dest["role"] != "USER" || !reflect.DeepEqual(dest["tags"], []interface{}{}) {
+		t.Errorf("expected the defaults, got %v", dest)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	for _, schema := range []string{
+		`"string"`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": ["string", "long"]}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int", "default": 1.5}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "Unknown"}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a"}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": {"type": "map", "values": {"type": "record", "name": "V", "fields": []}}}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": {"type": "array", "items": {"type": "array", "items": {"type": "record", "name": "V", "fields": []}}}}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": {"type": "array", "items": ["null", {"type": "record", "name": "V", "fields": []}]}}]}`,
+		`{"type": "record", "name": "Node", "fields": [{"name": "children", "type": {"type": "array", "items": "Node"}}]}`,
+		`{"type": "record", "name": "Node", "fields": [{"name": "next", "type": ["null", "Node"]}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "at", "type": {"type": "int", "logicalType": "timestamp-millis"}}]}`,
+	} {
+		if _, err := Import([]byte(schema)); err == nil {
+			t.Errorf("expected an error for %s", schema)
+		}
+	}
+}
+
+func TestImportArraysAndTimes(t *testing.T) {
+	schema, err := Import([]byte(`{
+		"type": "record", "name": "Order",
+		"fields": [
+			{"name": "lines", "type": {"type": "array", "items": {"type": "record", "name": "Line", "fields": [
+				{"name": "sku", "type": "string"},
+				{"name": "qty", "type": "int"},
+				{"name": "note", "type": ["null", "string"]}
+			]}}},
+			{"name": "returns", "type": {"type": "array", "items": "Line"}, "default": []},
+			{"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+			{"name": "stamps", "type": {"type": "array", "items": {"type": "long", "logicalType": "timestamp-micros"}}},
+			{"name": "ratio", "type": "float"},
+			{"name": "weight", "type": "double"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := schema.Validators["lines"]
+	if lines.Type != "[]map[string]interface {}" || len(lines.Items) != 3 || !lines.Items["qty"].IsRequired || lines.Items["note"].IsRequired {
+		t.Errorf("expected the lines items validators, got %+v %v", lines, lines.Items)
+	}
+	if returns := schema.Validators["returns"]; len(returns.Items) != 3 || returns.Items["sku"].Type != "string" {
+		t.Errorf("expected the named record items validators, got %+v", returns)
+	}
+	if at, day := schema.Validators["at"], schema.Validators["day"]; at.Type != "int64" || day.Type != "int32" || schema.Validators["stamps"].Type != "[]json.Number" {
+		t.Errorf("expected the times as integers, got %+v %+v", at, day)
+	}
+	if ratio, weight := schema.Validators["ratio"], schema.Validators["weight"]; ratio.Boundaries != float32Boundaries || weight.Boundaries != (validation.Boundaries{}) {
+		t.Errorf("expected the float bounds only, got %+v %+v", ratio.Boundaries, weight.Boundaries)
+	}
+
+	_, errors := schema.Validate(map[string]interface{}{
+		"lines":  []interface{}{map[string]interface{}{"sku": "a", "qty": json.Number("2")}, map[string]interface{}{"sku": 1, "qty": json.Number("1")}},
+		"at":     json.Number("1700000000000"),
+		"day":    json.Number("19000"),
+		"stamps": []interface{}{json.Number("1700000000000000")},
+		"ratio":  1e39,
+		"weight": 1e39,
+	}, validation.Options{Usage: validation.INIT})
+	if len(errors) != 2 || errors[0].Field != "lines.1.sku" || errors[0].Code != "type_mismatch" || errors[1].Field != "ratio" || errors[1].Code != "out_of_boundaries" {
+		t.Errorf("expected the line and ratio errors, got %v", errors)
+	}
+}
+
+func TestExport(t *testing.T) {
+	schema := &validation.Schema{Validators: map[string]*validation.Validator{
+		"name":         {Type: "string", IsRequired: true},
+		"count":        {Type: "int64", Default: func(interface{}) interface{} { return 1 }},
+		"price":        {Type: "string", Decimal: &validation.Decimal{Scale: 2, Min: "0", Max: "9999.99"}, IsRequired: true},
+		"tags":         {Type: "[]string", IsRequired: true},
+		"address":      {Type: "map[string]interface {}", IsRequired: true},
+		"address.city": {Type: "string"},
+	}}
+	data, err := Export(schema, "Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"fields":[` +
+		`{"name":"address","type":{"fields":[{"name":"city","type":["null","string"],"default":null}],"name":"Item_address","type":"record"}},` +
+		`{"name":"count","type":"long","default":1},` +
+		`{"name":"name","type":"string"},` +
+		`{"name":"price","type":{"logicalType":"decimal","precision":6,"scale":2,"type":"bytes"}},` +
+		`{"name":"tags","type":{"items":"string","type":"array"}}` +
+		`],"name":"Item","type":"record"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	// the exported schema is imported back into the same rules
+	imported, err := Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price := imported.Validators["price"]; price.Type != "json.Number" || !reflect.DeepEqual(price.Decimal, &validation.Decimal{Scale: 2, Min: "-9999.99", Max: "9999.99"}) {
+		t.Errorf("unexpected imported price %+v", price)
+	}
+
+	if _, err := Export(&validation.Schema{Validators: map[string]*validation.Validator{"a.b": {Type: "string"}}}, "R"); err == nil {
+		t.Error("expected an error for a path outside a record")
+	}
+	if _, err := Export(&validation.Schema{Validators: map[string]*validation.Validator{"at": {Type: "time.Time"}}}, "R"); err == nil {
+		t.Error("expected an error for a time.Time validator")
+	}
+}
+
+func TestExportArraysOfRecords(t *testing.T) {
+	schema := &validation.Schema{Validators: map[string]*validation.Validator{
+		"lines": {Type: "[]map[string]interface {}", IsRequired: true, Items: map[string]*validation.Validator{
+			"qty": {Type: "int32", IsRequired: true},
+		}},
+		"ratio": {Type: "float64", Boundaries: float32Boundaries, IsRequired: true},
+	}}
+	data, err := Export(schema, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"fields":[` +
+		`{"name":"lines","type":{"items":{"fields":[{"name":"qty","type":"int"}],"name":"Order_lines","type":"record"},"type":"array"}},` +
+		`{"name":"ratio","type":"float"}` +
+		`],"name":"Order","type":"record"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	imported, err := Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := imported.Validators["lines"]; lines.Type != "[]map[string]interface {}" || lines.Items["qty"].Type != "int32" || imported.Validators["ratio"].Boundaries != float32Boundaries {
+		t.Errorf("expected the same rules once imported back, got %+v", imported.Validators)
+	}
+
+	invalid := &validation.Schema{Validators: map[string]*validation.Validator{
+		"lines": {Type: "[]map[string]interface {}", Items: map[string]*validation.Validator{"a.b": {Type: "string"}}},
+	}}
+	if _, err := Export(invalid, "Order"); err == nil || err.Error() != "avro: lines: a.b is not inside a record" {
+		t.Errorf("expected the items path error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// this private function returns a schema of the usual rules, and a valid document for it
+func benchmarkSchema() (*Schema, map[string]interface{}) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":    {Type: "string", Regexp: "^[A-Za-z ]{2,50}$", IsRequired: true},
+		"age":     {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"active":  {Type: "bool"},
+		"tags":    {Type: "[]string"},
+		"address": {Type: "map[string]string"},
+		"city":    {Type: "string", Source: "address.city", Target: "city"},
+	}}
+	document := map[string]interface{}{
+		"name":    "Ada Lovelace",
+		"age":     json.Number("36"),
+		"active":  true,
+		"tags":    []interface{}{"a", "b", "c"},
+		"address": map[string]interface{}{"street": "12 St James's Square", "city": "London"},
+	}
+	return schema, document
+}
+
+func BenchmarkValidate(b *testing.B) {
+	schema, document := benchmarkSchema()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, errors := schema.Validate(document, Options{Usage: INIT}); len(errors) > 0 {
+			b.Fatal(errors)
+		}
+	}
+}
+
+func BenchmarkCompiledValidate(b *testing.B) {
+	schema, document := benchmarkSchema()
+	compiled := MustCompile(schema)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, errors := compiled.Validate(document, Options{Usage: INIT}); len(errors) > 0 {
+				b.Fatal(errors)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct is the rule of a birth date, a "YYYY-MM-DD" string or a time.Time whose date is the one of its submitted offset, so Validator.Location should then be nil
+// the age is the number of birthdays past on the current date in Location: someone born on February 29 turns a year older on March 1 in the common years
+type Birthdate struct {
+	MinAge   int              // the min age, in years
+	MaxAge   int              // the max age, in years, unlimited if 0
+	Location *time.Location   `json:"-"` // the location of the current date, UTC if nil
+	Now      func() time.Time `json:"-"` // this function returns the current time, the one of Options.Now if nil
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the age of someone born on the date, at the time
+func (b *Birthdate) Age(birth time.Time, at time.Time) int {
+	location := b.Location
+	if location == nil {
+		location = time.UTC
+	}
+	year, month, day := at.In(location).Date()
+	birthYear, birthMonth, birthDay := birth.Date()
+	age := year - birthYear
+	if month < birthMonth || month == birthMonth && day < birthDay {
+		age--
+	}
+	return age
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function checks the age of someone born on the value date is within the bounds of the rule; a date in the future is refused whatever the bounds
+func checkBirthdate(validator *Validator, in string, value interface{}, opt Options, errors *[]*DataError) bool {
+	rule := validator.Birthdate
+	var birth time.Time
+	switch value := value.(type) {
+	case time.Time:
+		birth = value
+	case string:
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			*errors = append(*errors, NewError("invalid_format", in, value, map[string]interface{}{"expected": "YYYY-MM-DD"}))
+			return false
+		}
+		birth = parsed
+	default:
+		return true
+	}
+
+	now := currentTime(opt)
+	if rule.Now != nil {
+		now = rule.Now()
+	}
+	age := rule.Age(birth, now)
+	params := map[string]interface{}{"min_age": rule.MinAge, "max_age": rule.MaxAge, "age": age}
+	if age < 0 || age < rule.MinAge {
+		*errors = append(*errors, NewError("too_young", in, value, params))
+		return false
+	}
+	if rule.MaxAge > 0 && age > rule.MaxAge {
+		*errors = append(*errors, NewError("too_old", in, value, params))
+		return false
+	}
+	return true
+}
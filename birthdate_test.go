@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBirthdate(t *testing.T) {
+	rule := &Birthdate{MinAge: 18, MaxAge: 120}
+	schema := &Schema{Validators: map[string]*Validator{
+		"birth":   {Type: "string", Birthdate: rule},
+		"created": {Type: "time.Time", Birthdate: rule},
+	}}
+	tests := []struct {
+		now      string
+		birth    string
+		location *time.Location
+		code     string
+	}{
+		{"2024-02-28T12:00:00Z", "2006-02-28", nil, ""},
+		{"2024-02-27T12:00:00Z", "2006-02-28", nil, "too_young"},
+		{"2024-02-28T23:00:00Z", "2006-02-29", nil, "invalid_format"},
+		{"2024-02-29T00:00:00Z", "2006-03-01", nil, "too_young"},
+		// someone born on February 29 turns a year older on March 1 in the common years
+		{"2026-02-28T00:00:00Z", "2008-02-29", nil, "too_young"},
+		{"2026-03-01T00:00:00Z", "2008-02-29", nil, ""},
+		{"2026-03-01T00:00:00Z", "1900-01-01", nil, "too_old"},
+		{"2026-03-01T00:00:00Z", "2030-01-01", nil, "too_young"},
+		// the current date is the one of the location
+		{"2026-02-28T15:00:00Z", "2008-03-01", time.FixedZone("+10", 10*3600), ""},
+		{"2026-02-28T15:00:00Z", "2008-03-01", nil, "too_young"},
+	}
+	for _, test := range tests {
+		now, _ := time.Parse(time.RFC3339, test.now)
+		rule.Now, rule.Location = func() time.Time { return now }, test.location
+		_, errors := schema.Validate(map[string]interface{}{"birth": test.birth}, Options{Usage: INIT})
+		if test.code == "" && len(errors) > 0 || test.code != "" && (len(errors) != 1 || errors[0].Code != test.code) {
+			t.Errorf("%s on %s: expected %q, got %v", test.birth, test.now, test.code, errors)
+		}
+	}
+
+	// a date in the future is refused whatever the bounds
+	rule.MinAge, rule.Location = 0, nil
+	if _, errors := schema.Validate(map[string]interface{}{"birth": "2030-01-01"}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "too_young" {
+		t.Errorf("expected the future date to be refused, got %v", errors)
+	}
+	// the date of a time is the one of its offset
+	if _, errors := schema.Validate(map[string]interface{}{"created": "2000-01-01T00:30:00+02:00"}, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	invalid := &Schema{Validators: map[string]*Validator{"birth": {Type: "int", Birthdate: &Birthdate{MinAge: 30, MaxAge: 20}}}}
+	if errs := CheckSchema(invalid); len(errs) != 2 {
+		t.Errorf("expected the type and the ages to be reported, got %v", errs)
+	}
+}
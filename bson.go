@@ -0,0 +1,29 @@
+//go:build !js
+
+package validation
+
+import (
+	"gopkg.in/mgo.v2/bson"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function tells whether the string is the hex representation of an ObjectId
+func isObjectIdHex(str string) bool {
+	return bson.IsObjectIdHex(str)
+}
+
+// This function returns the bson.ObjectId of the hex string – see Options.ObjectIdFactory
+func BsonObjectId(hex string) interface{} {
+	return bson.ObjectIdHex(hex)
+}
+
+// this private function returns the hex representation of the value if it is a bson.ObjectId, the value itself otherwise
+func objectIdString(value interface{}) interface{} {
+	if id, ok := value.(bson.ObjectId); ok {
+		return id.Hex()
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+//go:build js
+
+package validation
+
+import (
+	"encoding/hex"
+)
+
+// the js builds (WebAssembly in the browser) do not depend on mgo: the ObjectIds are their hex strings there
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function tells whether the string is the hex representation of an ObjectId
+func isObjectIdHex(str string) bool {
+	if len(str) != 24 {
+		return false
+	}
+	_, err := hex.DecodeString(str)
+	return err == nil
+}
+
+// this private function returns the value: there is no bson.ObjectId in the js builds
+func objectIdString(value interface{}) interface{} {
+	return value
+}
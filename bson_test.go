@@ -0,0 +1,54 @@
+//go:build !js
+
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestObjectIds(t *testing.T) {
+	for str, valid := range map[string]bool{"5f1b2c3d4e5f60718293a4b5": true, "5F1B2C3D4E5F60718293A4B5": true, "5f1b2c3d4e5f60718293a4b": false, "5f1b2c3d4e5f60718293a4bz": false, "": false} {
+		if isObjectIdHex(str) != valid {
+			t.Errorf("%q: expected %v", str, valid)
+		}
+	}
+
+	id := bson.ObjectIdHex("5f1b2c3d4e5f60718293a4b5")
+	if value := objectIdString(id); value != "5f1b2c3d4e5f60718293a4b5" {
+		t.Errorf("expected the hex string, got %v", value)
+	}
+	if value := objectIdString(12); value != 12 {
+		t.Errorf("expected the value itself, got %v", value)
+	}
+}
+
+func TestSerializeObjectId(t *testing.T) {
+	id := bson.NewObjectId()
+	schema := &Schema{Validators: map[string]*Validator{"_id": {Type: "bson.ObjectId"}, "refs": {Type: "[]bson.ObjectId"}}}
+	dest, err := Serialize(schema, map[string]interface{}{"_id": id, "refs": []bson.ObjectId{id}}, USER)
+	if err != nil || dest["_id"] != id.Hex() || dest["refs"].([]interface{})[0] != id.Hex() {
+		t.Errorf("expected the hex strings, got %v %v", dest, err)
+	}
+}
+
+func TestObjectIdFactory(t *testing.T) {
+	hex := "5f1b2c3d4e5f60718293a4b5"
+	schema := &Schema{Validators: map[string]*Validator{"id": {Type: "bson.ObjectId"}, "ids": {Type: "[]bson.ObjectId"}}}
+	document := map[string]interface{}{"id": hex, "ids": []interface{}{hex, bson.ObjectIdHex(hex)}}
+	dest, errors := schema.Validate(document, Options{Usage: INIT, ObjectIdFactory: BsonObjectId})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := map[string]interface{}{"id": bson.ObjectIdHex(hex), "ids": []interface{}{bson.ObjectIdHex(hex), bson.ObjectIdHex(hex)}}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v", expected, dest)
+	}
+
+	// on GET, the values are left as they are
+	if dest, _ := schema.Validate(document, Options{Usage: GET, ObjectIdFactory: BsonObjectId}); dest["id"] != hex {
+		t.Errorf("expected the hex string on GET, got %#v", dest["id"])
+	}
+}
@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This interface stores the verdicts of the expensive custom tests, by test name and value – see CachedTest
+// it must be safe for concurrent use; LRUCache is the in-memory one, and a shared one (e.g. Redis) may be plugged in
+type VerdictCache interface {
+	Get(key string) (Verdict, bool)
+	Set(key string, verdict Verdict)
+}
+
+// This struct is the verdict of a custom test
+type Verdict struct {
+	OK  bool
+	Err *DataError
+}
+
+// This struct is an in-memory VerdictCache evicting the least recently used verdicts, and the ones older than its time to live
+type LRUCache struct {
+	size    int
+	ttl     time.Duration
+	mu      sync.Mutex
+	order   *list.List               // the entries, the most recently used first
+	entries map[string]*list.Element // the elements of the order, by key
+}
+
+// This private struct is an entry of an LRUCache
+type lruEntry struct {
+	key     string
+	value   interface{} // a Verdict, or the outcome of a validation – see OutcomeCache
+	expires time.Time   // zero if the value never expires
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the verdict stored for the key, if not expired
+func (c *LRUCache) Get(key string) (Verdict, bool) {
+	value, ok := c.get(key)
+	if !ok {
+		return Verdict{}, false
+	}
+	return value.(Verdict), true
+}
+
+// This method stores the verdict for the key, evicting the least recently used one if the cache is full
+func (c *LRUCache) Set(key string, verdict Verdict) {
+	c.set(key, verdict)
+}
+
+// This method returns the number of verdicts stored, the expired ones included until they are looked up or evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// this private method returns the value stored for the key, if not expired
+func (c *LRUCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// this private method stores the value for the key, evicting the least recently used one if the cache is full
+func (c *LRUCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expires: expires}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns an in-memory cache of the size, whose verdicts expire after the time to live – never if 0
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	if size <= 0 {
+		panic("validation: LRUCache of a non-positive size")
+	}
+	return &LRUCache{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element, size)}
+}
+
+// This function returns the custom test looking its verdicts up in the cache before running the test, e.g. a referral code lookup
+// the name identifies the test in the cache, which may be shared between tests; the verdicts are stored by value, its type included,
+// but the internal errors – e.g. the test_unavailable verdict of a Guard – and the values which cannot be encoded as JSON are never cached
+func CachedTest(cache VerdictCache, name string, test func(interface{}) (bool, *DataError)) func(interface{}) (bool, *DataError) {
+	return func(value interface{}) (bool, *DataError) {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return test(value)
+		}
+		key := name + "\x00" + typeName(value) + "\x00" + string(encoded)
+		if verdict, ok := cache.Get(key); ok {
+			return verdict.OK, verdict.Err
+		}
+		ok, dataErr := test(value)
+		if dataErr == nil || dataErr.Type != "Internal error" {
+			cache.Set(key, Verdict{OK: ok, Err: dataErr})
+		}
+		return ok, dataErr
+	}
+}
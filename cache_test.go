@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2, 0)
+	cache.Set("a", Verdict{OK: true})
+	cache.Set("b", Verdict{OK: false})
+	cache.Get("a")
+	cache.Set("c", Verdict{OK: true})
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("expected the least recently used verdict to be evicted")
+	}
+	if verdict, ok := cache.Get("a"); !ok || !verdict.OK || cache.Len() != 2 {
+		t.Errorf("expected a to be kept, got %v %v", verdict, ok)
+	}
+
+	expiring := NewLRUCache(2, 10*time.Millisecond)
+	expiring.Set("a", Verdict{OK: true})
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := expiring.Get("a"); ok || expiring.Len() != 0 {
+		t.Errorf("expected the verdict to expire")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a non-positive size to panic")
+		}
+	}()
+	NewLRUCache(0, 0)
+}
+
+func TestCachedTest(t *testing.T) {
+	calls := 0
+	cache := NewLRUCache(10, 0)
+	test := CachedTest(cache, "code", func(value interface{}) (bool, *DataError) {
+		calls++
+		switch value {
+		case "down":
+			return false, NewError("test_unavailable", "", value, nil)
+		case "bad":
+			return false, NewError("custom_test_failed", "", value, nil)
+		}
+		return true, nil
+	})
+	for _, value := range []interface{}{"ok", "ok", "bad", "bad", json.Number("1"), "1", "down", "down", func() {}, func() {}} {
+		test(value)
+	}
+	// "ok", "bad", json.Number("1") and "1" once, and the unavailable and unencodable values every time
+	if calls != 8 {
+		t.Errorf("expected 8 calls, got %d", calls)
+	}
+	if ok, err := test("bad"); ok || err == nil || err.Code != "custom_test_failed" {
+		t.Errorf("expected the cached failure, got %v %v", ok, err)
+	}
+}
@@ -0,0 +1,289 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This public function verifies the schema is consistent, to be run at startup
+// every problem is reported as a *DataError of type "Schema error", the field being the validator path
+func CheckSchema(schema *Schema) []error {
+	return checkSchema(schema, schema)
+}
+
+// this private function verifies the schema is consistent, its references being resolved against the root schema – see CheckSchema
+func checkSchema(schema *Schema, root *Schema) []error {
+	errors := make([]error, 0)
+	report := func(field string, format string, args ...interface{}) {
+		errors = append(errors, &DataError{Type: "Schema error", Reason: fmt.Sprintf(format, args...), Field: field})
+	}
+
+	for _, path := range schema.sortedPaths() {
+		validator := schema.Validators[path]
+		if validator == nil {
+			report(path, "nil validator")
+			continue
+		}
+
+		if _, err := parseType(validator.Type); err != nil {
+			report(path, "invalid type %q: %v", validator.Type, err)
+		}
+		if validator.Regexp != "" {
+			if _, err := compilePattern(validator.Regexp); err != nil {
+				report(path, "invalid regexp: %v", err)
+			}
+		}
+		for i, rule := range validator.Regexps {
+			if _, err := compilePattern(rule.Pattern); err != nil {
+				report(path, "invalid regexp of the rule %d: %v", i, err)
+			}
+			if rule.Mode != MustMatch && rule.Mode != MustNotMatch {
+				report(path, "invalid mode %d of the regexp rule %d", rule.Mode, i)
+			}
+		}
+		if validator.Boundaries.Min > validator.Boundaries.Max {
+			report(path, "boundaries min %v is greater than max %v", validator.Boundaries.Min, validator.Boundaries.Max)
+		}
+		if validator.IntBoundaries != nil && validator.IntBoundaries.Min > validator.IntBoundaries.Max {
+			report(path, "integer boundaries min %d is greater than max %d", validator.IntBoundaries.Min, validator.IntBoundaries.Max)
+		}
+		if validator.Decimal != nil {
+			min, minOk := parseDecimal(validator.Decimal.Min)
+			max, maxOk := parseDecimal(validator.Decimal.Max)
+			if !minOk || !maxOk {
+				report(path, "invalid decimal boundaries %q and %q", validator.Decimal.Min, validator.Decimal.Max)
+			} else if min != nil && max != nil && min.Cmp(max) > 0 {
+				report(path, "decimal min %s is greater than max %s", validator.Decimal.Min, validator.Decimal.Max)
+			}
+		}
+		if validator.Money != nil {
+			for _, currency := range validator.Money.Currencies {
+				if _, ok := currencyMinorUnits[currency]; !ok {
+					report(path, "unknown currency %q", currency)
+				}
+			}
+			for _, amounts := range []map[string]string{validator.Money.Min, validator.Money.Max} {
+				for currency, amount := range amounts {
+					if decimal, ok := parseDecimal(amount); !ok || decimal == nil {
+						report(path, "invalid %s amount boundary %q", currency, amount)
+					}
+				}
+			}
+		}
+		for usage, rights := range validator.Rights {
+			if rights < UNAUTHENTICATED || rights > NONE {
+				report(path, "invalid rights %d for usage %d", rights, usage)
+			}
+		}
+		for i, candidate := range validator.Defaults {
+			if candidate.Value == nil {
+				report(path, "nil Value fn for the conditional default %d", i)
+			}
+		}
+		if validator.Tuple != nil || validator.AdditionalItems != nil {
+			if !strings.HasPrefix(validator.Type, "[]") {
+				report(path, "tuple validators on the non-array type %q", validator.Type)
+			}
+			if validator.Tuple == nil {
+				report(path, "additional items validator without tuple")
+			}
+			// the problems of the positions validators are reported under the item path
+			for _, err := range checkSchema(&Schema{Validators: tupleValidators(validator)}, root) {
+				itemErr := err.(*DataError)
+				itemErr.Field = path + "." + itemErr.Field
+				errors = append(errors, itemErr)
+			}
+		}
+		if validator.UniqueBy != "" && !strings.HasPrefix(validator.Type, "[]") {
+			report(path, "unique key on the non-array type %q", validator.Type)
+		}
+		if validator.SearchQuery != nil {
+			if validator.Type != "string" {
+				report(path, "search query rule on the non-string type %q", validator.Type)
+			}
+			if min, max := validator.SearchQuery.lengths(); min > max {
+				report(path, "search query min length %d is greater than max %d", min, max)
+			}
+		}
+		if validator.Format != "" {
+			if _, ok := formatCheck(validator.Format); !ok {
+				report(path, "unknown format %q", validator.Format)
+			}
+			if validator.Type != "string" {
+				report(path, "format on the non-string type %q", validator.Type)
+			}
+		}
+		if _, integer := integerBits[validator.Type]; validator.LocaleNumbers && !integer && validator.Decimal == nil && validator.Type != "float64" && validator.Type != "json.Number" {
+			report(path, "locale numbers on the non-numeric type %q", validator.Type)
+		}
+		if (validator.TimeWindow != nil || validator.DefaultNow) && validator.Type != "time.Time" {
+			report(path, "time rule on the non-time type %q", validator.Type)
+		}
+		if validator.Birthdate != nil {
+			if validator.Type != "string" && validator.Type != "time.Time" {
+				report(path, "birth date on the non-date type %q", validator.Type)
+			}
+			if validator.Birthdate.MaxAge > 0 && validator.Birthdate.MinAge > validator.Birthdate.MaxAge {
+				report(path, "min age %d is greater than max %d", validator.Birthdate.MinAge, validator.Birthdate.MaxAge)
+			}
+		}
+		if validator.Schedule != nil && !strings.HasPrefix(validator.Type, "map[string]") {
+			report(path, "schedule on the non-document type %q", validator.Type)
+		}
+		if validator.BlockedDomains != nil && validator.Format != "domain" {
+			report(path, "blocked domains with the non-domain format %q", validator.Format)
+		}
+		if validator.HashOf != "" {
+			if _, ok := hashFormats[validator.Format]; !ok {
+				report(path, "hash check with the non-hash format %q", validator.Format)
+			}
+		}
+		if validator.Ordered != nil && !strings.HasPrefix(validator.Type, "[]") {
+			report(path, "order on the non-array type %q", validator.Type)
+		}
+		for rule := range validator.DisabledWhen {
+			if _, ok := flaggableRules[rule]; !ok {
+				report(path, "unknown flagged rule %q", rule)
+			}
+		}
+		if validator.Ref != "" {
+			if resolveRef(root, validator.Ref) == nil {
+				report(path, "unknown schema reference %q", validator.Ref)
+			}
+			if !strings.HasPrefix(validator.Type, "[]") && !strings.HasPrefix(validator.Type, "map[") && validator.Type != "interface {}" {
+				report(path, "schema reference on the non-document type %q", validator.Type)
+			}
+			if validator.Items != nil || validator.Tuple != nil {
+				report(path, "schema reference along with items validators")
+			}
+		}
+		if validator.Items != nil {
+			if !strings.HasPrefix(validator.Type, "[]") {
+				report(path, "items validators on the non-array type %q", validator.Type)
+			}
+			// the problems of the element validators are reported under the array path
+			for _, err := range checkSchema(&Schema{Validators: validator.Items}, root) {
+				itemErr := err.(*DataError)
+				itemErr.Field = path + ".$." + itemErr.Field
+				errors = append(errors, itemErr)
+			}
+		}
+	}
+
+	if _, ok := schema.Validators[schema.VersionField]; ok {
+		report(schema.VersionField, "validator written for the version field")
+	}
+	if validator, ok := schema.Validators[schema.IdempotencyField]; ok && validator != nil && validator.Type != "string" {
+		report(schema.IdempotencyField, "idempotency key of the non-string type %q", validator.Type)
+	}
+
+	if schema.SoftDelete != nil {
+		if _, ok := schema.Validators[schema.SoftDelete.field()]; !ok {
+			report(schema.SoftDelete.field(), "no validator for the soft-delete field")
+		}
+		for _, path := range append(append([]string(nil), schema.SoftDelete.Protected...), schema.SoftDelete.Visible...) {
+			if _, ok := schema.Validators[path]; !ok {
+				report(path, "unknown field in the soft-delete convention")
+			}
+		}
+	}
+
+	for i, groups := range [][][]string{schema.ExclusiveGroups, schema.AnyOfGroups} {
+		kind := [...]string{"exclusive", "any-of"}[i]
+		for _, group := range groups {
+			if len(group) < 2 {
+				report("", "%s group %v of less than two fields", kind, group)
+			}
+			required := 0
+			for _, path := range group {
+				validator, ok := schema.Validators[path]
+				if !ok {
+					report(path, "unknown field in the %s group %v", kind, group)
+				} else if validator != nil && validator.IsRequired {
+					required++
+				}
+			}
+			if kind == "exclusive" && required > 1 {
+				report("", "exclusive group %v of several required fields", group)
+			}
+		}
+	}
+
+	for path, required := range schema.DependentRules {
+		if _, ok := schema.Validators[path]; !ok {
+			report(path, "unknown field in the dependent rules")
+		}
+		for _, dependent := range required {
+			if _, ok := schema.Validators[dependent]; !ok {
+				report(dependent, "unknown field required by %q", path)
+			}
+		}
+	}
+	for path, validators := range schema.DependentValidators {
+		if _, ok := schema.Validators[path]; !ok {
+			report(path, "unknown field in the dependent validators")
+		}
+		errors = append(errors, checkSchema(&Schema{Validators: validators}, root)...)
+	}
+
+	if schema == root {
+		for name, definition := range schema.Definitions {
+			if definition == nil {
+				report("", "nil definition %q", name)
+				continue
+			}
+			// the problems of the definitions are reported under their name
+			for _, err := range checkSchema(&Schema{Validators: definition.Validators}, root) {
+				definitionErr := err.(*DataError)
+				definitionErr.Field = "#" + name + "." + definitionErr.Field
+				errors = append(errors, definitionErr)
+			}
+		}
+	}
+
+	for path, fn := range schema.Computed {
+		if fn == nil {
+			report(path, "nil computed fn")
+		}
+	}
+
+	for name, profile := range schema.Profiles {
+		if profile == nil {
+			report("", "nil profile %q", name)
+			continue
+		}
+		if profile.Usage < INIT || profile.Usage > SET {
+			report("", "invalid usage %d for profile %q", profile.Usage, name)
+		}
+		for _, path := range profile.Fields {
+			if _, ok := schema.Validators[path]; !ok {
+				report(path, "unknown field in profile %q", name)
+			}
+		}
+		for path := range profile.Rights {
+			if _, ok := schema.Validators[path]; !ok {
+				report(path, "unknown field in the rights of profile %q", name)
+			}
+		}
+	}
+
+	return errors
+}
+
+// this private function tells whether the string is a Go identifier
+func isIdentifier(str string) bool {
+	if str == "" {
+		return false
+	}
+	for i, r := range str {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSchema(t *testing.T) {
+	schema := &Schema{
+		Validators: map[string]*Validator{
+			"a":    {Type: "x y", Regexp: "(", Boundaries: Boundaries{Min: 2, Max: 1}, Rights: [3]int{9}},
+			"b":    nil,
+			"good": {Type: "string", Regexp: "^[a-z]+$", Boundaries: Boundaries{Min: 0, Max: 1}},
+		},
+		Profiles: map[string]*Profile{"p": {Usage: 5, Fields: []string{"z"}}},
+	}
+	errors := CheckSchema(schema)
+	expected := []string{"invalid type", "invalid regexp", "boundaries min 2", "invalid rights 9", "nil validator", "invalid usage 5", "unknown field in profile"}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for i, err := range errors {
+		if !strings.Contains(err.Error(), expected[i]) {
+			t.Errorf("error %d: expected %q, got %v", i, expected[i], err)
+		}
+	}
+
+	if _, err := schema.Compile(); err == nil || !strings.Contains(err.Error(), "invalid type") {
+		t.Errorf("expected Compile to refuse the schema, got %v", err)
+	}
+	if errors := CheckSchema(&Schema{Validators: map[string]*Validator{"good": schema.Validators["good"]}}); len(errors) > 0 {
+		t.Errorf("expected no error, got %v", errors)
+	}
+}
+
+func TestCheckTypeAny(t *testing.T) {
+	validators := map[string]*Validator{"any": {Type: "interface {}"}}
+	for _, value := range []interface{}{"a", true, []interface{}{1}, map[string]interface{}{"a": 1}} {
+		if _, errors := Validate(validators, map[string]interface{}{"any": value}, Options{Usage: INIT}); len(errors) > 0 {
+			t.Errorf("%v: expected no errors, got %v", value, errors)
+		}
+	}
+}
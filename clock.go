@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct bounds a time relatively to the current one of Options.Now, e.g. an event in the past, or an appointment within the next 90 days
+type TimeWindow struct {
+	NotPast   bool          // the time cannot be before the current one
+	NotFuture bool          // the time cannot be after the current one
+	MaxPast   time.Duration // how long before the current time the time can be, unlimited if 0
+	MaxFuture time.Duration // how long after the current time the time can be, unlimited if 0
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the earliest and latest times of the window at the current time, the zero time when unbounded
+func (w *TimeWindow) Bounds(now time.Time) (time.Time, time.Time) {
+	var earliest, latest time.Time
+	if w.NotPast {
+		earliest = now
+	} else if w.MaxPast > 0 {
+		earliest = now.Add(-w.MaxPast)
+	}
+	if w.NotFuture {
+		latest = now
+	} else if w.MaxFuture > 0 {
+		latest = now.Add(w.MaxFuture)
+	}
+	return earliest, latest
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the current time of the validation – see Options.Now
+func currentTime(opt Options) time.Time {
+	if opt.Now != nil {
+		return opt.Now()
+	}
+	return time.Now()
+}
+
+// this private function checks the time is within the window of the validator at the current time
+func checkTimeWindow(validator *Validator, in string, value interface{}, opt Options, errors *[]*DataError) bool {
+	t, ok := value.(time.Time)
+	if !ok {
+		return true
+	}
+	earliest, latest := validator.TimeWindow.Bounds(currentTime(opt))
+	params := map[string]interface{}{"min": earliest, "max": latest}
+	if !earliest.IsZero() && t.Before(earliest) {
+		*errors = append(*errors, NewError("too_early", in, value, params))
+		return false
+	}
+	if !latest.IsZero() && t.After(latest) {
+		*errors = append(*errors, NewError("too_late", in, value, params))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptionsNow(t *testing.T) {
+	fixed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	options := Options{Usage: INIT, Now: func() time.Time { return fixed }}
+	schema := &Schema{Validators: map[string]*Validator{
+		"created":     {Type: "time.Time", DefaultNow: true},
+		"appointment": {Type: "time.Time", TimeWindow: &TimeWindow{NotPast: true, MaxFuture: 24 * time.Hour}},
+		"born":        {Type: "string", Birthdate: &Birthdate{MinAge: 18}},
+		"lines":       {Type: "[]map[string]interface {}", Items: map[string]*Validator{"at": {Type: "time.Time", DefaultNow: true}}},
+	}}
+
+	// the clock reaches the items too
+	document := map[string]interface{}{"appointment": "2026-03-01T18:00:00Z", "born": "2008-03-01", "lines": []interface{}{map[string]interface{}{}}}
+	dest, errors := schema.Validate(document, options)
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if dest["created"] != fixed || dest["lines"].([]interface{})[0].(map[string]interface{})["at"] != fixed {
+		t.Errorf("expected the defaults at the fixed time, got %v", dest)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{"appointment": "2026-03-02T18:00:00Z", "born": "2008-03-02"}, options)
+	if len(errors) != 2 || errors[0].Code != "too_late" || errors[1].Code != "too_young" {
+		t.Errorf("expected too late and too young, got %v", errors)
+	}
+	_, errors = schema.Validate(map[string]interface{}{"appointment": "2026-03-01T11:00:00Z"}, options)
+	if len(errors) != 1 || errors[0].Code != "too_early" {
+		t.Errorf("expected too early, got %v", errors)
+	}
+
+	if errs := CheckSchema(&Schema{Validators: map[string]*Validator{"created": {Type: "string", DefaultNow: true}}}); len(errs) != 1 {
+		t.Errorf("expected the non-time type to be reported, got %v", errs)
+	}
+}
+
+func TestTimeWindowBounds(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	earliest, latest := (&TimeWindow{MaxPast: time.Hour, NotFuture: true}).Bounds(now)
+	if !earliest.Equal(now.Add(-time.Hour)) || !latest.Equal(now) {
+		t.Errorf("unexpected bounds %v and %v", earliest, latest)
+	}
+	if earliest, latest := (&TimeWindow{}).Bounds(now); !earliest.IsZero() || !latest.IsZero() {
+		t.Errorf("expected an unbounded window, got %v and %v", earliest, latest)
+	}
+}
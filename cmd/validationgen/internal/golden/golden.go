@@ -0,0 +1,5 @@
+// Package golden holds the validators generated from the schemas of the validationgen tests
+// the generated file is compiled with the module and checked against the engine by the tests
+package golden
+
+//go:generate go run ../.. -package golden -o validation_gen.go ../../testdata/schemas.json
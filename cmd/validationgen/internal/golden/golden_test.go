@@ -0,0 +1,71 @@
+package golden
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grebett/validation"
+)
+
+func TestParity(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/schemas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schemas map[string]*validation.Schema
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		t.Fatal(err)
+	}
+	generated := map[string]func(map[string]interface{}) validation.DataErrors{"User": ValidateUser, "Event": ValidateEvent}
+
+	tests := []struct {
+		schema string
+		doc    string
+	}{
+		{"User", `{"name": "Ada", "active": true, "address": {"city": "London"}}`},
+		{"User", `{"name": "ada", "active": "yes", "address": {}}`},
+		{"User", `{"address": "London"}`},
+		{"User", `{"name": "Ada", "active": false, "address": {"city": 1}, "id": "5f1b2c3d4e5f60718293a4b5"}`},
+		{"User", `{"name": "Ada", "active": true, "id": "xyz", "age": 121, "visits": 0}`},
+		{"User", `{"name": "Ada", "active": true, "age": 300, "visits": -1}`},
+		{"User", `{"name": "Ada", "active": true, "age": 1.5, "visits": 1001}`},
+		{"User", `{"name": "Ada", "active": true, "score": 1.5, "weight": 1e300, "balance": -1}`},
+		{"User", `{"name": "Ada", "active": true, "score": -0.5, "balance": 12.5}`},
+		{"User", `{"name": "Ada", "active": true, "score": "high", "weight": true, "balance": "1"}`},
+		{"User", `{"name": "Ada", "active": true, "extra": "y", "tags": ["a", 1], "ratios": [0.5, "x"]}`},
+		{"User", `{"name": "Ada", "active": true, "extra": 11, "tags": [], "ratios": "x"}`},
+		{"User", `{"name": "Ada", "active": true, "extra": {"a": 1}, "tags": "a"}`},
+		{"User", `{"name": "Ada", "active": true, "items": [1, "a", null]}`},
+		{"User", `{"name": "Ada", "active": true, "items": {"a": 1}}`},
+		{"Event", `{"at": 1700000000, "payload": {"a": 1}}`},
+		{"Event", `{"at": -1, "payload": 1}`},
+		{"Event", `{"at": "now", "payload": "x"}`},
+		{"Event", `{}`},
+	}
+	for _, test := range tests {
+		for _, useNumber := range []bool{true, false} {
+			var doc map[string]interface{}
+			decoder := json.NewDecoder(strings.NewReader(test.doc))
+			if useNumber {
+				decoder.UseNumber()
+			}
+			if err := decoder.Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			_, expected := validation.Validate(schemas[test.schema].Validators, doc, validation.Options{Usage: validation.INIT})
+			got := generated[test.schema](doc)
+			if len(got) != len(expected) {
+				t.Errorf("%s %s (UseNumber %v): expected %v, got %v", test.schema, test.doc, useNumber, expected, got)
+				continue
+			}
+			for i := range got {
+				if got[i].Error() != expected[i].Error() || got[i].Code != expected[i].Code || !reflect.DeepEqual(got[i].Params, expected[i].Params) || !reflect.DeepEqual(got[i].Path, expected[i].Path) {
+					t.Errorf("%s %s (UseNumber %v): expected %#v, got %#v", test.schema, test.doc, useNumber, expected[i], got[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,428 @@
+// Code generated by validationgen; DO NOT EDIT.
+
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/grebett/validation"
+)
+
+// the patterns of the validators, compiled once
+var (
+	vgRegexp0 = regexp.MustCompile("^x")
+	vgRegexp1 = regexp.MustCompile("^[A-Z][a-z]+$")
+)
+
+// ValidateEvent validates the document against the Event schema, reporting the errors validation.Validate reports on INIT
+func ValidateEvent(doc map[string]interface{}) validation.DataErrors {
+	var errors validation.DataErrors
+
+	// at
+	if value, ok := vgRead(doc, "at"); !ok {
+		errors = append(errors, vgError("type_mismatch", "at", nil, map[string]interface{}{"expected": "int64"}))
+	} else if vgIsEmpty(value) {
+		errors = append(errors, vgError("required", "at", nil, nil))
+	} else {
+		var n int64
+		var code string
+		switch v := value.(type) {
+		case int64:
+			n = int64(v)
+		case json.Number, float64:
+			n, code = vgInt(v, 64)
+		default:
+			code = "type_mismatch"
+		}
+		if code == "out_of_range" {
+			errors = append(errors, vgError("out_of_range", "at", value, map[string]interface{}{"type": "int64"}))
+		} else if code != "" {
+			errors = append(errors, vgError("type_mismatch", "at", vgMismatchValue(value), map[string]interface{}{"expected": "int64"}))
+		} else if float64(n) < float64(0) || float64(n) > float64(4.1024448e+09) {
+			errors = append(errors, vgError("out_of_boundaries", "at", int64(n), map[string]interface{}{"min": float64(0), "max": float64(4.1024448e+09)}))
+		}
+	}
+
+	// payload
+	if value, ok := vgRead(doc, "payload"); !ok {
+		errors = append(errors, vgError("type_mismatch", "payload", nil, map[string]interface{}{"expected": "interface {}"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if number, ok := value.(json.Number); ok {
+			if f, _ := number.Float64(); f < float64(0) || f > float64(0) {
+				errors = append(errors, vgError("out_of_boundaries", "payload", number, map[string]interface{}{"min": float64(0), "max": float64(0)}))
+			}
+		}
+	}
+	return errors
+}
+
+// ValidateUser validates the document against the User schema, reporting the errors validation.Validate reports on INIT
+func ValidateUser(doc map[string]interface{}) validation.DataErrors {
+	var errors validation.DataErrors
+
+	// active
+	if value, ok := vgRead(doc, "active"); !ok {
+		errors = append(errors, vgError("type_mismatch", "active", nil, map[string]interface{}{"expected": "bool"}))
+	} else if vgIsEmpty(value) {
+		errors = append(errors, vgError("required", "active", nil, nil))
+	} else {
+		if _, ok := value.(bool); !ok {
+			errors = append(errors, vgError("type_mismatch", "active", vgTypeName(value), map[string]interface{}{"expected": "bool"}))
+		}
+	}
+
+	// address
+	if value, ok := vgRead(doc, "address"); !ok {
+		errors = append(errors, vgError("type_mismatch", "address", nil, map[string]interface{}{"expected": "map[string]interface {}"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if _, ok := value.(map[string]interface{}); !ok {
+			errors = append(errors, vgError("type_mismatch", "address", vgTypeName(value), map[string]interface{}{"expected": "map[string]interface {}"}))
+		}
+	}
+
+	// address.city
+	if value, ok := vgRead(doc, "address", "city"); !ok {
+		errors = append(errors, vgError("type_mismatch", "address.city", nil, map[string]interface{}{"expected": "string"}))
+	} else if vgIsEmpty(value) {
+		errors = append(errors, vgError("required", "address.city", nil, nil))
+	} else {
+		if _, ok := value.(string); !ok {
+			errors = append(errors, vgError("type_mismatch", "address.city", vgTypeName(value), map[string]interface{}{"expected": "string"}))
+		}
+	}
+
+	// age
+	if value, ok := vgRead(doc, "age"); !ok {
+		errors = append(errors, vgError("type_mismatch", "age", nil, map[string]interface{}{"expected": "int8"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		var n int64
+		var code string
+		switch v := value.(type) {
+		case int8:
+			n = int64(v)
+		case json.Number, float64:
+			n, code = vgInt(v, 8)
+		default:
+			code = "type_mismatch"
+		}
+		if code == "out_of_range" {
+			errors = append(errors, vgError("out_of_range", "age", value, map[string]interface{}{"type": "int8"}))
+		} else if code != "" {
+			errors = append(errors, vgError("type_mismatch", "age", vgMismatchValue(value), map[string]interface{}{"expected": "int8"}))
+		} else if float64(n) < float64(0) || float64(n) > float64(120) {
+			errors = append(errors, vgError("out_of_boundaries", "age", int8(n), map[string]interface{}{"min": float64(0), "max": float64(120)}))
+		}
+	}
+
+	// balance
+	if value, ok := vgRead(doc, "balance"); !ok {
+		errors = append(errors, vgError("type_mismatch", "balance", nil, map[string]interface{}{"expected": "json.Number"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if number, ok := value.(json.Number); !ok {
+			errors = append(errors, vgError("type_mismatch", "balance", vgTypeName(value), map[string]interface{}{"expected": "json.Number"}))
+		} else if f, _ := number.Float64(); f < float64(0) || f > float64(1e+06) {
+			errors = append(errors, vgError("out_of_boundaries", "balance", number, map[string]interface{}{"min": float64(0), "max": float64(1e+06)}))
+		}
+	}
+
+	// extra
+	if value, ok := vgRead(doc, "extra"); !ok {
+		errors = append(errors, vgError("type_mismatch", "extra", nil, map[string]interface{}{"expected": "interface {}"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if str, ok := value.(string); ok && !vgRegexp0.MatchString(str) {
+			errors = append(errors, vgError("regex_not_match", "extra", str, map[string]interface{}{"pattern": "^x"}))
+		}
+		if number, ok := value.(json.Number); ok {
+			if f, _ := number.Float64(); f < float64(0) || f > float64(10) {
+				errors = append(errors, vgError("out_of_boundaries", "extra", number, map[string]interface{}{"min": float64(0), "max": float64(10)}))
+			}
+		}
+		if f, ok := value.(float64); ok && (f < float64(0) || f > float64(10)) {
+			errors = append(errors, vgError("out_of_boundaries", "extra", f, map[string]interface{}{"min": float64(0), "max": float64(10)}))
+		}
+	}
+
+	// id
+	if value, ok := vgRead(doc, "id"); !ok {
+		errors = append(errors, vgError("type_mismatch", "id", nil, map[string]interface{}{"expected": "bson.ObjectId"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if str, ok := value.(string); (!ok || !vgIsObjectIdHex(str)) && vgTypeName(value) != "bson.ObjectId" {
+			errors = append(errors, vgError("type_mismatch", "id", vgTypeName(value), map[string]interface{}{"expected": "bson.ObjectId"}))
+		}
+	}
+
+	// items
+	if value, ok := vgRead(doc, "items"); !ok {
+		errors = append(errors, vgError("type_mismatch", "items", nil, map[string]interface{}{"expected": "[]interface {}"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		switch items := value.(type) {
+		case []interface{}:
+			_ = items
+		default:
+			errors = append(errors, vgError("type_mismatch", "items", vgTypeName(value), map[string]interface{}{"expected": "[]interface {}"}))
+		}
+	}
+
+	// name
+	if value, ok := vgRead(doc, "name"); !ok {
+		errors = append(errors, vgError("type_mismatch", "name", nil, map[string]interface{}{"expected": "string"}))
+	} else if vgIsEmpty(value) {
+		errors = append(errors, vgError("required", "name", nil, nil))
+	} else {
+		if str, ok := value.(string); !ok {
+			errors = append(errors, vgError("type_mismatch", "name", vgTypeName(value), map[string]interface{}{"expected": "string"}))
+		} else if ok && !vgRegexp1.MatchString(str) {
+			errors = append(errors, vgError("regex_not_match", "name", str, map[string]interface{}{"pattern": "^[A-Z][a-z]+$"}))
+		}
+	}
+
+	// ratios
+	if value, ok := vgRead(doc, "ratios"); !ok {
+		errors = append(errors, vgError("type_mismatch", "ratios", nil, map[string]interface{}{"expected": "[]float64"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		switch items := value.(type) {
+		case []float64:
+		case []interface{}:
+			for i, item := range items {
+				if itemType := vgTypeName(item); itemType != "float64" {
+					errors = append(errors, vgErrorAt("type_mismatch", "ratios", i, "[] contains "+itemType, map[string]interface{}{"expected": "[]float64"}))
+					break
+				}
+			}
+		default:
+			errors = append(errors, vgError("type_mismatch", "ratios", vgTypeName(value), map[string]interface{}{"expected": "[]float64"}))
+		}
+	}
+
+	// score
+	if value, ok := vgRead(doc, "score"); !ok {
+		errors = append(errors, vgError("type_mismatch", "score", nil, map[string]interface{}{"expected": "float64"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		f, ok := value.(float64)
+		if number, isNumber := value.(json.Number); isNumber {
+			var err error
+			if f, err = number.Float64(); err != nil {
+				errors = append(errors, vgError("type_mismatch", "score", vgTypeName(value), map[string]interface{}{"expected": "float64"}))
+			}
+			ok = err == nil
+		} else if !ok {
+			errors = append(errors, vgError("type_mismatch", "score", vgTypeName(value), map[string]interface{}{"expected": "float64"}))
+		}
+		if ok && (f < float64(-1) || f > float64(1)) {
+			errors = append(errors, vgError("out_of_boundaries", "score", f, map[string]interface{}{"min": float64(-1), "max": float64(1)}))
+		}
+	}
+
+	// tags
+	if value, ok := vgRead(doc, "tags"); !ok {
+		errors = append(errors, vgError("type_mismatch", "tags", nil, map[string]interface{}{"expected": "[]string"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		switch items := value.(type) {
+		case []string:
+		case []interface{}:
+			for i, item := range items {
+				if itemType := vgTypeName(item); itemType != "string" {
+					errors = append(errors, vgErrorAt("type_mismatch", "tags", i, "[] contains "+itemType, map[string]interface{}{"expected": "[]string"}))
+					break
+				}
+			}
+		default:
+			errors = append(errors, vgError("type_mismatch", "tags", vgTypeName(value), map[string]interface{}{"expected": "[]string"}))
+		}
+	}
+
+	// visits
+	if value, ok := vgRead(doc, "visits"); !ok {
+		errors = append(errors, vgError("type_mismatch", "visits", nil, map[string]interface{}{"expected": "uint16"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		var n uint64
+		var code string
+		switch v := value.(type) {
+		case uint16:
+			n = uint64(v)
+		case json.Number, float64:
+			n, code = vgUint(v, 16)
+		default:
+			code = "type_mismatch"
+		}
+		if code == "out_of_range" {
+			errors = append(errors, vgError("out_of_range", "visits", value, map[string]interface{}{"type": "uint16"}))
+		} else if code != "" {
+			errors = append(errors, vgError("type_mismatch", "visits", vgMismatchValue(value), map[string]interface{}{"expected": "uint16"}))
+		} else if n > 1000 || n < 1 {
+			errors = append(errors, vgError("out_of_boundaries", "visits", uint16(n), map[string]interface{}{"min": int64(1), "max": int64(1000)}))
+		}
+	}
+
+	// weight
+	if value, ok := vgRead(doc, "weight"); !ok {
+		errors = append(errors, vgError("type_mismatch", "weight", nil, map[string]interface{}{"expected": "float64"}))
+	} else if vgIsEmpty(value) {
+	} else {
+		if number, ok := value.(json.Number); ok {
+			if _, err := number.Float64(); err != nil {
+				errors = append(errors, vgError("type_mismatch", "weight", vgTypeName(value), map[string]interface{}{"expected": "float64"}))
+			}
+		} else if _, ok := value.(float64); !ok {
+			errors = append(errors, vgError("type_mismatch", "weight", vgTypeName(value), map[string]interface{}{"expected": "float64"}))
+		}
+	}
+	return errors
+}
+
+// vgRead returns the value at the path, false if the path goes through a value which is not a document
+func vgRead(doc map[string]interface{}, keys ...string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, key := range keys {
+		if value == nil {
+			return nil, true
+		}
+		parent, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value = parent[key]
+	}
+	return value, true
+}
+
+// vgIsEmpty tells whether the value is missing for the engine: nil or an empty array
+func vgIsEmpty(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(value) == 0
+	case []string:
+		return len(value) == 0
+	case []bool:
+		return len(value) == 0
+	case []float64:
+		return len(value) == 0
+	case []json.Number:
+		return len(value) == 0
+	case []map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// vgTypeName returns the type of the value as written in the validators
+func vgTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return "string"
+	case json.Number:
+		return "json.Number"
+	case bool:
+		return "bool"
+	case float64:
+		return "float64"
+	case map[string]interface{}:
+		return "map[string]interface {}"
+	case []interface{}:
+		return "[]interface {}"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// vgIsObjectIdHex tells whether the string is the hex form of an ObjectId
+func vgIsObjectIdHex(str string) bool {
+	if len(str) != 24 {
+		return false
+	}
+	for _, r := range str {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// vgError returns the error of the code about the field
+func vgError(code string, field string, value interface{}, params map[string]interface{}) *validation.DataError {
+	err := validation.NewError(code, field, value, params)
+	err.Path = validation.ParsePath(field)
+	return err
+}
+
+// vgErrorAt returns the error of the code about an item of the field
+func vgErrorAt(code string, field string, index int, value interface{}, params map[string]interface{}) *validation.DataError {
+	err := vgError(code, field, value, params)
+	err.Path = append(err.Path, validation.PathSegment{Index: index, IsIndex: true})
+	return err
+}
+
+// vgMismatchValue returns the value of a type mismatch on an integer, as reported by the engine
+func vgMismatchValue(value interface{}) interface{} {
+	switch value.(type) {
+	case json.Number:
+		return "json.Number"
+	case float64:
+		return "float64"
+	}
+	return vgTypeName(value)
+}
+
+// vgInt converts a json.Number or a float64 into a signed integer of the bit size, or returns the code of the failure
+func vgInt(value interface{}, bits int) (int64, string) {
+	switch number := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseInt(string(number), 10, bits)
+		if numError, ok := err.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return 0, "out_of_range"
+		} else if err != nil {
+			return 0, "type_mismatch"
+		}
+		return n, ""
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return 0, "type_mismatch"
+		}
+		if number < -math.Ldexp(1, bits-1) || number >= math.Ldexp(1, bits-1) {
+			return 0, "out_of_range"
+		}
+		return int64(number), ""
+	}
+	return 0, "type_mismatch"
+}
+
+// vgUint converts a json.Number or a float64 into an unsigned integer of the bit size, or returns the code of the failure
+func vgUint(value interface{}, bits int) (uint64, string) {
+	switch number := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseUint(string(number), 10, bits)
+		if numError, ok := err.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return 0, "out_of_range"
+		} else if err != nil {
+			return 0, "type_mismatch"
+		}
+		return n, ""
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return 0, "type_mismatch"
+		}
+		if number < 0 || number >= math.Ldexp(1, bits) {
+			return 0, "out_of_range"
+		}
+		return uint64(number), ""
+	}
+	return 0, "type_mismatch"
+}
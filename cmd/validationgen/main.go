@@ -0,0 +1,554 @@
+// Command validationgen generates static validation functions from schema definitions, for the hot paths where the reflective engine is too slow
+// it can also generate the TypeScript interfaces and zod schemas of the same definitions, so the front-end shares the contract
+//
+// Usage:
+//
+//	validationgen -package models -o validation_gen.go -ts contract.ts schemas.json...
+//
+// each input file is a JSON object of named schemas, written as validation.Schema values: {"User": {"Validators": {"name": {"Type": "string", "IsRequired": true}}}}
+// every schema gets a function ValidateUser(doc map[string]interface{}) validation.DataErrors, reporting the errors validation.Validate would report on INIT
+// the generated checks are direct type switches, precompiled regexps and inlined boundaries; the document is neither modified nor copied
+// the rules that cannot be written in JSON or that need the engine (Decimal, Money, RawJSON, Regexps, Source, Target, Rights, Immutable, Items, Tuple, UniqueBy, Ordered, Ref, Format, HashOf, BlockedDomains,
+// Boolish, LocaleNumbers, SearchQuery, Nullable, RequiredOnSet, Schedule, Birthdate, TimeWindow) are refused
+// the helpers of the generated file are unexported: a package holds a single generated file, whose schemas are all generated at once
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/grebett/validation"
+	_ "github.com/grebett/validation/patterns"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct writes the generated file
+type generator struct {
+	body    bytes.Buffer
+	regexps []string // the patterns, compiled once in package variables
+	usesInt bool     // whether the integer helpers are needed
+}
+
+// the bit sizes of the integers, by validator type – the ones of int and uint are strconv.IntSize in the generated code
+var integerTypes = map[string]struct {
+	bits     int
+	unsigned bool
+}{
+	"int": {64, false}, "int8": {8, false}, "int16": {16, false}, "int32": {32, false}, "int64": {64, false},
+	"uint": {64, true}, "uint8": {8, true}, "uint16": {16, true}, "uint32": {32, true}, "uint64": {64, true},
+}
+
+// the item types the generated code checks inside the arrays
+var sliceItemTypes = map[string]bool{
+	"string": true, "bool": true, "float64": true, "json.Number": true, "interface {}": true, "map[string]interface {}": true,
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method writes the validation function of a schema
+func (g *generator) schema(name string, schema *validation.Schema) error {
+	paths := make([]string, 0, len(schema.Validators))
+	for path := range schema.Validators {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fmt.Fprintf(&g.body, "\n// Validate%s validates the document against the %s schema, reporting the errors validation.Validate reports on INIT\n", name, name)
+	fmt.Fprintf(&g.body, "func Validate%s(doc map[string]interface{}) validation.DataErrors {\n\tvar errors validation.DataErrors\n", name)
+	for _, path := range paths {
+		if err := g.field(path, schema.Validators[path]); err != nil {
+			return fmt.Errorf("%s: %s: %v", name, path, err)
+		}
+	}
+	g.body.WriteString("\treturn errors\n}\n")
+	return nil
+}
+
+// This method writes the checks of a field
+func (g *generator) field(path string, validator *validation.Validator) error {
+	switch {
+	case validator.Decimal != nil, validator.Money != nil, validator.RawJSON:
+		return fmt.Errorf("decimal, money and raw JSON validators need the engine")
+	case len(validator.Regexps) > 0:
+		return fmt.Errorf("regexp rules need the engine")
+	case validator.Source != "", validator.Target != "", validator.Immutable, validator.Rights != [3]int{}:
+		return fmt.Errorf("renamed, immutable and rights-restricted fields need the engine")
+	case validator.Tuple != nil, validator.AdditionalItems != nil, validator.UniqueBy != "", validator.Ordered != nil:
+		return fmt.Errorf("tuple, unique key and ordered validators need the engine")
+	case validator.Format != "", validator.HashOf != "", validator.BlockedDomains != nil:
+		return fmt.Errorf("format and hash validators need the engine")
+	case validator.Items != nil, validator.Ref != "":
+		return fmt.Errorf("items and referenced schema validators need the engine")
+	case validator.Boolish, validator.LocaleNumbers, validator.SearchQuery != nil:
+		return fmt.Errorf("lenient parsing and search query validators need the engine")
+	case validator.Nullable, validator.RequiredOnSet:
+		return fmt.Errorf("nullable and required on SET fields need the engine")
+	case validator.Schedule != nil, validator.Birthdate != nil, validator.TimeWindow != nil:
+		return fmt.Errorf("schedule, birth date and time validators need the engine")
+	}
+
+	keys := make([]string, 0)
+	for _, segment := range validation.ParsePath(path) {
+		keys = append(keys, fmt.Sprintf("%q", segment.Key))
+	}
+	expected := fmt.Sprintf("map[string]interface{}{\"expected\": %q}", validator.Type)
+
+	fmt.Fprintf(&g.body, "\n\t// %s\n", path)
+	fmt.Fprintf(&g.body, "\tif value, ok := vgRead(doc, %s); !ok {\n", strings.Join(keys, ", "))
+	fmt.Fprintf(&g.body, "\t\terrors = append(errors, vgError(\"type_mismatch\", %q, nil, %s))\n", path, expected)
+	g.body.WriteString("\t} else if vgIsEmpty(value) {\n")
+	if validator.IsRequired {
+		fmt.Fprintf(&g.body, "\t\terrors = append(errors, vgError(\"required\", %q, nil, nil))\n", path)
+	}
+	g.body.WriteString("\t} else {\n")
+	if err := g.check(path, validator, expected); err != nil {
+		return err
+	}
+	g.body.WriteString("\t}\n")
+	return nil
+}
+
+// This method writes the checks of a present value, held by the variable value
+func (g *generator) check(path string, validator *validation.Validator, expected string) error {
+	mismatch := fmt.Sprintf("errors = append(errors, vgError(\"type_mismatch\", %q, vgTypeName(value), %s))", path, expected)
+	_type := validator.Type
+
+	if integer, ok := integerTypes[_type]; ok {
+		g.usesInt = true
+		kind, helper, variable := "int64", "vgInt", "n"
+		if integer.unsigned {
+			kind, helper = "uint64", "vgUint"
+		}
+		fmt.Fprintf(&g.body, "\t\tvar n %s\n\t\tvar code string\n\t\tswitch v := value.(type) {\n", kind)
+		fmt.Fprintf(&g.body, "\t\tcase %s:\n\t\t\tn = %s(v)\n", _type, kind)
+		bits := fmt.Sprint(integer.bits)
+		if _type == "int" || _type == "uint" {
+			bits = "strconv.IntSize"
+		}
+		fmt.Fprintf(&g.body, "\t\tcase json.Number, float64:\n\t\t\tn, code = %s(v, %s)\n", helper, bits)
+		g.body.WriteString("\t\tdefault:\n\t\t\tcode = \"type_mismatch\"\n\t\t}\n")
+		g.body.WriteString("\t\tif code == \"out_of_range\" {\n")
+		fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"out_of_range\", %q, value, map[string]interface{}{\"type\": %q}))\n", path, _type)
+		g.body.WriteString("\t\t} else if code != \"\" {\n")
+		fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"type_mismatch\", %q, vgMismatchValue(value), %s))\n", path, expected)
+		fmt.Fprintf(&g.body, "\t\t} else if %s {\n", integerOutOfBounds(validator, variable, integer.unsigned))
+		fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"out_of_boundaries\", %q, %s(n), %s))\n", path, _type, boundsParams(validator))
+		g.body.WriteString("\t\t}\n")
+		return nil
+	}
+
+	switch {
+	case _type == "string" || _type == "bson.ObjectId":
+		variable, condition := "_", "!ok"
+		if _type == "bson.ObjectId" {
+			variable, condition = "str", "(!ok || !vgIsObjectIdHex(str)) && vgTypeName(value) != \"bson.ObjectId\""
+		}
+		if validator.Regexp != "" {
+			variable = "str"
+		}
+		fmt.Fprintf(&g.body, "\t\tif %s, ok := value.(string); %s {\n\t\t\t%s\n", variable, condition, mismatch)
+		if validator.Regexp != "" {
+			fmt.Fprintf(&g.body, "\t\t} else if ok && !%s.MatchString(str) {\n", g.regexp(validator.Regexp))
+			fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"regex_not_match\", %q, str, map[string]interface{}{\"pattern\": %q}))\n", path, validator.Regexp)
+		}
+		g.body.WriteString("\t\t}\n")
+	case _type == "bool":
+		fmt.Fprintf(&g.body, "\t\tif _, ok := value.(bool); !ok {\n\t\t\t%s\n\t\t}\n", mismatch)
+	case _type == "float64":
+		// a json.Number is coerced into a float64 by the engine, and the float64 values are bounded only when the boundaries are set
+		if validator.Boundaries == (validation.Boundaries{}) {
+			fmt.Fprintf(&g.body, "\t\tif number, ok := value.(json.Number); ok {\n\t\t\tif _, err := number.Float64(); err != nil {\n\t\t\t\t%s\n\t\t\t}\n", mismatch)
+			fmt.Fprintf(&g.body, "\t\t} else if _, ok := value.(float64); !ok {\n\t\t\t%s\n\t\t}\n", mismatch)
+			break
+		}
+		g.body.WriteString("\t\tf, ok := value.(float64)\n\t\tif number, isNumber := value.(json.Number); isNumber {\n\t\t\tvar err error\n")
+		fmt.Fprintf(&g.body, "\t\t\tif f, err = number.Float64(); err != nil {\n\t\t\t\t%s\n\t\t\t}\n\t\t\tok = err == nil\n", mismatch)
+		fmt.Fprintf(&g.body, "\t\t} else if !ok {\n\t\t\t%s\n\t\t}\n", mismatch)
+		fmt.Fprintf(&g.body, "\t\tif ok && (%s) {\n", floatOutOfBounds(validator, "f"))
+		fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"out_of_boundaries\", %q, f, %s))\n\t\t}\n", path, floatParams(validator))
+	case _type == "json.Number":
+		fmt.Fprintf(&g.body, "\t\tif number, ok := value.(json.Number); !ok {\n\t\t\t%s\n", mismatch)
+		fmt.Fprintf(&g.body, "\t\t} else if f, _ := number.Float64(); %s {\n", floatOutOfBounds(validator, "f"))
+		fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"out_of_boundaries\", %q, number, %s))\n\t\t}\n", path, floatParams(validator))
+	case _type == "map[string]interface {}":
+		fmt.Fprintf(&g.body, "\t\tif _, ok := value.(map[string]interface{}); !ok {\n\t\t\t%s\n\t\t}\n", mismatch)
+	case _type == "interface {}":
+		// any value is accepted, but the engine still checks the strings and numbers rules
+		if validator.Regexp != "" {
+			fmt.Fprintf(&g.body, "\t\tif str, ok := value.(string); ok && !%s.MatchString(str) {\n", g.regexp(validator.Regexp))
+			fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"regex_not_match\", %q, str, map[string]interface{}{\"pattern\": %q}))\n\t\t}\n", path, validator.Regexp)
+		}
+		fmt.Fprintf(&g.body, "\t\tif number, ok := value.(json.Number); ok {\n\t\t\tif f, _ := number.Float64(); %s {\n", floatOutOfBounds(validator, "f"))
+		fmt.Fprintf(&g.body, "\t\t\t\terrors = append(errors, vgError(\"out_of_boundaries\", %q, number, %s))\n\t\t\t}\n\t\t}\n", path, floatParams(validator))
+		if validator.Boundaries != (validation.Boundaries{}) {
+			fmt.Fprintf(&g.body, "\t\tif f, ok := value.(float64); ok && (%s) {\n", floatOutOfBounds(validator, "f"))
+			fmt.Fprintf(&g.body, "\t\t\terrors = append(errors, vgError(\"out_of_boundaries\", %q, f, %s))\n\t\t}\n", path, floatParams(validator))
+		}
+	case strings.HasPrefix(_type, "[]") && sliceItemTypes[_type[2:]]:
+		item := _type[2:]
+		g.body.WriteString("\t\tswitch items := value.(type) {\n")
+		// the []interface{} case is the slice type itself for an "[]interface {}", which cannot be written twice
+		if item != "interface {}" {
+			fmt.Fprintf(&g.body, "\t\tcase %s:\n", goType(_type))
+		}
+		g.body.WriteString("\t\tcase []interface{}:\n")
+		if item != "interface {}" {
+			g.body.WriteString("\t\t\tfor i, item := range items {\n")
+			fmt.Fprintf(&g.body, "\t\t\t\tif itemType := vgTypeName(item); itemType != %q {\n", item)
+			fmt.Fprintf(&g.body, "\t\t\t\t\terrors = append(errors, vgErrorAt(\"type_mismatch\", %q, i, \"[] contains \"+itemType, %s))\n", path, expected)
+			g.body.WriteString("\t\t\t\t\tbreak\n\t\t\t\t}\n\t\t\t}\n")
+		} else {
+			g.body.WriteString("\t\t\t_ = items\n")
+		}
+		fmt.Fprintf(&g.body, "\t\tdefault:\n\t\t\t%s\n\t\t}\n", mismatch)
+	default:
+		return fmt.Errorf("type %q is not supported, the engine must be used", _type)
+	}
+	return nil
+}
+
+// This method returns the variable of the compiled regexp, a named pattern being written as its source
+func (g *generator) regexp(pattern string) string {
+	pattern = validation.ResolvePattern(pattern)
+	for i, known := range g.regexps {
+		if known == pattern {
+			return fmt.Sprintf("vgRegexp%d", i)
+		}
+	}
+	g.regexps = append(g.regexps, pattern)
+	return fmt.Sprintf("vgRegexp%d", len(g.regexps)-1)
+}
+
+// This method returns the formatted source of the generated file
+func (g *generator) source(pkg string) ([]byte, error) {
+	var file bytes.Buffer
+	file.WriteString("// Code generated by validationgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n", pkg)
+	if g.usesInt {
+		file.WriteString("\t\"math\"\n\t\"strconv\"\n")
+	}
+	if len(g.regexps) > 0 {
+		file.WriteString("\t\"regexp\"\n")
+	}
+	file.WriteString("\n\t\"github.com/grebett/validation\"\n)\n")
+
+	if len(g.regexps) > 0 {
+		file.WriteString("\n// the patterns of the validators, compiled once\nvar (\n")
+		for i, pattern := range g.regexps {
+			fmt.Fprintf(&file, "\tvgRegexp%d = regexp.MustCompile(%q)\n", i, pattern)
+		}
+		file.WriteString(")\n")
+	}
+	file.Write(g.body.Bytes())
+	file.WriteString(helpers)
+	if g.usesInt {
+		file.WriteString(integerHelpers)
+	}
+	return format.Source(file.Bytes())
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+func main() {
+	pkg := flag.String("package", "", "the package of the generated Go file")
+	output := flag.String("o", "", "the generated Go file, the standard output if empty")
+	ts := flag.String("ts", "", "the generated TypeScript module, with the interfaces and zod schemas")
+	flag.Parse()
+	if (*pkg == "" && *ts == "") || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: validationgen [-package name [-o file.go]] [-ts file.ts] schemas.json...")
+		os.Exit(2)
+	}
+
+	schemas, names, err := readSchemas(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "validationgen:", err)
+		os.Exit(1)
+	}
+	if *ts != "" {
+		if err := os.WriteFile(*ts, typescript(schemas, names), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "validationgen:", err)
+			os.Exit(1)
+		}
+	}
+	if *pkg == "" {
+		return
+	}
+
+	source, err := generate(*pkg, schemas, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "validationgen:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(source)
+		return
+	}
+	if err := os.WriteFile(*output, source, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "validationgen:", err)
+		os.Exit(1)
+	}
+}
+
+// this private function reads the schemas of the files, and returns them with their sorted names
+func readSchemas(files []string) (map[string]*validation.Schema, []string, error) {
+	schemas := make(map[string]*validation.Schema)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		var fileSchemas map[string]*validation.Schema
+		if err := json.Unmarshal(data, &fileSchemas); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for name, schema := range fileSchemas {
+			if _, ok := schemas[name]; ok {
+				return nil, nil, fmt.Errorf("%s: schema %s is defined twice", file, name)
+			}
+			if !isExportable(name) {
+				return nil, nil, fmt.Errorf("%s: schema name %q is not an exported Go identifier", file, name)
+			}
+			if errors := validation.CheckSchema(schema); len(errors) > 0 {
+				return nil, nil, fmt.Errorf("%s: %s: %v", file, name, errors[0])
+			}
+			schemas[name] = schema
+		}
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return schemas, names, nil
+}
+
+// this private function returns the generated Go source of the schemas
+func generate(pkg string, schemas map[string]*validation.Schema, names []string) ([]byte, error) {
+	g := &generator{}
+	for _, name := range names {
+		if err := g.schema(name, schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+	return g.source(pkg)
+}
+
+// this private function tells whether the name can follow "Validate" in an exported function name
+func isExportable(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != "" && unicode.IsUpper([]rune(name)[0])
+}
+
+// this private function returns the Go syntax of a validator type
+func goType(_type string) string {
+	return strings.Replace(_type, "interface {}", "interface{}", -1)
+}
+
+// this private function returns the condition of an integer out of the validator boundaries, as checked by the engine
+func integerOutOfBounds(validator *validation.Validator, variable string, unsigned bool) string {
+	if validator.IntBoundaries == nil {
+		return floatOutOfBounds(validator, "float64("+variable+")")
+	}
+	min, max := validator.IntBoundaries.Min, validator.IntBoundaries.Max
+	if !unsigned {
+		return fmt.Sprintf("%s < %d || %s > %d", variable, min, variable, max)
+	}
+	if max < 0 {
+		return "true"
+	}
+	condition := fmt.Sprintf("%s > %d", variable, uint64(max))
+	if min > 0 {
+		condition += fmt.Sprintf(" || %s < %d", variable, uint64(min))
+	}
+	return condition
+}
+
+// this private function returns the condition of a float out of the validator boundaries
+func floatOutOfBounds(validator *validation.Validator, variable string) string {
+	return fmt.Sprintf("%s < %s || %s > %s", variable, floatLiteral(validator.Boundaries.Min), variable, floatLiteral(validator.Boundaries.Max))
+}
+
+// this private function returns a float constant in Go syntax
+func floatLiteral(f float64) string {
+	return fmt.Sprintf("float64(%v)", f)
+}
+
+// this private function returns the parameters of an out of boundaries error on an integer
+func boundsParams(validator *validation.Validator) string {
+	if validator.IntBoundaries != nil {
+		return fmt.Sprintf("map[string]interface{}{\"min\": int64(%d), \"max\": int64(%d)}", validator.IntBoundaries.Min, validator.IntBoundaries.Max)
+	}
+	return floatParams(validator)
+}
+
+// this private function returns the parameters of an out of boundaries error on a float
+func floatParams(validator *validation.Validator) string {
+	return fmt.Sprintf("map[string]interface{}{\"min\": %s, \"max\": %s}", floatLiteral(validator.Boundaries.Min), floatLiteral(validator.Boundaries.Max))
+}
+
+// the helpers of every generated file
+const helpers = `
+// vgRead returns the value at the path, false if the path goes through a value which is not a document
+func vgRead(doc map[string]interface{}, keys ...string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, key := range keys {
+		if value == nil {
+			return nil, true
+		}
+		parent, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value = parent[key]
+	}
+	return value, true
+}
+
+// vgIsEmpty tells whether the value is missing for the engine: nil or an empty array
+func vgIsEmpty(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(value) == 0
+	case []string:
+		return len(value) == 0
+	case []bool:
+		return len(value) == 0
+	case []float64:
+		return len(value) == 0
+	case []json.Number:
+		return len(value) == 0
+	case []map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// vgTypeName returns the type of the value as written in the validators
+func vgTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return "string"
+	case json.Number:
+		return "json.Number"
+	case bool:
+		return "bool"
+	case float64:
+		return "float64"
+	case map[string]interface{}:
+		return "map[string]interface {}"
+	case []interface{}:
+		return "[]interface {}"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// vgIsObjectIdHex tells whether the string is the hex form of an ObjectId
+func vgIsObjectIdHex(str string) bool {
+	if len(str) != 24 {
+		return false
+	}
+	for _, r := range str {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// vgError returns the error of the code about the field
+func vgError(code string, field string, value interface{}, params map[string]interface{}) *validation.DataError {
+	err := validation.NewError(code, field, value, params)
+	err.Path = validation.ParsePath(field)
+	return err
+}
+
+// vgErrorAt returns the error of the code about an item of the field
+func vgErrorAt(code string, field string, index int, value interface{}, params map[string]interface{}) *validation.DataError {
+	err := vgError(code, field, value, params)
+	err.Path = append(err.Path, validation.PathSegment{Index: index, IsIndex: true})
+	return err
+}
+`
+
+// the helpers of the generated files checking integers
+const integerHelpers = `
+// vgMismatchValue returns the value of a type mismatch on an integer, as reported by the engine
+func vgMismatchValue(value interface{}) interface{} {
+	switch value.(type) {
+	case json.Number:
+		return "json.Number"
+	case float64:
+		return "float64"
+	}
+	return vgTypeName(value)
+}
+
+// vgInt converts a json.Number or a float64 into a signed integer of the bit size, or returns the code of the failure
+func vgInt(value interface{}, bits int) (int64, string) {
+	switch number := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseInt(string(number), 10, bits)
+		if numError, ok := err.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return 0, "out_of_range"
+		} else if err != nil {
+			return 0, "type_mismatch"
+		}
+		return n, ""
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return 0, "type_mismatch"
+		}
+		if number < -math.Ldexp(1, bits-1) || number >= math.Ldexp(1, bits-1) {
+			return 0, "out_of_range"
+		}
+		return int64(number), ""
+	}
+	return 0, "type_mismatch"
+}
+
+// vgUint converts a json.Number or a float64 into an unsigned integer of the bit size, or returns the code of the failure
+func vgUint(value interface{}, bits int) (uint64, string) {
+	switch number := value.(type) {
+	case json.Number:
+		n, err := strconv.ParseUint(string(number), 10, bits)
+		if numError, ok := err.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return 0, "out_of_range"
+		} else if err != nil {
+			return 0, "type_mismatch"
+		}
+		return n, ""
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return 0, "type_mismatch"
+		}
+		if number < 0 || number >= math.Ldexp(1, bits) {
+			return 0, "out_of_range"
+		}
+		return uint64(number), ""
+	}
+	return 0, "type_mismatch"
+}
+`
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	schemas, names, err := readSchemas([]string{"testdata/schemas.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := generate("golden", schemas, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("internal/golden/validation_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, golden) {
+		t.Errorf("the generated source differs from internal/golden/validation_gen.go, run go generate in internal/golden")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		schemas string
+		err     string
+	}{
+		{`{"user": {"Validators": {"name": {"Type": "string"}}}}`, `schema name "user" is not an exported Go identifier`},
+		{`{"User": {"Validators": {"price": {"Type": "string", "Decimal": {"Scale": 2}}}}}`, "User: price: decimal, money and raw JSON validators need the engine"},
+		{`{"User": {"Validators": {"name": {"Type": "string", "Source": "fullName"}}}}`, "User: name: renamed, immutable and rights-restricted fields need the engine"},
+		{`{"User": {"Validators": {"name": {"Type": "string", "Immutable": true}}}}`, "User: name: renamed, immutable and rights-restricted fields need the engine"},
+		{`{"User": {"Validators": {"loc": {"Type": "[]interface {}", "Tuple": [{"Type": "float64"}]}}}}`, "User: loc: tuple, unique key and ordered validators need the engine"},
+		{`{"User": {"Validators": {"addresses": {"Type": "[]interface {}", "UniqueBy": "label"}}}}`, "User: addresses: tuple, unique key and ordered validators need the engine"},
+		{`{"User": {"Validators": {"points": {"Type": "[]float64", "Ordered": {"Strict": true}}}}}`, "User: points: tuple, unique key and ordered validators need the engine"},
+		{`{"User": {"Validators": {"sum": {"Type": "string", "Format": "sha256hex", "HashOf": "file"}}}}`, "User: sum: format and hash validators need the engine"},
+		{`{"User": {"Validators": {"upload": {"Type": "string", "Format": "filename"}}}}`, "User: upload: format and hash validators need the engine"},
+		{`{"User": {"Validators": {"site": {"Type": "string", "Format": "domain"}}}}`, "User: site: format and hash validators need the engine"},
+		{`{"User": {"Validators": {"password": {"Type": "string", "Regexps": [{"Pattern": "[0-9]"}]}}}}`, "User: password: regexp rules need the engine"},
+		{`{"User": {"Validators": {"lines": {"Type": "[]interface {}", "Items": {"qty": {"Type": "int"}}}}}}`, "User: lines: items and referenced schema validators need the engine"},
+		{`{"User": {"Validators": {"parent": {"Type": "map[string]interface {}", "Ref": "#"}}}}`, "User: parent: items and referenced schema validators need the engine"},
+		{`{"User": {"Validators": {"active": {"Type": "bool", "Boolish": true}}}}`, "User: active: lenient parsing and search query validators need the engine"},
+		{`{"User": {"Validators": {"ratio": {"Type": "float64", "LocaleNumbers": true}}}}`, "User: ratio: lenient parsing and search query validators need the engine"},
+		{`{"User": {"Validators": {"q": {"Type": "string", "SearchQuery": {}}}}}`, "User: q: lenient parsing and search query validators need the engine"},
+		{`{"User": {"Validators": {"bio": {"Type": "string", "Nullable": true}}}}`, "User: bio: nullable and required on SET fields need the engine"},
+		{`{"User": {"Validators": {"id": {"Type": "string", "RequiredOnSet": true}}}}`, "User: id: nullable and required on SET fields need the engine"},
+		{`{"User": {"Validators": {"hours": {"Type": "map[string]interface {}", "Schedule": {}}}}}`, "User: hours: schedule, birth date and time validators need the engine"},
+		{`{"User": {"Validators": {"born": {"Type": "string", "Birthdate": {"MinAge": 18}}}}}`, "User: born: schedule, birth date and time validators need the engine"},
+		{`{"User": {"Validators": {"at": {"Type": "time.Time", "TimeWindow": {}}}}}`, "User: at: schedule, birth date and time validators need the engine"},
+		{`{"User": {"Validators": {"at": {"Type": "time.Time"}}}}`, `User: at: type "time.Time" is not supported, the engine must be used`},
+		{`{"User": {"Validators": {"ids": {"Type": "[]int"}}}}`, `User: ids: type "[]int" is not supported, the engine must be used`},
+		{`{"User": {"Validators": {"name": {"Type": "string", "Regexp": "("}}}}`, "User: "},
+		{`{"User": [1]}`, "cannot unmarshal"},
+	}
+	for _, test := range tests {
+		file := filepath.Join(t.TempDir(), "schemas.json")
+		if err := os.WriteFile(file, []byte(test.schemas), 0644); err != nil {
+			t.Fatal(err)
+		}
+		schemas, names, err := readSchemas([]string{file})
+		if err == nil {
+			_, err = generate("models", schemas, names)
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected %q, got %v", test.schemas, test.err, err)
+		}
+	}
+
+	if _, _, err := readSchemas([]string{"testdata/schemas.json", "testdata/schemas.json"}); err == nil || !strings.Contains(err.Error(), "is defined twice") {
+		t.Errorf("expected the duplicated schemas to be refused, got %v", err)
+	}
+}
+
+func TestIsExportable(t *testing.T) {
+	for name, exportable := range map[string]bool{"User": true, "User2": true, "Ünicode": true, "user": false, "2User": false, "User-2": false, "": false} {
+		if isExportable(name) != exportable {
+			t.Errorf("%q: expected %v", name, exportable)
+		}
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	schemas, names, err := readSchemas([]string{"testdata/schemas.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("testdata/schemas.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if module := typescript(schemas, names); !bytes.Equal(module, golden) {
+		t.Errorf("expected testdata/schemas.ts, got:\n%s", module)
+	}
+}
+
+func TestTypeExpr(t *testing.T) {
+	tests := []struct {
+		_type string
+		zod   string
+		ts    string
+	}{
+		{"uint8", "z.number().int()", "number"},
+		{"[]string", "z.array(z.string())", "string[]"},
+		{"[]map[string]interface {}", "z.array(z.record(z.string(), z.unknown()))", "(Record<string, unknown>)[]"},
+		{"map[string][]bool", "z.record(z.string(), z.array(z.boolean()))", "Record<string, boolean[]>"},
+		{"map[bson.ObjectId]json.Number", `z.record(z.string().regex(new RegExp("^[0-9a-fA-F]{24}$")), z.number())`, "Record<string, number>"},
+		{"time.Time", "z.unknown()", "unknown"},
+	}
+	for _, test := range tests {
+		if zod, ts := typeExpr(test._type); zod != test.zod || ts != test.ts {
+			t.Errorf("%s: expected %s %s, got %s %s", test._type, test.zod, test.ts, zod, ts)
+		}
+	}
+
+	for key, expected := range map[string]string{"name": "name", "first_name": "first_name", "$set": `"$set"`, "content-type": `"content-type"`, "2fa": `"2fa"`} {
+		if got := tsKey(key); got != expected {
+			t.Errorf("%q: expected %s, got %s", key, expected, got)
+		}
+	}
+}
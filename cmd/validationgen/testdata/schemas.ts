@@ -0,0 +1,49 @@
+// Code generated by validationgen; DO NOT EDIT.
+
+import { z } from "zod";
+
+export const EventSchema = z.object({
+  at: z.number().int().gte(0).lte(4.1024448e+09),
+  payload: z.unknown().nullish(),
+});
+
+export interface Event {
+  at: number;
+  payload?: unknown | null;
+}
+
+export const UserSchema = z.object({
+  active: z.boolean(),
+  address: z.object({
+    city: z.string(),
+  }).nullish(),
+  age: z.number().int().gte(0).lte(120).nullish(),
+  balance: z.number().gte(0).lte(1e+06).nullish(),
+  extra: z.unknown().nullish(),
+  id: z.string().regex(new RegExp("^[0-9a-fA-F]{24}$")).nullish(),
+  items: z.array(z.unknown()).nullish(),
+  name: z.string().regex(new RegExp("^[A-Z][a-z]+$")),
+  ratios: z.array(z.number()).nullish(),
+  score: z.number().gte(-1).lte(1).nullish(),
+  tags: z.array(z.string()).nullish(),
+  visits: z.number().int().gte(1).lte(1000).nullish(),
+  weight: z.number().nullish(),
+});
+
+export interface User {
+  active: boolean;
+  address?: {
+    city: string;
+  } | null;
+  age?: number | null;
+  balance?: number | null;
+  extra?: unknown | null;
+  id?: string | null;
+  items?: unknown[] | null;
+  name: string;
+  ratios?: number[] | null;
+  score?: number | null;
+  tags?: string[] | null;
+  visits?: number | null;
+  weight?: number | null;
+}
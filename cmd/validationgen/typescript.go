@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a key of the submitted documents: the validator written for it, if any, and its sub-keys
+type tsNode struct {
+	validator *validation.Validator
+	children  map[string]*tsNode
+}
+
+// the pattern of the ObjectId hex strings, as the engine accepts them
+const objectIdPattern = "^[0-9a-fA-F]{24}$"
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the zod schema and the TypeScript type of the node
+func (n *tsNode) types(indent string) (string, string) {
+	if len(n.children) > 0 && (n.validator == nil || n.validator.Type == "map[string]interface {}") {
+		var zod, ts strings.Builder
+		zod.WriteString("z.object({\n")
+		ts.WriteString("{\n")
+		keys := make([]string, 0, len(n.children))
+		for key := range n.children {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := n.children[key]
+			childZod, childTS := child.types(indent + "  ")
+			optional := child.validator == nil || !child.validator.IsRequired
+			name := tsKey(key)
+			if optional {
+				fmt.Fprintf(&zod, "%s  %s: %s.nullish(),\n", indent, name, childZod)
+				fmt.Fprintf(&ts, "%s  %s?: %s | null;\n", indent, name, childTS)
+			} else {
+				fmt.Fprintf(&zod, "%s  %s: %s,\n", indent, name, childZod)
+				fmt.Fprintf(&ts, "%s  %s: %s;\n", indent, name, childTS)
+			}
+		}
+		zod.WriteString(indent + "})")
+		ts.WriteString(indent + "}")
+		return zod.String(), ts.String()
+	}
+
+	validator := n.validator
+	switch {
+	case validator.RawJSON:
+		return "z.unknown()", "unknown"
+	case validator.Money != nil:
+		return moneyTypes(validator.Money)
+	case validator.Decimal != nil:
+		return "z.union([z.string(), z.number()])", "string | number"
+	}
+	zod, ts := typeExpr(validator.Type)
+
+	if _, ok := integerTypes[validator.Type]; ok {
+		if validator.IntBoundaries != nil {
+			zod += fmt.Sprintf(".gte(%d).lte(%d)", validator.IntBoundaries.Min, validator.IntBoundaries.Max)
+		} else {
+			zod += fmt.Sprintf(".gte(%s).lte(%s)", jsNumber(validator.Boundaries.Min), jsNumber(validator.Boundaries.Max))
+		}
+	} else if validator.Type == "json.Number" || (validator.Type == "float64" && validator.Boundaries != (validation.Boundaries{})) {
+		zod += fmt.Sprintf(".gte(%s).lte(%s)", jsNumber(validator.Boundaries.Min), jsNumber(validator.Boundaries.Max))
+	}
+	if validator.Regexp != "" && (validator.Type == "string" || validator.Type == "bson.ObjectId") {
+		zod += fmt.Sprintf(".regex(new RegExp(%s))", jsString(validation.ResolvePattern(validator.Regexp)))
+	}
+	return zod, ts
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the TypeScript module of the schemas: a zod schema and an interface for each of them
+// the documents are described as submitted on INIT: the keys are the Source ones, the optional keys accept null
+func typescript(schemas map[string]*validation.Schema, names []string) []byte {
+	var module bytes.Buffer
+	module.WriteString("// Code generated by validationgen; DO NOT EDIT.\n\nimport { z } from \"zod\";\n")
+	for _, name := range names {
+		root := &tsNode{children: make(map[string]*tsNode)}
+		for path, validator := range schemas[name].Validators {
+			in, _ := validator.Paths(path, validation.INIT)
+			node := root
+			for _, segment := range validation.ParsePath(in) {
+				child, ok := node.children[segment.Key]
+				if !ok {
+					child = &tsNode{children: make(map[string]*tsNode)}
+					node.children[segment.Key] = child
+				}
+				node = child
+			}
+			node.validator = validator
+		}
+
+		zod, ts := root.types("")
+		fmt.Fprintf(&module, "\nexport const %sSchema = %s;\n", name, zod)
+		fmt.Fprintf(&module, "\nexport interface %s %s\n", name, ts)
+	}
+	return module.Bytes()
+}
+
+// this private function returns the zod schema and the TypeScript type of a validator type
+func typeExpr(_type string) (string, string) {
+	if _, ok := integerTypes[_type]; ok {
+		return "z.number().int()", "number"
+	}
+	switch _type {
+	case "string":
+		return "z.string()", "string"
+	case "bson.ObjectId":
+		return fmt.Sprintf("z.string().regex(new RegExp(%s))", jsString(objectIdPattern)), "string"
+	case "bool":
+		return "z.boolean()", "boolean"
+	case "float64", "json.Number":
+		return "z.number()", "number"
+	case "map[string]interface {}":
+		return "z.record(z.string(), z.unknown())", "Record<string, unknown>"
+	}
+	if strings.HasPrefix(_type, "[]") {
+		zod, ts := typeExpr(_type[2:])
+		if strings.Contains(ts, " ") {
+			ts = "(" + ts + ")"
+		}
+		return fmt.Sprintf("z.array(%s)", zod), ts + "[]"
+	}
+	if strings.HasPrefix(_type, "map[") {
+		key, value := splitMapType(_type)
+		zodKey := "z.string()"
+		if key == "map[bson.ObjectId]" {
+			zodKey, _ = typeExpr("bson.ObjectId")
+		}
+		zod, ts := typeExpr(value)
+		return fmt.Sprintf("z.record(%s, %s)", zodKey, zod), fmt.Sprintf("Record<string, %s>", ts)
+	}
+	return "z.unknown()", "unknown"
+}
+
+// this private function returns the zod schema and the TypeScript type of a money sub-document
+func moneyTypes(money *validation.Money) (string, string) {
+	amount, currency := money.Keys()
+	currencySchema := "z.string().length(3)"
+	currencyType := "string"
+	if len(money.Currencies) > 0 {
+		quoted := make([]string, len(money.Currencies))
+		for i, code := range money.Currencies {
+			quoted[i] = jsString(code)
+		}
+		currencySchema = fmt.Sprintf("z.enum([%s])", strings.Join(quoted, ", "))
+		currencyType = strings.Join(quoted, " | ")
+	}
+	zod := fmt.Sprintf("z.object({ %s: z.union([z.string(), z.number()]), %s: %s })", tsKey(amount), tsKey(currency), currencySchema)
+	ts := fmt.Sprintf("{ %s: string | number; %s: %s }", tsKey(amount), tsKey(currency), currencyType)
+	return zod, ts
+}
+
+// this private function returns the key as written in an object literal: bare if it is an identifier, quoted otherwise
+func tsKey(key string) string {
+	if isExportable(strings.ToUpper(key[:1])+key[1:]) && !strings.ContainsAny(key, "$") {
+		return key
+	}
+	return jsString(key)
+}
+
+// this private function returns the string as a JavaScript literal
+func jsString(str string) string {
+	quoted, _ := json.Marshal(str)
+	return string(quoted)
+}
+
+// this private function returns the number as a JavaScript literal
+func jsNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// this private function splits a map type representation into its key part, brackets included, and its value part
+func splitMapType(_type string) (string, string) {
+	i := strings.Index(_type, "]")
+	if i < 0 {
+		return _type, ""
+	}
+	return _type[:i+1], _type[i+1:]
+}
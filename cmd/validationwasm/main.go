@@ -0,0 +1,104 @@
+//go:build js && wasm
+
+// Command validationwasm exposes the validation engine to the browser, for instant form feedback with the exact server rules
+//
+// Build:
+//
+//	GOOS=js GOARCH=wasm go build -o validation.wasm ./cmd/validationwasm
+//
+// once loaded with wasm_exec.js, it registers the global function
+//
+//	validate(schema, document, usage) -> {document, errors}
+//
+// where schema and document are JSON strings, the schema being written as a validation.Schema value, and usage one of "INIT", "SET" or "GET" ("INIT" if omitted)
+// the result holds the validated document and the errors as marshalled by the server, or a single "error" string when the inputs are malformed
+// the compiled schemas are cached by their JSON source, so validating on every keystroke does not recompile them
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/grebett/validation"
+)
+
+// the compiled schemas, by JSON source
+var schemas = make(map[string]*validation.Schema)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+func main() {
+	js.Global().Set("validate", js.FuncOf(validate))
+	select {}
+}
+
+// this private function is the validate JavaScript function
+func validate(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return result(nil, nil, fmt.Errorf("validate expects a schema and a document"))
+	}
+	schema, err := loadSchema(args[0].String())
+	if err != nil {
+		return result(nil, nil, err)
+	}
+
+	usage := validation.INIT
+	if len(args) > 2 && args[2].Type() == js.TypeString {
+		switch args[2].String() {
+		case "INIT":
+		case "SET":
+			usage = validation.SET
+		case "GET":
+			usage = validation.GET
+		default:
+			return result(nil, nil, fmt.Errorf("unknown usage %q", args[2].String()))
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(args[1].String())))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return result(nil, nil, fmt.Errorf("invalid document: %s", err))
+	}
+	validated, errors := schema.Validate(document, validation.Options{Usage: usage})
+	return result(validated, errors, nil)
+}
+
+// this private function returns the compiled schema of the JSON source, from the cache if it was already loaded
+func loadSchema(source string) (*validation.Schema, error) {
+	if schema, ok := schemas[source]; ok {
+		return schema, nil
+	}
+	schema := new(validation.Schema)
+	if err := json.Unmarshal([]byte(source), schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %s", err)
+	}
+	if errs := validation.CheckSchema(schema); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid schema: %s", errs[0])
+	}
+	schemas[source] = schema
+	return schema, nil
+}
+
+// this private function returns the JavaScript object of the validation result, going through JSON so the values keep their server representation
+func result(document map[string]interface{}, errors validation.DataErrors, err error) interface{} {
+	var out map[string]interface{}
+	if err != nil {
+		out = map[string]interface{}{"error": err.Error()}
+	} else {
+		if errors == nil {
+			errors = validation.DataErrors{}
+		}
+		out = map[string]interface{}{"document": document, "errors": errors}
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		raw, _ = json.Marshal(map[string]interface{}{"error": err.Error()})
+	}
+	return js.Global().Get("JSON").Call("parse", string(raw))
+}
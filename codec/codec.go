@@ -0,0 +1,89 @@
+// Package codec decodes the CBOR and MessagePack payloads into the representation the JSON path of the validation package uses, then validates them
+// it lives apart so that the JSON-only users of the validation package do not depend on the decoders
+package codec
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/grebett/validation"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function decodes a CBOR document – see validation.Normalize for the representation of the values
+// the tags the decoder does not know are dropped, their content kept
+func DecodeCBOR(data []byte) (map[string]interface{}, error) {
+	var decoded interface{}
+	if err := cbor.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return document(untag(decoded))
+}
+
+// This function decodes a MessagePack document – see validation.Normalize for the representation of the values
+func DecodeMsgPack(data []byte) (map[string]interface{}, error) {
+	var decoded interface{}
+	if err := msgpack.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return document(decoded)
+}
+
+// This function decodes a CBOR document and runs the schema against it – see validation.Validate
+// a payload that cannot be decoded is reported as a single "invalid_payload" error
+func ValidateCBOR(data []byte, schema validation.DocumentValidator, opt validation.Options) (map[string]interface{}, validation.DataErrors) {
+	doc, err := DecodeCBOR(data)
+	if err != nil {
+		return nil, invalidPayload("cbor", err)
+	}
+	return schema.Validate(doc, opt)
+}
+
+// This function decodes a MessagePack document and runs the schema against it – see validation.Validate
+// a payload that cannot be decoded is reported as a single "invalid_payload" error
+func ValidateMsgPack(data []byte, schema validation.DocumentValidator, opt validation.Options) (map[string]interface{}, validation.DataErrors) {
+	doc, err := DecodeMsgPack(data)
+	if err != nil {
+		return nil, invalidPayload("msgpack", err)
+	}
+	return schema.Validate(doc, opt)
+}
+
+// this private function normalizes the decoded value, which must be a map
+func document(decoded interface{}) (map[string]interface{}, error) {
+	normalized, err := validation.Normalize(decoded)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := normalized.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the payload is not a document but a %T", decoded)
+	}
+	return doc, nil
+}
+
+// this private function replaces the CBOR tags by their content, at any depth
+func untag(value interface{}) interface{} {
+	switch value := value.(type) {
+	case cbor.Tag:
+		return untag(value.Content)
+	case map[interface{}]interface{}:
+		for key, item := range value {
+			value[key] = untag(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = untag(item)
+		}
+	}
+	return value
+}
+
+// this private function returns the error reporting a payload that cannot be decoded
+func invalidPayload(format string, err error) validation.DataErrors {
+	return validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"format": format, "error": err.Error()})}
+}
@@ -0,0 +1,81 @@
+package codec
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/grebett/validation"
+)
+
+// this private function returns the schema the payloads of the tests are validated against
+func personSchema() *validation.Schema {
+	return &validation.Schema{Validators: map[string]*validation.Validator{
+		"name": {Type: "string", IsRequired: true},
+		"age":  {Type: "json.Number", Boundaries: validation.Boundaries{Min: 0, Max: 150}},
+	}}
+}
+
+// this private function decodes the hexadecimal payload of a test
+func payload(t *testing.T, str string) []byte {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestValidateCBOR(t *testing.T) {
+	// {"name": "Ada", "age": 1000(36)}, the age being tagged with a tag the decoder does not know
+	dest, errors := ValidateCBOR(payload(t, "a2646e616d656341646163616765d903e81824"), personSchema(), validation.Options{Usage: validation.INIT})
+	expected := map[string]interface{}{"name": "Ada", "age": json.Number("36")}
+	if len(errors) > 0 || !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v %v", expected, dest, errors)
+	}
+
+	// {"age": 200}
+	if _, errors := ValidateCBOR(payload(t, "a163616765"+"18c8"), personSchema(), validation.Options{Usage: validation.INIT}); len(errors) != 2 {
+		t.Errorf("expected the required and boundaries errors, got %v", errors)
+	}
+}
+
+func TestValidateMsgPack(t *testing.T) {
+	// {"name": "Ada", "age": 36}
+	dest, errors := ValidateMsgPack(payload(t, "82a46e616d65a3416461a361676524"), personSchema(), validation.Options{Usage: validation.INIT})
+	expected := map[string]interface{}{"name": "Ada", "age": json.Number("36")}
+	if len(errors) > 0 || !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v %v", expected, dest, errors)
+	}
+}
+
+func TestInvalidPayload(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+	}{
+		{"cbor", "ff"},
+		{"cbor", "01"},
+		{"msgpack", "c1"},
+		{"msgpack", "93010203"},
+	}
+	for _, test := range tests {
+		validate := ValidateCBOR
+		if test.format == "msgpack" {
+			validate = ValidateMsgPack
+		}
+		_, errors := validate(payload(t, test.data), personSchema(), validation.Options{Usage: validation.INIT})
+		if len(errors) != 1 || errors[0].Code != "invalid_payload" || errors[0].Params["format"] != test.format {
+			t.Errorf("%s %s: expected an invalid_payload error, got %v", test.format, test.data, errors)
+		}
+	}
+}
+
+func TestUntag(t *testing.T) {
+	tagged := []interface{}{cbor.Tag{Number: 1000, Content: map[interface{}]interface{}{"a": cbor.Tag{Number: 1001, Content: "b"}}}}
+	expected := []interface{}{map[interface{}]interface{}{"a": "b"}}
+	if untagged := untag(tagged); !reflect.DeepEqual(untagged, expected) {
+		t.Errorf("expected %v, got %v", expected, untagged)
+	}
+}
@@ -0,0 +1,269 @@
+package validation
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct sets exact boundaries for an integer value, which float64 Boundaries cannot hold above 2^53
+type IntBoundaries struct {
+	Min int64
+	Max int64
+}
+
+// the bit sizes of the integer types the numbers are coerced to
+var integerBits = map[string]int{
+	"int": strconv.IntSize, "int8": 8, "int16": 16, "int32": 32, "int64": 64,
+	"uint": strconv.IntSize, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64,
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function converts the submitted value into the validator type, when the conversion is lossless
+// e.g. a json.Number is converted into an int64 for an "int64" validator, provided it is an integer that fits
+// the value is returned unchanged when no conversion applies, and the type check reports the mismatch if any
+func coerce(validator *Validator, value interface{}) (interface{}, *DataError) {
+	if validator.RawJSON {
+		return coerceRawJSON(validator, value)
+	}
+	if validator.Decimal != nil {
+		return coerceDecimal(validator, value)
+	}
+	if validator.Boolish && validator.Type == "bool" {
+		return coerceBool(value)
+	}
+	if validator.Type == "time.Time" {
+		return coerceTime(validator, value)
+	}
+	if bits, ok := integerBits[validator.Type]; ok {
+		return coerceInteger(validator.Type, bits, value)
+	}
+	// a number decoded with UseNumber, or normalized from another format, is read as encoding/json would have
+	if number, ok := value.(json.Number); ok && validator.Type == "float64" {
+		if float, err := number.Float64(); err == nil {
+			return float, nil
+		}
+	}
+	return value, nil
+}
+
+// this private function converts the boolean-ish strings of query strings and CSV files into a bool: "true", "1", "yes" and "false", "0", "no", whatever the case
+func coerceBool(value interface{}) (interface{}, *DataError) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	switch strings.ToLower(str) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	}
+	return value, NewError("type_mismatch", "", "string", map[string]interface{}{"expected": "bool"})
+}
+
+// this private function returns the value as a json.RawMessage, checking it is well-formed and not too large
+// the bytes are kept untouched when submitted as a json.RawMessage (or []byte), the decoded values are encoded again
+func coerceRawJSON(validator *Validator, value interface{}) (interface{}, *DataError) {
+	var raw json.RawMessage
+	switch value := value.(type) {
+	case json.RawMessage:
+		raw = value
+	case []byte:
+		raw = json.RawMessage(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return value, NewError("invalid_json", "", nil, map[string]interface{}{"error": err.Error()})
+		}
+		raw = encoded
+	}
+
+	if validator.MaxRawSize > 0 && len(raw) > validator.MaxRawSize {
+		return value, NewError("too_large", "", len(raw), map[string]interface{}{"max": validator.MaxRawSize})
+	}
+	if !json.Valid(raw) {
+		return value, NewError("invalid_json", "", nil, nil)
+	}
+	return raw, nil
+}
+
+// this private function converts a json.Number or a float64 into the integer type, checking for overflows
+func coerceInteger(_type string, bits int, value interface{}) (interface{}, *DataError) {
+	unsigned := _type[0] == 'u'
+	var signedValue int64
+	var unsignedValue uint64
+
+	switch number := value.(type) {
+	case json.Number:
+		var err error
+		if unsigned {
+			unsignedValue, err = strconv.ParseUint(string(number), 10, bits)
+		} else {
+			signedValue, err = strconv.ParseInt(string(number), 10, bits)
+		}
+		if numError, ok := err.(*strconv.NumError); ok && numError.Err == strconv.ErrRange {
+			return value, NewError("out_of_range", "", value, map[string]interface{}{"type": _type})
+		} else if err != nil {
+			return value, NewError("type_mismatch", "", "json.Number", map[string]interface{}{"expected": _type})
+		}
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return value, NewError("type_mismatch", "", "float64", map[string]interface{}{"expected": _type})
+		}
+		// the float64 bounds are the exact powers of two the integer types can or cannot reach
+		if unsigned {
+			if number < 0 || number >= math.Ldexp(1, bits) {
+				return value, NewError("out_of_range", "", value, map[string]interface{}{"type": _type})
+			}
+			unsignedValue = uint64(number)
+		} else {
+			if number < -math.Ldexp(1, bits-1) || number >= math.Ldexp(1, bits-1) {
+				return value, NewError("out_of_range", "", value, map[string]interface{}{"type": _type})
+			}
+			signedValue = int64(number)
+		}
+	default:
+		return value, nil
+	}
+
+	switch _type {
+	case "int":
+		return int(signedValue), nil
+	case "int8":
+		return int8(signedValue), nil
+	case "int16":
+		return int16(signedValue), nil
+	case "int32":
+		return int32(signedValue), nil
+	case "int64":
+		return signedValue, nil
+	case "uint":
+		return uint(unsignedValue), nil
+	case "uint8":
+		return uint8(unsignedValue), nil
+	case "uint16":
+		return uint16(unsignedValue), nil
+	case "uint32":
+		return uint32(unsignedValue), nil
+	}
+	return unsignedValue, nil
+}
+
+// this private function returns the integer behind the value, as an int64 or as an uint64 for the unsigned types
+func integerValue(value interface{}) (int64, uint64, bool, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), 0, false, true
+	case int8:
+		return int64(n), 0, false, true
+	case int16:
+		return int64(n), 0, false, true
+	case int32:
+		return int64(n), 0, false, true
+	case int64:
+		return n, 0, false, true
+	case uint:
+		return 0, uint64(n), true, true
+	case uint8:
+		return 0, uint64(n), true, true
+	case uint16:
+		return 0, uint64(n), true, true
+	case uint32:
+		return 0, uint64(n), true, true
+	case uint64:
+		return 0, n, true, true
+	}
+	return 0, 0, false, false
+}
+
+// this private function checks an integer value against the validator boundaries
+// the IntBoundaries are exact; without them, the float64 Boundaries apply like for a json.Number
+func checkIntegerBoundaries(validator *Validator, signedValue int64, unsignedValue uint64, unsigned bool) bool {
+	if validator.IntBoundaries == nil {
+		if unsigned {
+			return validator.CheckBoundaries(float64(unsignedValue))
+		}
+		return validator.CheckBoundaries(float64(signedValue))
+	}
+
+	min, max := validator.IntBoundaries.Min, validator.IntBoundaries.Max
+	if !unsigned {
+		return signedValue >= min && signedValue <= max
+	}
+	if max < 0 {
+		return false
+	}
+	return unsignedValue <= uint64(max) && (min <= 0 || unsignedValue >= uint64(min))
+}
+
+// this private function converts a string read outside of a JSON document (an environment variable, a header) into the JSON representation of the validator type
+// numbers become json.Number, booleans bool, the lists are comma-separated – their items converted into the item type – and the maps written in JSON
+// the string is returned unchanged when it cannot be converted, and the type check reports the mismatch
+func parseString(_type string, str string) interface{} {
+	switch {
+	case _type == "bool":
+		if b, err := strconv.ParseBool(strings.TrimSpace(str)); err == nil {
+			return b
+		}
+	case _type == "float64" || _type == "json.Number" || integerBits[_type] != 0:
+		var number json.Number
+		if json.Unmarshal([]byte(str), &number) == nil {
+			return number
+		}
+	case strings.HasPrefix(_type, "[]"):
+		if str == "" {
+			return []interface{}{}
+		}
+		items := strings.Split(str, ",")
+		values := make([]interface{}, len(items))
+		// the items are not coerced by the engine, which only coerces the fields themselves
+		itemValidator := &Validator{Type: _type[2:]}
+		for i, item := range items {
+			values[i] = parseString(itemValidator.Type, strings.TrimSpace(item))
+			if value, err := coerce(itemValidator, values[i]); err == nil {
+				values[i] = value
+			}
+		}
+		return values
+	case strings.HasPrefix(_type, "map["):
+		decoder := json.NewDecoder(strings.NewReader(str))
+		decoder.UseNumber()
+		var value map[string]interface{}
+		if decoder.Decode(&value) == nil {
+			return value
+		}
+	}
+	return str
+}
+
+// this private function converts the ObjectIds hex strings of a "bson.ObjectId" or "[]bson.ObjectId" value with the factory
+// the values already of another type (e.g. a bson.ObjectId) are left as they are
+func convertObjectIds(validator *Validator, value interface{}, factory func(hex string) interface{}) interface{} {
+	switch validator.Type {
+	case "bson.ObjectId":
+		if hex, ok := value.(string); ok {
+			return factory(hex)
+		}
+	case "[]bson.ObjectId":
+		if items, ok := value.([]interface{}); ok {
+			converted := make([]interface{}, len(items))
+			for i, item := range items {
+				if hex, ok := item.(string); ok {
+					item = factory(hex)
+				}
+				converted[i] = item
+			}
+			return converted
+		}
+	}
+	return value
+}
@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCoerceIntegers(t *testing.T) {
+	tests := []struct {
+		validator *Validator
+		value     interface{}
+		stored    interface{}
+		code      string
+	}{
+		{&Validator{Type: "int64", IntBoundaries: &IntBoundaries{Min: 0, Max: 1 << 62}}, json.Number("4611686018427387904"), int64(1 << 62), ""},
+		{&Validator{Type: "int64", IntBoundaries: &IntBoundaries{Min: 0, Max: 1 << 62}}, json.Number("4611686018427387905"), nil, "out_of_boundaries"},
+		{&Validator{Type: "uint8", Boundaries: Boundaries{Min: 0, Max: 300}}, float64(255), uint8(255), ""},
+		{&Validator{Type: "uint8", Boundaries: Boundaries{Min: 0, Max: 300}}, float64(256), nil, "out_of_range"},
+		{&Validator{Type: "int8", Boundaries: Boundaries{Min: 0, Max: 300}}, json.Number("300"), nil, "out_of_range"},
+		{&Validator{Type: "int8", Boundaries: Boundaries{Min: -128, Max: 127}}, json.Number("-128"), int8(-128), ""},
+		{&Validator{Type: "int", Boundaries: Boundaries{Min: 0, Max: 10}}, json.Number("1.5"), nil, "type_mismatch"},
+		{&Validator{Type: "int", Boundaries: Boundaries{Min: 0, Max: 10}}, float64(2.5), nil, "type_mismatch"},
+		{&Validator{Type: "uint", Boundaries: Boundaries{Min: 0, Max: 10}}, json.Number("-1"), nil, "type_mismatch"},
+		{&Validator{Type: "uint32", IntBoundaries: &IntBoundaries{Min: 5, Max: 10}}, json.Number("4"), nil, "out_of_boundaries"},
+		{&Validator{Type: "int16", Boundaries: Boundaries{Min: 0, Max: 10}}, "3", nil, "type_mismatch"},
+	}
+	for _, test := range tests {
+		dest, errors := Validate(map[string]*Validator{"n": test.validator}, map[string]interface{}{"n": test.value}, Options{Usage: INIT})
+		code := ""
+		if len(errors) > 0 {
+			code = errors[0].Code
+		}
+		if code != test.code || dest["n"] != test.stored {
+			t.Errorf("%s %v: expected %v %q, got %#v %v", test.validator.Type, test.value, test.stored, test.code, dest["n"], errors)
+		}
+	}
+}
+
+func TestCoerceRawJSON(t *testing.T) {
+	validator := &Validator{Type: "json.RawMessage", RawJSON: true, MaxRawSize: 16}
+	tests := []struct {
+		value  interface{}
+		stored string
+		code   string
+	}{
+		{map[string]interface{}{"a": []interface{}{float64(1), "b"}}, `{"a":[1,"b"]}`, ""},
+		{json.RawMessage(`{ "kept": true }`), `{ "kept": true }`, ""},
+		{[]byte(`[1, 2`), "", "invalid_json"},
+		{"a string longer than 16 bytes", "", "too_large"},
+		{map[string]interface{}{"f": func() {}}, "", "invalid_json"},
+	}
+	for _, test := range tests {
+		dest, errors := Validate(map[string]*Validator{"raw": validator}, map[string]interface{}{"raw": test.value}, Options{Usage: INIT})
+		code, stored := "", ""
+		if len(errors) > 0 {
+			code = errors[0].Code
+		} else if raw, ok := dest["raw"].(json.RawMessage); ok {
+			stored = string(raw)
+		}
+		if code != test.code || stored != test.stored {
+			t.Errorf("%v: expected %q %q, got %#v %v", test.value, test.stored, test.code, dest["raw"], errors)
+		}
+	}
+}
+
+func TestCoerceFloat(t *testing.T) {
+	bounded := &Validator{Type: "float64", Boundaries: Boundaries{Min: -100, Max: 100}}
+	unbounded := &Validator{Type: "float64"}
+	tests := []struct {
+		validator *Validator
+		value     interface{}
+		stored    interface{}
+		code      string
+	}{
+		{bounded, json.Number("12.5"), float64(12.5), ""},
+		{bounded, json.Number("1e9"), nil, "out_of_boundaries"},
+		{bounded, float64(1e9), nil, "out_of_boundaries"},
+		{bounded, float64(-100), float64(-100), ""},
+		{unbounded, float64(1e9), float64(1e9), ""},
+		{unbounded, json.Number("-1.5"), float64(-1.5), ""},
+		{unbounded, json.Number("x"), nil, "type_mismatch"},
+	}
+	for _, test := range tests {
+		dest, errors := Validate(map[string]*Validator{"n": test.validator}, map[string]interface{}{"n": test.value}, Options{Usage: INIT})
+		code := ""
+		if len(errors) > 0 {
+			code = errors[0].Code
+		}
+		if code != test.code || dest["n"] != test.stored {
+			t.Errorf("%s %v: expected %v %q, got %#v %v", test.validator.Type, test.value, test.stored, test.code, dest["n"], errors)
+		}
+	}
+}
+
+func TestCoerceBool(t *testing.T) {
+	validators := map[string]*Validator{"a": {Type: "bool", Boolish: true}, "b": {Type: "bool", Boolish: true}, "c": {Type: "bool", Boolish: true}, "strict": {Type: "bool"}}
+	dest, errors := Validate(validators, map[string]interface{}{"a": "YES", "b": "0", "c": true}, Options{Usage: INIT})
+	if len(errors) > 0 || dest["a"] != true || dest["b"] != false || dest["c"] != true {
+		t.Errorf("expected the boolean-ish strings to be converted, got %v %v", dest, errors)
+	}
+	_, errors = Validate(validators, map[string]interface{}{"a": "maybe", "strict": "true"}, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Code != "type_mismatch" || errors[1].Code != "type_mismatch" || errors[1].Field != "strict" {
+		t.Errorf("expected two type mismatches, got %v", errors)
+	}
+}
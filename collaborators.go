@@ -0,0 +1,101 @@
+package validation
+
+import "github.com/grebett/tools"
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This interface matches the strings against the Regexp of the validators
+type RegexpMatcher interface {
+	MatchRegexp(validator *Validator, str string) (bool, error)
+}
+
+// This interface checks the values are of the Type of the validators, returning the type_mismatch error, nil if the value matches
+type TypeChecker interface {
+	CheckType(validator *Validator, path string, value interface{}) *DataError
+}
+
+// This interface tells whether the user rights reach the ones required
+type RightsChecker interface {
+	CheckRights(userRights int, required int) bool
+}
+
+// This interface reads the values of the submitted documents at the dotted paths of the validators
+type Lookup interface {
+	ReadDeep(document map[string]interface{}, path string) (interface{}, error)
+}
+
+// This struct gathers what the validation of the fields consults, so the unit tests can inject fakes and assert they are consulted
+// the nil ones are the default ones – see CompiledSchema.WithCollaborators
+type Collaborators struct {
+	Regexps RegexpMatcher
+	Types   TypeChecker
+	Rights  RightsChecker
+	Lookup  Lookup
+}
+
+// These private structs are the default collaborators
+type defaultRegexps struct{}
+type defaultTypes struct{}
+type defaultRights struct{}
+type defaultLookup struct{}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns a copy of the compiled schema validating the documents with the collaborators, the nil ones being the default ones
+// the copy shares the validators of the compiled schema
+func (c *CompiledSchema) WithCollaborators(collaborators Collaborators) *CompiledSchema {
+	return &CompiledSchema{schema: c.schema, collaborators: &collaborators}
+}
+
+// This method runs the compiled regexp of the validator
+func (defaultRegexps) MatchRegexp(validator *Validator, str string) (bool, error) {
+	return validator.ExecRegexp(str)
+}
+
+// This method runs the type check of the engine
+func (defaultTypes) CheckType(validator *Validator, path string, value interface{}) *DataError {
+	var errors []*DataError
+	if !checkType(validator, path, value, &errors) {
+		return errors[0]
+	}
+	return nil
+}
+
+// This method compares the rights as Validator.CheckRights does
+func (defaultRights) CheckRights(userRights int, required int) bool {
+	return userRights >= required
+}
+
+// This method reads the value as tools.ReadDeep does
+func (defaultLookup) ReadDeep(document map[string]interface{}, path string) (interface{}, error) {
+	return tools.ReadDeep(document, path)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the collaborators of the validation, the default ones in place of the missing ones
+func collaboratorsOf(opt Options) Collaborators {
+	var c Collaborators
+	if opt.collaborators != nil {
+		c = *opt.collaborators
+	}
+	if c.Regexps == nil {
+		c.Regexps = defaultRegexps{}
+	}
+	if c.Types == nil {
+		c.Types = defaultTypes{}
+	}
+	if c.Rights == nil {
+		c.Rights = defaultRights{}
+	}
+	if c.Lookup == nil {
+		c.Lookup = defaultLookup{}
+	}
+	return c
+}
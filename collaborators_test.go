@@ -0,0 +1,59 @@
+package validation
+
+import "testing"
+
+// the fakes of the collaborators, counting their calls
+type fakeRegexps struct{ calls int }
+type fakeRights struct{}
+type fakeTypes struct{ paths []string }
+type fakeLookup struct{ values map[string]interface{} }
+
+func (f *fakeRegexps) MatchRegexp(validator *Validator, str string) (bool, error) {
+	f.calls++
+	return str == "yes", nil
+}
+
+func (fakeRights) CheckRights(userRights int, required int) bool {
+	return true
+}
+
+func (f *fakeTypes) CheckType(validator *Validator, path string, value interface{}) *DataError {
+	f.paths = append(f.paths, path)
+	return nil
+}
+
+func (f fakeLookup) ReadDeep(document map[string]interface{}, path string) (interface{}, error) {
+	return f.values[path], nil
+}
+
+func TestCollaborators(t *testing.T) {
+	compiled := MustCompile(&Schema{Validators: map[string]*Validator{
+		"a":     {Type: "string", Regexp: "^x$", Rights: [3]int{ADMIN, ADMIN, ADMIN}},
+		"lines": {Type: "[]map[string]interface {}", Items: map[string]*Validator{"b": {Type: "string", Regexp: "^x$"}}},
+	}})
+	regexps := &fakeRegexps{}
+	faked := compiled.WithCollaborators(Collaborators{Regexps: regexps, Rights: fakeRights{}})
+	_, errors := faked.Validate(map[string]interface{}{"a": "yes", "lines": []interface{}{map[string]interface{}{"b": "no"}}}, Options{Usage: INIT})
+	if regexps.calls != 2 || len(errors) != 1 || errors[0].Field != "lines.0.b" {
+		t.Errorf("expected the fakes to be consulted for the items too, got %d calls and %v", regexps.calls, errors)
+	}
+	if _, errors := ValidateField(faked, "a", "yes", Options{Usage: INIT}); len(errors) > 0 || regexps.calls != 3 {
+		t.Errorf("expected ValidateField to consult the fakes, got %d calls and %v", regexps.calls, errors)
+	}
+
+	// the compiled schema itself keeps the default collaborators
+	if _, errors := compiled.Validate(map[string]interface{}{"a": "yes"}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "regex_not_match" {
+		t.Errorf("expected the default regexps, got %v", errors)
+	}
+
+	types := &fakeTypes{}
+	lookup := fakeLookup{values: map[string]interface{}{"a": 42}}
+	dest, errors := compiled.WithCollaborators(Collaborators{Types: types, Lookup: lookup, Rights: fakeRights{}}).Validate(map[string]interface{}{}, Options{Usage: INIT})
+	// the fake type check lets the int through, the boundaries then refuse it
+	if len(errors) != 1 || errors[0].Field != "a" || errors[0].Value != 42 || dest["a"] != nil {
+		t.Errorf("expected the value of the fake lookup to be checked, got %v %v", dest, errors)
+	}
+	if len(types.paths) != 1 || types.paths[0] != "a" {
+		t.Errorf("expected the fake type check on a, got %v", types.paths)
+	}
+}
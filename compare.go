@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is the outcome of the validation of a corpus against two versions of a schema – see Compare
+type ComparisonReport struct {
+	Total       int                  // the number of documents of the corpus
+	AcceptedByA int                  // the number of documents schema A accepts
+	AcceptedByB int                  // the number of documents schema B accepts
+	Differences []DocumentDifference // the documents the schemas validate differently, in the corpus order
+}
+
+// This struct is a document of the corpus the two schemas validate differently: one refuses it, or they report different errors or dests
+type DocumentDifference struct {
+	Index       int                    // the index of the document in the corpus
+	Document    map[string]interface{} // the document
+	AcceptedByA bool                   // whether schema A accepts the document
+	AcceptedByB bool                   // whether schema B accepts the document
+	OnlyA       DataErrors             // the errors only schema A reports, by field and code
+	OnlyB       DataErrors             // the errors only schema B reports, by field and code
+	DestDiffers bool                   // the validated documents differ, e.g. a new default or transform
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// ComparisonReport stringer, e.g. "1000 documents: A accepts 950, B accepts 940, 12 differ"
+func (r *ComparisonReport) String() string {
+	return fmt.Sprintf("%s: A accepts %d, B accepts %d, %d differ", plural(r.Total, "document"), r.AcceptedByA, r.AcceptedByB, len(r.Differences))
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates every document of the corpus against both schemas with the options, for an offline evaluation of a schema rollout
+// e.g. Compare(current, candidate, storedDocuments, Options{Usage: INIT}) tells which stored documents the candidate would refuse and why
+// the corpus is left untouched; the functions of the schemas (custom tests, defaults...) are run twice per document
+func Compare(a DocumentValidator, b DocumentValidator, corpus []map[string]interface{}, opt Options) *ComparisonReport {
+	opt.ShadowSchema = nil
+	report := &ComparisonReport{Total: len(corpus)}
+	for i, document := range corpus {
+		destA, errorsA := a.Validate(document, opt)
+		destB, errorsB := b.Validate(document, opt)
+
+		difference := DocumentDifference{Index: i, Document: document, AcceptedByA: len(errorsA) == 0, AcceptedByB: len(errorsB) == 0}
+		if difference.AcceptedByA {
+			report.AcceptedByA++
+		}
+		if difference.AcceptedByB {
+			report.AcceptedByB++
+		}
+		difference.OnlyA = missingErrors(errorsA, errorsB)
+		difference.OnlyB = missingErrors(errorsB, errorsA)
+		difference.DestDiffers = difference.AcceptedByA && difference.AcceptedByB && !reflect.DeepEqual(destA, destB)
+		if len(difference.OnlyA) > 0 || len(difference.OnlyB) > 0 || difference.DestDiffers {
+			report.Differences = append(report.Differences, difference)
+		}
+	}
+	return report
+}
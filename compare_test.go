@@ -0,0 +1,22 @@
+package validation
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	current := &Schema{Validators: map[string]*Validator{"code": {Type: "string"}}}
+	candidate := MustCompile(&Schema{Validators: map[string]*Validator{"code": {Type: "string", Regexp: "^x"}}})
+	corpus := []map[string]interface{}{{"code": "x"}, {"code": "y"}, {"code": 1}}
+
+	report := Compare(current, candidate, corpus, Options{Usage: INIT})
+	if report.String() != "3 documents: A accepts 2, B accepts 1, 1 differ" {
+		t.Errorf("unexpected report %q", report)
+	}
+	// the document both schemas refuse for the same reason is not a difference
+	if len(report.Differences) != 1 || report.Differences[0].Index != 1 {
+		t.Fatalf("expected the second document to differ, got %v", report.Differences)
+	}
+	difference := report.Differences[0]
+	if !difference.AcceptedByA || difference.AcceptedByB || len(difference.OnlyA) != 0 || len(difference.OnlyB) != 1 || difference.OnlyB[0].Code != "regex_not_match" {
+		t.Errorf("unexpected difference %+v", difference)
+	}
+}
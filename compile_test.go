@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// this private function returns a schema whose rules the tests change on the source once compiled
+func compileSchema() *Schema {
+	return &Schema{Validators: map[string]*Validator{
+		"name":    {Type: "string", Regexp: "^[a-z]+$", IsRequired: true},
+		"age":     {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"address": {Type: "map[string]string"},
+	}}
+}
+
+// this private function returns the errors as a string independent of their order, the fields being validated in any order
+func sortedErrors(errors []*DataError) string {
+	messages := make([]string, len(errors))
+	for i, err := range errors {
+		messages[i] = err.Error()
+	}
+	sort.Strings(messages)
+	return fmt.Sprint(messages)
+}
+
+func TestCompiledSchemaConcurrentUse(t *testing.T) {
+	schema := compileSchema()
+	compiled := MustCompile(schema)
+	document := func() map[string]interface{} {
+		return map[string]interface{}{"name": "Name", "age": json.Number("200")}
+	}
+	_, expected := compiled.Validate(document(), Options{Usage: INIT})
+	if len(expected) != 2 {
+		t.Fatalf("expected 2 errors, got %v", expected)
+	}
+
+	var wg sync.WaitGroup
+	failures := make(chan string, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, errors := compiled.Validate(document(), Options{Usage: INIT}); sortedErrors(errors) != sortedErrors(expected) {
+					failures <- sortedErrors(errors)
+					return
+				}
+			}
+		}()
+	}
+
+	// the source schema is modified meanwhile, which must not affect the compiled one
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 50; j++ {
+			schema.Validators["name"].Regexp = "^[A-Za-z]+$"
+			schema.Validators["age"].Boundaries.Max = float64(300 + j)
+			schema.Validators["address"].IsRequired = true
+		}
+	}()
+
+	wg.Wait()
+	close(failures)
+	for failure := range failures {
+		t.Errorf("the compiled schema changed along with its source: %s", failure)
+	}
+	if copied := compiled.Schema(); copied.Validators["name"].Regexp != "^[a-z]+$" {
+		t.Errorf("expected the compiled pattern, got %q", copied.Validators["name"].Regexp)
+	}
+}
+
+func TestCompileInvalidRegexp(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"name": {Type: "string", Regexp: "[a-z"}}}
+	if _, err := schema.Compile(); err == nil {
+		t.Fatal("expected an error for the invalid pattern")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustCompile to panic")
+		}
+	}()
+	MustCompile(schema)
+}
+
+func TestValidateCopiesSubDocuments(t *testing.T) {
+	address := map[string]interface{}{"city": "Paris"}
+	dest, errors := MustCompile(compileSchema()).Validate(map[string]interface{}{"name": "ada", "address": address}, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatal(errors)
+	}
+	dest["address"].(map[string]interface{})["city"] = "Lyon"
+	if address["city"] != "Paris" {
+		t.Errorf("dest shares its sub-documents with the submitted data: %v", address)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"lines":  {Type: "[]interface {}", Items: map[string]*Validator{"qty": {Type: "int", IntBoundaries: &IntBoundaries{Min: 1, Max: 10}}}},
+		"point":  {Type: "[]interface {}", Tuple: []*Validator{{Type: "float64"}}, AdditionalItems: &Validator{Type: "string"}},
+		"scores": {Type: "[]interface {}", Ordered: &Ordered{Key: "value"}},
+		"email":  {Type: "string", Labels: map[string]string{"fr": "Adresse e-mail"}, DocSlugs: map[string]string{"regex_not_match": "email-format"}},
+		"site":   {Type: "string", Format: "domain", BlockedDomains: DomainSet{"example.com": true}},
+		"beta":   {Type: "bool", DisabledWhen: map[string]func(ctx context.Context) bool{"required": func(context.Context) bool { return true }}},
+	}}
+	clone := schema.Clone()
+	schema.Validators["lines"].Items["qty"].IntBoundaries.Max = 100
+	schema.Validators["lines"].Items["sku"] = &Validator{Type: "string", IsRequired: true}
+	schema.Validators["point"].Tuple[0].IsRequired = true
+	schema.Validators["point"].AdditionalItems.Type = "int"
+	schema.Validators["scores"].Ordered.Descending = true
+	schema.Validators["email"].Labels["fr"] = "Courriel"
+	schema.Validators["email"].DocSlugs["regex_not_match"] = "email"
+	schema.Validators["site"].BlockedDomains.(DomainSet)["example.org"] = true
+	schema.Validators["beta"].DisabledWhen["type"] = func(context.Context) bool { return true }
+
+	if qty := clone.Validators["lines"].Items["qty"]; qty.IntBoundaries.Max != 10 {
+		t.Errorf("expected the items validators to be copied, got %v", qty.IntBoundaries)
+	}
+	if _, ok := clone.Validators["lines"].Items["sku"]; ok {
+		t.Errorf("expected the items validators map to be copied")
+	}
+	if point := clone.Validators["point"]; point.Tuple[0].IsRequired || point.AdditionalItems.Type != "string" {
+		t.Errorf("expected the tuple validators to be copied, got %v %v", point.Tuple[0], point.AdditionalItems)
+	}
+	if ordered := clone.Validators["scores"].Ordered; ordered.Descending {
+		t.Errorf("expected the Ordered rule to be copied, got %v", ordered)
+	}
+	if labels := clone.Validators["email"].Labels; labels["fr"] != "Adresse e-mail" {
+		t.Errorf("expected the Labels to be copied, got %v", labels)
+	}
+	if slugs := clone.Validators["email"].DocSlugs; slugs["regex_not_match"] != "email-format" {
+		t.Errorf("expected the DocSlugs to be copied, got %v", slugs)
+	}
+	if disabled := clone.Validators["beta"].DisabledWhen; len(disabled) != 1 || disabled["required"] == nil {
+		t.Errorf("expected the DisabledWhen rules to be copied, got %v", disabled)
+	}
+	if blocked := clone.Validators["site"].BlockedDomains.(DomainSet); len(blocked) != 1 {
+		t.Errorf("expected the blocked domains to be copied, got %v", blocked)
+	}
+}
@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrencyKeepsPathOrder(t *testing.T) {
+	var calls int32
+	counted := func(interface{}) (bool, *DataError) {
+		atomic.AddInt32(&calls, 1)
+		return true, nil
+	}
+	validators := make(map[string]*Validator)
+	document := make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("field%02d", i)
+		validators[path] = &Validator{Type: "json.Number", Field: path, Boundaries: Boundaries{Min: 0, Max: 10}, CustomTest: counted}
+		document[path] = json.Number(fmt.Sprint(i))
+	}
+
+	_, sequential := Validate(validators, document, Options{Usage: INIT})
+	_, parallel := Validate(validators, document, Options{Usage: INIT, Concurrency: 4})
+	if fmt.Sprint(parallel) != fmt.Sprint(sequential) {
+		t.Errorf("expected the sequential errors %v, got %v", sequential, parallel)
+	}
+	if len(parallel) != 9 || parallel[0].Field != "field11" || parallel[8].Field != "field19" {
+		t.Errorf("expected the errors of field11 to field19 in order, got %v", parallel)
+	}
+	// the custom test only runs on the values within the boundaries
+	if calls != 22 {
+		t.Errorf("expected 22 custom tests, got %d", calls)
+	}
+}
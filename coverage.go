@@ -0,0 +1,226 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct collects the rules the validations run, across a test run or a production window, to find the dead schema entries
+// it is shared by the validations through Options.Coverage, and is safe for concurrent use
+// the rules are: required, default, decimal, boolish, rawjson, type, items, ref, tuple, unique, ordered, regexp, boundaries, money, custom, rights, immutable, cross and transform
+type Coverage struct {
+	mu     sync.Mutex
+	fields map[string]*FieldCoverage // what was recorded, by path
+}
+
+// This struct is what the validations recorded about a field
+type FieldCoverage struct {
+	Seen   int            // the number of validations the field was submitted to
+	Rules  map[string]int // the number of times each rule was run
+	Errors map[string]int // the number of errors reported, by code
+}
+
+// This struct is the report of a Coverage against a schema
+type CoverageReport struct {
+	Fields         map[string]*FieldCoverage // what was recorded, by path
+	NeverSeen      []string                  // the paths of the fields never submitted, sorted
+	NeverExercised []string                  // the rules never run, as "path: rule", sorted by path
+	NeverFailed    []string                  // the rules run but never failed, as "path: rule", sorted by path – the ones a test suite should make fail
+}
+
+// the error codes of the rules
+var ruleErrors = map[string][]string{
+	"required":   {"required"},
+	"rawjson":    {"invalid_json", "too_large"},
+	"decimal":    {"type_mismatch", "too_many_decimals", "out_of_boundaries"},
+	"boolish":    {"type_mismatch"},
+	"type":       {"type_mismatch", "out_of_range"},
+	"regexp":     {"regex_not_match", "regex_match"},
+	"boundaries": {"out_of_boundaries"},
+	"money":      {"invalid_currency", "type_mismatch", "too_many_decimals", "out_of_boundaries"},
+	"unique":     {"duplicate_item"},
+	"ordered":    {"not_ordered"},
+	"custom":     {"custom_test_failed"},
+	"rights":     {"insufficient_rights"},
+	"immutable":  {"immutable"},
+	"cross":      {"cross_test_failed"},
+	"transform":  {"transform_failed"},
+
+	// the rules sanitizing the value before checking it
+	"searchquery":   {"invalid_search_query"},
+	"localenumbers": {"invalid_format"},
+
+	// the rules of the string formats
+	"format": {"invalid_format", "blocked_domain"},
+	"hash":   {"hash_mismatch"},
+
+	// the rules of the composite sub-documents
+	"schedule":   {"unknown_field", "type_mismatch", "too_many_items", "invalid_format", "invalid_range", "overlapping_ranges"},
+	"birthdate":  {"invalid_format", "too_young", "too_old"},
+	"timewindow": {"too_early", "too_late"},
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method records the validation of a field
+func (c *Coverage) record(path string, seen bool, rules []string, errors []*DataError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fields == nil {
+		c.fields = make(map[string]*FieldCoverage)
+	}
+	field, ok := c.fields[path]
+	if !ok {
+		field = &FieldCoverage{Rules: make(map[string]int), Errors: make(map[string]int)}
+		c.fields[path] = field
+	}
+	if seen {
+		field.Seen++
+	}
+	for _, rule := range rules {
+		field.Rules[rule]++
+	}
+	for _, err := range errors {
+		code := err.Code
+		if code == "" {
+			// the custom tests errors are completed once the validation is over
+			code = "custom_test_failed"
+		}
+		field.Errors[code]++
+	}
+}
+
+// This method returns what was recorded so far, and lists the fields never submitted and the rules of the schema never run or never failed
+func (c *Coverage) Report(schema *Schema) *CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := &CoverageReport{Fields: make(map[string]*FieldCoverage, len(c.fields))}
+	for path, field := range c.fields {
+		copied := &FieldCoverage{Seen: field.Seen, Rules: make(map[string]int, len(field.Rules)), Errors: make(map[string]int, len(field.Errors))}
+		for rule, count := range field.Rules {
+			copied.Rules[rule] = count
+		}
+		for code, count := range field.Errors {
+			copied.Errors[code] = count
+		}
+		report.Fields[path] = copied
+	}
+
+	for _, path := range schema.sortedPaths() {
+		field := report.Fields[path]
+		if field == nil {
+			field = &FieldCoverage{}
+		}
+		if field.Seen == 0 {
+			report.NeverSeen = append(report.NeverSeen, path)
+		}
+		for _, rule := range validatorRules(schema.Validators[path]) {
+			if field.Rules[rule] == 0 {
+				report.NeverExercised = append(report.NeverExercised, path+": "+rule)
+			} else if !ruleFailed(rule, field.Errors) {
+				report.NeverFailed = append(report.NeverFailed, path+": "+rule)
+			}
+		}
+	}
+	return report
+}
+
+// This method forgets everything recorded so far
+func (c *Coverage) Reset() {
+	c.mu.Lock()
+	c.fields = nil
+	c.mu.Unlock()
+}
+
+// CoverageReport stringer
+func (r *CoverageReport) String() string {
+	var str strings.Builder
+	fmt.Fprintf(&str, "%d fields never submitted", len(r.NeverSeen))
+	for _, path := range r.NeverSeen {
+		fmt.Fprintf(&str, "\n  - %s", path)
+	}
+	fmt.Fprintf(&str, "\n%d rules never run", len(r.NeverExercised))
+	for _, rule := range r.NeverExercised {
+		fmt.Fprintf(&str, "\n  - %s", rule)
+	}
+	fmt.Fprintf(&str, "\n%d rules never failed", len(r.NeverFailed))
+	for _, rule := range r.NeverFailed {
+		fmt.Fprintf(&str, "\n  - %s", rule)
+	}
+	return str.String()
+}
+
+// This method marshals the report as JSON, the fields being sorted by the encoder
+func (r *CoverageReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns a new empty collector
+func NewCoverage() *Coverage {
+	return &Coverage{fields: make(map[string]*FieldCoverage)}
+}
+
+// this private function returns the rules the validator is written with
+func validatorRules(validator *Validator) []string {
+	var rules []string
+	add := func(rule string, written bool) {
+		if written {
+			rules = append(rules, rule)
+		}
+	}
+	_, integer := integerBits[validator.Type]
+	add("required", validator.IsRequired || validator.RequiredOnSet)
+	add("default", !validator.IsRequired && (validator.Default != nil || validator.DefaultFromDoc != nil || len(validator.Defaults) > 0 || validator.DefaultNow))
+	add("rawjson", validator.RawJSON)
+	add("decimal", !validator.RawJSON && validator.Decimal != nil)
+	add("boolish", !validator.RawJSON && validator.Decimal == nil && validator.Boolish && validator.Type == "bool")
+	add("localenumbers", validator.LocaleNumbers)
+	add("type", !validator.RawJSON && validator.Type != "interface {}")
+	add("items", validator.Items != nil)
+	add("ref", validator.Ref != "")
+	add("tuple", validator.Tuple != nil)
+	add("unique", validator.UniqueBy != "")
+	add("ordered", validator.Ordered != nil)
+	add("searchquery", validator.SearchQuery != nil)
+	add("regexp", validator.Regexp != "" || len(validator.Regexps) > 0)
+	add("format", validator.Format != "")
+	bounded := validator.Type == "float64" && validator.Boundaries != (Boundaries{})
+	add("boundaries", validator.Decimal == nil && (integer || bounded || validator.Type == "json.Number" || validator.IntBoundaries != nil))
+	add("money", validator.Money != nil)
+	add("schedule", validator.Schedule != nil)
+	add("timewindow", validator.TimeWindow != nil)
+	add("birthdate", validator.Birthdate != nil)
+	add("custom", validator.CustomTest != nil)
+	add("rights", validator.Rights != [3]int{})
+	add("immutable", validator.Immutable)
+	add("hash", validator.HashOf != "")
+	add("cross", validator.CrossTest != nil)
+	add("transform", validator.Transform != nil)
+	return rules
+}
+
+// this private function tells whether the errors recorded for a field include the ones of the rule; the rules that cannot fail (default) are deemed to have failed
+func ruleFailed(rule string, errors map[string]int) bool {
+	codes, ok := ruleErrors[rule]
+	if !ok {
+		return true
+	}
+	for _, code := range codes {
+		if errors[code] > 0 {
+			return true
+		}
+	}
+	return false
+}
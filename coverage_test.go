@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	coverage := NewCoverage()
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":  {Type: "string", Regexp: "^a", IsRequired: true},
+		"age":   {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 10}, CustomTest: func(interface{}) (bool, *DataError) { return true, nil }},
+		"ratio": {Type: "float64", Boundaries: Boundaries{Min: 0, Max: 1}},
+		"never": {Type: "string", Regexp: "x"},
+		"role":  {Type: "string", Default: func(interface{}) interface{} { return "user" }},
+	}}
+	schema.Validate(map[string]interface{}{"name": "b", "age": json.Number("3"), "ratio": 2.0}, Options{Usage: INIT, Coverage: coverage, Concurrency: 4})
+	schema.Validate(map[string]interface{}{"name": "a", "age": json.Number("11")}, Options{Usage: INIT, Coverage: coverage})
+
+	report := coverage.Report(schema)
+	name := report.Fields["name"]
+	if name.Seen != 2 || name.Rules["required"] != 2 || name.Rules["regexp"] != 2 || name.Errors["regex_not_match"] != 1 {
+		t.Errorf("unexpected name coverage %+v", name)
+	}
+	if ratio := report.Fields["ratio"]; ratio.Seen != 1 || ratio.Rules["boundaries"] != 1 || ratio.Errors["out_of_boundaries"] != 1 {
+		t.Errorf("unexpected ratio coverage %+v", ratio)
+	}
+	if !reflect.DeepEqual(report.NeverSeen, []string{"never", "role"}) {
+		t.Errorf("expected never and role never submitted, got %v", report.NeverSeen)
+	}
+	// the custom test of age is not run on the out of boundaries value
+	if expected := []string{"never: type", "never: regexp", "role: type"}; !reflect.DeepEqual(report.NeverExercised, expected) {
+		t.Errorf("expected %v, got %v", expected, report.NeverExercised)
+	}
+	if expected := []string{"age: type", "age: custom", "name: required", "name: type", "ratio: type"}; !reflect.DeepEqual(report.NeverFailed, expected) {
+		t.Errorf("expected %v, got %v", expected, report.NeverFailed)
+	}
+	if str := report.String(); !strings.HasPrefix(str, "2 fields never submitted\n  - never\n  - role\n3 rules never run") ||
+		!strings.HasSuffix(str, "\n5 rules never failed\n  - age: type\n  - age: custom\n  - name: required\n  - name: type\n  - ratio: type") {
+		t.Errorf("unexpected report %s", str)
+	}
+	if data, err := report.JSON(); err != nil || !strings.Contains(string(data), `"NeverSeen": [`) {
+		t.Errorf("unexpected JSON report %s %v", data, err)
+	}
+
+	coverage.Reset()
+	if report := coverage.Report(schema); len(report.Fields) != 0 || len(report.NeverSeen) != 5 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
+
+func TestValidatorRules(t *testing.T) {
+	tests := []struct {
+		validator *Validator
+		rules     string
+	}{
+		{&Validator{Type: "float64"}, "type"},
+		{&Validator{Type: "float64", Boundaries: Boundaries{Max: 1}}, "type boundaries"},
+		{&Validator{Type: "json.Number", IsRequired: true}, "required type boundaries"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "decimal type"},
+		{&Validator{Type: "json.RawMessage", RawJSON: true}, "rawjson"},
+		{&Validator{Type: "interface {}", Rights: [3]int{1, 0, 0}, Immutable: true}, "rights immutable"},
+	}
+	for _, test := range tests {
+		if rules := strings.Join(validatorRules(test.validator), " "); rules != test.rules {
+			t.Errorf("%+v: expected %q, got %q", test.validator, test.rules, rules)
+		}
+	}
+}
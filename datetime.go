@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// the layouts of the submitted datetime strings, tried in order
+var timeLayouts = []string{time.RFC3339Nano, time.RFC1123Z, time.RFC1123}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function converts the submitted datetime into a time.Time, for a "time.Time" validator
+// the strings are RFC 3339 or RFC 1123 dates, the numbers unix times in seconds (UTC); the time is then converted into the validator Location if any
+func coerceTime(validator *Validator, value interface{}) (interface{}, *DataError) {
+	var parsed time.Time
+	switch v := value.(type) {
+	case time.Time:
+		parsed = v
+	case string:
+		var err error
+		for _, layout := range timeLayouts {
+			if parsed, err = time.Parse(layout, v); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return value, NewError("invalid_format", "", value, map[string]interface{}{"expected": "RFC 3339, RFC 1123 or unix time"})
+		}
+	case json.Number:
+		seconds, err := v.Float64()
+		if err != nil || math.IsInf(seconds, 0) {
+			return value, NewError("invalid_format", "", value, map[string]interface{}{"expected": "RFC 3339, RFC 1123 or unix time"})
+		}
+		if integer, err := v.Int64(); err == nil {
+			parsed = time.Unix(integer, 0).UTC()
+		} else {
+			parsed = unixTime(seconds)
+		}
+	case float64:
+		parsed = unixTime(v)
+	default:
+		signed, unsigned, isUnsigned, ok := integerValue(value)
+		if !ok {
+			// the type check reports the mismatch
+			return value, nil
+		}
+		if isUnsigned {
+			signed = int64(unsigned)
+		}
+		parsed = time.Unix(signed, 0).UTC()
+	}
+
+	if validator.Location != nil {
+		parsed = parsed.In(validator.Location)
+	}
+	return parsed, nil
+}
+
+// this private function returns the time of a unix time in seconds with a fractional part, in UTC
+func unixTime(seconds float64) time.Time {
+	integer, fraction := math.Modf(seconds)
+	return time.Unix(int64(integer), int64(fraction*1e9)).UTC()
+}
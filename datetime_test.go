@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCoerceTime(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"a": {Type: "time.Time"},
+		"b": {Type: "time.Time", Location: time.UTC},
+		"c": {Type: "time.Time"},
+		"d": {Type: "time.Time"},
+	}}
+	dest, errors := schema.Validate(map[string]interface{}{
+		"a": "2020-01-02T03:04:05+02:00",
+		"b": "Thu, 02 Jan 2020 03:04:05 +0200",
+		"c": json.Number("1600000000.5"),
+		"d": 1600000000,
+	}, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if a := dest["a"].(time.Time); a.Hour() != 3 || a.Unix() != 1577927045 {
+		t.Errorf("expected the submitted offset to be kept, got %v", a)
+	}
+	if b := dest["b"].(time.Time); b.Location() != time.UTC || b.Hour() != 1 {
+		t.Errorf("expected the time in UTC, got %v", b)
+	}
+	if c := dest["c"].(time.Time); c.Unix() != 1600000000 || c.Nanosecond() != 5e8 {
+		t.Errorf("expected the fractional unix time, got %v", c)
+	}
+	if d := dest["d"].(time.Time); d.Unix() != 1600000000 {
+		t.Errorf("expected the unix time, got %v", d)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{"a": "yesterday", "b": true}, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Code != "invalid_format" || errors[1].Code != "type_mismatch" {
+		t.Errorf("expected the invalid format and the type mismatch, got %v", errors)
+	}
+}
+
+func TestTimeFactory(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"at": {Type: "time.Time"}}}
+	millis := func(t time.Time) interface{} { return t.UnixNano() / 1e6 }
+	dest, errors := schema.Validate(map[string]interface{}{"at": json.Number("1600000000")}, Options{Usage: SET, TimeFactory: millis})
+	if len(errors) > 0 || dest["at"] != int64(1600000000000) {
+		t.Errorf("expected the converted time, got %v %v", dest, errors)
+	}
+	if dest, _ := schema.Validate(map[string]interface{}{"at": time.Unix(0, 0)}, Options{Usage: GET, TimeFactory: millis}); !dest["at"].(time.Time).Equal(time.Unix(0, 0)) {
+		t.Errorf("expected the time on GET, got %v", dest)
+	}
+}
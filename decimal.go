@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct sets the constraints of a decimal value, e.g. a monetary amount, checked without float64 approximations
+// the value can be submitted as a json.Number, a string, a float64, a math/big number or a decimal type printing itself (Decimal128)
+// it is written in dest according to the validator Type: "*big.Rat", "*big.Float", "json.Number" or "string"
+type Decimal struct {
+	Scale int    // the maximal number of decimal places, unlimited if negative – the exact digits being written then
+	Min   string // the min boundary, as a decimal string – unbounded if empty
+	Max   string // the max boundary, as a decimal string – unbounded if empty
+}
+
+// the syntax of the decimal strings: an optional sign, digits and an optional fraction part, e.g. "-12.50" – big.Rat would also parse "3/4" or "1e3"
+var decimalSyntax = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function parses the value as an exact decimal, checks it against the validator Decimal constraints and converts it into the validator Type
+func coerceDecimal(validator *Validator, value interface{}) (interface{}, *DataError) {
+	rat, ok := parseDecimal(value)
+	if !ok || rat == nil {
+		return value, NewError("type_mismatch", "", typeName(value), map[string]interface{}{"expected": "decimal"})
+	}
+
+	constraints := validator.Decimal
+	if !hasScale(rat, constraints.Scale) {
+		return value, NewError("too_many_decimals", "", value, map[string]interface{}{"scale": constraints.Scale})
+	}
+	min, _ := parseDecimal(constraints.Min)
+	max, _ := parseDecimal(constraints.Max)
+	if (min != nil && rat.Cmp(min) < 0) || (max != nil && rat.Cmp(max) > 0) {
+		return value, NewError("out_of_boundaries", "", value, map[string]interface{}{"min": constraints.Min, "max": constraints.Max})
+	}
+
+	switch validator.Type {
+	case "*big.Rat":
+		return rat, nil
+	case "*big.Float":
+		return new(big.Float).SetRat(rat), nil
+	case "json.Number", "string":
+		str, ok := decimalString(rat, constraints.Scale)
+		if !ok {
+			return value, NewError("too_many_decimals", "", value, map[string]interface{}{"scale": constraints.Scale})
+		}
+		if validator.Type == "json.Number" {
+			return json.Number(str), nil
+		}
+		return str, nil
+	}
+	return value, nil
+}
+
+// this private function writes the decimal with the scale decimal places, or with its exact digits if the scale is negative
+// false if the digits never end, e.g. 1/3 – the decimal has at most scale decimal places otherwise, so it is never rounded
+func decimalString(rat *big.Rat, scale int) (string, bool) {
+	if scale >= 0 {
+		return rat.FloatString(scale), true
+	}
+	// the digits end if the denominator only has the factors 2 and 5 of 10, as many decimal places as the most frequent one
+	denominator := new(big.Int).Set(rat.Denom())
+	places := 0
+	for _, factor := range []int64{2, 5} {
+		divisor, remainder, count := big.NewInt(factor), new(big.Int), 0
+		for {
+			quotient, _ := new(big.Int).QuoRem(denominator, divisor, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			denominator = quotient
+			count++
+		}
+		if count > places {
+			places = count
+		}
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	return rat.FloatString(places), true
+}
+
+// this private function returns the exact rational value of a decimal, nil for an empty string
+func parseDecimal(value interface{}) (*big.Rat, bool) {
+	var str string
+	switch number := value.(type) {
+	case string:
+		if number == "" {
+			return nil, true
+		}
+		str = number
+	case json.Number:
+		str = string(number)
+	case float64:
+		// the shortest representation is the decimal the float was parsed from
+		str = strconv.FormatFloat(number, 'f', -1, 64)
+	case *big.Rat:
+		if number == nil {
+			return nil, false
+		}
+		return new(big.Rat).Set(number), true
+	case *big.Float:
+		if number == nil || number.IsInf() {
+			return nil, false
+		}
+		rat, _ := number.Rat(nil)
+		return rat, true
+	case *big.Int:
+		if number == nil {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(number), true
+	case fmt.Stringer:
+		str = number.String()
+	default:
+		return nil, false
+	}
+
+	if !decimalSyntax.MatchString(str) {
+		return nil, false
+	}
+	rat, ok := new(big.Rat).SetString(str)
+	return rat, ok
+}
+
+// this private function tells whether the decimal has at most scale decimal places
+func hasScale(rat *big.Rat, scale int) bool {
+	if scale < 0 {
+		return true
+	}
+	shifted := new(big.Rat).Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	return shifted.IsInt()
+}
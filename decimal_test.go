@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		validator *Validator
+		value     interface{}
+		stored    string
+		code      string
+	}{
+		{&Validator{Type: "*big.Rat", Decimal: &Decimal{Scale: 2, Min: "0", Max: "1000"}}, json.Number("12.30"), "123/10", ""},
+		{&Validator{Type: "*big.Rat", Decimal: &Decimal{Scale: 2, Min: "0", Max: "1000"}}, json.Number("-0.01"), "", "out_of_boundaries"},
+		{&Validator{Type: "json.Number", Decimal: &Decimal{Scale: 2}}, "1.005", "", "too_many_decimals"},
+		{&Validator{Type: "json.Number", Decimal: &Decimal{Scale: 2}}, "1.5", "1.50", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2, Max: "10"}}, 10.01, "", "out_of_boundaries"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2, Max: "10"}}, 0.1, "0.10", ""},
+		{&Validator{Type: "*big.Float", Decimal: &Decimal{Scale: 4}}, big.NewRat(1, 4), "0.25", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "abc", "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, true, "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "3/4", "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, json.Number("1e3"), "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "0x10", "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "", "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 0}}, "12", "12", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 0}}, "12.5", "", "too_many_decimals"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: -1}}, "12.345", "12.345", ""},
+		{&Validator{Type: "json.Number", Decimal: &Decimal{Scale: -1}}, "-0.000001", "-0.000001", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: -1}}, 0.1, "0.1", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: -1}}, "100", "100", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: -1}}, big.NewRat(1, 8), "0.125", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: -1}}, big.NewRat(1, 3), "", "too_many_decimals"},
+		{&Validator{Type: "*big.Rat", Decimal: &Decimal{Scale: -1}}, big.NewRat(1, 3), "1/3", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, "+1.5", "1.50", ""},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, (*big.Rat)(nil), "", "type_mismatch"},
+		{&Validator{Type: "string", Decimal: &Decimal{Scale: 2}}, (*big.Int)(nil), "", "type_mismatch"},
+	}
+	for _, test := range tests {
+		dest, errors := Validate(map[string]*Validator{"n": test.validator}, map[string]interface{}{"n": test.value}, Options{Usage: INIT})
+		code, stored := "", ""
+		if len(errors) > 0 {
+			code = errors[0].Code
+		} else if str, ok := dest["n"].(interface{ String() string }); ok {
+			stored = str.String()
+		} else if str, ok := dest["n"].(string); ok {
+			stored = str
+		}
+		if code != test.code || stored != test.stored {
+			t.Errorf("%s %v: expected %q %q, got %#v %v", test.validator.Type, test.value, test.stored, test.code, dest["n"], errors)
+		}
+	}
+}
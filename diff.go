@@ -0,0 +1,448 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a difference between two versions of a schema, on a validator rule
+// Breaking tells whether the clients of the old version may be refused or surprised by the new one: a new required field, a tighter rule, a removed field...
+type Change struct {
+	Path     string      // the path of the validator
+	Kind     string      // the kind of change, e.g. "field_added", "boundaries_tightened" – see DiffSchemas
+	Breaking bool        // whether the change breaks the contract of the old version
+	Old      interface{} // the old rule, nil for an added field
+	New      interface{} // the new rule, nil for a removed field
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// Change stringer
+func (c Change) String() string {
+	breaking := ""
+	if c.Breaking {
+		breaking = " (breaking)"
+	}
+	return fmt.Sprintf("%s: %s%s: %v -> %v", c.Path, c.Kind, breaking, c.Old, c.New)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function reports the differences between two versions of a schema, sorted by path, e.g. to flag the incompatible API contract changes in CI
+// the kinds of change are:
+//   - field_added (breaking if required without default), field_removed (breaking)
+//   - type_changed, source_changed, target_changed, regexp_changed, regexps_changed, format_changed (breaking)
+//   - ref_changed (breaking)
+//   - required_added, required_on_set_added (breaking), required_removed, required_on_set_removed
+//   - immutable_added (breaking), immutable_removed
+//   - nullable_removed (breaking), nullable_added
+//   - boundaries_tightened (breaking), boundaries_relaxed – the numeric, decimal and raw JSON size limits
+//   - currencies_tightened (breaking), currencies_relaxed – the accepted currencies and the amount limits of Money
+//   - unique_, order_, search_query_, hash_, schedule_, birthdate_, time_window_ tightened (breaking) or relaxed – a rule added tightens the validator, a rule removed relaxes it
+//   - rights_tightened (breaking), rights_relaxed – the minimal rights of INIT, GET or SET
+//   - exclusive_group_added, any_of_group_added (breaking), exclusive_group_removed, any_of_group_removed – the field groups of the document, with an empty path
+//   - dependent_required_added (breaking), dependent_required_removed – a field required when the one of the path holds a value, the new or old one
+//
+// the changes of the items validators are reported under the array path followed by ".$.", e.g. "lines.$.qty",
+// the ones of the tuple validators under the array path followed by the position or "$" for the additional items, e.g. "point.0",
+// and the ones of the dependent validators under the path of the field they depend on followed by ":", e.g. "country:postcode"
+// the functions of the validators (defaults aside) cannot be compared and are ignored
+func DiffSchemas(old, new *Schema) []Change {
+	changes := diffValidatorMaps("", old.Validators, new.Validators)
+	changes = append(changes, diffGroups("exclusive_group", old.ExclusiveGroups, new.ExclusiveGroups)...)
+	changes = append(changes, diffGroups("any_of_group", old.AnyOfGroups, new.AnyOfGroups)...)
+	changes = append(changes, diffDependents(old, new)...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// This function returns the breaking changes only
+func BreakingChanges(changes []Change) []Change {
+	var breaking []Change
+	for _, change := range changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// this private function reports the validators added, removed and changed between two versions of a set of validators, their paths following the prefix
+func diffValidatorMaps(prefix string, old, new map[string]*Validator) []Change {
+	var changes []Change
+	for path, validator := range new {
+		if _, ok := old[path]; !ok {
+			hasDefault := validator.Default != nil || validator.DefaultFromDoc != nil || len(validator.Defaults) > 0 || validator.DefaultNow
+			changes = append(changes, Change{Path: prefix + path, Kind: "field_added", Breaking: validator.IsRequired && !hasDefault, New: validator.Type})
+		}
+	}
+	for path, before := range old {
+		after, ok := new[path]
+		if !ok {
+			changes = append(changes, Change{Path: prefix + path, Kind: "field_removed", Breaking: true, Old: before.Type})
+			continue
+		}
+		changes = append(changes, diffValidators(prefix+path, before, after)...)
+	}
+	return changes
+}
+
+// this private function reports the differences between two versions of a validator
+func diffValidators(path string, before, after *Validator) []Change {
+	var changes []Change
+	add := func(kind string, breaking bool, old, new interface{}) {
+		changes = append(changes, Change{Path: path, Kind: kind, Breaking: breaking, Old: old, New: new})
+	}
+	// the changes on a rule are either tightened (breaking) or relaxed
+	graded := func(kind string, tightened bool, old, new interface{}) {
+		if tightened {
+			add(kind+"_tightened", true, old, new)
+		} else {
+			add(kind+"_relaxed", false, old, new)
+		}
+	}
+
+	if before.Type != after.Type {
+		add("type_changed", true, before.Type, after.Type)
+	}
+	if before.Source != after.Source {
+		add("source_changed", true, before.Source, after.Source)
+	}
+	if before.Target != after.Target {
+		add("target_changed", true, before.Target, after.Target)
+	}
+	if before.Regexp != after.Regexp {
+		add("regexp_changed", true, before.Regexp, after.Regexp)
+	}
+	if !sameRegexRules(before.Regexps, after.Regexps) {
+		add("regexps_changed", true, before.Regexps, after.Regexps)
+	}
+	if before.Format != after.Format {
+		add("format_changed", true, before.Format, after.Format)
+	}
+	if before.Ref != after.Ref {
+		add("ref_changed", true, before.Ref, after.Ref)
+	}
+	if before.HashOf != after.HashOf {
+		graded("hash", after.HashOf != "", before.HashOf, after.HashOf)
+	}
+	if before.IsRequired != after.IsRequired {
+		if after.IsRequired {
+			add("required_added", true, false, true)
+		} else {
+			add("required_removed", false, true, false)
+		}
+	}
+	if before.RequiredOnSet != after.RequiredOnSet {
+		if after.RequiredOnSet {
+			add("required_on_set_added", true, false, true)
+		} else {
+			add("required_on_set_removed", false, true, false)
+		}
+	}
+	if before.Immutable != after.Immutable {
+		if after.Immutable {
+			add("immutable_added", true, false, true)
+		} else {
+			add("immutable_removed", false, true, false)
+		}
+	}
+	if before.Nullable != after.Nullable {
+		if after.Nullable {
+			add("nullable_added", false, false, true)
+		} else {
+			add("nullable_removed", true, true, false)
+		}
+	}
+
+	if oldMin, oldMax, ok := numericRange(before); ok {
+		if newMin, newMax, ok := numericRange(after); ok && (oldMin.Cmp(newMin) != 0 || oldMax.Cmp(newMax) != 0) {
+			graded("boundaries", newMin.Cmp(oldMin) > 0 || newMax.Cmp(oldMax) < 0, [2]string{oldMin.Text('f', -1), oldMax.Text('f', -1)}, [2]string{newMin.Text('f', -1), newMax.Text('f', -1)})
+		}
+	}
+	if before.Decimal != nil && after.Decimal != nil && *before.Decimal != *after.Decimal {
+		graded("boundaries", decimalTightened(before.Decimal, after.Decimal), *before.Decimal, *after.Decimal)
+	}
+	if before.RawJSON && after.RawJSON && before.MaxRawSize != after.MaxRawSize {
+		graded("boundaries", after.MaxRawSize > 0 && (before.MaxRawSize == 0 || after.MaxRawSize < before.MaxRawSize), before.MaxRawSize, after.MaxRawSize)
+	}
+	if before.Money != nil && after.Money != nil {
+		if tightened, changed := moneyChanged(before.Money, after.Money); changed {
+			graded("currencies", tightened, before.Money, after.Money)
+		}
+	}
+
+	// the rules of the arrays, of the strings and of the composite values: adding one tightens the validator, removing one relaxes it
+	if before.UniqueBy != after.UniqueBy {
+		graded("unique", after.UniqueBy != "", before.UniqueBy, after.UniqueBy)
+	}
+	if !reflect.DeepEqual(before.Ordered, after.Ordered) {
+		// only giving up the strict order relaxes an order
+		relaxed := before.Ordered != nil && after.Ordered != nil && before.Ordered.Key == after.Ordered.Key && before.Ordered.Descending == after.Ordered.Descending && !after.Ordered.Strict
+		graded("order", after.Ordered != nil && !relaxed, before.Ordered, after.Ordered)
+	}
+	if !reflect.DeepEqual(before.SearchQuery, after.SearchQuery) {
+		tightened := after.SearchQuery != nil
+		if before.SearchQuery != nil && after.SearchQuery != nil {
+			oldMin, oldMax := before.SearchQuery.lengths()
+			newMin, newMax := after.SearchQuery.lengths()
+			tightened = newMin > oldMin || newMax < oldMax || before.SearchQuery.CaseSensitive != after.SearchQuery.CaseSensitive
+		}
+		graded("search_query", tightened, before.SearchQuery, after.SearchQuery)
+	}
+	if !reflect.DeepEqual(before.Schedule, after.Schedule) {
+		tightened := after.Schedule != nil
+		if before.Schedule != nil && after.Schedule != nil {
+			oldOpen, oldClose := before.Schedule.Keys()
+			newOpen, newClose := after.Schedule.Keys()
+			tightened = oldOpen != newOpen || oldClose != newClose || limitLowered(before.Schedule.MaxRanges, after.Schedule.MaxRanges)
+		}
+		graded("schedule", tightened, before.Schedule, after.Schedule)
+	}
+	if (before.Birthdate == nil) != (after.Birthdate == nil) || (before.Birthdate != nil && (before.Birthdate.MinAge != after.Birthdate.MinAge || before.Birthdate.MaxAge != after.Birthdate.MaxAge)) {
+		tightened := after.Birthdate != nil
+		if before.Birthdate != nil && after.Birthdate != nil {
+			tightened = after.Birthdate.MinAge > before.Birthdate.MinAge || limitLowered(before.Birthdate.MaxAge, after.Birthdate.MaxAge)
+		}
+		graded("birthdate", tightened, before.Birthdate, after.Birthdate)
+	}
+	if !reflect.DeepEqual(before.TimeWindow, after.TimeWindow) {
+		tightened := after.TimeWindow != nil
+		if before.TimeWindow != nil && after.TimeWindow != nil {
+			tightened = timeWindowTightened(before.TimeWindow, after.TimeWindow)
+		}
+		graded("time_window", tightened, before.TimeWindow, after.TimeWindow)
+	}
+
+	if before.Rights != after.Rights {
+		tightened := false
+		for i := range after.Rights {
+			tightened = tightened || after.Rights[i] > before.Rights[i]
+		}
+		graded("rights", tightened, before.Rights, after.Rights)
+	}
+
+	changes = append(changes, diffValidatorMaps(path+".$.", before.Items, after.Items)...)
+	changes = append(changes, diffValidatorMaps(path+".", tupleValidators(before), tupleValidators(after))...)
+	return changes
+}
+
+// this private function tells whether a new limit, unlimited if 0, is lower than the old one
+func limitLowered(before, after int) bool {
+	return after > 0 && (before == 0 || after < before)
+}
+
+// this private function tells whether the new time window refuses times the old one accepted, on either side of the current time
+func timeWindowTightened(before, after *TimeWindow) bool {
+	now := time.Now()
+	oldEarliest, oldLatest := before.Bounds(now)
+	newEarliest, newLatest := after.Bounds(now)
+	return (!newEarliest.IsZero() && (oldEarliest.IsZero() || newEarliest.After(oldEarliest))) ||
+		(!newLatest.IsZero() && (oldLatest.IsZero() || newLatest.Before(oldLatest)))
+}
+
+// this private function returns the validators of the tuple positions by index, and the one of the additional items by "$" – see Validator.Tuple
+func tupleValidators(validator *Validator) map[string]*Validator {
+	positions := make(map[string]*Validator, len(validator.Tuple)+1)
+	for i, item := range validator.Tuple {
+		positions[strconv.Itoa(i)] = item
+	}
+	if validator.AdditionalItems != nil {
+		positions["$"] = validator.AdditionalItems
+	}
+	return positions
+}
+
+// this private function tells whether two lists of regexp rules check the same patterns in the same modes, their messages aside
+func sameRegexRules(before, after []RegexRule) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if before[i].Pattern != after[i].Pattern || before[i].Mode != after[i].Mode {
+			return false
+		}
+	}
+	return true
+}
+
+// this private function returns the numeric boundaries of the validator, IntBoundaries or else Boundaries, and whether it has some
+// the float64 values are unbounded when no Boundaries are set, and the decimals have their own boundaries, so their validators have none
+func numericRange(validator *Validator) (*big.Float, *big.Float, bool) {
+	if validator.Decimal != nil || validator.Money != nil {
+		return nil, nil, false
+	}
+	if validator.Type == "float64" && validator.Boundaries == (Boundaries{}) {
+		return new(big.Float).SetInf(true), new(big.Float).SetInf(false), true
+	}
+	if validator.IntBoundaries != nil {
+		return new(big.Float).SetInt64(validator.IntBoundaries.Min), new(big.Float).SetInt64(validator.IntBoundaries.Max), true
+	}
+	if _, ok := integerBits[validator.Type]; ok || validator.Type == "json.Number" || validator.Type == "float64" {
+		min, max := validator.Boundaries.Min, validator.Boundaries.Max
+		if math.IsNaN(min) || math.IsNaN(max) {
+			return nil, nil, false
+		}
+		return big.NewFloat(min), big.NewFloat(max), true
+	}
+	return nil, nil, false
+}
+
+// this private function tells whether the new decimal constraints refuse values the old ones accepted
+func decimalTightened(before, after *Decimal) bool {
+	if after.Scale >= 0 && (before.Scale < 0 || after.Scale < before.Scale) {
+		return true
+	}
+	return limitTightened(before.Min, after.Min, -1) || limitTightened(before.Max, after.Max, 1)
+}
+
+// this private function tells whether a new decimal limit refuses values the old one accepted – sign is -1 for a min, 1 for a max
+// an empty limit is unbounded
+func limitTightened(before, after string, sign int) bool {
+	if after == "" {
+		return false
+	}
+	if before == "" {
+		return true
+	}
+	old, ok := parseDecimal(before)
+	if !ok {
+		return true
+	}
+	new, ok := parseDecimal(after)
+	if !ok {
+		return true
+	}
+	return new.Cmp(old)*sign < 0
+}
+
+// this private function compares the currencies and amount limits of two Money rules: it returns whether the new one refuses amounts the old one accepted, and whether they differ
+func moneyChanged(before, after *Money) (bool, bool) {
+	tightened, changed := false, false
+	accepted := func(money *Money, code string) bool {
+		if len(money.Currencies) == 0 {
+			_, ok := CurrencyMinorUnits(code)
+			return ok
+		}
+		for _, currency := range money.Currencies {
+			if currency == code {
+				return true
+			}
+		}
+		return false
+	}
+	if len(before.Currencies) != len(after.Currencies) {
+		changed = true
+	}
+	for _, code := range before.Currencies {
+		if !accepted(after, code) {
+			tightened, changed = true, true
+		}
+	}
+	if len(before.Currencies) == 0 && len(after.Currencies) > 0 {
+		tightened = true
+	}
+	for _, code := range after.Currencies {
+		if !accepted(before, code) {
+			changed = true
+		}
+	}
+
+	codes := make(map[string]bool)
+	for code := range before.Min {
+		codes[code] = true
+	}
+	for code := range after.Min {
+		codes[code] = true
+	}
+	for code := range before.Max {
+		codes[code] = true
+	}
+	for code := range after.Max {
+		codes[code] = true
+	}
+	for code := range codes {
+		if before.Min[code] != after.Min[code] || before.Max[code] != after.Max[code] {
+			changed = true
+			tightened = tightened || limitTightened(before.Min[code], after.Min[code], -1) || limitTightened(before.Max[code], after.Max[code], 1)
+		}
+	}
+	return tightened, changed
+}
+
+// this private function reports the changes of the fields required and of the validators run when another field holds a value
+func diffDependents(old, new *Schema) []Change {
+	var changes []Change
+	for path, required := range new.DependentRules {
+		for _, dependent := range required {
+			if !containsString(old.DependentRules[path], dependent) {
+				changes = append(changes, Change{Path: path, Kind: "dependent_required_added", Breaking: true, New: dependent})
+			}
+		}
+	}
+	for path, required := range old.DependentRules {
+		for _, dependent := range required {
+			if !containsString(new.DependentRules[path], dependent) {
+				changes = append(changes, Change{Path: path, Kind: "dependent_required_removed", Old: dependent})
+			}
+		}
+	}
+
+	paths := make(map[string]bool, len(old.DependentValidators)+len(new.DependentValidators))
+	for path := range old.DependentValidators {
+		paths[path] = true
+	}
+	for path := range new.DependentValidators {
+		paths[path] = true
+	}
+	for path := range paths {
+		changes = append(changes, diffValidatorMaps(path+":", old.DependentValidators[path], new.DependentValidators[path])...)
+	}
+	return changes
+}
+
+// this private function reports the field groups added and removed, a group being the same whatever the order of its paths
+func diffGroups(kind string, old, new [][]string) []Change {
+	key := func(group []string) string {
+		sorted := append([]string(nil), group...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, ",")
+	}
+	before := make(map[string]bool, len(old))
+	for _, group := range old {
+		before[key(group)] = true
+	}
+	after := make(map[string]bool, len(new))
+	var changes []Change
+	for _, group := range new {
+		after[key(group)] = true
+		if !before[key(group)] {
+			changes = append(changes, Change{Kind: kind + "_added", Breaking: true, New: group})
+		}
+	}
+	for _, group := range old {
+		if !after[key(group)] {
+			changes = append(changes, Change{Kind: kind + "_removed", Old: group})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	old := &Schema{Validators: map[string]*Validator{
+		"age":      {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 150}},
+		"name":     {Type: "string", IsRequired: true},
+		"nickname": {Type: "string"},
+		"score":    {Type: "float64"},
+		"ratio":    {Type: "float64", Boundaries: Boundaries{Min: 0, Max: 1}},
+		"price":    {Type: "string", Decimal: &Decimal{Scale: 2, Max: "100"}},
+		"balance":  {Type: "map[string]interface {}", Money: &Money{Currencies: []string{"EUR", "USD"}}},
+		"code":     {Type: "string", Regexp: "^[a-z]+$", Rights: [3]int{0, 0, 1}},
+		"id":       {Type: "string", Immutable: true},
+	}}
+	new := &Schema{Validators: map[string]*Validator{
+		"age":     {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 120}},
+		"name":    {Type: "string"},
+		"score":   {Type: "float64", Boundaries: Boundaries{Min: 0, Max: 100}},
+		"ratio":   {Type: "float64"},
+		"price":   {Type: "string", Decimal: &Decimal{Scale: 2, Max: "1000"}},
+		"balance": {Type: "map[string]interface {}", Money: &Money{Currencies: []string{"EUR"}}},
+		"code":    {Type: "string", Regexp: "^[a-z0-9]+$", Rights: [3]int{0, 0, 2}},
+		"id":      {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 10}},
+		"email":   {Type: "string", IsRequired: true},
+		"country": {Type: "string", IsRequired: true, Default: func(interface{}) interface{} { return "FR" }},
+	}}
+
+	expected := []string{
+		"age: boundaries_tightened (breaking): [0 150] -> [0 120]",
+		"balance: currencies_tightened (breaking): EUR,USD -> EUR",
+		"code: regexp_changed (breaking): ^[a-z]+$ -> ^[a-z0-9]+$",
+		"code: rights_tightened (breaking): [0 0 1] -> [0 0 2]",
+		"country: field_added: <nil> -> string",
+		"email: field_added (breaking): <nil> -> string",
+		"id: immutable_removed: true -> false",
+		"id: type_changed (breaking): string -> json.Number",
+		"name: required_removed: true -> false",
+		"nickname: field_removed (breaking): string -> <nil>",
+		"price: boundaries_relaxed: {2  100} -> {2  1000}",
+		"ratio: boundaries_relaxed: [0 1] -> [-Inf +Inf]",
+		"score: boundaries_tightened (breaking): [-Inf +Inf] -> [0 100]",
+	}
+	changes := DiffSchemas(old, new)
+	for i, change := range changes {
+		if change.Kind == "currencies_tightened" {
+			changes[i].Old, changes[i].New = strings.Join(change.Old.(*Money).Currencies, ","), strings.Join(change.New.(*Money).Currencies, ",")
+		}
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], change.String())
+		}
+	}
+	if breaking := BreakingChanges(changes); len(breaking) != 8 {
+		t.Errorf("expected 8 breaking changes, got %v", breaking)
+	}
+	if changes := DiffSchemas(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestDecimalTightened(t *testing.T) {
+	tests := []struct {
+		before, after Decimal
+		tightened     bool
+	}{
+		{Decimal{Scale: 2}, Decimal{Scale: 3}, false},
+		{Decimal{Scale: 2}, Decimal{Scale: 1}, true},
+		{Decimal{Scale: -1}, Decimal{Scale: 4}, true},
+		{Decimal{Scale: 2, Min: "0"}, Decimal{Scale: 2}, false},
+		{Decimal{Scale: 2}, Decimal{Scale: 2, Min: "-10"}, true},
+		{Decimal{Scale: 2, Min: "-10"}, Decimal{Scale: 2, Min: "-20"}, false},
+		{Decimal{Scale: 2, Max: "10"}, Decimal{Scale: 2, Max: "9.99"}, true},
+	}
+	for _, test := range tests {
+		if tightened := decimalTightened(&test.before, &test.after); tightened != test.tightened {
+			t.Errorf("%v -> %v: expected %v, got %v", test.before, test.after, test.tightened, tightened)
+		}
+	}
+}
+
+func TestDiffArrayValidators(t *testing.T) {
+	old := &Schema{Validators: map[string]*Validator{
+		"lines": {Type: "[]interface {}", Items: map[string]*Validator{"qty": {Type: "int"}, "note": {Type: "string"}}},
+		"point": {Type: "[]interface {}", Tuple: []*Validator{{Type: "float64"}, {Type: "float64"}}},
+	}}
+	new := &Schema{Validators: map[string]*Validator{
+		"lines": {Type: "[]interface {}", Items: map[string]*Validator{"qty": {Type: "int", IsRequired: true}, "sku": {Type: "string"}}},
+		"point": {Type: "[]interface {}", Tuple: []*Validator{{Type: "float64"}, {Type: "string"}}, AdditionalItems: &Validator{Type: "string"}},
+	}}
+	expected := []string{
+		"lines.$.note: field_removed (breaking): string -> <nil>",
+		"lines.$.qty: required_added (breaking): false -> true",
+		"lines.$.sku: field_added: <nil> -> string",
+		"point.$: field_added: <nil> -> string",
+		"point.1: type_changed (breaking): float64 -> string",
+	}
+	changes := DiffSchemas(old, new)
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], change.String())
+		}
+	}
+}
+
+func TestDiffRules(t *testing.T) {
+	old := &Schema{
+		Validators: map[string]*Validator{
+			"author":  {Type: "map[string]interface {}", Ref: "user"},
+			"email":   {Type: "string", Nullable: true},
+			"tags":    {Type: "[]interface {}", Ordered: &Ordered{Strict: true}},
+			"born":    {Type: "string", Birthdate: &Birthdate{MinAge: 13}},
+			"country": {Type: "string"},
+		},
+		DependentRules: map[string][]string{"country": {"city"}},
+	}
+	new := &Schema{
+		Validators: map[string]*Validator{
+			"author":  {Type: "map[string]interface {}", Ref: "member", RequiredOnSet: true},
+			"email":   {Type: "string"},
+			"tags":    {Type: "[]interface {}", Ordered: &Ordered{}, UniqueBy: "id"},
+			"born":    {Type: "string", Birthdate: &Birthdate{MinAge: 18}},
+			"country": {Type: "string"},
+		},
+		DependentRules:      map[string][]string{"country": {"city", "postcode"}},
+		DependentValidators: map[string]map[string]*Validator{"country": {"postcode": {Type: "string"}}},
+	}
+	expected := []string{
+		"author: ref_changed (breaking): user -> member",
+		"author: required_on_set_added (breaking): false -> true",
+		"born: birthdate_tightened (breaking): &{13 0 UTC <nil>} -> &{18 0 UTC <nil>}",
+		"country: dependent_required_added (breaking): <nil> -> postcode",
+		"country:postcode: field_added: <nil> -> string",
+		"email: nullable_removed (breaking): true -> false",
+		"tags: order_relaxed: &{false  true} -> &{false  false}",
+		"tags: unique_tightened (breaking):  -> id",
+	}
+	changes := DiffSchemas(old, new)
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], change.String())
+		}
+	}
+}
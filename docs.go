@@ -0,0 +1,34 @@
+package validation
+
+import "strings"
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the URL of the documentation of the rule at fault: the base URL followed by its slug, the error code by default
+func docURL(base string, slug string) string {
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(slug, "/")
+}
+
+// this private function links the errors about the field to the documentation of its rules, as the slugs of the validator name it – see Validator.DocSlugs
+// the errors are copied before being linked, since custom tests may return shared ones
+func linkFieldErrors(errors []*DataError, field string, slugs map[string]string, base string) {
+	if base == "" || len(slugs) == 0 {
+		return
+	}
+	for i, err := range errors {
+		if err == nil || err.Field != field || err.Docs != "" {
+			continue
+		}
+		slug, ok := slugs[errorCode(err)]
+		if !ok {
+			if slug, ok = slugs["*"]; !ok {
+				continue
+			}
+		}
+		linked := *err
+		linked.Docs = docURL(base, slug)
+		errors[i] = &linked
+	}
+}
@@ -0,0 +1,23 @@
+package validation
+
+import "testing"
+
+func TestDocs(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"email": {Type: "string", IsRequired: true, DocSlugs: map[string]string{"required": "fields/email"}},
+		"lines": {Type: "[]map[string]interface {}", Items: map[string]*Validator{"sku": {Type: "string", DocSlugs: map[string]string{"*": "/lines"}}}},
+	}}
+	_, errors := schema.Validate(map[string]interface{}{"lines": []interface{}{map[string]interface{}{"sku": 1}}}, Options{Usage: INIT, DocBaseURL: "https://api.example.com/docs/"})
+	if len(errors) != 2 || errors[0].Docs != "https://api.example.com/docs/fields/email" || errors[1].Docs != "https://api.example.com/docs/lines" {
+		t.Errorf("expected the slugs of the validators, got %v", errors)
+	}
+
+	// the other rules link to their error code
+	_, errors = schema.Validate(map[string]interface{}{"email": 1}, Options{Usage: INIT, DocBaseURL: "https://api.example.com/docs"})
+	if len(errors) != 1 || errors[0].Docs != "https://api.example.com/docs/type_mismatch" {
+		t.Errorf("expected the code as the slug, got %v", errors)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Docs != "" {
+		t.Errorf("expected no link without a base URL, got %v", errors)
+	}
+}
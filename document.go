@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"sort"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function checks the groups of fields of the schema against the merged document: one field at most of each exclusive group, one at least of each any-of group
+// an any-of group is only checked when the whole document is known, on INIT and on SET with Existing
+func checkGroups(schema *Schema, merged map[string]interface{}, opt Options, errors *[]*DataError) {
+	for _, group := range schema.ExclusiveGroups {
+		if present := presentFields(schema, group, merged, opt.Usage); len(present) > 1 {
+			*errors = append(*errors, NewError("exclusive_fields", "", present, map[string]interface{}{"fields": group}))
+		}
+	}
+	if opt.Usage == SET && opt.Existing == nil {
+		return
+	}
+	for _, group := range schema.AnyOfGroups {
+		if present := presentFields(schema, group, merged, opt.Usage); len(present) == 0 {
+			*errors = append(*errors, NewError("missing_any_of", "", nil, map[string]interface{}{"fields": group}))
+		}
+	}
+}
+
+// this private function returns the paths of the group whose field holds a value in the merged document – a removed field holds none
+func presentFields(schema *Schema, group []string, merged map[string]interface{}, usage int) []string {
+	var present []string
+	for _, path := range group {
+		out := path
+		if validator, ok := schema.Validators[path]; ok {
+			_, out = validator.Paths(path, usage)
+		}
+		if value := readExisting(merged, out); value != Unset && !isEmpty(value) {
+			present = append(present, path)
+		}
+	}
+	return present
+}
+
+// this private function runs the document test of the schema against the validated document, the existing one overlaid with dest on SET
+func checkDocument(schema *Schema, dest map[string]interface{}, opt Options, errors *[]*DataError) {
+	doc := resultDocument(dest, opt)
+	var ok bool
+	var err *DataError
+	if panicked := callSafely("", "DocumentTest", func() { ok, err = schema.DocumentTest(doc) }); panicked != nil {
+		*errors = append(*errors, panicked)
+		return
+	}
+	if !ok {
+		if err == nil {
+			err = NewError("document_test_failed", "", nil, nil)
+		}
+		*errors = append(*errors, err)
+	}
+}
+
+// this private function returns the whole validated document: dest itself, or on SET the existing document overlaid with the dest paths
+func resultDocument(dest map[string]interface{}, opt Options) map[string]interface{} {
+	if opt.Usage != SET {
+		return dest
+	}
+	doc := make(map[string]interface{})
+	if opt.Existing != nil {
+		doc = copyDeep(opt.Existing)
+	}
+	for path, value := range dest {
+		if err := tools.WriteDeep(doc, path, value); err != nil {
+			panic(err)
+		}
+	}
+	return doc
+}
+
+// this private function returns the stricter of two limits, 0 being unlimited
+func stricterLimit(a, b int) int {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// this private function returns a deep copy of groups of paths
+func cloneGroups(groups [][]string) [][]string {
+	if groups == nil {
+		return nil
+	}
+	clone := make([][]string, len(groups))
+	for i, group := range groups {
+		clone[i] = append([]string(nil), group...)
+	}
+	return clone
+}
+
+// this private function returns the fields of the dependent rules missing from the merged document, as required errors
+func checkDependents(schema *Schema, merged map[string]interface{}, opt Options, errors *[]*DataError) {
+	paths := make([]string, 0, len(schema.DependentRules))
+	for path := range schema.DependentRules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if len(presentFields(schema, []string{path}, merged, opt.Usage)) == 0 {
+			continue
+		}
+		for _, required := range schema.DependentRules[path] {
+			if len(presentFields(schema, []string{required}, merged, opt.Usage)) == 0 {
+				*errors = append(*errors, NewError("required", required, nil, map[string]interface{}{"dependsOn": path}))
+			}
+		}
+	}
+}
+
+// this private function returns a copy of the schema with the validators of the fields holding a value in the document added up
+func applyDependents(schema *Schema, _map map[string]interface{}, opt Options) *Schema {
+	merged := rename(schema, _map, opt.Usage)
+	if opt.Existing != nil && opt.Usage != GET {
+		merged = mergeDeep(copyDeep(opt.Existing), merged)
+	}
+
+	paths := make([]string, 0, len(schema.DependentValidators))
+	for path := range schema.DependentValidators {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// in a stable order, a validator depending on several fields being the one of the last path
+	var dependents map[string]*Validator
+	for _, path := range paths {
+		if len(presentFields(schema, []string{path}, merged, opt.Usage)) == 0 {
+			continue
+		}
+		if dependents == nil {
+			dependents = make(map[string]*Validator)
+		}
+		for dependent, validator := range schema.DependentValidators[path] {
+			dependents[dependent] = validator
+		}
+	}
+	return applyOverride(schema, dependents)
+}
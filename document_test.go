@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocumentRules(t *testing.T) {
+	schema := &Schema{
+		Validators:      map[string]*Validator{"email": {Type: "string"}, "phone": {Type: "string", Nullable: true}, "min": {Type: "float64"}, "max": {Type: "float64"}},
+		ExclusiveGroups: [][]string{{"email", "phone"}},
+		AnyOfGroups:     [][]string{{"email", "phone"}},
+		MaxFields:       3,
+		DocumentTest: func(doc map[string]interface{}) (bool, *DataError) {
+			min, _ := doc["min"].(float64)
+			max, _ := doc["max"].(float64)
+			return min <= max, nil
+		},
+	}
+	tests := []struct {
+		doc   map[string]interface{}
+		opt   Options
+		codes []string
+	}{
+		{map[string]interface{}{"email": "e"}, Options{Usage: INIT}, nil},
+		{map[string]interface{}{"email": "e", "phone": "p"}, Options{Usage: INIT}, []string{"exclusive_fields"}},
+		{map[string]interface{}{"min": 2.0, "max": 1.0}, Options{Usage: INIT}, []string{"missing_any_of"}},
+		{map[string]interface{}{"email": "e", "min": 2.0, "max": 1.0}, Options{Usage: INIT}, []string{"document_test_failed"}},
+		{map[string]interface{}{"email": "e", "phone": "p", "min": 2.0, "max": 1.0}, Options{Usage: INIT}, []string{"max_fields_exceeded"}},
+		{map[string]interface{}{"phone": "p"}, Options{Usage: SET, Existing: map[string]interface{}{"email": "e"}}, []string{"exclusive_fields"}},
+		{map[string]interface{}{"phone": nil}, Options{Usage: SET, Existing: map[string]interface{}{"phone": "p"}}, []string{"missing_any_of"}},
+		{map[string]interface{}{"phone": nil}, Options{Usage: SET}, nil},
+		{map[string]interface{}{"min": 1.0}, Options{Usage: SET, Existing: map[string]interface{}{"phone": "p", "max": 0.5}}, []string{"document_test_failed"}},
+		{map[string]interface{}{"email": "e", "phone": "p"}, Options{Usage: GET}, nil},
+	}
+	for _, test := range tests {
+		_, errors := schema.Validate(test.doc, test.opt)
+		if len(errors) != len(test.codes) {
+			t.Errorf("%v: expected %v, got %v", test.doc, test.codes, errors)
+			continue
+		}
+		for i, err := range errors {
+			if err.Code != test.codes[i] || err.Field != "" {
+				t.Errorf("%v: expected %s on the document, got %s on %q", test.doc, test.codes[i], err.Code, err.Field)
+			}
+		}
+	}
+
+	// the option and the schema limits: the stricter applies
+	if _, errors := schema.Validate(map[string]interface{}{"email": "e", "min": 1.0}, Options{Usage: INIT, MaxFields: 1}); len(errors) != 1 || errors[0].Code != "max_fields_exceeded" {
+		t.Errorf("expected the option limit to apply, got %v", errors)
+	}
+	clone := schema.Clone()
+	clone.ExclusiveGroups[0][0] = "min"
+	if schema.ExclusiveGroups[0][0] != "email" || clone.DocumentTest == nil {
+		t.Errorf("expected the clone to be deep")
+	}
+}
+
+func TestGroupsCheck(t *testing.T) {
+	schema := &Schema{
+		Validators:      map[string]*Validator{"email": {Type: "string", IsRequired: true}, "phone": {Type: "string", IsRequired: true}},
+		ExclusiveGroups: [][]string{{"email", "phone"}},
+		AnyOfGroups:     [][]string{{"email"}, {"email", "fax"}},
+	}
+	expected := []string{"less than two fields", "unknown field in the any-of group", "several required fields"}
+	errors := CheckSchema(schema)
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for _, message := range expected {
+		found := false
+		for _, err := range errors {
+			found = found || strings.Contains(err.Error(), message)
+		}
+		if !found {
+			t.Errorf("expected %q in %v", message, errors)
+		}
+	}
+
+	changes := DiffSchemas(&Schema{ExclusiveGroups: [][]string{{"a", "b"}}, AnyOfGroups: [][]string{{"c", "d"}}}, &Schema{ExclusiveGroups: [][]string{{"b", "a"}}, AnyOfGroups: [][]string{{"c", "e"}}})
+	if len(changes) != 2 || changes[0].Kind != "any_of_group_added" || !changes[0].Breaking || changes[1].Kind != "any_of_group_removed" || changes[1].Breaking {
+		t.Errorf("expected the any-of group to be replaced, got %v", changes)
+	}
+
+	description, err := describeSchema(&Schema{Validators: schema.Validators, ExclusiveGroups: schema.ExclusiveGroups, MaxFields: 5})
+	if err != nil || !strings.Contains(string(description), `"ExclusiveGroups":[["email","phone"]]`) || !strings.Contains(string(description), `"MaxFields":5`) {
+		t.Errorf("expected the groups and the limit to be published, got %s %v", description, err)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	schema := &Schema{
+		Validators:     map[string]*Validator{"card": {Type: "string"}, "cvv": {Type: "string"}, "country": {Type: "string"}, "zip": {Type: "string"}},
+		DependentRules: map[string][]string{"card": {"cvv"}},
+		DependentValidators: map[string]map[string]*Validator{"country": {
+			"zip": {Type: "string", Regexp: `^\d{5}$`, IsRequired: true},
+		}},
+	}
+	if errors := CheckSchema(schema); len(errors) > 0 {
+		t.Fatalf("expected no schema errors, got %v", errors)
+	}
+	tests := []struct {
+		doc    map[string]interface{}
+		opt    Options
+		errors []string
+	}{
+		{map[string]interface{}{"card": "c", "country": "US", "zip": "abc"}, Options{Usage: INIT}, []string{"regex_not_match zip", "required cvv"}},
+		{map[string]interface{}{"zip": "abc"}, Options{Usage: INIT}, nil},
+		{map[string]interface{}{"country": "US"}, Options{Usage: INIT}, []string{"required zip"}},
+		{map[string]interface{}{"card": "c"}, Options{Usage: SET, Existing: map[string]interface{}{"cvv": "1"}}, nil},
+		{map[string]interface{}{"zip": "abc"}, Options{Usage: SET, Existing: map[string]interface{}{"country": "US"}}, []string{"regex_not_match zip"}},
+	}
+	for _, test := range tests {
+		_, errors := schema.Validate(test.doc, test.opt)
+		var got []string
+		for _, err := range errors {
+			got = append(got, err.Code+" "+err.Field)
+		}
+		if strings.Join(got, ", ") != strings.Join(test.errors, ", ") {
+			t.Errorf("%v: expected %v, got %v", test.doc, test.errors, got)
+		}
+	}
+
+	errors := CheckSchema(&Schema{
+		Validators:          map[string]*Validator{"card": {Type: "string"}},
+		DependentRules:      map[string][]string{"card": {"cvv"}},
+		DependentValidators: map[string]map[string]*Validator{"country": {"zip": {Type: "x y"}}},
+	})
+	if len(errors) != 3 {
+		t.Errorf("expected the unknown fields and the invalid dependent type, got %v", errors)
+	}
+	clone := schema.Clone()
+	clone.DependentRules["card"][0] = "zip"
+	clone.DependentValidators["country"]["zip"].Regexp = ""
+	if schema.DependentRules["card"][0] != "cvv" || schema.DependentValidators["country"]["zip"].Regexp == "" {
+		t.Errorf("expected the clone to be deep")
+	}
+}
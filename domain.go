@@ -0,0 +1,287 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This interface is a list of domains, e.g. the disposable email providers – see Validator.BlockedDomains
+// the domains are passed in their ASCII form, lower-cased, and the parent ones are checked too: "x.mailinator.com" then "mailinator.com"
+type DomainList interface {
+	Contains(domain string) bool
+}
+
+// This type is a DomainList held in memory, by ASCII domain
+type DomainSet map[string]bool
+
+// the parameters of the punycode of the internationalized domain names – see RFC 3492
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method tells whether the domain is in the set
+func (s DomainSet) Contains(domain string) bool {
+	return s[domain]
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the domain name in its ASCII form, and whether it is valid – the "domain" format of Validator.Format
+// the name is lower-cased, its trailing dot removed, and its internationalized labels converted into their punycode A-labels: "Bücher.example" becomes "xn--bcher-kva.example"
+// the name is refused unless it has two labels at least, of 1 to 63 letters, digits and inner hyphens once converted, for 253 characters at most, and a top-level label which is not numeric
+// the A-labels submitted must decode into letters, digits and marks; the Unicode mapping of IDNA (width, compatibility forms...) is not applied
+func Domain(name string) (string, bool) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return name, false
+	}
+	for i, label := range labels {
+		ascii, ok := domainLabel(label)
+		if !ok {
+			return name, false
+		}
+		labels[i] = ascii
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return name, false
+	}
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > 253 {
+		return name, false
+	}
+	return ascii, true
+}
+
+// this private function returns the ASCII form of a domain label, and whether it is valid
+func domainLabel(label string) (string, bool) {
+	ascii := label
+	if !isASCII(label) {
+		for _, r := range label {
+			if !isIDNRune(r) {
+				return label, false
+			}
+		}
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return label, false
+		}
+		ascii = "xn--" + encoded
+	}
+	if len(ascii) == 0 || len(ascii) > 63 || ascii[0] == '-' || ascii[len(ascii)-1] == '-' {
+		return label, false
+	}
+	for i := 0; i < len(ascii); i++ {
+		if c := ascii[i]; !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return label, false
+		}
+	}
+	// the hyphens in third and fourth positions are reserved to the A-labels
+	if len(ascii) >= 4 && ascii[2:4] == "--" {
+		if !strings.HasPrefix(ascii, "xn--") {
+			return label, false
+		}
+		decoded, err := punycodeDecode(ascii[4:])
+		if err != nil || decoded == "" || isASCII(decoded) {
+			return label, false
+		}
+		for _, r := range decoded {
+			if !isIDNRune(r) {
+				return label, false
+			}
+		}
+	}
+	return ascii, true
+}
+
+// this private function checks the domain and its parent ones are not in the blocked list
+func checkBlockedDomain(validator *Validator, in string, domain string, errors *[]*DataError) bool {
+	for parent := domain; strings.Contains(parent, "."); parent = parent[strings.Index(parent, ".")+1:] {
+		if validator.BlockedDomains.Contains(parent) {
+			*errors = append(*errors, NewError("blocked_domain", in, domain, map[string]interface{}{"domain": parent}))
+			return false
+		}
+	}
+	return true
+}
+
+// this private function tells whether the string is made of ASCII characters only
+func isASCII(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// this private function tells whether the rune can be part of an internationalized label
+func isIDNRune(r rune) bool {
+	return r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)
+}
+
+// this private function returns the punycode of a label, without the "xn--" prefix
+func punycodeEncode(label string) (string, error) {
+	input := []rune(label)
+	var output []byte
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+	basic := len(output)
+	handled := basic
+	if basic > 0 {
+		output = append(output, '-')
+	}
+
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handled < len(input) {
+		m := rune(unicode.MaxRune)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		delta += int(m-n) * (handled + 1)
+		if delta < 0 {
+			return "", errors.New("punycode overflow")
+		}
+		n = m
+		for _, r := range input {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				output = append(output, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			output = append(output, punycodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(output), nil
+}
+
+// this private function returns the label of a punycode, without the "xn--" prefix
+func punycodeDecode(encoded string) (string, error) {
+	var output []rune
+	pos := 0
+	if b := strings.LastIndex(encoded, "-"); b > 0 {
+		output = []rune(encoded[:b])
+		pos = b + 1
+	}
+
+	n, i, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for pos < len(encoded) {
+		previous, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos >= len(encoded) {
+				return "", errors.New("truncated punycode")
+			}
+			digit, ok := punycodeValue(encoded[pos])
+			pos++
+			if !ok {
+				return "", errors.New("invalid punycode digit")
+			}
+			i += digit * w
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punycodeBase - t
+			if i < 0 || w <= 0 || w > 1<<24 {
+				return "", errors.New("punycode overflow")
+			}
+		}
+		length := len(output) + 1
+		bias = punycodeAdapt(i-previous, length, previous == 0)
+		n += rune(i / length)
+		i %= length
+		if n > unicode.MaxRune {
+			return "", errors.New("punycode overflow")
+		}
+		output = append(output[:i], append([]rune{n}, output[i:]...)...)
+		i++
+	}
+	return string(output), nil
+}
+
+// this private function returns the threshold of the kth digit
+func punycodeThreshold(k, bias int) int {
+	switch t := k - bias; {
+	case t <= punycodeTMin:
+		return punycodeTMin
+	case t >= punycodeTMax:
+		return punycodeTMax
+	default:
+		return t
+	}
+}
+
+// this private function returns the bias following a delta
+func punycodeAdapt(delta, points int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / points
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// this private function returns the character of a digit
+func punycodeDigit(digit int) byte {
+	if digit < 26 {
+		return byte('a' + digit)
+	}
+	return byte('0' + digit - 26)
+}
+
+// this private function returns the digit of a character
+func punycodeValue(c byte) (int, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package validation
+
+import "testing"
+
+func TestDomain(t *testing.T) {
+	valid := map[string]string{
+		"Bücher.Example.":       "xn--bcher-kva.example",
+		"münchen.de":            "xn--mnchen-3ya.de",
+		"例え.テスト":                "xn--r8jz45g.xn--zckzah",
+		"xn--bcher-kva.example": "xn--bcher-kva.example",
+		"a-b.co":                "a-b.co",
+	}
+	for name, expected := range valid {
+		if ascii, ok := Domain(name); !ok || ascii != expected {
+			t.Errorf("%q: expected %q, got %q (%v)", name, expected, ascii, ok)
+		}
+	}
+	for _, name := range []string{"localhost", "-a.com", "a..com", "ab--c.com", "xn--zz.com", "a.123", "a_b.com", "xn--abc.com"} {
+		if ascii, ok := Domain(name); ok {
+			t.Errorf("%q: expected the domain to be refused, got %q", name, ascii)
+		}
+	}
+}
+
+func TestPunycode(t *testing.T) {
+	for label, encoded := range map[string]string{"bücher": "bcher-kva", "münchen": "mnchen-3ya", "例え": "r8jz45g"} {
+		if got, err := punycodeEncode(label); err != nil || got != encoded {
+			t.Errorf("%q: expected %q, got %q (%v)", label, encoded, got, err)
+		}
+		if got, err := punycodeDecode(encoded); err != nil || got != label {
+			t.Errorf("%q: expected %q, got %q (%v)", encoded, label, got, err)
+		}
+	}
+	if _, err := punycodeDecode("zz"); err == nil {
+		t.Errorf("expected the truncated punycode to be refused")
+	}
+}
+
+func TestBlockedDomains(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"domain": {Type: "string", Format: "domain", BlockedDomains: DomainSet{"mailinator.com": true}}}}
+	// the subdomains of a blocked domain are blocked too
+	_, errors := schema.Validate(map[string]interface{}{"domain": "X.Mailinator.com"}, Options{Usage: INIT})
+	if len(errors) != 1 || errors[0].Code != "blocked_domain" || errors[0].Params["domain"] != "mailinator.com" {
+		t.Errorf("expected the blocked domain, got %v", errors)
+	}
+	dest, errors := schema.Validate(map[string]interface{}{"domain": "Example.com"}, Options{Usage: INIT})
+	if len(errors) > 0 || dest["domain"] != "example.com" {
+		t.Errorf("expected the lower-cased domain, got %v and %v", dest, errors)
+	}
+
+	invalid := &Schema{Validators: map[string]*Validator{"domain": {Type: "string", Format: "filename", BlockedDomains: DomainSet{}}}}
+	if errs := CheckSchema(invalid); len(errs) != 1 {
+		t.Errorf("expected the blocked domains of a non-domain format to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,169 @@
+// Package dynamovalidation converts the DynamoDB items into the representation the JSON path of the validation package uses and back, so the items written through the AWS SDK are validated with the same schemas
+// it lives apart so that the users of the validation package do not depend on the AWS SDK
+package dynamovalidation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function converts an item into a document: S into a string, N into a json.Number, BOOL into a bool, NULL into nil, L into a []interface{} and M into a map[string]interface{}
+// the sets become arrays too, and the binaries base64 strings as validation.Normalize does
+func FromItem(item map[string]types.AttributeValue) (map[string]interface{}, error) {
+	doc := make(map[string]interface{}, len(item))
+	for key, attribute := range item {
+		value, err := fromAttribute(attribute)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		doc[key] = value
+	}
+	return doc, nil
+}
+
+// This function converts a validated document into an item, e.g. for a PutItem: the strings and the times into S, the numbers into N, the bools into BOOL, nil into NULL,
+// the arrays into L and the documents into M – the dest of a SET holding the Unset sentinel is not an item and is refused
+func ToItem(doc map[string]interface{}) (map[string]types.AttributeValue, error) {
+	normalized, err := validation.Normalize(doc)
+	if err != nil {
+		return nil, err
+	}
+	attribute, err := toAttribute(normalized)
+	if err != nil {
+		return nil, err
+	}
+	return attribute.(*types.AttributeValueMemberM).Value, nil
+}
+
+// This function validates an item and returns the validated one – see validation.Validate
+// an item that cannot be converted is reported as a single "invalid_payload" error
+func ValidateItem(item map[string]types.AttributeValue, schema validation.DocumentValidator, opt validation.Options) (map[string]types.AttributeValue, validation.DataErrors) {
+	doc, err := FromItem(item)
+	if err != nil {
+		return nil, invalidPayload(err)
+	}
+	dest, errors := schema.Validate(doc, opt)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	validated, err := ToItem(dest)
+	if err != nil {
+		return nil, invalidPayload(err)
+	}
+	return validated, errors
+}
+
+// this private function converts an attribute value into its JSON representation
+func fromAttribute(attribute types.AttributeValue) (interface{}, error) {
+	switch attribute := attribute.(type) {
+	case *types.AttributeValueMemberS:
+		return attribute.Value, nil
+	case *types.AttributeValueMemberN:
+		return number(attribute.Value)
+	case *types.AttributeValueMemberBOOL:
+		return attribute.Value, nil
+	case *types.AttributeValueMemberNULL:
+		return nil, nil
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(attribute.Value), nil
+	case *types.AttributeValueMemberL:
+		items := make([]interface{}, len(attribute.Value))
+		for i, item := range attribute.Value {
+			value, err := fromAttribute(item)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", i, err)
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *types.AttributeValueMemberM:
+		return FromItem(attribute.Value)
+	case *types.AttributeValueMemberSS:
+		items := make([]interface{}, len(attribute.Value))
+		for i, item := range attribute.Value {
+			items[i] = item
+		}
+		return items, nil
+	case *types.AttributeValueMemberNS:
+		items := make([]interface{}, len(attribute.Value))
+		for i, item := range attribute.Value {
+			value, err := number(item)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", i, err)
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *types.AttributeValueMemberBS:
+		items := make([]interface{}, len(attribute.Value))
+		for i, item := range attribute.Value {
+			items[i] = base64.StdEncoding.EncodeToString(item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute value of type %T", attribute)
+}
+
+// this private function converts a normalized value into an attribute value
+func toAttribute(value interface{}) (types.AttributeValue, error) {
+	switch value := value.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case string:
+		return &types.AttributeValueMemberS{Value: value}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: value.String()}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: value}, nil
+	case []interface{}:
+		items := make([]types.AttributeValue, len(value))
+		for i, item := range value {
+			attribute, err := toAttribute(item)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", i, err)
+			}
+			items[i] = attribute
+		}
+		return &types.AttributeValueMemberL{Value: items}, nil
+	case map[string]interface{}:
+		// the keys are converted in order, so the error reported is always the same
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attributes := make(map[string]types.AttributeValue, len(value))
+		for _, key := range keys {
+			attribute, err := toAttribute(value[key])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			attributes[key] = attribute
+		}
+		return &types.AttributeValueMemberM{Value: attributes}, nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %T", value)
+}
+
+// this private function checks the string of a N attribute is a number
+func number(str string) (json.Number, error) {
+	var n json.Number
+	if err := json.Unmarshal([]byte(str), &n); err != nil {
+		return "", fmt.Errorf("invalid number %q", str)
+	}
+	return n, nil
+}
+
+// this private function returns the error reporting an item that cannot be converted
+func invalidPayload(err error) validation.DataErrors {
+	return validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"format": "dynamodb", "error": err.Error()})}
+}
@@ -0,0 +1,75 @@
+package dynamovalidation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/grebett/validation"
+)
+
+func TestFromItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"name":    &types.AttributeValueMemberS{Value: "bob"},
+		"age":     &types.AttributeValueMemberN{Value: "42"},
+		"active":  &types.AttributeValueMemberBOOL{Value: true},
+		"bio":     &types.AttributeValueMemberNULL{Value: true},
+		"avatar":  &types.AttributeValueMemberB{Value: []byte("hi")},
+		"tags":    &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"scores":  &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"city": &types.AttributeValueMemberS{Value: "Paris"}}},
+		"lines":   &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberN{Value: "3"}}},
+	}
+	doc, err := FromItem(item)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]interface{}{
+		"name": "bob", "age": json.Number("42"), "active": true, "bio": nil, "avatar": "aGk=",
+		"tags": []interface{}{"a", "b"}, "scores": []interface{}{json.Number("1"), json.Number("2.5")},
+		"address": map[string]interface{}{"city": "Paris"}, "lines": []interface{}{json.Number("3")},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("expected %v, got %v", expected, doc)
+	}
+
+	if _, err := FromItem(map[string]types.AttributeValue{"age": &types.AttributeValueMemberN{Value: "x"}}); err == nil {
+		t.Errorf("expected the invalid number to be refused")
+	}
+}
+
+func TestValidateItem(t *testing.T) {
+	schema := &validation.Schema{Validators: map[string]*validation.Validator{
+		"name": {Type: "string", IsRequired: true},
+		"age":  {Type: "int64", Boundaries: validation.Boundaries{Min: 0, Max: 150}},
+		"tags": {Type: "[]string"},
+	}}
+	item := map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: "bob"},
+		"age":  &types.AttributeValueMemberN{Value: "42"},
+		"tags": &types.AttributeValueMemberSS{Value: []string{"a"}},
+	}
+	validated, errors := ValidateItem(item, schema, validation.Options{Usage: validation.INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	// the string sets come back as lists
+	expected := map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: "bob"},
+		"age":  &types.AttributeValueMemberN{Value: "42"},
+		"tags": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+	}
+	if !reflect.DeepEqual(validated, expected) {
+		t.Errorf("expected %v, got %v", expected, validated)
+	}
+
+	item["age"] = &types.AttributeValueMemberN{Value: "200"}
+	if _, errors := ValidateItem(item, schema, validation.Options{Usage: validation.INIT}); len(errors) != 1 || errors[0].Code != "out_of_boundaries" {
+		t.Errorf("expected the age to be out of boundaries, got %v", errors)
+	}
+	item["age"] = &types.AttributeValueMemberN{Value: "x"}
+	if _, errors := ValidateItem(item, schema, validation.Options{Usage: validation.INIT}); len(errors) != 1 || errors[0].Code != "invalid_payload" {
+		t.Errorf("expected an invalid payload, got %v", errors)
+	}
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"os"
+	"strings"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates the configuration held by the environment variables, typically at startup
+// the variable of a validator is the prefix followed by its path in upper case, the dots replaced by underscores: "db.port" is read from APP_DB_PORT with the "APP_" prefix
+// the strings are converted into the validator types (numbers, booleans, comma-separated lists, JSON maps) before the validation, which applies the defaults and requireds on INIT as usual
+// an empty variable is considered unset, but for the string validators
+func ValidateEnv(prefix string, schema DocumentValidator, opt Options) (map[string]interface{}, DataErrors) {
+	doc := make(map[string]interface{})
+	for path, validator := range schemaValidators(schema) {
+		in, _ := validator.Paths(path, opt.Usage)
+		str, ok := os.LookupEnv(prefix + strings.ToUpper(strings.Replace(in, ".", "_", -1)))
+		if !ok || (str == "" && validator.Type != "string") {
+			continue
+		}
+		if err := tools.WriteDeep(doc, in, parseString(validator.Type, str)); err != nil {
+			// the path goes through another variable, which is reported by the validation
+			continue
+		}
+	}
+	return schema.Validate(doc, opt)
+}
+
+// this private function returns the validators of a Schema, a CompiledSchema or a swappable schema like RemoteSchema
+func schemaValidators(schema DocumentValidator) map[string]*Validator {
+	return documentSchema(schema).Validators
+}
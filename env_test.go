@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidateEnv(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"db.host":   {Type: "string", IsRequired: true},
+		"db.port":   {Type: "int", Boundaries: Boundaries{Min: 1, Max: 65535}},
+		"debug":     {Type: "bool", Default: func(interface{}) interface{} { return false }},
+		"hosts":     {Type: "[]string"},
+		"ports":     {Type: "[]int", Boundaries: Boundaries{Min: 1, Max: 65535}},
+		"labels":    {Type: "map[string]string"},
+		"ratio":     {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 1}},
+		"unset.int": {Type: "int", Boundaries: Boundaries{Min: 0, Max: 1}},
+	}}
+	env := map[string]string{
+		"APP_DB_HOST":   "localhost",
+		"APP_DB_PORT":   "5432",
+		"APP_HOSTS":     "a, b",
+		"APP_PORTS":     "80,443",
+		"APP_LABELS":    `{"env": "prod"}`,
+		"APP_RATIO":     "0.5",
+		"APP_UNSET_INT": "",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	dest, errors := ValidateEnv("APP_", schema, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := map[string]interface{}{
+		"db":     map[string]interface{}{"host": "localhost", "port": 5432},
+		"debug":  false,
+		"hosts":  []interface{}{"a", "b"},
+		"ports":  []interface{}{80, 443},
+		"labels": map[string]interface{}{"env": "prod"},
+		"ratio":  json.Number("0.5"),
+	}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v", expected, dest)
+	}
+
+	os.Setenv("APP_DB_PORT", "port")
+	os.Unsetenv("APP_DB_HOST")
+	compiled := MustCompile(schema)
+	_, errors = ValidateEnv("APP_", compiled, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Field != "db.host" || errors[1].Field != "db.port" || errors[1].Code != "type_mismatch" {
+		t.Errorf("expected the missing host and the invalid port, got %v", errors)
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		_type    string
+		str      string
+		expected interface{}
+	}{
+		{"bool", " true", true},
+		{"bool", "yes", "yes"},
+		{"float64", "1.5", json.Number("1.5")},
+		{"uint8", "12", json.Number("12")},
+		{"int", "twelve", "twelve"},
+		{"[]bool", "", []interface{}{}},
+		{"[]bool", "true,0", []interface{}{true, false}},
+		{"map[string]string", "not json", "not json"},
+		{"string", " kept ", " kept "},
+	}
+	for _, test := range tests {
+		if value := parseString(test._type, test.str); !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("%s %q: expected %#v, got %#v", test._type, test.str, test.expected, value)
+		}
+	}
+}
+
+// this struct stands for the schemas swapped at runtime, such as watch.Schema
+type swappedSchema struct {
+	compiled *CompiledSchema
+}
+
+func (s *swappedSchema) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	return s.compiled.Validate(_map, opt)
+}
+
+func (s *swappedSchema) Compiled() *CompiledSchema {
+	return s.compiled
+}
+
+func TestValidateEnvSwappedSchema(t *testing.T) {
+	schema := &swappedSchema{MustCompile(&Schema{Validators: map[string]*Validator{"debug": {Type: "bool"}}})}
+	os.Setenv("SWAPPED_DEBUG", "1")
+	defer os.Unsetenv("SWAPPED_DEBUG")
+	if dest, errors := ValidateEnv("SWAPPED_", schema, Options{Usage: INIT}); len(errors) > 0 || dest["debug"] != true {
+		t.Errorf("expected debug to be true, got %v %v", dest, errors)
+	}
+}
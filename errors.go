@@ -0,0 +1,308 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a step of the path to a field: either a document key or an array index
+type PathSegment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// This type is the list of errors returned by a validation
+type DataErrors []*DataError
+
+// the type and reason of the errors, by code
+var errorReasons = map[string][2]string{
+	"required":             {"Validation error", "Required"},
+	"type_mismatch":        {"Validation error", "Type mismatch"},
+	"regex_not_match":      {"Validation error", "Regex not match"},
+	"regex_match":          {"Validation error", "Regex match"},
+	"invalid_search_query": {"Validation error", "Invalid search query"},
+	"hash_mismatch":        {"Validation error", "Hash mismatch"},
+	"blocked_domain":       {"Validation error", "Blocked domain"},
+	"not_in_enum":          {"Validation error", "Not in enum"},
+	"invalid_format":       {"Validation error", "Invalid format"},
+	"out_of_boundaries":    {"Validation error", "Out of boundaries"},
+	"out_of_range":         {"Validation error", "Out of range"},
+	"too_many_decimals":    {"Validation error", "Too many decimals"},
+	"invalid_currency":     {"Validation error", "Invalid currency"},
+	"invalid_json":         {"Validation error", "Invalid JSON"},
+	"too_large":            {"Validation error", "Too large"},
+	"insufficient_rights":  {"Validation error", "Insufficient rights"},
+	"immutable":            {"Validation error", "Immutable"},
+	"unknown_field":        {"Validation error", "Unknown field"},
+	"invalid_array_filter": {"Validation error", "Invalid array filter"},
+	"version_conflict":     {"Validation error", "Version conflict"},
+	"deleted_document":     {"Validation error", "Deleted document"},
+	"exclusive_fields":     {"Validation error", "Exclusive fields"},
+	"missing_any_of":       {"Validation error", "Missing field"},
+	"document_test_failed": {"Validation error", "Document test failed"},
+	"too_many_items":       {"Validation error", "Too many items"},
+	"duplicate_item":       {"Validation error", "Duplicate item"},
+	"not_ordered":          {"Validation error", "Not ordered"},
+	"invalid_range":        {"Validation error", "Invalid range"},
+	"overlapping_ranges":   {"Validation error", "Overlapping ranges"},
+	"too_young":            {"Validation error", "Too young"},
+	"too_old":              {"Validation error", "Too old"},
+	"too_early":            {"Validation error", "Too early"},
+	"too_late":             {"Validation error", "Too late"},
+	"invalid_filter":       {"Validation error", "Invalid filter"},
+	"not_sortable":         {"Validation error", "Not sortable"},
+	"invalid_stage":        {"Validation error", "Invalid stage"},
+	"custom_test_failed":   {"Validation error", "Custom test failed"},
+	"cross_test_failed":    {"Validation error", "Cross test failed"},
+	"transform_failed":     {"Transform error", "Transform failed"},
+	"max_depth_exceeded":   {"Input error", "Max depth exceeded"},
+	"max_fields_exceeded":  {"Input error", "Max fields exceeded"},
+	"invalid_payload":      {"Input error", "Invalid payload"},
+	"unknown_schema":       {"Input error", "Unknown schema"},
+	"unknown_profile":      {"Input error", "Unknown profile"},
+	"invalid_signature":    {"Authentication error", "Invalid signature"},
+	"internal_error":       {"Internal error", "Unexpected failure"},
+	"test_unavailable":     {"Internal error", "Test unavailable"},
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method gathers the errors by reason, keeping their order
+func (errors DataErrors) GroupByReason() map[string]DataErrors {
+	groups := make(map[string]DataErrors)
+	for _, err := range errors {
+		groups[err.Reason] = append(groups[err.Reason], err)
+	}
+	return groups
+}
+
+// This method returns the fields the errors are about, once each and in order
+func (errors DataErrors) Fields() []string {
+	fields := make([]string, 0, len(errors))
+	seen := make(map[string]bool, len(errors))
+	for _, err := range errors {
+		if !seen[err.Field] {
+			seen[err.Field] = true
+			fields = append(fields, err.Field)
+		}
+	}
+	return fields
+}
+
+// This method returns a one-line summary of the errors, fit for a log line
+// e.g. "3 errors on 2 fields: Type mismatch (2), Required (1)"
+func (errors DataErrors) Summary() string {
+	if len(errors) == 0 {
+		return "no error"
+	}
+
+	reasons := make([]string, 0)
+	counts := make(map[string]int)
+	for _, err := range errors {
+		if counts[err.Reason] == 0 {
+			reasons = append(reasons, err.Reason)
+		}
+		counts[err.Reason]++
+	}
+	for i, reason := range reasons {
+		reasons[i] = fmt.Sprintf("%s (%d)", reason, counts[reason])
+	}
+
+	return fmt.Sprintf("%s on %s: %s", plural(len(errors), "error"), plural(len(errors.Fields()), "field"), strings.Join(reasons, ", "))
+}
+
+// This method returns the summary followed by one error per line, fit for a terminal
+func (errors DataErrors) String() string {
+	var lines strings.Builder
+	lines.WriteString(errors.Summary())
+	for _, err := range errors {
+		lines.WriteString("\n  - ")
+		lines.WriteString(err.Error())
+	}
+	return lines.String()
+}
+
+// This method returns the JSON Pointer (RFC 6901) to the field the error is about, "" for the whole document
+func (e *DataError) Pointer() string {
+	var pointer strings.Builder
+	for _, segment := range e.Path {
+		pointer.WriteByte('/')
+		if segment.IsIndex {
+			pointer.WriteString(strconv.Itoa(segment.Index))
+		} else {
+			pointer.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment.Key))
+		}
+	}
+	return pointer.String()
+}
+
+// This method marshals the segment as a JSON string for a key or as a number for an index
+func (s PathSegment) MarshalJSON() ([]byte, error) {
+	if s.IsIndex {
+		return json.Marshal(s.Index)
+	}
+	return json.Marshal(s.Key)
+}
+
+// This method unmarshals a segment written by MarshalJSON
+func (s *PathSegment) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case string:
+		*s = PathSegment{Key: value}
+	case float64:
+		*s = PathSegment{Index: int(value), IsIndex: true}
+	default:
+		return fmt.Errorf("validation: invalid path segment %s", data)
+	}
+	return nil
+}
+
+// This method returns the segment as written in a dotted path
+func (s PathSegment) String() string {
+	if s.IsIndex {
+		return strconv.Itoa(s.Index)
+	}
+	return s.Key
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This public function splits a dotted path, as written in the schemas, into key segments
+func ParsePath(path string) []PathSegment {
+	if path == "" {
+		return nil
+	}
+	keys := strings.Split(path, ".")
+	segments := make([]PathSegment, len(keys))
+	for i, key := range keys {
+		segments[i] = PathSegment{Key: key}
+	}
+	return segments
+}
+
+// This function returns the default error for the code
+func NewError(code string, field string, value interface{}, params map[string]interface{}) *DataError {
+	reason, ok := errorReasons[code]
+	if !ok {
+		reason = [2]string{"Validation error", code}
+	}
+	return &DataError{Type: reason[0], Reason: reason[1], Field: field, Value: value, Code: code, Params: params}
+}
+
+// this private function joins two dotted paths, either of them possibly empty
+func joinPath(prefix string, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+	return prefix + "." + path
+}
+
+// this private function completes the errors once the validation is over
+// the errors with no code (the custom tests ones) get one and all of them the Meta of the options, then the error factory replaces them if provided
+func finishErrors(errors []*DataError, opt Options) {
+	for i, err := range errors {
+		if err.Code == "" {
+			completed := *err
+			completed.Code = errorCode(err)
+			err = &completed
+		}
+		if opt.Meta != nil && err.Meta == nil {
+			correlated := *err
+			correlated.Meta = opt.Meta
+			err = &correlated
+		}
+		if opt.ErrorFactory != nil {
+			// a failing factory keeps the default error
+			var built *DataError
+			callSafely(err.Field, "ErrorFactory", func() { built = opt.ErrorFactory(err.Code, err.Field, err.Value, err.Params) })
+			if built != nil {
+				if built.Path == nil {
+					built.Path = err.Path
+				}
+				if built.Code == "" {
+					built.Code = err.Code
+				}
+				if built.Meta == nil {
+					built.Meta = opt.Meta
+				}
+				err = built
+			}
+		}
+		if err.Code == "not_in_enum" {
+			err = suggestEnum(err)
+		}
+		if opt.DocBaseURL != "" && err.Docs == "" {
+			linked := *err
+			linked.Docs = docURL(opt.DocBaseURL, err.Code)
+			err = &linked
+		}
+		if opt.Messages != nil && err.Message == "" {
+			// a template failing to render leaves the reason alone
+			if message, e := opt.Messages.Render(err, opt.Locale); e == nil && message != "" {
+				rendered := *err
+				rendered.Message = message
+				err = &rendered
+			}
+		}
+		errors[i] = err
+	}
+}
+
+// this private function returns the count followed by the noun, in the plural if needed
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// this private function completes the errors missing a field or a path with the provided ones
+// the errors are copied before being completed, since custom tests may return shared ones
+func locateErrors(errors []*DataError, field string, path []PathSegment) {
+	for i, err := range errors {
+		if err == nil || (err.Field != "" && err.Path != nil) {
+			continue
+		}
+		located := *err
+		if located.Field == "" {
+			located.Field = field
+		}
+		if located.Path == nil {
+			located.Path = path
+		}
+		errors[i] = &located
+	}
+}
+
+// this private function returns the machine-readable code of the error, built from its reason if it has none
+// e.g. "Type mismatch" gives "type_mismatch"
+func errorCode(err *DataError) string {
+	if err.Code != "" {
+		return err.Code
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, err.Reason)
+}
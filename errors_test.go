@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestErrorPointers(t *testing.T) {
+	validators := map[string]*Validator{
+		"tags":       {Type: "[]string"},
+		"a/b.c~d":    {Type: "string", IsRequired: true},
+		"profile.id": {Type: "string"},
+	}
+	document := map[string]interface{}{
+		"tags":    []interface{}{"a", float64(1)},
+		"profile": map[string]interface{}{"id": true},
+		"extra":   "x",
+	}
+	_, errors := Validate(validators, document, Options{Usage: INIT, Strict: true})
+
+	pointers := make(map[string]string)
+	for _, err := range errors {
+		pointers[err.Reason+" "+err.Field] = err.Pointer()
+	}
+	expected := map[string]string{
+		"Required a/b.c~d":         "/a~1b/c~0d",
+		"Type mismatch profile.id": "/profile/id",
+		"Type mismatch tags":       "/tags/1",
+		"Unknown field extra":      "/extra",
+	}
+	if !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("expected %v, got %v", expected, pointers)
+	}
+}
+
+func TestPathSegmentJSON(t *testing.T) {
+	path := append(ParsePath("lines.sku"), PathSegment{Index: 2, IsIndex: true})
+	data, err := json.Marshal(path)
+	if err != nil || string(data) != `["lines","sku",2]` {
+		t.Fatalf("expected the keys and the index, got %s, %v", data, err)
+	}
+	var decoded []PathSegment
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, path) {
+		t.Errorf("expected %v, got %v, %v", path, decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`[true]`), &decoded); err == nil {
+		t.Error("expected an error for a boolean segment")
+	}
+}
+
+func TestDataErrorsSummary(t *testing.T) {
+	errors := DataErrors{
+		{Type: "Validation error", Reason: "Type mismatch", Field: "a", Value: "bool"},
+		{Type: "Validation error", Reason: "Required", Field: "b"},
+		{Type: "Validation error", Reason: "Type mismatch", Field: "a", Value: "string"},
+	}
+	if summary := errors.Summary(); summary != "3 errors on 2 fields: Type mismatch (2), Required (1)" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if summary := errors[1:2].Summary(); summary != "1 error on 1 field: Required (1)" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if summary := DataErrors(nil).Summary(); summary != "no error" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if fields := errors.Fields(); !reflect.DeepEqual(fields, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", fields)
+	}
+	if groups := errors.GroupByReason(); len(groups) != 2 || len(groups["Type mismatch"]) != 2 || groups["Type mismatch"][1] != errors[2] {
+		t.Errorf("unexpected groups %v", groups)
+	}
+	expected := "1 error on 1 field: Required (1)\n  - Validation error for b = <nil>: Required"
+	if str := errors[1:2].String(); str != expected {
+		t.Errorf("expected %q, got %q", expected, str)
+	}
+}
+
+func TestErrorCodesAndFactory(t *testing.T) {
+	validators := map[string]*Validator{
+		"name":  {Type: "string", Regexp: "^[a-z]+$"},
+		"age":   {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"email": {Type: "string", IsRequired: true},
+		"nick": {Type: "string", CustomTest: func(interface{}) (bool, *DataError) {
+			return false, &DataError{Type: "Validation error", Reason: "Already taken"}
+		}},
+	}
+	document := map[string]interface{}{"name": "Ada", "age": json.Number("200"), "nick": "ada"}
+
+	_, errors := Validate(validators, document, Options{Usage: INIT})
+	codes := make(map[string]string)
+	for _, err := range errors {
+		codes[err.Field] = err.Code
+	}
+	expected := map[string]string{"name": "regex_not_match", "age": "out_of_boundaries", "email": "required", "nick": "already_taken"}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected %v, got %v", expected, codes)
+	}
+
+	var params map[string]interface{}
+	factory := func(code, field string, value interface{}, p map[string]interface{}) *DataError {
+		if code != "out_of_boundaries" {
+			return nil
+		}
+		params = p
+		return &DataError{Type: "Validation error", Reason: "Too old"}
+	}
+	_, errors = Validate(validators, document, Options{Usage: INIT, ErrorFactory: factory})
+	if params["min"] != float64(0) || params["max"] != float64(150) {
+		t.Errorf("expected the boundaries in the params, got %v", params)
+	}
+	groups := errors.GroupByReason()
+	if built := groups["Too old"]; len(built) != 1 || built[0].Code != "out_of_boundaries" || built[0].Pointer() != "/age" {
+		t.Errorf("expected the factory error with the code and path of the field, got %v", built)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	err := NewError("required", "name", nil, nil)
+	if err.Type != "Validation error" || err.Reason != "Required" || err.Code != "required" || err.Field != "name" {
+		t.Errorf("unexpected required error %#v", err)
+	}
+	err = NewError("already_taken", "nick", "ada", map[string]interface{}{"by": "someone"})
+	if err.Type != "Validation error" || err.Reason != "already_taken" || err.Params["by"] != "someone" {
+		t.Errorf("unexpected error of an unknown code %#v", err)
+	}
+}
+
+func TestErrorsMeta(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"name": {Type: "string", IsRequired: true}, "age": {Type: "int"}}}
+	meta := map[string]interface{}{"requestId": "r1"}
+	_, errors := schema.Validate(map[string]interface{}{"age": "x"}, Options{Usage: INIT, Meta: meta})
+	if len(errors) != 2 || errors[0].Meta["requestId"] != "r1" || errors[1].Meta["requestId"] != "r1" {
+		t.Fatalf("expected the metadata on every error, got %v", errors)
+	}
+	encoded, _ := json.Marshal(errors[0])
+	var decoded map[string]interface{}
+	json.Unmarshal(encoded, &decoded)
+	if !reflect.DeepEqual(decoded["meta"], meta) {
+		t.Errorf("expected the metadata in the JSON, got %s", encoded)
+	}
+
+	// the built errors get the metadata too, unless they have their own
+	factory := func(code, field string, value interface{}, params map[string]interface{}) *DataError {
+		if field == "age" {
+			return &DataError{Reason: "Bad age", Meta: map[string]interface{}{"own": true}}
+		}
+		return &DataError{Reason: "Bad"}
+	}
+	_, errors = schema.Validate(map[string]interface{}{"age": "x"}, Options{Usage: INIT, Meta: meta, ErrorFactory: factory})
+	if errors[0].Meta["own"] != true || errors[1].Meta["requestId"] != "r1" {
+		t.Errorf("unexpected metadata %v and %v", errors[0].Meta, errors[1].Meta)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{}, Options{Usage: INIT}); errors[0].Meta != nil {
+		t.Errorf("expected no metadata, got %v", errors[0].Meta)
+	}
+}
@@ -0,0 +1,61 @@
+package validation
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a record Process quarantines, along with the errors it was refused for
+type InvalidRecord struct {
+	Index  int                    // the position of the record in the input, from 0
+	Record map[string]interface{} // the record as read
+	Errors DataErrors
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates the records of a streaming ingestion job: the validated ones are sent on the first channel, the invalid ones on the second, so a bad record is quarantined rather than stopping the job
+// the records are validated one at a time and sent in their input order, and both channels are closed once in is, or Options.Context done
+// the caller must drain both channels, since a record waits until it is received
+func Process(in <-chan map[string]interface{}, schema DocumentValidator, opt Options) (<-chan map[string]interface{}, <-chan InvalidRecord) {
+	valid := make(chan map[string]interface{})
+	invalid := make(chan InvalidRecord)
+	var done <-chan struct{}
+	if opt.Context != nil {
+		done = opt.Context.Done()
+	}
+
+	go func() {
+		defer close(valid)
+		defer close(invalid)
+		for index := 0; ; index++ {
+			var record map[string]interface{}
+			var ok bool
+			select {
+			case record, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-done:
+				return
+			}
+
+			dest, errors := schema.Validate(record, opt)
+			if len(errors) > 0 {
+				select {
+				case invalid <- InvalidRecord{Index: index, Record: record, Errors: errors}:
+				case <-done:
+					return
+				}
+				continue
+			}
+			select {
+			case valid <- dest:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return valid, invalid
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestProcess(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"name": {Type: "string", IsRequired: true}}}
+	in := make(chan map[string]interface{})
+	go func() {
+		for _, record := range []map[string]interface{}{{"name": "a"}, {"other": 1}, {"name": "b"}, {}} {
+			in <- record
+		}
+		close(in)
+	}()
+	valid, invalid := Process(in, schema, Options{Usage: INIT})
+
+	var wg sync.WaitGroup
+	var quarantined []InvalidRecord
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for record := range invalid {
+			quarantined = append(quarantined, record)
+		}
+	}()
+	var validated []map[string]interface{}
+	for dest := range valid {
+		validated = append(validated, dest)
+	}
+	wg.Wait()
+
+	if len(validated) != 2 || validated[0]["name"] != "a" || validated[1]["name"] != "b" {
+		t.Errorf("expected the valid records in order, got %v", validated)
+	}
+	if len(quarantined) != 2 || quarantined[0].Index != 1 || quarantined[1].Index != 3 || len(quarantined[1].Errors) != 1 {
+		t.Errorf("expected the records 1 and 3 to be quarantined, got %v", quarantined)
+	}
+}
+
+func TestProcessCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// the channels are closed although in never is
+	valid, invalid := Process(make(chan map[string]interface{}), &Schema{}, Options{Usage: INIT, Context: ctx})
+	for range valid {
+	}
+	for range invalid {
+	}
+}
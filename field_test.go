@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateField(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"age":          {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 100}},
+		"address.city": {Type: "string", Regexp: "^[A-Z]", IsRequired: true},
+		"role":         {Type: "string", Rights: [3]int{ADMIN, 0, ADMIN}, Default: func(interface{}) interface{} { return "user" }},
+		"name":         {Type: "string", Source: "fullName"},
+	}}
+	tests := []struct {
+		path    string
+		value   interface{}
+		opt     Options
+		written interface{}
+		code    string
+		field   string
+	}{
+		{"age", json.Number("5"), Options{Usage: INIT}, 5, "", ""},
+		{"age", json.Number("500"), Options{Usage: INIT}, nil, "out_of_boundaries", "age"},
+		{"address.city", "paris", Options{Usage: INIT}, nil, "regex_not_match", "address.city"},
+		{"address.city", nil, Options{Usage: INIT}, nil, "required", "address.city"},
+		{"address.city", nil, Options{Usage: INIT, Existing: map[string]interface{}{"address": map[string]interface{}{"city": "Paris"}}}, nil, "", ""},
+		{"role", nil, Options{Usage: INIT, UserRights: ADMIN}, "user", "", ""},
+		{"role", "admin", Options{Usage: SET, UserRights: USER}, nil, "insufficient_rights", "role"},
+		{"name", "Ada", Options{Usage: INIT}, "Ada", "", ""},
+		{"unknown", "x", Options{Usage: SET}, nil, "unknown_field", "unknown"},
+	}
+	for _, test := range tests {
+		written, errors := ValidateField(schema, test.path, test.value, test.opt)
+		code, field := "", ""
+		if len(errors) > 0 {
+			code, field = errors[0].Code, errors[0].Field
+		}
+		if written != test.written || code != test.code || field != test.field || len(errors) > 1 {
+			t.Errorf("%s %v: expected %v %q %q, got %v %v", test.path, test.value, test.written, test.code, test.field, written, errors)
+		}
+	}
+
+	if _, errors := ValidateField(MustCompile(schema), "age", json.Number("1.5"), Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "type_mismatch" {
+		t.Errorf("expected a type mismatch through the compiled schema, got %v", errors)
+	}
+}
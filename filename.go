@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the max length of a file name, in bytes, as most file systems allow
+const maxFilenameLength = 255
+
+// the max length of a file path, in bytes
+const maxFilepathLength = 4096
+
+// the device names Windows reserves, with or without extension
+var reservedFilenames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the file name normalized, and whether it is safe to use on any file system – the "filename" format of Validator.Format
+// the surrounding spaces and the trailing dots, which Windows drops, are removed; the name is refused if it is then empty, "." or "..",
+// longer than 255 bytes, a device name Windows reserves (CON, NUL, COM1...), or holds a control character, a null byte, a separator or one of <>:"|?*
+func Filename(name string) (string, bool) {
+	name = strings.TrimRight(strings.TrimSpace(name), ". ")
+	if name == "" || len(name) > maxFilenameLength {
+		return name, false
+	}
+	if strings.ContainsAny(name, `/\<>:"|?*`) || strings.IndexFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return name, false
+	}
+	if reservedFilenames[strings.ToUpper(strings.SplitN(name, ".", 2)[0])] {
+		return name, false
+	}
+	return name, true
+}
+
+// This function returns the relative file path normalized, and whether it is safe to join to a base directory – the "filepath" format of Validator.Format
+// the backslashes become slashes and the empty and "." components are removed; the path is refused if it is absolute (a leading slash or a drive letter),
+// longer than 4096 bytes, climbs up with a ".." component, or one of its components is not a safe Filename
+func Filepath(path string) (string, bool) {
+	path = strings.Replace(strings.TrimSpace(path), `\`, "/", -1)
+	if strings.HasPrefix(path, "/") || len(path) > maxFilepathLength {
+		return path, false
+	}
+	var components []string
+	for _, component := range strings.Split(path, "/") {
+		if component == "" || component == "." {
+			continue
+		}
+		if strings.TrimSpace(component) == ".." {
+			return path, false
+		}
+		name, ok := Filename(component)
+		if !ok {
+			return path, false
+		}
+		components = append(components, name)
+	}
+	if len(components) == 0 {
+		return path, false
+	}
+	return strings.Join(components, "/"), true
+}
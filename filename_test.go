@@ -0,0 +1,45 @@
+package validation
+
+import "testing"
+
+func TestFilename(t *testing.T) {
+	for name, expected := range map[string]string{" report.pdf. ": "report.pdf", "résumé v2.txt": "résumé v2.txt", ".hidden": ".hidden"} {
+		if normalized, ok := Filename(name); !ok || normalized != expected {
+			t.Errorf("%q: expected %q, got %q (%v)", name, expected, normalized, ok)
+		}
+	}
+	for _, name := range []string{"", "..", "a/b", `a\b`, "con.txt", "NUL", "a\x00b", "x:y", string(make([]byte, 256))} {
+		if _, ok := Filename(name); ok {
+			t.Errorf("%q: expected the name to be refused", name)
+		}
+	}
+}
+
+func TestFilepath(t *testing.T) {
+	for path, expected := range map[string]string{`a\b/./c.txt`: "a/b/c.txt", "a//b/": "a/b"} {
+		if normalized, ok := Filepath(path); !ok || normalized != expected {
+			t.Errorf("%q: expected %q, got %q (%v)", path, expected, normalized, ok)
+		}
+	}
+	for _, path := range []string{"", "./", "../etc/passwd", "/etc", "a/../../b", `C:\x`, "a/LPT1", "a/ .. /b"} {
+		if _, ok := Filepath(path); ok {
+			t.Errorf("%q: expected the path to be refused", path)
+		}
+	}
+}
+
+func TestFilenameFormats(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name": {Type: "string", Format: "filename"},
+		"path": {Type: "string", Format: "filepath"},
+	}}
+	// the normalized names are written in dest
+	dest, errors := schema.Validate(map[string]interface{}{"name": " report.pdf. ", "path": `a\b/./c.txt`}, Options{Usage: INIT})
+	if len(errors) > 0 || dest["name"] != "report.pdf" || dest["path"] != "a/b/c.txt" {
+		t.Errorf("expected the normalized names, got %v and %v", dest, errors)
+	}
+	_, errors = schema.Validate(map[string]interface{}{"name": "..", "path": "../etc/passwd"}, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Code != "invalid_format" || errors[1].Code != "invalid_format" {
+		t.Errorf("expected both names to be refused, got %v", errors)
+	}
+}
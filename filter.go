@@ -0,0 +1,223 @@
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the operators a filter may use on a field, and whether their operands are fully validated – see ValidateFilter
+// the bounds of a range only have the type of the field: the rules on the values (boundaries, pattern, custom test...) do not apply to them
+var filterOperators = map[string]bool{
+	"$eq": true, "$ne": true, "$in": true, "$nin": true,
+	"$gt": false, "$gte": false, "$lt": false, "$lte": false,
+	"$exists": false, "$not": false,
+}
+
+// the logical operators joining filters
+var filterLogicals = map[string]bool{"$and": true, "$or": true, "$nor": true}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function checks a client-supplied MongoDB filter and returns it rewritten for the stored documents, e.g. the filter of a list endpoint
+// only the fields of the schema the user has the GET rights for can be filtered on, with the $and, $or, $nor logical operators
+// and the $eq, $ne, $in, $nin, $gt, $gte, $lt, $lte, $exists and $not field operators – $where, $expr, $regex... are refused
+// the values are validated against the field validators, then converted into their stored form (Target path, ObjectIds, times, transform)
+// the value of an array field may be one of its items, as MongoDB matches the arrays containing it
+func ValidateFilter(schema DocumentValidator, filter map[string]interface{}, opt Options) (validated map[string]interface{}, errors DataErrors) {
+	errors = make([]*DataError, 0)
+	defer func() {
+		if r := recover(); r != nil {
+			validated, errors = nil, append(errors, internalError("", r))
+		}
+		finishErrors(errors, opt)
+	}()
+
+	if err := checkSize(filter, opt.MaxDepth, opt.MaxFields); err != nil {
+		errors = append(errors, err)
+		return nil, errors
+	}
+	root := documentSchema(schema)
+	opt.root = root
+	profiled, opt, minRights, err := applyProfile(root, opt)
+	if err != nil {
+		errors = append(errors, err)
+		return nil, errors
+	}
+	profiled = applyOverride(profiled, opt.Override)
+	rights := resolveRights(opt)
+	if rights < minRights {
+		errors = append(errors, NewError("insufficient_rights", "", nil, map[string]interface{}{"rights": minRights}))
+		return nil, errors
+	}
+
+	var filterErrors []*DataError
+	validated = validateFilter(profiled, submittedFields(profiled), filter, "", rights, opt, &filterErrors)
+	if len(filterErrors) > 0 {
+		errors = append(errors, filterErrors...)
+		return nil, errors
+	}
+	return validated, errors
+}
+
+// this private function validates a filter document, the prefix locating it in the client filter
+func validateFilter(schema *Schema, fields map[string]string, filter map[string]interface{}, prefix string, rights int, opt Options, errors *[]*DataError) map[string]interface{} {
+	validated := make(map[string]interface{}, len(filter))
+	for _, key := range sortedKeys(filter) {
+		value, location := filter[key], prefix+key
+		if strings.HasPrefix(key, "$") {
+			if !filterLogicals[key] {
+				*errors = append(*errors, NewError("invalid_filter", location, nil, map[string]interface{}{"operator": key}))
+				continue
+			}
+			clauses, ok := value.([]interface{})
+			if !ok || len(clauses) == 0 {
+				*errors = append(*errors, NewError("invalid_filter", location, nil, map[string]interface{}{"operator": key, "expected": "a non-empty array of filters"}))
+				continue
+			}
+			joined := make([]interface{}, len(clauses))
+			for i, clause := range clauses {
+				document, ok := clause.(map[string]interface{})
+				if !ok {
+					*errors = append(*errors, NewError("invalid_filter", location+"."+strconv.Itoa(i), nil, map[string]interface{}{"operator": key, "expected": "a filter"}))
+					continue
+				}
+				joined[i] = validateFilter(schema, fields, document, location+"."+strconv.Itoa(i)+".", rights, opt, errors)
+			}
+			validated[key] = joined
+			continue
+		}
+
+		path, ok := fields[key]
+		if !ok {
+			*errors = append(*errors, NewError("unknown_field", location, nil, nil))
+			continue
+		}
+		validator := schema.Validators[path]
+		// filtering on a field reveals its value
+		if !collaboratorsOf(opt).Rights.CheckRights(rights, validator.Rights[GET]) {
+			*errors = append(*errors, NewError("insufficient_rights", location, nil, map[string]interface{}{"rights": validator.Rights[GET]}))
+			continue
+		}
+		_, stored := validator.Paths(path, INIT)
+		validated[stored] = filterCondition(validator, location, value, rights, opt, errors)
+	}
+	return validated
+}
+
+// this private function validates the condition on a field: a value, or a document of field operators
+func filterCondition(validator *Validator, location string, value interface{}, rights int, opt Options, errors *[]*DataError) interface{} {
+	// a document holding an operator is a document of operators, its other keys being refused
+	operators, ok := value.(map[string]interface{})
+	if !ok || !hasOperator(operators) {
+		return filterOperand(validator, location, value, true, rights, opt, errors)
+	}
+
+	validated := make(map[string]interface{}, len(operators))
+	for _, operator := range sortedKeys(operators) {
+		operand, field := operators[operator], location+"."+operator
+		full, ok := filterOperators[operator]
+		if !ok {
+			*errors = append(*errors, NewError("invalid_filter", field, nil, map[string]interface{}{"operator": operator}))
+			continue
+		}
+		switch operator {
+		case "$exists":
+			if _, ok := operand.(bool); !ok {
+				*errors = append(*errors, NewError("type_mismatch", field, typeName(operand), map[string]interface{}{"expected": "bool"}))
+				continue
+			}
+			validated[operator] = operand
+		case "$not":
+			validated[operator] = filterCondition(validator, field, operand, rights, opt, errors)
+		case "$in", "$nin":
+			list, ok := operand.([]interface{})
+			if !ok {
+				*errors = append(*errors, NewError("type_mismatch", field, typeName(operand), map[string]interface{}{"expected": "[]interface {}"}))
+				continue
+			}
+			items := make([]interface{}, len(list))
+			for i, item := range list {
+				items[i] = filterOperand(validator, field+"."+strconv.Itoa(i), item, full, rights, opt, errors)
+			}
+			validated[operator] = items
+		default:
+			validated[operator] = filterOperand(validator, field, operand, full, rights, opt, errors)
+		}
+	}
+	return validated
+}
+
+// this private function validates an operand against the field validator and returns its stored form
+// the operand of an array field may be one of its items; the range bounds (full false) are only checked for their type
+func filterOperand(validator *Validator, location string, value interface{}, full bool, rights int, opt Options, errors *[]*DataError) interface{} {
+	if value == nil {
+		return nil
+	}
+	operand := *validator
+	operand.Rights, operand.IsRequired, operand.RequiredOnSet, operand.Immutable, operand.CrossTest = [3]int{}, false, false, false, nil
+	if strings.HasPrefix(operand.Type, "[]") && reflect.ValueOf(value).Kind() != reflect.Slice {
+		operand.Type = operand.Type[2:]
+		for _, rule := range []string{"items", "ref", "tuple", "unique", "ordered"} {
+			flaggableRules[rule](&operand)
+		}
+	}
+	if !full {
+		for _, rule := range []string{"regexp", "boundaries", "money", "custom"} {
+			flaggableRules[rule](&operand)
+		}
+	}
+
+	// the operand is validated as a value submitted on SET, to be converted as the stored one
+	operandOpt := itemOptions(opt, rights, false)
+	operandOpt.Usage = SET
+	document := map[string]interface{}{"operand": value}
+	result := validateField("operand", &operand, document, document, rights, operandOpt)
+	for _, err := range result.errors {
+		located := *err
+		located.Field = location + strings.TrimPrefix(err.Field, "operand")
+		located.Path = append(ParsePath(location), err.Path[1:]...)
+		*errors = append(*errors, &located)
+	}
+	if !result.write {
+		return value
+	}
+	return result.value
+}
+
+// this private function returns the schema paths of the validators, by the path the clients know the fields by
+func submittedFields(schema *Schema) map[string]string {
+	fields := make(map[string]string, len(schema.Validators))
+	for path, validator := range schema.Validators {
+		in, _ := validator.Paths(path, INIT)
+		fields[in] = path
+	}
+	return fields
+}
+
+// this private function tells whether one of the keys of the document is an operator
+func hasOperator(document map[string]interface{}) bool {
+	for key := range document {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// this private function returns the keys of the document, sorted
+func sortedKeys(document map[string]interface{}) []string {
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
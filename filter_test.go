@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateFilter(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"age":    {Type: "int64", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"name":   {Type: "string", Regexp: "^[a-z]+$", Target: "n"},
+		"tags":   {Type: "[]string"},
+		"secret": {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+	}}
+
+	// the range bounds only have the type of the field, the value of an array field may be one of its items
+	filter := map[string]interface{}{
+		"age":  map[string]interface{}{"$gte": json.Number("18"), "$lt": json.Number("1000")},
+		"$or":  []interface{}{map[string]interface{}{"name": "bob"}, map[string]interface{}{"tags": map[string]interface{}{"$in": []interface{}{"a", "b"}}}},
+		"tags": "x",
+	}
+	validated, errors := ValidateFilter(schema, filter, Options{UserRights: USER})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if validated["$or"].([]interface{})[0].(map[string]interface{})["n"] != "bob" {
+		t.Errorf("expected the filter to be rewritten for the stored documents, got %v", validated)
+	}
+	if validated["age"].(map[string]interface{})["$lt"] != int64(1000) {
+		t.Errorf("expected the bound to be converted, got %v", validated["age"])
+	}
+
+	refused := map[string]interface{}{
+		"age":    map[string]interface{}{"$where": "1", "$eq": json.Number("200")},
+		"$where": "sleep(1000)",
+		"secret": "x",
+		"other":  1,
+		"name":   map[string]interface{}{"$in": []interface{}{"Bob"}},
+	}
+	validated, errors = ValidateFilter(schema, refused, Options{UserRights: USER})
+	expected := map[string]string{
+		"$where":     "invalid_filter",
+		"age.$eq":    "out_of_boundaries",
+		"age.$where": "invalid_filter",
+		"name.$in.0": "regex_not_match",
+		"other":      "unknown_field",
+		"secret":     "insufficient_rights",
+	}
+	if validated != nil || len(errors) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, errors)
+	}
+	for _, err := range errors {
+		if expected[err.Field] != err.Code {
+			t.Errorf("%s: expected %q, got %q", err.Field, expected[err.Field], err.Code)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the rules a feature flag can disable – see Validator.DisabledWhen
+// the type, the rights and the transform are never disabled, the stored data and its access depending on them
+var flaggableRules = map[string]func(v *Validator){
+	"required": func(v *Validator) { v.IsRequired, v.RequiredOnSet = false, false },
+	"regexp":   func(v *Validator) { v.Regexp, v.Regexps = "", nil },
+	"boundaries": func(v *Validator) {
+		v.Boundaries, v.IntBoundaries = Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}, nil
+	},
+	"money":     func(v *Validator) { v.Money = nil },
+	"items":     func(v *Validator) { v.Items = nil },
+	"ref":       func(v *Validator) { v.Ref = "" },
+	"tuple":     func(v *Validator) { v.Tuple, v.AdditionalItems = nil, nil },
+	"unique":    func(v *Validator) { v.UniqueBy = "" },
+	"ordered":   func(v *Validator) { v.Ordered = nil },
+	"custom":    func(v *Validator) { v.CustomTest = nil },
+	"immutable": func(v *Validator) { v.Immutable = false },
+	"cross":     func(v *Validator) { v.CrossTest = nil },
+
+	// the rules sanitizing the value: the value is kept as submitted without them
+	"searchquery": func(v *Validator) { v.SearchQuery = nil },
+
+	// the rules of the string formats
+	"format": func(v *Validator) { v.Format, v.HashOf, v.BlockedDomains = "", "", nil },
+	"hash":   func(v *Validator) { v.HashOf = "" },
+
+	// the rules of the composite sub-documents
+	"schedule":   func(v *Validator) { v.Schedule = nil },
+	"birthdate":  func(v *Validator) { v.Birthdate = nil },
+	"timewindow": func(v *Validator) { v.TimeWindow = nil },
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the validator without the rules its feature flags disable for the call, the validator itself if none is
+func enabledRules(validator *Validator, opt Options) *Validator {
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var enabled *Validator
+	for rule, disabled := range validator.DisabledWhen {
+		disable, ok := flaggableRules[rule]
+		if !ok {
+			panic(fmt.Errorf("validation: unknown flagged rule %q", rule))
+		}
+		if disabled == nil || !disabled(ctx) {
+			continue
+		}
+		if enabled == nil {
+			copied := *validator
+			enabled = &copied
+		}
+		disable(enabled)
+	}
+	if enabled == nil {
+		return validator
+	}
+	return enabled
+}
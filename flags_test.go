@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type flagKey struct{}
+
+func TestDisabledWhen(t *testing.T) {
+	off := func(ctx context.Context) bool { return ctx.Value(flagKey{}) == "off" }
+	schema := &Schema{Validators: map[string]*Validator{
+		"code":  {Type: "string", Regexp: "^x$", DisabledWhen: map[string]func(context.Context) bool{"regexp": off}},
+		"count": {Type: "json.Number", IsRequired: true, Boundaries: Boundaries{Min: 0, Max: 3}, DisabledWhen: map[string]func(context.Context) bool{"boundaries": off, "required": off}},
+	}}
+	document := map[string]interface{}{"code": "y", "count": json.Number("9")}
+
+	if _, errors := schema.Validate(document, Options{Usage: INIT}); len(errors) != 2 {
+		t.Errorf("expected the rules to run while their flags are on, got %v", errors)
+	}
+	ctx := context.WithValue(context.Background(), flagKey{}, "off")
+	if _, errors := schema.Validate(document, Options{Usage: INIT, Context: ctx}); len(errors) > 0 {
+		t.Errorf("expected the flagged rules to be disabled, got %v", errors)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{}, Options{Usage: INIT, Context: ctx}); len(errors) > 0 {
+		t.Errorf("expected the required rule to be disabled, got %v", errors)
+	}
+	if !schema.Validators["count"].IsRequired {
+		t.Errorf("expected the validator of the schema to be left untouched")
+	}
+
+	// the type cannot be disabled
+	unknown := &Schema{Validators: map[string]*Validator{"code": {Type: "string", DisabledWhen: map[string]func(context.Context) bool{"type": off}}}}
+	if errors := CheckSchema(unknown); len(errors) != 1 || !strings.Contains(errors[0].Error(), "unknown flagged rule") {
+		t.Errorf("expected the unknown flagged rule to be reported, got %v", errors)
+	}
+}
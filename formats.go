@@ -0,0 +1,114 @@
+package validation
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the checks of the named formats of the strings, returning the normalized string written in dest and whether it is valid – see Validator.Format
+var formats = map[string]func(str string) (string, bool){
+	"md5hex":    hexFormat(md5.Size * 2),
+	"sha1hex":   hexFormat(sha1.Size * 2),
+	"sha256hex": hexFormat(sha256.Size * 2),
+	"filename":  Filename,
+	"filepath":  Filepath,
+	"domain":    Domain,
+	"timezone":  Timezone,
+}
+
+// the hash functions of the hash formats, by format – see Validator.HashOf
+var hashFormats = map[string]func() hash.Hash{
+	"md5hex":    md5.New,
+	"sha1hex":   sha1.New,
+	"sha256hex": sha256.New,
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the check of a format: a named one, or "hexlen:<n>" for n hexadecimal digits
+func formatCheck(format string) (func(str string) (string, bool), bool) {
+	if check, ok := formats[format]; ok {
+		return check, true
+	}
+	if strings.HasPrefix(format, "hexlen:") {
+		if n, err := strconv.Atoi(format[len("hexlen:"):]); err == nil && n > 0 {
+			return hexFormat(n), true
+		}
+	}
+	return nil, false
+}
+
+// this private function returns the check of the strings made of n hexadecimal digits, in either case
+func hexFormat(n int) func(str string) (string, bool) {
+	return func(str string) (string, bool) {
+		return str, len(str) == n && strings.Trim(str, "0123456789abcdefABCDEF") == ""
+	}
+}
+
+// this private function checks the string value is of the Format of the validator, and returns it normalized
+func checkFormat(validator *Validator, in string, value interface{}, errors *[]*DataError) (interface{}, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	check, ok := formatCheck(validator.Format)
+	if !ok {
+		panic("validation: unknown format " + strconv.Quote(validator.Format))
+	}
+	normalized, ok := check(str)
+	if !ok {
+		*errors = append(*errors, NewError("invalid_format", in, value, map[string]interface{}{"expected": validator.Format}))
+		return value, false
+	}
+	if validator.BlockedDomains != nil && validator.Format == "domain" && !checkBlockedDomain(validator, in, normalized, errors) {
+		return value, false
+	}
+	return normalized, true
+}
+
+// this private function checks the value is the hash of the content of the HashOf field in the merged document, with the hash function of the Format
+// the check is skipped when the merged document holds no content, which the rules of the content field report if it is required
+func checkHash(validator *Validator, in string, value interface{}, merged map[string]interface{}, errors *[]*DataError) bool {
+	str, ok := value.(string)
+	if !ok {
+		return true
+	}
+	newHash, ok := hashFormats[validator.Format]
+	if !ok {
+		panic("validation: no hash function for the format " + strconv.Quote(validator.Format))
+	}
+	var content []byte
+	contentValue, err := tools.ReadDeep(merged, validator.HashOf)
+	switch contentValue := contentValue.(type) {
+	case string:
+		content = []byte(contentValue)
+	case []byte:
+		content = contentValue
+	default:
+		if err != nil || contentValue == nil {
+			return true
+		}
+		*errors = append(*errors, NewError("hash_mismatch", in, value, map[string]interface{}{"field": validator.HashOf}))
+		return false
+	}
+	h := newHash()
+	h.Write(content)
+	if !strings.EqualFold(str, hex.EncodeToString(h.Sum(nil))) {
+		*errors = append(*errors, NewError("hash_mismatch", in, value, map[string]interface{}{"field": validator.HashOf}))
+		return false
+	}
+	return true
+}
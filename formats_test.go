@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"body": {Type: "string"},
+		"sum":  {Type: "string", Format: "sha256hex", HashOf: "body"},
+		"md5":  {Type: "string", Format: "md5hex"},
+		"id":   {Type: "string", Format: "hexlen:5"},
+	}}
+	// the hashes are compared in either case
+	valid := map[string]interface{}{
+		"body": "hello",
+		"sum":  "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824",
+		"md5":  "5d41402abc4b2a76b9719d911017c592",
+		"id":   "abc0f",
+	}
+	if _, errors := schema.Validate(valid, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+	// with no content, the hash is not checked
+	if _, errors := schema.Validate(map[string]interface{}{"sum": valid["sum"]}, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	invalid := map[string]interface{}{"body": "hellO", "sum": valid["sum"], "md5": "zz", "id": "abc0"}
+	_, errors := schema.Validate(invalid, Options{Usage: INIT})
+	expected := map[string]string{"sum": "hash_mismatch", "md5": "invalid_format", "id": "invalid_format"}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, errors)
+	}
+	for _, err := range errors {
+		if expected[err.Field] != err.Code {
+			t.Errorf("%s: expected %q, got %q", err.Field, expected[err.Field], err.Code)
+		}
+	}
+
+	unknown := &Schema{Validators: map[string]*Validator{"sum": {Type: "string", Format: "hexlen:x", HashOf: "body"}}}
+	errs := CheckSchema(unknown)
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "unknown format") || !strings.Contains(errs[1].Error(), "non-hash format") {
+		t.Errorf("expected the unknown format and the hash check to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func FuzzValidate(f *testing.F) {
+	for _, seed := range []string{
+		`{"name": "ab", "count": 3, "tags": ["a", "b"]}`,
+		`{"name": 12, "count": "x", "tags": "a"}`,
+		`{"count": 1e400}`,
+		`{"tags": [["a"], {"b": 1}, null]}`,
+		`{"address": {"city": 1}}`,
+		`{"address": "Paris"}`,
+		`{"address": {"geo": {"lat": 1}}}`,
+		`{"x": [], "y": {}}`,
+		`{"price": "12.50"}`,
+		`{"price": "1e3"}`,
+		`{"price": "-0.5", "count": 3}`,
+		`{"price": 12.5}`,
+		`{"price": "12.505"}`,
+		`{"price": "3/4"}`,
+		`{"price": "1/2", "count": 1}`,
+		`{"price": "0x10"}`,
+		`{"price": ""}`,
+		`{"ratio": "12.3456789"}`,
+		`{"ratio": 0.1, "price": "1"}`,
+		`{"balance": {"amount": "100.25", "currency": "EUR"}}`,
+		`{"balance": {"amount": 10, "currency": "JPY"}}`,
+		`{"balance": {"amount": "10.5", "currency": "JPY"}}`,
+		`{"balance": "EUR"}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":         {Type: "string", Regexp: "^[a-z]+$"},
+		"count":        {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 10}},
+		"tags":         {Type: "[]string"},
+		"address":      {Type: "map[string]string"},
+		"address.city": {Type: "string", IsRequired: true},
+		"balance":      {Type: "map[string]interface {}", Money: &Money{}},
+		"price":        {Type: "string", Decimal: &Decimal{Scale: 2, Min: "-100", Max: "100"}},
+		"ratio":        {Type: "json.Number", Decimal: &Decimal{Scale: -1}},
+		"x":            {Type: "x"},
+		"y":            {Type: "[", CustomTest: func(interface{}) (bool, *DataError) { return false, nil }},
+	}}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var document map[string]interface{}
+		if decoder.Decode(&document) != nil || document == nil {
+			return
+		}
+		_, errors := schema.Validate(document, Options{Usage: INIT})
+		for _, err := range errors {
+			if err.Type == "Internal error" {
+				t.Fatalf("internal error on %s: %v", data, err)
+			}
+		}
+	})
+}
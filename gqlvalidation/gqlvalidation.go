@@ -0,0 +1,126 @@
+// Package gqlvalidation validates the input objects of a gqlgen server against the schemas of the validation package
+// the schemas are registered by name for the @validate directive, or by "Type.field" for the field middleware, which validates the raw arguments of the field
+package gqlvalidation
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/grebett/validation"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct holds the schemas the input objects are validated against
+// the errors are reported as GraphQL errors whose extensions carry the code, the input field path and the rule parameters
+type Validator struct {
+	Schemas map[string]validation.DocumentValidator      // the schemas, by directive argument or by "Type.field"
+	Options func(ctx context.Context) validation.Options // the options of the validations, Options{Usage: INIT} if nil
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method implements the directive "directive @validate(schema: String!) on INPUT_OBJECT | ARGUMENT_DEFINITION"
+// it validates the raw input map, obj for an input object or the argument itself, before the resolver runs
+func (v *Validator) Directive(ctx context.Context, obj interface{}, next graphql.Resolver, schema string) (interface{}, error) {
+	input, ok := obj.(map[string]interface{})
+	if !ok {
+		value, err := next(ctx)
+		if err != nil {
+			return value, err
+		}
+		if input, ok = value.(map[string]interface{}); !ok {
+			// a typed argument was already unmarshalled: the field middleware must be used instead
+			return value, nil
+		}
+		if err := v.validate(ctx, schema, input); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+	if err := v.validate(ctx, schema, input); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// This method is a gqlgen field middleware, to register with AroundFields
+// the raw arguments of the fields a schema is registered for, by "Type.field", are validated before the resolver runs
+func (v *Validator) Middleware(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	field := graphql.GetFieldContext(ctx)
+	if field == nil || field.Field.Field == nil {
+		return next(ctx)
+	}
+	name := field.Object + "." + field.Field.Name
+	if _, ok := v.Schemas[name]; !ok {
+		return next(ctx)
+	}
+
+	var variables map[string]interface{}
+	if operation := graphql.GetOperationContext(ctx); operation != nil {
+		variables = operation.Variables
+	}
+	if err := v.validate(ctx, name, field.Field.ArgumentMap(variables)); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// This method validates the input against the named schema and returns the errors as GraphQL errors, an "unknown_schema" one if the schema is not registered
+// all the errors but the last one are added to the response, the last one is returned to fail the field
+func (v *Validator) validate(ctx context.Context, name string, input map[string]interface{}) error {
+	schema, ok := v.Schemas[name]
+	if !ok {
+		// the directive names a schema that is not registered
+		unknown := validation.NewError("unknown_schema", "", nil, map[string]interface{}{"schema": name})
+		return Errors(graphql.GetPath(ctx), []*validation.DataError{unknown})[0]
+	}
+	normalized, err := validation.Normalize(input)
+	if err != nil {
+		return &gqlerror.Error{Message: err.Error(), Path: graphql.GetPath(ctx), Extensions: map[string]interface{}{"code": "invalid_payload"}}
+	}
+
+	opt := validation.Options{Usage: validation.INIT}
+	if v.Options != nil {
+		opt = v.Options(ctx)
+	}
+	_, errors := schema.Validate(normalized.(map[string]interface{}), opt)
+	if len(errors) == 0 {
+		return nil
+	}
+	list := Errors(graphql.GetPath(ctx), errors)
+	for _, err := range list[:len(list)-1] {
+		graphql.AddError(ctx, err)
+	}
+	return list[len(list)-1]
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function converts the errors into GraphQL errors located at the response path
+// the extensions hold the error code, the input field path ("address.city") and segments, the rule parameters and the correlation metadata
+func Errors(path ast.Path, errors []*validation.DataError) gqlerror.List {
+	list := make(gqlerror.List, 0, len(errors))
+	for _, err := range errors {
+		extensions := map[string]interface{}{"code": err.Code, "field": err.Field}
+		if len(err.Path) > 0 {
+			extensions["path"] = err.Path
+		}
+		if len(err.Params) > 0 {
+			extensions["params"] = err.Params
+		}
+		if len(err.Meta) > 0 {
+			extensions["meta"] = err.Meta
+		}
+		list = append(list, &gqlerror.Error{Message: err.Error(), Path: path, Extensions: extensions})
+	}
+	return list
+}
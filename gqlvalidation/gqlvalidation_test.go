@@ -0,0 +1,74 @@
+package gqlvalidation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grebett/validation"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestDirective(t *testing.T) {
+	v := &Validator{Schemas: map[string]validation.DocumentValidator{
+		"user": &validation.Schema{Validators: map[string]*validation.Validator{
+			"name": {Type: "string", IsRequired: true},
+			"age":  {Type: "json.Number", Boundaries: validation.Boundaries{Min: 0, Max: 150}},
+		}},
+	}}
+	resolved := false
+	next := func(ctx context.Context) (interface{}, error) {
+		resolved = true
+		return "ok", nil
+	}
+
+	value, err := v.Directive(context.Background(), map[string]interface{}{"name": "Ada", "age": 36}, next, "user")
+	if err != nil || value != "ok" || !resolved {
+		t.Errorf("expected the resolver to run, got %v %v", value, err)
+	}
+
+	resolved = false
+	_, err = v.Directive(context.Background(), map[string]interface{}{"age": json.Number("36")}, next, "user")
+	gqlErr, ok := err.(*gqlerror.Error)
+	if !ok || resolved {
+		t.Fatalf("expected a GraphQL error before the resolver, got %v", err)
+	}
+	if gqlErr.Extensions["code"] != "required" || gqlErr.Extensions["field"] != "name" {
+		t.Errorf("expected the required name, got %v", gqlErr.Extensions)
+	}
+
+	resolved = false
+	_, err = v.Directive(context.Background(), map[string]interface{}{"name": "Ada"}, next, "account")
+	if gqlErr, ok := err.(*gqlerror.Error); !ok || resolved || gqlErr.Extensions["code"] != "unknown_schema" {
+		t.Errorf("expected the unknown schema to fail the field, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	path := ast.Path{ast.PathName("createUser")}
+	errors := []*validation.DataError{
+		validation.NewError("out_of_boundaries", "address.zip", 5, map[string]interface{}{"min": 10, "max": 20}),
+		validation.NewError("required", "name", nil, nil),
+	}
+	errors[0].Meta = map[string]interface{}{"requestId": "r1"}
+	list := Errors(path, errors)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(list))
+	}
+	if list[0].Extensions["code"] != "out_of_boundaries" || list[0].Extensions["field"] != "address.zip" || list[0].Extensions["params"] == nil || len(list[0].Path) != 1 {
+		t.Errorf("unexpected error %v", list[0])
+	}
+	if meta, ok := list[0].Extensions["meta"].(map[string]interface{}); !ok || meta["requestId"] != "r1" {
+		t.Errorf("expected the metadata, got %v", list[0].Extensions)
+	}
+	if _, ok := list[1].Extensions["params"]; ok {
+		t.Errorf("expected no params, got %v", list[1].Extensions)
+	}
+	if _, ok := list[1].Extensions["meta"]; ok {
+		t.Errorf("expected no metadata, got %v", list[1].Extensions)
+	}
+	if list[1].Message != errors[1].Error() {
+		t.Errorf("expected %q, got %q", errors[1].Error(), list[1].Message)
+	}
+}
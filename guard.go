@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct protects the validation from a custom test calling a remote dependency, e.g. a uniqueness check against a database – see Wrap
+// the tests run with a timeout, a limited concurrency and behind a circuit breaker: once open, the fallback verdict is returned without calling the dependency
+// it is safe for concurrent use, and a Guard wraps a single test, whose failures are the only ones opening its circuit
+type Guard struct {
+	Timeout          time.Duration                              // the max duration of a test, waiting for a free slot included – unlimited if 0
+	MaxConcurrent    int                                        // the max number of tests running at once, the others waiting for a slot – unlimited if 0
+	FailureThreshold int                                        // the number of failures in a row (timeouts, panics) opening the circuit, never opened if 0
+	Cooldown         time.Duration                              // the delay before an open circuit lets a trial test through, closing it on success – 30 seconds if 0
+	Fallback         func(value interface{}) (bool, *DataError) // this function returns the verdict when the test cannot be run in time, a test_unavailable error if nil
+
+	once     sync.Once
+	slots    chan struct{}
+	mu       sync.Mutex
+	failures int       // the failures in a row
+	openedAt time.Time // when the circuit was opened, zero if closed
+	trial    bool      // a trial test of the half-open circuit is running
+}
+
+// This private struct is the outcome of a guarded test
+type guardedVerdict struct {
+	ok    bool
+	err   *DataError
+	crash bool // the test panicked
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the custom test running the remote test under the guard, to be set as a Validator.CustomTest
+// the context of the remote test is canceled once the Timeout is elapsed: the test should give up then, its slot being held until it returns
+func (g *Guard) Wrap(test func(ctx context.Context, value interface{}) (bool, *DataError)) func(interface{}) (bool, *DataError) {
+	return func(value interface{}) (bool, *DataError) {
+		g.once.Do(g.init)
+		if !g.allow() {
+			return g.fallback(value)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if g.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), g.Timeout)
+		}
+		defer cancel()
+
+		// a slot is waited for until the timeout, a saturation being no failure of the dependency
+		if g.slots != nil {
+			select {
+			case g.slots <- struct{}{}:
+			case <-ctx.Done():
+				g.release()
+				return g.fallback(value)
+			}
+		}
+
+		verdicts := make(chan guardedVerdict, 1)
+		go func() {
+			defer func() {
+				if g.slots != nil {
+					<-g.slots
+				}
+				if r := recover(); r != nil {
+					log.Printf("validation: recovered from a panic in a guarded test: %v\n%s", r, debug.Stack())
+					verdicts <- guardedVerdict{crash: true}
+				}
+			}()
+			ok, err := test(ctx, value)
+			verdicts <- guardedVerdict{ok: ok, err: err}
+		}()
+
+		select {
+		case verdict := <-verdicts:
+			if verdict.crash {
+				g.record(false)
+				return g.fallback(value)
+			}
+			g.record(true)
+			return verdict.ok, verdict.err
+		case <-ctx.Done():
+			g.record(false)
+			return g.fallback(value)
+		}
+	}
+}
+
+// This method tells whether the circuit is open, the dependency being deemed down
+func (g *Guard) Open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.openedAt.IsZero()
+}
+
+// This method sets the slots of the concurrent tests up
+func (g *Guard) init() {
+	if g.MaxConcurrent > 0 {
+		g.slots = make(chan struct{}, g.MaxConcurrent)
+	}
+}
+
+// This method tells whether a test may be run: always if the circuit is closed, and once the cooldown is elapsed for a single trial test if open
+func (g *Guard) allow() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.openedAt.IsZero() {
+		return true
+	}
+	cooldown := g.Cooldown
+	if cooldown == 0 {
+		cooldown = 30 * time.Second
+	}
+	if g.trial || time.Since(g.openedAt) < cooldown {
+		return false
+	}
+	g.trial = true
+	return true
+}
+
+// This method lets another trial test through, the current one having not been run
+func (g *Guard) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.trial = false
+}
+
+// This method records the outcome of a test: a success closes the circuit, and a failure opens it once the threshold is reached – or again after a trial
+func (g *Guard) record(success bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	trial := g.trial
+	g.trial = false
+	if success {
+		g.failures, g.openedAt = 0, time.Time{}
+		return
+	}
+	g.failures++
+	if g.FailureThreshold > 0 && (trial || g.failures >= g.FailureThreshold) {
+		g.openedAt = time.Now()
+	}
+}
+
+// This method returns the fallback verdict
+func (g *Guard) fallback(value interface{}) (bool, *DataError) {
+	if g.Fallback != nil {
+		return g.Fallback(value)
+	}
+	return false, NewError("test_unavailable", "", value, nil)
+}
@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	var calls, slow int32 = 0, 1
+	guard := &Guard{Timeout: 20 * time.Millisecond, FailureThreshold: 2, Cooldown: 50 * time.Millisecond}
+	test := guard.Wrap(func(ctx context.Context, value interface{}) (bool, *DataError) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&slow) == 1 {
+			<-ctx.Done()
+		}
+		return value == "ok", nil
+	})
+
+	// two timeouts open the circuit, the next tests failing fast
+	for i := 0; i < 4; i++ {
+		if ok, err := test("ok"); ok || err == nil || err.Code != "test_unavailable" {
+			t.Errorf("call %d: expected the test to be unavailable, got %v %v", i, ok, err)
+		}
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 2 || !guard.Open() {
+		t.Fatalf("expected 2 calls and an open circuit, got %d calls", calls)
+	}
+
+	// once the cooldown elapsed, a successful trial closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&slow, 0)
+	if ok, err := test("ok"); !ok || err != nil || guard.Open() {
+		t.Errorf("expected the trial to close the circuit, got %v %v", ok, err)
+	}
+	if ok, _ := test("ko"); ok || guard.Open() {
+		t.Errorf("expected the verdict of the test")
+	}
+}
+
+func TestGuardConcurrency(t *testing.T) {
+	var running, max int32
+	guard := &Guard{MaxConcurrent: 2, Timeout: time.Second}
+	test := guard.Wrap(func(ctx context.Context, value interface{}) (bool, *DataError) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return true, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			test(1)
+		}()
+	}
+	wg.Wait()
+	if max := atomic.LoadInt32(&max); max != 2 {
+		t.Errorf("expected 2 tests at most at once, got %d", max)
+	}
+}
+
+func TestGuardPanic(t *testing.T) {
+	guard := &Guard{FailureThreshold: 1, Fallback: func(interface{}) (bool, *DataError) { return true, nil }}
+	test := guard.Wrap(func(ctx context.Context, value interface{}) (bool, *DataError) { panic("boom") })
+	if ok, err := test(1); !ok || err != nil || !guard.Open() {
+		t.Errorf("expected the fallback verdict and an open circuit, got %v %v", ok, err)
+	}
+}
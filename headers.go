@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates the headers of a request or a response, the validators being written for the header names: "X-Api-Key", "Accept", ...
+// the names are matched case-insensitively and the values converted into the validator types as for ValidateEnv
+// a slice validator receives every value of the header, the comma-separated lists being split; any other validator receives a single value and a repeated header is a type mismatch
+// the headers no validator is written for are ignored
+func ValidateHeaders(header http.Header, schema DocumentValidator, opt Options) (map[string]interface{}, DataErrors) {
+	doc := make(map[string]interface{})
+	for path, validator := range schemaValidators(schema) {
+		in, _ := validator.Paths(path, opt.Usage)
+		values := headerValues(header, in)
+		if len(values) == 0 {
+			continue
+		}
+
+		doc[in] = parseValues(validator.Type, values)
+	}
+	return schema.Validate(doc, opt)
+}
+
+// This function validates the parameters of a query string, the validators being written for the parameter names
+// the values are converted into the validator types as for ValidateHeaders: a slice validator receives every value of a repeated parameter, any other validator a single one
+// the parameters no validator is written for are ignored
+func ValidateQuery(values url.Values, schema DocumentValidator, opt Options) (map[string]interface{}, DataErrors) {
+	doc := make(map[string]interface{})
+	for path, validator := range schemaValidators(schema) {
+		in, _ := validator.Paths(path, opt.Usage)
+		if params, ok := values[in]; ok && len(params) > 0 {
+			doc[in] = parseValues(validator.Type, params)
+		}
+	}
+	return schema.Validate(doc, opt)
+}
+
+// this private function returns the values of the header, whatever the case of its name in the map
+func headerValues(header http.Header, name string) []string {
+	if values := header.Values(name); len(values) > 0 {
+		return values
+	}
+	var values []string
+	for key, keyValues := range header {
+		if strings.EqualFold(key, name) {
+			values = append(values, keyValues...)
+		}
+	}
+	return values
+}
+
+// this private function converts the values of a header or a query parameter into the validator type
+// a repeated value is a type mismatch, unless the validator is a slice one
+func parseValues(_type string, values []string) interface{} {
+	if strings.HasPrefix(_type, "[]") {
+		return parseString(_type, strings.Join(values, ","))
+	}
+	if len(values) == 1 {
+		return parseString(_type, strings.TrimSpace(values[0]))
+	}
+	repeated := make([]interface{}, len(values))
+	for i, value := range values {
+		repeated[i] = value
+	}
+	return repeated
+}
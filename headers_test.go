@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestValidateHeaders(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"X-Api-Key":    {Type: "string", IsRequired: true, Regexp: "^[a-z0-9]{8}$"},
+		"X-Request-Id": {Type: "string"},
+		"Accept":       {Type: "[]string"},
+		"X-Retries":    {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 5}},
+	}}
+
+	header := http.Header{}
+	header.Set("X-Api-Key", "abcd1234")
+	header.Add("Accept", "text/html, application/json")
+	header.Add("Accept", "*/*")
+	header["x-retries"] = []string{" 3 "}
+	header.Set("X-Ignored", "yes")
+	dest, errors := ValidateHeaders(header, schema, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := map[string]interface{}{
+		"X-Api-Key": "abcd1234",
+		"Accept":    []interface{}{"text/html", "application/json", "*/*"},
+		"X-Retries": json.Number("3"),
+	}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v", expected, dest)
+	}
+
+	header = http.Header{}
+	header.Add("X-Request-Id", "a")
+	header.Add("X-Request-Id", "b")
+	_, errors = ValidateHeaders(header, schema, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Field != "X-Api-Key" || errors[0].Code != "required" || errors[1].Field != "X-Request-Id" || errors[1].Code != "type_mismatch" {
+		t.Errorf("expected the missing key and the repeated request id, got %v", errors)
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"page": {Type: "json.Number", Boundaries: Boundaries{Min: 1, Max: 100}},
+		"tag":  {Type: "[]string"},
+		"sort": {Type: "string"},
+	}}
+	values := url.Values{"page": {"2"}, "tag": {"a", "b,c"}, "Sort": {"name"}, "other": {"x"}}
+	dest, errors := ValidateQuery(values, schema, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := map[string]interface{}{"page": json.Number("2"), "tag": []interface{}{"a", "b", "c"}}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v", expected, dest)
+	}
+
+	_, errors = ValidateQuery(url.Values{"sort": {"a", "b"}, "page": {"0"}}, schema, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Code != "out_of_boundaries" || errors[1].Field != "sort" || errors[1].Code != "type_mismatch" {
+		t.Errorf("expected the page out of boundaries and the repeated sort, got %v", errors)
+	}
+}
+
+func TestValidateHeadersRemoteSchema(t *testing.T) {
+	remote := &RemoteSchema{}
+	if err := remote.update([]byte(`{"Validators": {"X-Api-Key": {"Type": "string", "IsRequired": true}}}`), "", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, errors := ValidateHeaders(http.Header{}, remote, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "required" {
+		t.Errorf("expected the missing key, got %v", errors)
+	}
+}
+
+func TestValidateHeadersRecorder(t *testing.T) {
+	recorder := &Recorder{Schema: &Schema{Validators: map[string]*Validator{"X-Retries": {Type: "json.Number", Boundaries: Boundaries{Min: 0, Max: 5}}}}, Dir: t.TempDir()}
+	header := http.Header{}
+	header.Set("X-Retries", "6")
+	if _, errors := ValidateHeaders(header, recorder, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "out_of_boundaries" {
+		t.Errorf("expected the retries out of boundaries, got %v", errors)
+	}
+}
@@ -0,0 +1,70 @@
+package validation
+
+import "regexp"
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the default pattern of the idempotency keys: 8 to 255 letters, digits, "_", "-", "." or ":", e.g. a UUID – see Schema.IdempotencyField
+const idempotencyKeyPattern = `^[A-Za-z0-9_.:-]{8,255}$`
+
+// the compiled default pattern, shared by the implicit validators of the idempotency fields
+var idempotencyKeyRegexp = regexp.MustCompile(idempotencyKeyPattern)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the idempotency key of a validation: the one validated on INIT and GET, or the stored one on SET – see Schema.IdempotencyField
+// the key is empty if the schema has no idempotency field or the document none
+func IdempotencyKey(schema DocumentValidator, dest map[string]interface{}, opt Options) string {
+	s := documentSchema(schema)
+	if s.IdempotencyField == "" {
+		return ""
+	}
+	// the key is read where the validation wrote it, or where it is stored on SET
+	stored, written := s.IdempotencyField, s.IdempotencyField
+	if validator, ok := s.Validators[s.IdempotencyField]; ok {
+		_, stored = validator.Paths(s.IdempotencyField, INIT)
+		_, written = validator.Paths(s.IdempotencyField, opt.Usage)
+	}
+	key := readExisting(dest, written)
+	if opt.Usage == SET {
+		key = readExisting(opt.Existing, stored)
+	}
+	str, _ := key.(string)
+	return str
+}
+
+// this private function returns the schema whose idempotency field validator is required on INIT, immutable, and of the default pattern unless it has a pattern or a format of its own
+// the validator is written when the schema has none for the field
+func withIdempotencyKey(schema *Schema) *Schema {
+	path := schema.IdempotencyField
+	validator, ok := schema.Validators[path]
+	if ok && validator.IsRequired && validator.Immutable && (validator.Regexp != "" || validator.Format != "") {
+		return schema
+	}
+
+	var keyValidator *Validator
+	if ok {
+		copied := *validator
+		keyValidator = &copied
+	} else {
+		keyValidator = &Validator{Type: "string"}
+	}
+	keyValidator.IsRequired, keyValidator.Immutable = true, true
+	if keyValidator.Regexp == "" && keyValidator.Format == "" {
+		keyValidator.Regexp, keyValidator.compiled = idempotencyKeyPattern, idempotencyKeyRegexp
+	}
+
+	validators := make(map[string]*Validator, len(schema.Validators)+1)
+	for other, validator := range schema.Validators {
+		validators[other] = validator
+	}
+	validators[path] = keyValidator
+	keyed := *schema
+	keyed.Validators = validators
+	keyed.paths = nil
+	return &keyed
+}
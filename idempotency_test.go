@@ -0,0 +1,53 @@
+package validation
+
+import "testing"
+
+func TestIdempotencyField(t *testing.T) {
+	schema := &Schema{IdempotencyField: "requestId", Validators: map[string]*Validator{"name": {Type: "string"}}}
+
+	// the key has its rules with no validator written
+	_, errors := schema.Validate(map[string]interface{}{"name": "a"}, Options{Usage: INIT})
+	if len(errors) != 1 || errors[0].Code != "required" || errors[0].Field != "requestId" {
+		t.Errorf("expected the key to be required, got %v", errors)
+	}
+	_, errors = schema.Validate(map[string]interface{}{"name": "a", "requestId": "bad key"}, Options{Usage: INIT})
+	if len(errors) != 1 || errors[0].Code != "regex_not_match" {
+		t.Errorf("expected the default pattern, got %v", errors)
+	}
+	existing := map[string]interface{}{"name": "a", "requestId": "0b5e6c2a-1111"}
+	_, errors = schema.Validate(map[string]interface{}{"requestId": "0b5e6c2a-2222"}, Options{Usage: SET, Existing: existing})
+	if len(errors) != 1 || errors[0].Code != "immutable" {
+		t.Errorf("expected the key to be immutable, got %v", errors)
+	}
+
+	// the validator of the schema keeps its own pattern, and is left untouched
+	schema.Validators["requestId"] = &Validator{Type: "string", Regexp: "^[0-9]+$"}
+	_, errors = schema.Validate(map[string]interface{}{"name": "a", "requestId": "12"}, Options{Usage: INIT})
+	if len(errors) > 0 || schema.Validators["requestId"].IsRequired {
+		t.Errorf("expected the own pattern, got %v", errors)
+	}
+	schema.Validators["requestId"] = &Validator{Type: "int"}
+	if errs := CheckSchema(schema); len(errs) != 1 {
+		t.Errorf("expected the non-string key to be reported, got %v", errs)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	schema := &Schema{IdempotencyField: "requestId", Validators: map[string]*Validator{"name": {Type: "string"}}}
+	result := Evaluate(schema, map[string]interface{}{"name": "a", "requestId": "0b5e6c2a-1111"}, Options{Usage: INIT})
+	if !result.Valid() || result.IdempotencyKey != "0b5e6c2a-1111" || result.Dest["requestId"] != "0b5e6c2a-1111" {
+		t.Errorf("expected the key of the request, got %+v", result)
+	}
+
+	// on SET, the key is the stored one
+	existing := map[string]interface{}{"name": "a", "requestId": "0b5e6c2a-1111"}
+	result = Evaluate(schema, map[string]interface{}{"name": "b"}, Options{Usage: SET, Existing: existing})
+	if !result.Valid() || result.IdempotencyKey != "0b5e6c2a-1111" {
+		t.Errorf("expected the stored key, got %+v", result)
+	}
+	// an invalid request has no key
+	result = Evaluate(schema, map[string]interface{}{"name": 1, "requestId": "0b5e6c2a-1111"}, Options{Usage: INIT})
+	if result.Valid() || result.IdempotencyKey != "" {
+		t.Errorf("expected no key, got %+v", result)
+	}
+}
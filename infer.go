@@ -0,0 +1,290 @@
+package validation
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct gathers the values observed at a path of the sample documents
+type inference struct {
+	present int           // the number of samples holding a non-nil value at the path
+	values  []interface{} // the non-nil values
+}
+
+// the patterns InferSchema proposes for the strings all matching them
+var (
+	inferEmailPattern = `^[^@\s]+@[^@\s]+\.[^@\s]+$`
+	inferDatePattern  = `^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([Zz]|[+-]\d{2}:?\d{2})?)?$`
+	inferEmail        = regexp.MustCompile(inferEmailPattern)
+	inferDate         = regexp.MustCompile(inferDatePattern)
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function guesses the validators of the documents from samples, e.g. documents read from an existing collection, as a starting point to write a schema
+// the validators describe the documents as submitted in JSON:
+//   - the sub-documents are "map[string]interface {}" validators whose keys get their own dotted validators
+//   - the ObjectIds (bson.ObjectId or hex strings) are "bson.ObjectId", the times (time.Time or RFC 3339 strings) and emails are strings with a Regexp
+//   - the integers are "int64" with the observed IntBoundaries, the other numbers "json.Number" with the observed Boundaries, or "float64" if they all are float64
+//   - the arrays are typed after their items, "[]interface {}" if they differ
+//
+// a field is required if every sample holds a non-nil value for it; the values of different types give an "interface {}" validator
+// the keys containing a dot cannot be written in a path and are skipped
+func InferSchema(samples []map[string]interface{}) map[string]*Validator {
+	observed := make(map[string]*inference)
+	for _, sample := range samples {
+		inferDocument("", reflect.ValueOf(sample), observed)
+	}
+
+	validators := make(map[string]*Validator, len(observed))
+	for path, inference := range observed {
+		validator := inferValidator(inference.values)
+		validator.Field = path[strings.LastIndex(path, ".")+1:]
+		validator.IsRequired = inference.present == len(samples)
+		validators[path] = validator
+	}
+	return validators
+}
+
+// this private function records the values of the document keys, and of the sub-documents ones
+func inferDocument(prefix string, document reflect.Value, observed map[string]*inference) {
+	keys := document.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		if strings.Contains(key.String(), ".") {
+			continue
+		}
+		path := joinPath(prefix, key.String())
+		if observed[path] == nil {
+			observed[path] = &inference{}
+		}
+		value := document.MapIndex(key).Interface()
+		if value == nil {
+			continue
+		}
+		observed[path].present++
+		observed[path].values = append(observed[path].values, value)
+		if isDocument(value) {
+			inferDocument(path, reflect.ValueOf(value), observed)
+		}
+	}
+}
+
+// this private function returns the validator of the values observed at a path
+func inferValidator(values []interface{}) *Validator {
+	kinds := make(map[string]bool)
+	for i, value := range values {
+		// the times are submitted in JSON as RFC 3339 strings, so they mix with the date strings
+		if moment, ok := value.(time.Time); ok {
+			values[i] = moment.Format(time.RFC3339Nano)
+		}
+		kinds[inferKind(values[i])] = true
+	}
+	if len(kinds) != 1 {
+		// the integers mixed with decimals are numbers, the hex strings mixed with other strings are strings, the other mixes are not typed
+		if len(kinds) == 2 && kinds["integer"] && kinds["number"] {
+			kinds = map[string]bool{"number": true}
+		} else if len(kinds) == 2 && kinds["objectId"] && kinds["string"] {
+			kinds = map[string]bool{"string": true}
+		} else {
+			return untypedValidator()
+		}
+	}
+
+	var kind string
+	for kind = range kinds {
+	}
+	switch kind {
+	case "objectId":
+		return &Validator{Type: "bson.ObjectId"}
+	case "string":
+		validator := &Validator{Type: "string"}
+		if allMatch(values, inferDate) {
+			validator.Regexp = inferDatePattern
+		} else if allMatch(values, inferEmail) {
+			validator.Regexp = inferEmailPattern
+		}
+		return validator
+	case "integer":
+		bounds := &IntBoundaries{Min: math.MaxInt64, Max: math.MinInt64}
+		for _, value := range values {
+			integer, _ := inferInteger(value)
+			if integer < bounds.Min {
+				bounds.Min = integer
+			}
+			if integer > bounds.Max {
+				bounds.Max = integer
+			}
+		}
+		return &Validator{Type: "int64", IntBoundaries: bounds}
+	case "number":
+		bounds := Boundaries{Min: math.Inf(1), Max: math.Inf(-1)}
+		floats := true
+		for _, value := range values {
+			_, isFloat := value.(float64)
+			floats = floats && isFloat
+			number, _ := inferFloat(value)
+			bounds.Min = math.Min(bounds.Min, number)
+			bounds.Max = math.Max(bounds.Max, number)
+		}
+		if floats {
+			return &Validator{Type: "float64"}
+		}
+		return &Validator{Type: "json.Number", Boundaries: bounds}
+	case "bool":
+		return &Validator{Type: "bool"}
+	case "document":
+		return &Validator{Type: "map[string]interface {}"}
+	case "array":
+		items := make(map[string]bool)
+		for _, value := range values {
+			array := reflect.ValueOf(value)
+			for i := 0; i < array.Len(); i++ {
+				if item := array.Index(i).Interface(); item != nil {
+					items[inferItemType(item)] = true
+				}
+			}
+		}
+		if len(items) == 2 && items["json.Number"] && items["float64"] {
+			items = map[string]bool{"json.Number": true}
+		}
+		if len(items) != 1 {
+			return &Validator{Type: "[]interface {}"}
+		}
+		var item string
+		for item = range items {
+		}
+		return &Validator{Type: "[]" + item}
+	}
+	return untypedValidator()
+}
+
+// this private function returns the validator of the values of different types: any number is accepted, the zero Boundaries refusing them otherwise
+func untypedValidator() *Validator {
+	return &Validator{Type: "interface {}", Boundaries: Boundaries{Min: -math.MaxFloat64, Max: math.MaxFloat64}}
+}
+
+// this private function returns the kind of a value: objectId, string, time, integer, number, bool, document, array, or its type name
+func inferKind(value interface{}) string {
+	if _, ok := inferInteger(value); ok {
+		return "integer"
+	}
+	if _, ok := inferFloat(value); ok {
+		return "number"
+	}
+	switch value := value.(type) {
+	case bool:
+		return "bool"
+	case time.Time:
+		return "time"
+	case []byte:
+		return "string"
+	case string:
+		if isObjectIdHex(value) {
+			return "objectId"
+		}
+		return "string"
+	}
+	if _, ok := objectIdString(value).(string); ok {
+		return "objectId"
+	}
+	if isDocument(value) {
+		return "document"
+	}
+	if kind := reflect.ValueOf(value).Kind(); kind == reflect.Slice || kind == reflect.Array {
+		return "array"
+	}
+	return typeName(value)
+}
+
+// this private function returns the type of an array item as submitted in JSON: numbers are json.Number, unless they are float64
+func inferItemType(item interface{}) string {
+	switch inferKind(item) {
+	case "objectId":
+		return "bson.ObjectId"
+	case "string", "time":
+		return "string"
+	case "integer", "number":
+		if _, ok := item.(float64); ok {
+			return "float64"
+		}
+		return "json.Number"
+	case "bool":
+		return "bool"
+	case "document":
+		return "map[string]interface {}"
+	case "array":
+		return "[]interface {}"
+	}
+	return typeName(item)
+}
+
+// this private function returns the value as an int64 if it is an integral number that fits
+func inferInteger(value interface{}) (int64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		integer, err := strconv.ParseInt(string(value), 10, 64)
+		return integer, err == nil
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < math.Ldexp(1, 63) {
+			return int64(value), true
+		}
+		return 0, false
+	}
+	number := reflect.ValueOf(value)
+	switch number.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number.Uint() <= math.MaxInt64 {
+			return int64(number.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// this private function returns the value as a float64 if it is a number
+func inferFloat(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		number, err := value.Float64()
+		return number, err == nil
+	case float64:
+		return value, !math.IsNaN(value) && !math.IsInf(value, 0)
+	case float32:
+		return float64(value), true
+	}
+	if integer, ok := inferInteger(value); ok {
+		return float64(integer), true
+	}
+	return 0, false
+}
+
+// this private function tells whether the value is a map with string keys, e.g. a map[string]interface{} or a bson.M
+func isDocument(value interface{}) bool {
+	_type := reflect.TypeOf(value)
+	return _type != nil && _type.Kind() == reflect.Map && _type.Key().Kind() == reflect.String
+}
+
+// this private function tells whether all the values are strings matching the regexp
+func allMatch(values []interface{}, regexp *regexp.Regexp) bool {
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok || !regexp.MatchString(str) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestInferSchema(t *testing.T) {
+	var samples []map[string]interface{}
+	for _, sample := range []string{
+		`{"_id": "507f1f77bcf86cd799439011", "email": "a@b.co", "age": 31, "score": 1.5, "tags": ["a", "b"], "ratios": [1, 2.5], "address": {"city": "Paris", "zip": 75001}, "at": "2024-01-02T03:04:05Z", "any": 1, "a.b": 1}`,
+		`{"_id": "507f1f77bcf86cd799439012", "email": "c@d.co", "age": 45, "score": 3, "tags": [], "address": {"city": "Lyon"}, "at": "2024-02-02", "any": "x", "active": true}`,
+	} {
+		decoder := json.NewDecoder(strings.NewReader(sample))
+		decoder.UseNumber()
+		var document map[string]interface{}
+		if err := decoder.Decode(&document); err != nil {
+			t.Fatal(err)
+		}
+		samples = append(samples, document)
+	}
+	samples = append(samples, map[string]interface{}{"_id": bson.ObjectIdHex("507f1f77bcf86cd799439013"), "email": "e@f.co", "age": 7, "score": json.Number("2"), "address": bson.M{"city": "Nice"}, "at": time.Now(), "any": nil})
+
+	validators := InferSchema(samples)
+	expected := map[string]string{
+		"_id":          "bson.ObjectId required",
+		"email":        "string required " + inferEmailPattern,
+		"age":          "int64 required [7 45]",
+		"score":        "json.Number required {1.5 3}",
+		"tags":         "[]string",
+		"ratios":       "[]json.Number",
+		"address":      "map[string]interface {} required",
+		"address.city": "string required",
+		"address.zip":  "int64 [75001 75001]",
+		"at":           "string required " + inferDatePattern,
+		"any":          fmt.Sprint("interface {} ", Boundaries{Min: -math.MaxFloat64, Max: math.MaxFloat64}),
+		"active":       "bool",
+	}
+	if len(validators) != len(expected) {
+		t.Errorf("expected %d validators, got %d", len(expected), len(validators))
+	}
+	for path, description := range expected {
+		validator, ok := validators[path]
+		if !ok {
+			t.Errorf("%s: expected a validator", path)
+			continue
+		}
+		got := validator.Type
+		if validator.IsRequired {
+			got += " required"
+		}
+		if validator.Regexp != "" {
+			got += " " + validator.Regexp
+		}
+		if validator.IntBoundaries != nil {
+			got += fmt.Sprintf(" [%d %d]", validator.IntBoundaries.Min, validator.IntBoundaries.Max)
+		} else if validator.Boundaries != (Boundaries{}) {
+			got += fmt.Sprint(" ", validator.Boundaries)
+		}
+		if got != description {
+			t.Errorf("%s: expected %q, got %q", path, description, got)
+		}
+	}
+	if validators["address.city"].Field != "city" {
+		t.Errorf("expected the field city, got %q", validators["address.city"].Field)
+	}
+
+	// the inferred schema accepts its JSON samples
+	for _, sample := range samples[:2] {
+		if _, errors := Validate(validators, sample, Options{Usage: INIT}); len(errors) > 0 {
+			t.Errorf("%v: expected no errors, got %v", sample, errors)
+		}
+	}
+}
+
+func TestInferValidatorMixes(t *testing.T) {
+	tests := []struct {
+		values []interface{}
+		_type  string
+	}{
+		{[]interface{}{1.5, 2.5}, "float64"},
+		{[]interface{}{"507f1f77bcf86cd799439011", "name"}, "string"},
+		{[]interface{}{true, "yes"}, "interface {}"},
+		{[]interface{}{[]interface{}{1.5, json.Number("2")}}, "[]json.Number"},
+		{[]interface{}{[]interface{}{"a", true}}, "[]interface {}"},
+		{[]interface{}{[]interface{}{map[string]interface{}{}}, []interface{}{[]interface{}{}}}, "[]interface {}"},
+		{[]interface{}{[]byte("a")}, "string"},
+	}
+	for _, test := range tests {
+		if validator := inferValidator(test.values); validator.Type != test._type {
+			t.Errorf("%v: expected %s, got %s", test.values, test._type, validator.Type)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct is a SET key targeting the elements of an array, e.g. "items.2.qty" or "items.$[elem].qty"
+type positionalKey struct {
+	array    *Validator // the validator of the array
+	in       string     // the submitted path of the array
+	out      string     // the stored path of the array
+	selector string     // the index, "$", "$[]" or "$[identifier]"
+	rest     string     // the key inside the element, empty if the whole element is set
+}
+
+// the selectors of the array elements in the MongoDB update paths: an index, the positional operators $ and $[], or a filtered $[identifier]
+var positionalSelector = regexp.MustCompile(`^(\d+|\$|\$\[\]|\$\[([a-z][a-zA-Z0-9]*)\])$`)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function validates the sub-documents of an array against the validators of its items
+// the elements are validated as a whole: on SET, with the INIT rules, the required fields and the defaults included
+func checkItems(validator *Validator, in string, value interface{}, rights int, opt Options, errors *[]*DataError) (interface{}, bool) {
+	return checkElements(&Schema{Validators: validator.Items}, in, value, rights, opt, errors)
+}
+
+// this private function validates the sub-documents of an array against the schema, reporting the elements which are not documents
+func checkElements(schema *Schema, in string, value interface{}, rights int, opt Options, errors *[]*DataError) (interface{}, bool) {
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return value, true
+	}
+	items := make([]interface{}, slice.Len())
+	valid := true
+	for i := range items {
+		item := slice.Index(i).Interface()
+		field := in + "." + strconv.Itoa(i)
+		located := append(ParsePath(in), PathSegment{Index: i, IsIndex: true})
+		document, ok := item.(map[string]interface{})
+		if !ok {
+			err := NewError("type_mismatch", field, typeName(item), map[string]interface{}{"expected": "map[string]interface {}"})
+			err.Path = located
+			*errors = append(*errors, err)
+			valid = false
+			continue
+		}
+		validated, itemErrors := validate(schema, document, itemOptions(opt, rights, true))
+		if len(itemErrors) > 0 {
+			*errors = append(*errors, relocateErrors(itemErrors, field, located)...)
+			valid = false
+		}
+		items[i] = validated
+	}
+	return items, valid
+}
+
+// this private function returns the options the array elements and the referenced sub-documents are validated with: the rights are the ones resolved for the document
+// the error factory is applied once, by the validation of the document
+func itemOptions(opt Options, rights int, whole bool) Options {
+	usage := opt.Usage
+	if whole && usage == SET {
+		usage = INIT
+	}
+	return Options{
+		Usage: usage, UserRights: rights, Args: opt.Args, Strict: opt.Strict, MaxDepth: opt.MaxDepth, MaxFields: opt.MaxFields,
+		Partial: opt.Partial, Locale: opt.Locale, DocBaseURL: opt.DocBaseURL, Context: opt.Context, ObjectIdFactory: opt.ObjectIdFactory, TimeFactory: opt.TimeFactory,
+		Now: opt.Now, MaxRefDepth: opt.MaxRefDepth, root: opt.root, refDepth: opt.refDepth, collaborators: opt.collaborators,
+	}
+}
+
+// this private function returns copies of the errors of a sub-validation, located under the field
+func relocateErrors(errors []*DataError, field string, path []PathSegment) []*DataError {
+	relocated := make([]*DataError, len(errors))
+	for i, err := range errors {
+		copied := *err
+		copied.Field = joinPath(field, err.Field)
+		copied.Path = append(append(make([]PathSegment, 0, len(path)+len(err.Path)), path...), err.Path...)
+		relocated[i] = &copied
+	}
+	return relocated
+}
+
+// this private function parses a submitted SET key targeting the elements of an array whose validator has Items
+func parsePositional(schema *Schema, key string, usage int) (*positionalKey, bool) {
+	segments := strings.Split(key, ".")
+	for i := 1; i < len(segments); i++ {
+		if !positionalSelector.MatchString(segments[i]) {
+			continue
+		}
+		in := strings.Join(segments[:i], ".")
+		for path, validator := range schema.Validators {
+			if validator.Items == nil {
+				continue
+			}
+			if validatorIn, out := validator.Paths(path, usage); validatorIn == in {
+				return &positionalKey{array: validator, in: in, out: out, selector: segments[i], rest: strings.Join(segments[i+1:], ".")}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// this private function splits the positional keys off a SET document: it returns the document without them, and the positional keys sorted
+func splitPositional(schema *Schema, _map map[string]interface{}) (map[string]interface{}, []string) {
+	var keys []string
+	for key := range _map {
+		if strings.Contains(key, ".") {
+			if _, ok := parsePositional(schema, key, SET); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return _map, nil
+	}
+	sort.Strings(keys)
+	remaining := make(map[string]interface{}, len(_map)-len(keys))
+	for key, value := range _map {
+		remaining[key] = value
+	}
+	for _, key := range keys {
+		delete(remaining, key)
+	}
+	return remaining, keys
+}
+
+// this private function validates the positional keys of a SET document and writes their values in dest, as MongoDB update paths
+func validatePositional(schema *Schema, _map map[string]interface{}, keys []string, dest map[string]interface{}, rights int, opt Options, errors *[]*DataError) {
+	for _, key := range keys {
+		positional, _ := parsePositional(schema, key, SET)
+		located := ParsePath(key)
+		prefix := positional.in + "." + positional.selector
+		out := positional.out + "." + positional.selector
+
+		// modifying the elements requires the rights on the array
+		if ok := collaboratorsOf(opt).Rights.CheckRights(rights, positional.array.Rights[SET]); !ok {
+			err := NewError("insufficient_rights", key, nil, map[string]interface{}{"rights": positional.array.Rights[SET]})
+			err.Path = located
+			*errors = append(*errors, err)
+			continue
+		}
+
+		value := _map[key]
+		element := &Schema{Validators: positional.array.Items}
+		switch _, nested := parsePositional(element, positional.rest, SET); {
+		case positional.rest == "":
+			// the whole element is replaced
+			document, ok := value.(map[string]interface{})
+			if !ok {
+				err := NewError("type_mismatch", key, typeName(value), map[string]interface{}{"expected": "map[string]interface {}"})
+				err.Path = located
+				*errors = append(*errors, err)
+				continue
+			}
+			validated, itemErrors := validate(element, document, itemOptions(opt, rights, true))
+			if len(itemErrors) > 0 {
+				*errors = append(*errors, relocateErrors(itemErrors, prefix, ParsePath(prefix))...)
+				continue
+			}
+			dest[out] = validated
+
+		case nested:
+			// the key targets the elements of an array of the element
+			var nestedErrors []*DataError
+			nestedDest := make(map[string]interface{})
+			validatePositional(element, map[string]interface{}{positional.rest: value}, []string{positional.rest}, nestedDest, rights, opt, &nestedErrors)
+			*errors = append(*errors, relocateErrors(nestedErrors, prefix, ParsePath(prefix))...)
+			for path, item := range nestedDest {
+				dest[out+"."+path] = item
+			}
+
+		default:
+			// a key of the element is set: it is validated strictly, so an unknown one is reported
+			document := make(map[string]interface{})
+			if err := tools.WriteDeep(document, positional.rest, value); err != nil {
+				panic(err)
+			}
+			elementOpt := itemOptions(opt, rights, false)
+			elementOpt.Strict = true
+			validated, itemErrors := validate(element, document, elementOpt)
+			if len(itemErrors) > 0 {
+				*errors = append(*errors, relocateErrors(itemErrors, prefix, ParsePath(prefix))...)
+				continue
+			}
+			for path, item := range validated {
+				dest[out+"."+path] = item
+			}
+		}
+	}
+}
+
+// this private function checks the identifiers of the filtered selectors of the positional keys are all declared by the array filters, and the filters all used
+func checkArrayFilters(keys []string, filters []map[string]interface{}, errors *[]*DataError) {
+	used := make(map[string]bool)
+	for _, key := range keys {
+		for _, segment := range strings.Split(key, ".") {
+			match := positionalSelector.FindStringSubmatch(segment)
+			if match == nil || match[2] == "" {
+				continue
+			}
+			used[match[2]] = true
+			if !declaresFilter(filters, match[2]) {
+				err := NewError("invalid_array_filter", key, segment, map[string]interface{}{"identifier": match[2]})
+				err.Path = ParsePath(key)
+				*errors = append(*errors, err)
+			}
+		}
+	}
+
+	for _, filter := range filters {
+		keys := make([]string, 0, len(filter))
+		for key := range filter {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if identifier := strings.SplitN(key, ".", 2)[0]; !used[identifier] {
+				*errors = append(*errors, NewError("invalid_array_filter", key, nil, map[string]interface{}{"identifier": identifier}))
+			}
+		}
+	}
+}
+
+// this private function tells whether one of the array filters is about the identifier
+func declaresFilter(filters []map[string]interface{}, identifier string) bool {
+	for _, filter := range filters {
+		for key := range filter {
+			if key == identifier || strings.HasPrefix(key, identifier+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// the schema of an order with an array of lines
+func itemsSchema() *Schema {
+	return &Schema{Validators: map[string]*Validator{
+		"name": {Type: "string"},
+		"lines": {Type: "[]map[string]interface {}", Items: map[string]*Validator{
+			"qty": {Type: "int64", IsRequired: true, IntBoundaries: &IntBoundaries{Min: 0, Max: 10}},
+			"sku": {Type: "string"},
+		}},
+	}}
+}
+
+func TestItems(t *testing.T) {
+	schema := itemsSchema()
+	_, errors := schema.Validate(map[string]interface{}{"lines": []interface{}{
+		map[string]interface{}{"qty": json.Number("2"), "sku": "a"},
+		map[string]interface{}{"qty": json.Number("20")},
+		map[string]interface{}{"sku": "c"},
+	}}, Options{Usage: INIT})
+	expected := [][2]string{{"out_of_boundaries", "lines.1.qty"}, {"required", "lines.2.qty"}}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for i, err := range errors {
+		if err.Code != expected[i][0] || err.Field != expected[i][1] {
+			t.Errorf("error %d: expected %v, got %s on %s", i, expected[i], err.Code, err.Field)
+		}
+	}
+	if path := errors[0].Path; len(path) != 3 || !path[1].IsIndex || path[1].Index != 1 || path[2].Key != "qty" {
+		t.Errorf("expected the lines[1].qty path, got %v", path)
+	}
+
+	dest, errors := schema.Validate(map[string]interface{}{"lines": []interface{}{map[string]interface{}{"qty": json.Number("2")}}}, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if line := dest["lines"].([]interface{})[0].(map[string]interface{}); line["qty"] != int64(2) {
+		t.Errorf("expected the validated element, got %v", line)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{"lines": []interface{}{"a"}}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "type_mismatch" {
+		t.Errorf("expected the element type to be checked, got %v", errors)
+	}
+
+	// the items validators are checked by CheckSchema
+	broken := &Schema{Validators: map[string]*Validator{"tag": {Type: "string", Items: map[string]*Validator{"a": {Type: "x y"}}}}}
+	if errors := CheckSchema(broken); len(errors) != 2 || !strings.Contains(errors[0].Error(), "non-array type") || !strings.HasPrefix(errors[1].(*DataError).Field, "tag.$.a") {
+		t.Errorf("expected the non-array type and the invalid element type, got %v", errors)
+	}
+}
+
+func TestPositional(t *testing.T) {
+	schema := itemsSchema()
+	dest, errors := schema.Validate(map[string]interface{}{
+		"name":           "n",
+		"lines.2.qty":    json.Number("3"),
+		"lines.$[e].qty": json.Number("4"),
+		"lines.$":        map[string]interface{}{"qty": json.Number("1")},
+	}, Options{Usage: SET, ArrayFilters: []map[string]interface{}{{"e.qty": map[string]interface{}{"$gt": 1}}}})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if len(dest) != 4 || dest["lines.2.qty"] != int64(3) || dest["lines.$[e].qty"] != int64(4) || dest["lines.$"].(map[string]interface{})["qty"] != int64(1) {
+		t.Errorf("expected the positional update paths, got %v", dest)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{
+		"lines.2.qty":    json.Number("30"),
+		"lines.1.foo":    "x",
+		"lines.$[f].qty": json.Number("4"),
+		"lines.$":        "x",
+	}, Options{Usage: SET, ArrayFilters: []map[string]interface{}{{"e.qty": 1}}})
+	codes := make(map[string]string)
+	for _, err := range errors {
+		codes[err.Field] = err.Code
+	}
+	expected := map[string]string{
+		"lines.$[f].qty": "invalid_array_filter", "e.qty": "invalid_array_filter", "lines.1.foo": "unknown_field",
+		"lines.2.qty": "out_of_boundaries", "lines.$": "type_mismatch",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for field, code := range expected {
+		if codes[field] != code {
+			t.Errorf("expected %s on %s, got %q", code, field, codes[field])
+		}
+	}
+}
+
+func TestPositionalRights(t *testing.T) {
+	schema := itemsSchema()
+	schema.Validators["lines"].Rights = [3]int{0, 0, ADMIN}
+	_, errors := schema.Validate(map[string]interface{}{"lines.0.qty": json.Number("1")}, Options{Usage: SET, UserRights: USER})
+	if len(errors) != 1 || errors[0].Code != "insufficient_rights" || errors[0].Field != "lines.0.qty" {
+		t.Errorf("expected the rights on the array to be required, got %v", errors)
+	}
+}
@@ -0,0 +1,48 @@
+package validation
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a JSON:API error document – see https://jsonapi.org/format/#errors
+type JSONAPIDocument struct {
+	Errors []*JSONAPIError `json:"errors"`
+}
+
+// This struct is a JSON:API error object
+type JSONAPIError struct {
+	Status string         `json:"status,omitempty"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *JSONAPISource `json:"source,omitempty"`
+}
+
+// This inner struct locates the origin of a JSON:API error in the request document
+type JSONAPISource struct {
+	Pointer string `json:"pointer,omitempty"`
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This public function renders the errors as a JSON:API error document
+// root is the JSON Pointer to the validated document in the request, usually "/data/attributes"
+func JSONAPIErrors(errors []*DataError, root string) *JSONAPIDocument {
+	document := &JSONAPIDocument{Errors: make([]*JSONAPIError, 0, len(errors))}
+	for _, err := range errors {
+		status := "422"
+		if err.Type == "Internal error" {
+			status = "500"
+		}
+		document.Errors = append(document.Errors, &JSONAPIError{
+			Status: status,
+			Code:   errorCode(err),
+			Title:  err.Reason,
+			Detail: err.Error(),
+			Source: &JSONAPISource{Pointer: root + err.Pointer()},
+		})
+	}
+	return document
+}
@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONAPIErrors(t *testing.T) {
+	errors := []*DataError{
+		{Type: "Validation error", Reason: "Type mismatch", Field: "tags", Value: "[] contains float64", Path: append(ParsePath("tags"), PathSegment{Index: 1, IsIndex: true})},
+		{Type: "Internal error", Reason: "Unexpected failure", Field: "name", Path: ParsePath("name")},
+	}
+	data, err := json.Marshal(JSONAPIErrors(errors, "/data/attributes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"errors":[` +
+		`{"status":"422","code":"type_mismatch","title":"Type mismatch","detail":"Validation error for tags = [] contains float64: Type mismatch","source":{"pointer":"/data/attributes/tags/1"}},` +
+		`{"status":"500","code":"unexpected_failure","title":"Unexpected failure","detail":"Internal error for name = \u003cnil\u003e: Unexpected failure","source":{"pointer":"/data/attributes/name"}}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	if data, _ := json.Marshal(JSONAPIErrors(nil, "")); string(data) != `{"errors":[]}` {
+		t.Errorf("expected an empty errors array, got %s", data)
+	}
+}
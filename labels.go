@@ -0,0 +1,49 @@
+package validation
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the human-friendly name of the field in the locale, e.g. "Adresse e-mail" for "fr-CA":
+// the Labels of the locale, then of its language, then the Label, and "" if the validator has none
+func (v *Validator) LabelFor(locale string) string {
+	if label, ok := v.Labels[locale]; ok {
+		return label
+	}
+	if label, ok := v.Labels[language(locale)]; ok {
+		return label
+	}
+	return v.Label
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function names the field in the errors about it which are not named yet
+// the errors are copied before being named, since custom tests may return shared ones
+func labelErrors(errors []*DataError, field string, label string) {
+	if label == "" {
+		return
+	}
+	for i, err := range errors {
+		if err == nil || err.Field != field || err.Label != "" {
+			continue
+		}
+		labeled := *err
+		labeled.Label = label
+		errors[i] = &labeled
+	}
+}
+
+// this private function names the fields in the errors about the document as a whole, e.g. the missing fields of a group
+func labelDocumentErrors(schema *Schema, errors []*DataError, opt Options) {
+	for _, path := range schema.sortedPaths() {
+		validator := schema.Validators[path]
+		if validator.Label == "" && len(validator.Labels) == 0 {
+			continue
+		}
+		in, _ := validator.Paths(path, opt.Usage)
+		labelErrors(errors, in, validator.LabelFor(opt.Locale))
+	}
+}
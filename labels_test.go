@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelFor(t *testing.T) {
+	validator := &Validator{Label: "Email address", Labels: map[string]string{"fr": "Adresse e-mail", "fr-CA": "Courriel"}}
+	for locale, label := range map[string]string{"fr-CA": "Courriel", "fr_FR": "Adresse e-mail", "fr": "Adresse e-mail", "de": "Email address", "": "Email address"} {
+		if got := validator.LabelFor(locale); got != label {
+			t.Errorf("%q: expected %q, got %q", locale, label, got)
+		}
+	}
+	if label := (&Validator{}).LabelFor("fr"); label != "" {
+		t.Errorf("expected no label, got %q", label)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	shared := &DataError{Type: "Validation error", Reason: "Custom test failed", Code: "custom_test_failed"}
+	schema := &Schema{
+		Validators: map[string]*Validator{
+			"email": {Type: "string", IsRequired: true, Label: "Email address", Labels: map[string]string{"fr": "Adresse e-mail"}},
+			"phone": {Type: "string", Label: "Phone", CustomTest: func(interface{}) (bool, *DataError) { return false, shared }},
+			"fax":   {Type: "string", Label: "Fax"},
+		},
+		DependentRules: map[string][]string{"phone": {"fax"}},
+	}
+	_, errors := schema.Validate(map[string]interface{}{}, Options{Usage: INIT, Locale: "fr-CA"})
+	if len(errors) != 1 || errors[0].Label != "Adresse e-mail" || !strings.Contains(errors[0].Error(), "for Adresse e-mail =") {
+		t.Errorf("expected the French label, got %v", errors)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{"email": 3, "phone": "1"}, Options{Usage: INIT, Locale: "de"})
+	if len(errors) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errors)
+	}
+	for i, label := range []string{"Email address", "Phone", "Fax"} {
+		if errors[i].Label != label {
+			t.Errorf("error %d: expected the label %q, got %q", i, label, errors[i].Label)
+		}
+	}
+	if shared.Label != "" {
+		t.Errorf("expected the error returned by the custom test to be left untouched, got %q", shared.Label)
+	}
+}
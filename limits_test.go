@@ -0,0 +1,29 @@
+package validation
+
+import "testing"
+
+func TestInputLimits(t *testing.T) {
+	validators := map[string]*Validator{"x": {Type: "string"}}
+	document := map[string]interface{}{
+		"a": map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": "d"}}},
+	}
+	tests := []struct {
+		maxDepth, maxFields int
+		reason              string
+	}{
+		{0, 0, ""},
+		{4, 4, ""},
+		{3, 0, "Max depth exceeded"},
+		{0, 3, "Max fields exceeded"},
+	}
+	for _, test := range tests {
+		_, errors := Validate(validators, document, Options{Usage: INIT, MaxDepth: test.maxDepth, MaxFields: test.maxFields})
+		reason := ""
+		if len(errors) > 0 {
+			reason = errors[0].Reason
+		}
+		if reason != test.reason || len(errors) > 1 {
+			t.Errorf("MaxDepth %d, MaxFields %d: expected %q, got %v", test.maxDepth, test.maxFields, test.reason, errors)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct holds the separators of the numbers of a locale
+type numberSeparators struct {
+	decimal rune   // the decimal separator
+	groups  string // the accepted group separators of the thousands
+}
+
+// the separators of the languages writing the numbers otherwise than English, "1,234.56"
+// the spaces include the no-break ones the spreadsheets write
+var languageSeparators = map[string]numberSeparators{
+	"de": {',', "."}, "es": {',', "."}, "it": {',', "."}, "pt": {',', "."}, "nl": {',', "."}, "da": {',', "."},
+	"tr": {',', "."}, "id": {',', "."}, "ro": {',', "."}, "el": {',', "."},
+	"fr": {',', "   "}, "ru": {',', "   "}, "pl": {',', "   "}, "cs": {',', "   "},
+	"sk": {',', "   "}, "uk": {',', "   "}, "sv": {',', "   "}, "nb": {',', "   "},
+	"fi": {',', "   "}, "hu": {',', "   "},
+}
+
+// the separators of the locales differing from their language, e.g. the Swiss ones
+var localeSeparators = map[string]numberSeparators{
+	"de-CH": {'.', "'’"}, "fr-CH": {'.', "'’   "}, "it-CH": {'.', "'’"},
+	"pt-BR": {',', "."}, "es-MX": {'.', ","}, "en-ZA": {',', "   "},
+}
+
+// the separators of English, the ones of the unknown locales
+var defaultSeparators = numberSeparators{'.', ","}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function parses a number written with the separators of the locale, e.g. "1.234,56" in "de-DE" or "1 234,56" in "fr-FR", into its canonical form "1234.56"
+// the group separators are optional, but must then separate groups of three digits, so that "1.234" is 1234 in German and 1.234 in English, and "1.23" is refused in German
+// the locales are the ones of Options.Locale, English being the default
+func ParseLocaleNumber(str string, locale string) (json.Number, bool) {
+	separators, ok := localeSeparators[strings.Replace(locale, "_", "-", 1)]
+	if !ok {
+		if separators, ok = languageSeparators[language(locale)]; !ok {
+			separators = defaultSeparators
+		}
+	}
+
+	str = strings.TrimSpace(str)
+	var canonical strings.Builder
+	if sign, size := utf8.DecodeRuneInString(str); sign == '-' || sign == '−' || sign == '+' {
+		if sign != '+' {
+			canonical.WriteByte('-')
+		}
+		str = str[size:]
+	}
+
+	integer, fraction := str, ""
+	if i := strings.IndexRune(str, separators.decimal); i >= 0 {
+		integer, fraction = str[:i], str[i+utf8.RuneLen(separators.decimal):]
+		if fraction == "" || strings.Trim(fraction, "0123456789") != "" {
+			return "", false
+		}
+	}
+
+	// the digits are grouped by three, but the first group
+	var groups []string
+	var group strings.Builder
+	for _, r := range integer {
+		switch {
+		case strings.ContainsRune(separators.groups, r):
+			groups = append(groups, group.String())
+			group.Reset()
+		case r >= '0' && r <= '9':
+			group.WriteRune(r)
+		default:
+			return "", false
+		}
+	}
+	groups = append(groups, group.String())
+	for i, group := range groups {
+		if group == "" || len(groups) > 1 && (i == 0 && len(group) > 3 || i > 0 && len(group) != 3) {
+			return "", false
+		}
+		canonical.WriteString(group)
+	}
+	if fraction != "" {
+		canonical.WriteByte('.')
+		canonical.WriteString(fraction)
+	}
+	return json.Number(canonical.String()), true
+}
+
+// this private function parses the string value of a LocaleNumbers validator, the other values being returned as they are
+func parseLocaleNumber(value interface{}, locale string) (interface{}, *DataError) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	number, ok := ParseLocaleNumber(str, locale)
+	if !ok {
+		return value, NewError("invalid_format", "", value, map[string]interface{}{"expected": "number", "locale": locale})
+	}
+	return number, nil
+}
@@ -0,0 +1,54 @@
+package validation
+
+import "testing"
+
+func TestParseLocaleNumber(t *testing.T) {
+	tests := []struct {
+		str      string
+		locale   string
+		expected string
+	}{
+		{"1.234,56", "de-DE", "1234.56"},
+		{"1 234,5", "fr", "1234.5"},
+		{"1 234,5", "fr_FR", "1234.5"},
+		{"-1,234.5", "en", "-1234.5"},
+		{"1'234.5", "de-CH", "1234.5"},
+		{"1234", "de", "1234"},
+		{"1.234", "de", "1234"},
+		{"1.234", "", "1.234"},
+		{"−3,5", "ru", "-3.5"},
+		{"+7", "en", "7"},
+	}
+	for _, test := range tests {
+		if number, ok := ParseLocaleNumber(test.str, test.locale); !ok || string(number) != test.expected {
+			t.Errorf("%q in %q: expected %q, got %q (%v)", test.str, test.locale, test.expected, number, ok)
+		}
+	}
+	// the groups must be of three digits
+	for _, test := range [][2]string{{"1.23", "de"}, {"1..234", "de"}, {"1,", "de"}, {"1234.234,5", "de"}, {"12a", "en"}, {"", "en"}, {",5", "de"}} {
+		if number, ok := ParseLocaleNumber(test[0], test[1]); ok {
+			t.Errorf("%q in %q: expected the number to be refused, got %q", test[0], test[1], number)
+		}
+	}
+}
+
+func TestLocaleNumbers(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"ratio": {Type: "float64", LocaleNumbers: true},
+		"price": {Type: "string", LocaleNumbers: true, Decimal: &Decimal{Scale: 2}},
+		"count": {Type: "int64", LocaleNumbers: true, Boundaries: Boundaries{Min: 0, Max: 1e6}},
+	}}
+	document := map[string]interface{}{"ratio": "1.234,5", "price": "12,30", "count": "12.000"}
+	dest, errors := schema.Validate(document, Options{Usage: INIT, Locale: "de-DE"})
+	if len(errors) > 0 || dest["ratio"] != 1234.5 || dest["price"] != "12.30" || dest["count"] != int64(12000) {
+		t.Errorf("expected the numbers read in German, got %#v and %v", dest, errors)
+	}
+	_, errors = schema.Validate(map[string]interface{}{"ratio": "1,234.5"}, Options{Usage: INIT, Locale: "de-DE"})
+	if len(errors) != 1 || errors[0].Field != "ratio" || errors[0].Code != "invalid_format" {
+		t.Errorf("expected the English number to be refused in German, got %v", errors)
+	}
+
+	if errs := CheckSchema(&Schema{Validators: map[string]*Validator{"name": {Type: "string", LocaleNumbers: true}}}); len(errs) != 1 {
+		t.Errorf("expected the non-numeric type to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,159 @@
+package validation
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct renders the human-readable messages of the errors, by locale and error code – see Options.Messages
+// the templates are text/template ones, run against a MessageData, with a plural function choosing the form of a count by the plural rules of the locale:
+// e.g. {"en": {"too_short": `{{.Label}} must be at least {{.Params.min}} {{plural .Params.min "character" "characters"}}`}}
+type Messages struct {
+	fallback  string                                   // the locale of the messages when the requested one has none
+	templates map[string]map[string]*template.Template // the parsed templates, by locale then by code
+}
+
+// This struct is what the message templates are run against
+type MessageData struct {
+	Field  string                 // the path of the field
+	Label  string                 // the human-friendly name of the field, its path if it has none
+	Value  interface{}            // the value at fault
+	Code   string                 // the machine-readable reason, e.g. "out_of_boundaries"
+	Reason string                 // the default reason, e.g. "Out of boundaries"
+	Params map[string]interface{} // the parameters of the rule at fault, e.g. min and max
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the message of the error in the locale, the one of its language or the fallback locale
+// it returns "" if none of them has a template for the error code
+func (m *Messages) Render(err *DataError, locale string) (string, error) {
+	tmpl := m.lookup(err.Code, locale)
+	if tmpl == nil {
+		return "", nil
+	}
+	data := MessageData{Field: err.Field, Label: err.Label, Value: err.Value, Code: err.Code, Reason: err.Reason, Params: err.Params}
+	if data.Label == "" {
+		data.Label = err.Field
+	}
+	var message strings.Builder
+	if e := tmpl.Execute(&message, data); e != nil {
+		return "", fmt.Errorf("validation: cannot render the %q message: %s", err.Code, e)
+	}
+	return message.String(), nil
+}
+
+// This method returns the template of the code the closest to the locale, nil if there is none
+func (m *Messages) lookup(code string, locale string) *template.Template {
+	for _, candidate := range []string{locale, language(locale), m.fallback} {
+		if tmpl, ok := m.templates[candidate][code]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function parses the message templates, by locale (e.g. "fr" or "fr-CA") then by error code
+// the fallback locale is the one used for the locales without a template for the code, e.g. "en"
+func NewMessages(fallback string, templates map[string]map[string]string) (*Messages, error) {
+	m := &Messages{fallback: fallback, templates: make(map[string]map[string]*template.Template, len(templates))}
+	for locale, codes := range templates {
+		m.templates[locale] = make(map[string]*template.Template, len(codes))
+		funcs := template.FuncMap{"plural": pluralFunc(language(locale))}
+		for code, text := range codes {
+			tmpl, err := template.New(locale + "/" + code).Funcs(funcs).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("validation: invalid message template: %s", err)
+			}
+			m.templates[locale][code] = tmpl
+		}
+	}
+	return m, nil
+}
+
+// this private function returns the language of the locale, e.g. "fr" for "fr-CA"
+func language(locale string) string {
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// this private function returns the plural function of the message templates of the language: it picks among the forms the one of the count
+// the forms are the ones of the language, in order: e.g. singular and plural in English, or the one, few and many forms in Russian
+func pluralFunc(lang string) func(count interface{}, forms ...string) (string, error) {
+	return func(count interface{}, forms ...string) (string, error) {
+		if len(forms) == 0 {
+			return "", fmt.Errorf("no plural form")
+		}
+		i := pluralForm(lang, count)
+		if i >= len(forms) {
+			i = len(forms) - 1
+		}
+		return forms[i], nil
+	}
+}
+
+// this private function returns the index of the plural form of the count in the language
+// the counts which are not integers take the last form
+func pluralForm(lang string, count interface{}) int {
+	rat, ok := numberRat(count)
+	if !ok || !rat.IsInt() {
+		return 2
+	}
+	n := new(big.Int).Abs(rat.Num())
+	if !n.IsInt64() {
+		return 2
+	}
+	switch i, mod10, mod100 := n.Int64(), n.Int64()%10, n.Int64()%100; lang {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		// a single form
+		return 0
+	case "fr", "pt":
+		if i < 2 {
+			return 0
+		}
+		return 1
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return 0
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return 1
+		}
+		return 2
+	case "pl":
+		switch {
+		case i == 1:
+			return 0
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return 1
+		}
+		return 2
+	case "cs", "sk":
+		switch {
+		case i == 1:
+			return 0
+		case i >= 2 && i <= 4:
+			return 1
+		}
+		return 2
+	default:
+		if i == 1 {
+			return 0
+		}
+		return 1
+	}
+}
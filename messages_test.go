@@ -0,0 +1,65 @@
+package validation
+
+import "testing"
+
+func TestMessages(t *testing.T) {
+	messages, err := NewMessages("en", map[string]map[string]string{
+		"en": {"too_many_items": `{{.Label}} must have at most {{.Params.max}} {{plural .Params.max "item" "items"}}`, "required": "{{.Label}} is required"},
+		"fr": {"too_many_items": `{{.Label}} doit avoir au plus {{.Params.max}} {{plural .Params.max "élément" "éléments"}}`},
+		"ru": {"too_many_items": `{{plural .Params.max "элемент" "элемента" "элементов"}}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &Schema{Validators: map[string]*Validator{
+		"pos":  {Type: "[]interface {}", Tuple: []*Validator{{Type: "string"}}, Labels: map[string]string{"fr": "Position"}},
+		"name": {Type: "string", IsRequired: true},
+	}}
+	expected := map[string][2]string{
+		"en-US": {"name is required", "pos must have at most 1 item"},
+		"fr":    {"name is required", "Position doit avoir au plus 1 élément"},
+		"ru":    {"name is required", "элемент"},
+		"de":    {"name is required", "pos must have at most 1 item"},
+	}
+	for locale, rendered := range expected {
+		_, errors := schema.Validate(map[string]interface{}{"pos": []interface{}{"a", "b"}}, Options{Usage: INIT, Locale: locale, Messages: messages})
+		if len(errors) != 2 || errors[0].Message != rendered[0] || errors[1].Message != rendered[1] {
+			t.Errorf("%s: expected %v, got %v", locale, rendered, errors)
+		}
+	}
+
+	// the codes without a template keep their reason
+	_, errors := schema.Validate(map[string]interface{}{"name": 1}, Options{Usage: INIT, Messages: messages})
+	if len(errors) != 1 || errors[0].Message != "" || errors[0].Error() != "Validation error for name = int: Type mismatch" {
+		t.Errorf("expected no message, got %v", errors)
+	}
+
+	if _, err := NewMessages("en", map[string]map[string]string{"en": {"required": "{{"}}); err == nil {
+		t.Errorf("expected the invalid template to be refused")
+	}
+	broken, _ := NewMessages("en", map[string]map[string]string{"en": {"required": `{{plural .Params.max}}`}})
+	if _, err := broken.Render(&DataError{Code: "required"}, "en"); err == nil {
+		t.Errorf("expected the plural without forms to fail")
+	}
+}
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		lang  string
+		forms map[interface{}]int
+	}{
+		{"en", map[interface{}]int{1: 0, 0: 1, 2: 1, int64(21): 1, 1.5: 2}},
+		{"fr", map[interface{}]int{0: 0, 1: 0, 2: 1}},
+		{"ru", map[interface{}]int{1: 0, 2: 1, 5: 2, 11: 2, 21: 0, 22: 1, 112: 2}},
+		{"pl", map[interface{}]int{1: 0, 3: 1, 5: 2, 21: 2, 22: 1}},
+		{"cs", map[interface{}]int{1: 0, 4: 1, 5: 2}},
+		{"ja", map[interface{}]int{1: 0, 5: 0}},
+	}
+	for _, test := range tests {
+		for count, form := range test.forms {
+			if got := pluralForm(test.lang, count); got != form {
+				t.Errorf("%s %v: expected the form %d, got %d", test.lang, count, form, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package validation
+
+import "context"
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct describes the field a middleware wraps the validation of – see Schema.Use
+// for the items of an array and the referenced sub-documents, the paths are relative to the element
+type FieldInfo struct {
+	Path       string          // the schema path of the field
+	In         string          // the path the value is read at
+	Validator  *Validator      // the validator run, which a middleware may replace before calling the next one
+	Usage      int             // INIT, GET, SET
+	UserRights int             // the resolved rights of the user
+	Context    context.Context // Options.Context, context.Background() if nil
+}
+
+// This struct is the outcome of the validation of a field
+type FieldOutcome struct {
+	Value  interface{}  // the value written in dest
+	Write  bool         // whether the value is written in dest, false for an absent or invalid field
+	Errors []*DataError // the errors about the field, located at In if they have no field
+}
+
+// This type is the validation of a field, as wrapped by the middlewares
+type FieldValidation func(field FieldInfo) FieldOutcome
+
+// This type wraps the validation of every field, e.g. to time it, to trace it or to skip the fields a feature flag disables
+// it may change the field before calling next, or return its own outcome without calling it
+type ValidatorMiddleware func(next FieldValidation) FieldValidation
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method adds middlewares wrapping the validation of every field of the schema, its items and references included
+// the first middleware added is the outermost one; the schema must not be in use, and a compiled schema keeps the ones added before Compile
+func (s *Schema) Use(mw ...ValidatorMiddleware) {
+	s.middlewares = append(s.middlewares, mw...)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function validates the field through the middlewares of the schema the validation started with
+func runField(path string, validator *Validator, _map map[string]interface{}, merged map[string]interface{}, rights int, opt Options) fieldResult {
+	if opt.root == nil || len(opt.root.middlewares) == 0 {
+		return validateField(path, validator, _map, merged, rights, opt)
+	}
+
+	in, out := validator.Paths(path, opt.Usage)
+	var validation FieldValidation = func(field FieldInfo) FieldOutcome {
+		result := validateField(field.Path, field.Validator, _map, merged, rights, opt)
+		out = result.out
+		return FieldOutcome{Value: result.value, Write: result.write, Errors: result.errors}
+	}
+	middlewares := opt.root.middlewares
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		validation = middlewares[i](validation)
+	}
+
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	outcome := validation(FieldInfo{Path: path, In: in, Validator: validator, Usage: opt.Usage, UserRights: rights, Context: ctx})
+	locateErrors(outcome.Errors, in, ParsePath(in))
+	return fieldResult{errors: outcome.Errors, out: out, value: outcome.Value, write: outcome.Write}
+}
@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestMiddlewares(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":  {Type: "string"},
+		"beta":  {Type: "string", IsRequired: true},
+		"lines": {Type: "[]map[string]interface {}", Items: map[string]*Validator{"label": {Type: "string"}}},
+	}}
+	var mutex sync.Mutex
+	var seen []string
+	schema.Use(func(next FieldValidation) FieldValidation {
+		return func(field FieldInfo) FieldOutcome {
+			mutex.Lock()
+			seen = append(seen, field.Path)
+			mutex.Unlock()
+			return next(field)
+		}
+	}, func(next FieldValidation) FieldValidation {
+		return func(field FieldInfo) FieldOutcome {
+			// the flagged field is skipped with an error of the middleware, located at the field
+			if field.Path == "beta" {
+				return FieldOutcome{Errors: []*DataError{NewError("required", "", nil, nil)}}
+			}
+			return next(field)
+		}
+	})
+
+	document := map[string]interface{}{"name": "a", "lines": []interface{}{map[string]interface{}{"label": "b"}}}
+	dest, errors := MustCompile(schema).Validate(document, Options{Usage: INIT, Concurrency: 2})
+	if len(errors) != 1 || errors[0].Field != "beta" {
+		t.Errorf("expected the error of the middleware on beta, got %v", errors)
+	}
+	if dest["name"] != "a" {
+		t.Errorf("expected the wrapped fields to be validated, got %v", dest)
+	}
+
+	// the outer middleware sees every field, the items included
+	sort.Strings(seen)
+	expected := []string{"beta", "label", "lines", "name"}
+	if len(seen) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, seen)
+	}
+	for i := range expected {
+		if seen[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, seen)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct is the rule of a money sub-document, such as {"amount": "12.50", "currency": "EUR"}
+// the currency must be an ISO 4217 code, and the amount a decimal with no more decimal places than the currency minor units
+// the amount is stored as submitted, so a string amount must be written in the plain decimal syntax, e.g. "12.50" but neither "25/2" nor "1.25e1"
+type Money struct {
+	AmountKey   string            // the key of the amount, "amount" if empty
+	CurrencyKey string            // the key of the currency code, "currency" if empty
+	Currencies  []string          // the accepted currencies, all the ISO 4217 ones if empty
+	Min         map[string]string // the min amount by currency, as a decimal string
+	Max         map[string]string // the max amount by currency, as a decimal string
+}
+
+// the minor units (decimal places) of the ISO 4217 currencies
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2, "BRL": 2, "BSD": 2,
+	"BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2, "CNY": 2,
+	"COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2,
+	"ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2,
+	"GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IRR": 2,
+	"JMD": 2, "KES": 2, "KGS": 2, "KHR": 2, "KPW": 2, "KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2,
+	"LRD": 2, "LSL": 2, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2,
+	"MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2,
+	"NOK": 2, "NPR": 2, "NZD": 2, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2,
+	"RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2,
+	"SLE": 2, "SLL": 2, "SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2,
+	"TJS": 2, "TMT": 2, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "USD": 2, "USN": 2,
+	"UYU": 2, "UZS": 2, "VED": 2, "VES": 2, "WST": 2, "XCD": 2, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWL": 2,
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns a deep copy of the rule
+func (m *Money) Clone() *Money {
+	clone := *m
+	if m.Currencies != nil {
+		clone.Currencies = append([]string(nil), m.Currencies...)
+	}
+	clone.Min, clone.Max = copyStrings(m.Min), copyStrings(m.Max)
+	return &clone
+}
+
+// This method returns the keys of the amount and of the currency in the sub-document
+func (m *Money) Keys() (string, string) {
+	amountKey, currencyKey := m.AmountKey, m.CurrencyKey
+	if amountKey == "" {
+		amountKey = "amount"
+	}
+	if currencyKey == "" {
+		currencyKey = "currency"
+	}
+	return amountKey, currencyKey
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This public function returns the minor units of the ISO 4217 currency, and whether the code is known
+func CurrencyMinorUnits(code string) (int, bool) {
+	units, ok := currencyMinorUnits[code]
+	return units, ok
+}
+
+// this private function copies a map of strings
+func copyStrings(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// this private function runs the Money rule of the validator against the sub-document
+func checkMoney(validator *Validator, path string, document map[string]interface{}, errors *[]*DataError) bool {
+	rule := validator.Money
+	amountKey, currencyKey := rule.Keys()
+	located := func(err *DataError, key string) *DataError {
+		err.Path = append(ParsePath(path), PathSegment{Key: key})
+		return err
+	}
+
+	// the currency, whose code is compared in upper case as ISO 4217 writes it, e.g. "eur" is "EUR"
+	currency, _ := document[currencyKey].(string)
+	currency = strings.ToUpper(currency)
+	units, known := currencyMinorUnits[currency]
+	if known && len(rule.Currencies) > 0 {
+		known = false
+		for _, accepted := range rule.Currencies {
+			known = known || strings.EqualFold(accepted, currency)
+		}
+	}
+	if !known {
+		*errors = append(*errors, located(NewError("invalid_currency", validator.Field, document[currencyKey], map[string]interface{}{"currencies": rule.Currencies}), currencyKey))
+		return false
+	}
+
+	// the amount, refused unless in the plain decimal syntax since it is stored as submitted
+	amount, ok := parseDecimal(document[amountKey])
+	if !ok || amount == nil {
+		*errors = append(*errors, located(NewError("type_mismatch", validator.Field, typeName(document[amountKey]), map[string]interface{}{"expected": "decimal"}), amountKey))
+		return false
+	}
+	if !hasScale(amount, units) {
+		*errors = append(*errors, located(NewError("too_many_decimals", validator.Field, document[amountKey], map[string]interface{}{"scale": units, "currency": currency}), amountKey))
+		return false
+	}
+	min, _ := parseDecimal(rule.Min[currency])
+	max, _ := parseDecimal(rule.Max[currency])
+	if (min != nil && amount.Cmp(min) < 0) || (max != nil && amount.Cmp(max) > 0) {
+		*errors = append(*errors, located(NewError("out_of_boundaries", validator.Field, document[amountKey], map[string]interface{}{"min": rule.Min[currency], "max": rule.Max[currency], "currency": currency}), amountKey))
+		return false
+	}
+	return true
+}
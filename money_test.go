@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	validators := map[string]*Validator{"price": {Type: "map[string]interface {}", Field: "price", Money: &Money{
+		Currencies: []string{"EUR", "JPY", "KWD"},
+		Max:        map[string]string{"EUR": "100"},
+	}}}
+	tests := []struct {
+		price   map[string]interface{}
+		code    string
+		pointer string
+	}{
+		{map[string]interface{}{"amount": json.Number("12.5"), "currency": "EUR"}, "", ""},
+		{map[string]interface{}{"amount": "12.345", "currency": "KWD"}, "", ""},
+		{map[string]interface{}{"amount": "12.5", "currency": "JPY"}, "too_many_decimals", "/price/amount"},
+		{map[string]interface{}{"amount": "1000", "currency": "EUR"}, "out_of_boundaries", "/price/amount"},
+		{map[string]interface{}{"amount": "1", "currency": "USD"}, "invalid_currency", "/price/currency"},
+		{map[string]interface{}{"amount": "1", "currency": "XXX"}, "invalid_currency", "/price/currency"},
+		{map[string]interface{}{"amount": "12.5", "currency": "eur"}, "", ""},
+		{map[string]interface{}{"amount": "12.5", "currency": "jpy"}, "too_many_decimals", "/price/amount"},
+		{map[string]interface{}{"amount": "1000", "currency": "Eur"}, "out_of_boundaries", "/price/amount"},
+		{map[string]interface{}{"amount": "1", "currency": "usd"}, "invalid_currency", "/price/currency"},
+		{map[string]interface{}{"amount": true, "currency": "EUR"}, "type_mismatch", "/price/amount"},
+		{map[string]interface{}{"currency": "EUR"}, "type_mismatch", "/price/amount"},
+		{map[string]interface{}{"amount": "25/2", "currency": "EUR"}, "type_mismatch", "/price/amount"},
+		{map[string]interface{}{"amount": "1.25e1", "currency": "EUR"}, "type_mismatch", "/price/amount"},
+	}
+	for _, test := range tests {
+		_, errors := Validate(validators, map[string]interface{}{"price": test.price}, Options{Usage: INIT})
+		code, pointer := "", ""
+		if len(errors) > 0 {
+			code, pointer = errors[0].Code, errors[0].Pointer()
+		}
+		if code != test.code || pointer != test.pointer || len(errors) > 1 {
+			t.Errorf("%v: expected %q at %q, got %v", test.price, test.code, test.pointer, errors)
+		}
+	}
+}
+
+func TestMoneyKeysAndClone(t *testing.T) {
+	rule := &Money{AmountKey: "value", Currencies: []string{"EUR"}, Min: map[string]string{"EUR": "1"}}
+	if amount, currency := rule.Keys(); amount != "value" || currency != "currency" {
+		t.Errorf("expected value and currency, got %s and %s", amount, currency)
+	}
+	clone := rule.Clone()
+	clone.Currencies[0], clone.Min["EUR"] = "USD", "2"
+	if rule.Currencies[0] != "EUR" || rule.Min["EUR"] != "1" {
+		t.Errorf("the clone shares its lists with the rule: %v", rule)
+	}
+	if units, ok := CurrencyMinorUnits("BHD"); units != 3 || !ok {
+		t.Errorf("expected 3 minor units for BHD, got %d", units)
+	}
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the precompiled patterns a Validator.Regexp can reference by name, e.g. "@slug" – see RegisterPattern
+var namedPatterns = struct {
+	sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}{patterns: make(map[string]*regexp.Regexp)}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function registers a precompiled pattern under the name, for the validators to reference it as "@" followed by the name
+// the patterns sub-package registers the common ones when imported; registering a name twice or a nil pattern panics
+func RegisterPattern(name string, pattern *regexp.Regexp) {
+	if pattern == nil {
+		panic(fmt.Errorf("validation: nil pattern %q", name))
+	}
+	namedPatterns.Lock()
+	defer namedPatterns.Unlock()
+	if _, ok := namedPatterns.patterns[name]; ok {
+		panic(fmt.Errorf("validation: pattern %q registered twice", name))
+	}
+	namedPatterns.patterns[name] = pattern
+}
+
+// This function returns the source of the pattern a Validator.Regexp references by name, the Regexp itself if it is not a reference
+// e.g. for the code generators, the named pattern being unknown outside of the engine; an unknown name is returned as is
+func ResolvePattern(pattern string) string {
+	if compiled, ok := namedPattern(pattern); ok {
+		return compiled.String()
+	}
+	return pattern
+}
+
+// this private function returns the compiled pattern of a Validator.Regexp, looked up if it references a named pattern
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if !strings.HasPrefix(pattern, "@") {
+		return regexp.Compile(pattern)
+	}
+	compiled, ok := namedPattern(pattern)
+	if !ok {
+		return nil, fmt.Errorf("unknown named pattern %q", pattern)
+	}
+	return compiled, nil
+}
+
+// this private function returns the named pattern the Regexp references, if it is a known one
+func namedPattern(pattern string) (*regexp.Regexp, bool) {
+	if !strings.HasPrefix(pattern, "@") {
+		return nil, false
+	}
+	namedPatterns.RLock()
+	defer namedPatterns.RUnlock()
+	compiled, ok := namedPatterns.patterns[pattern[1:]]
+	return compiled, ok
+}
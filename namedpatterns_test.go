@@ -0,0 +1,38 @@
+package validation
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNamedPatterns(t *testing.T) {
+	RegisterPattern("testslug", regexp.MustCompile(`^[a-z-]+$`))
+	schema := &Schema{Validators: map[string]*Validator{
+		"slug":    {Type: "string", Regexp: "@testslug"},
+		"unknown": {Type: "string", Regexp: "@unknown"},
+	}}
+	if errs := CheckSchema(schema); len(errs) != 1 || errs[0].(*DataError).Field != "unknown" {
+		t.Fatalf("expected the unknown pattern to be reported, got %v", errs)
+	}
+	delete(schema.Validators, "unknown")
+
+	if _, errors := schema.Validate(map[string]interface{}{"slug": "a-b"}, Options{}); len(errors) != 0 {
+		t.Errorf("expected the named pattern to match, got %v", errors)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{"slug": "A b"}, Options{}); len(errors) != 1 || errors[0].Params["pattern"] != "@testslug" {
+		t.Errorf("expected the named pattern not to match, got %v", errors)
+	}
+	if _, errors := schema.Clone().Validate(map[string]interface{}{"slug": "@testslug"}, Options{}); len(errors) != 1 {
+		t.Errorf("expected the clone to match the pattern rather than its name, got %v", errors)
+	}
+	if ResolvePattern("@testslug") != `^[a-z-]+$` || ResolvePattern("^x$") != "^x$" || ResolvePattern("@unknown") != "@unknown" {
+		t.Errorf("expected the named pattern only to be resolved")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a name registered twice to panic")
+		}
+	}()
+	RegisterPattern("testslug", regexp.MustCompile(`.`))
+}
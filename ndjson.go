@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// the max size of a line of ValidateNDJSON, in bytes: a longer line is reported without being buffered
+var MaxNDJSONLine = 1 << 20
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates a stream of newline-delimited JSON documents of any size, e.g. a log or an event file, with the memory of a single line
+// fn receives the documents one at a time, in order, along with their errors, which hold their line number, from 1, in their "line" param: the next line is not read before fn returns,
+// so a slow consumer slows the reading down, and an error of fn stops it and is returned
+// the empty lines are skipped; a line that is not a JSON document or is longer than MaxNDJSONLine is reported to fn with a nil document and an "invalid_payload" error
+func ValidateNDJSON(r io.Reader, schema DocumentValidator, opt Options, fn func(doc map[string]interface{}, errs DataErrors) error) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := readLine(reader)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+		if err == bufio.ErrBufferFull {
+			payloadErr := NewError("invalid_payload", "", nil, map[string]interface{}{"format": "ndjson", "error": fmt.Sprintf("line longer than %d bytes", MaxNDJSONLine)})
+			if err := fn(nil, lineErrors(DataErrors{payloadErr}, line)); err != nil {
+				return err
+			}
+			continue
+		}
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			doc, decodeErr := decodeLine(trimmed)
+			var errors DataErrors
+			if decodeErr != nil {
+				errors = DataErrors{NewError("invalid_payload", "", nil, map[string]interface{}{"format": "ndjson", "error": decodeErr.Error()})}
+			} else {
+				doc, errors = schema.Validate(doc, opt)
+			}
+			if err := fn(doc, lineErrors(errors, line)); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// this private function reads the next line, without its newline
+// a line longer than MaxNDJSONLine is skipped up to its end, bufio.ErrBufferFull being returned
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > MaxNDJSONLine+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLong && (err == nil || err == io.EOF) {
+			return nil, bufio.ErrBufferFull
+		}
+		return bytes.TrimSuffix(line, []byte("\n")), err
+	}
+}
+
+// this private function decodes a line holding a single JSON document, its numbers as json.Number
+func decodeLine(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("the line is not a document")
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("the line holds more than a document")
+	}
+	return doc, nil
+}
+
+// this private function records the line number in the errors
+func lineErrors(errors DataErrors, line int) DataErrors {
+	for _, err := range errors {
+		if err.Params == nil {
+			err.Params = make(map[string]interface{})
+		}
+		err.Params["line"] = line
+	}
+	return errors
+}
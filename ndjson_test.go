@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateNDJSON(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"n": {Type: "int64", IsRequired: true, Boundaries: Boundaries{Min: 0, Max: 9}}}}
+	max := MaxNDJSONLine
+	MaxNDJSONLine = 20
+	defer func() { MaxNDJSONLine = max }()
+
+	input := "{\"n\": 1}\n\n{\"other\": 1}\n[1]\n{\"n\": 1, \"pad\": \"xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx\"}\n{\"n\": 2}"
+	var failures []interface{}
+	var documents []map[string]interface{}
+	err := ValidateNDJSON(strings.NewReader(input), schema, Options{Usage: INIT}, func(doc map[string]interface{}, errs DataErrors) error {
+		if len(errs) > 0 {
+			failures = append(failures, errs[0].Params["line"], errs[0].Code)
+		} else {
+			documents = append(documents, doc)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// the empty line is skipped but counted
+	expected := []interface{}{3, "required", 4, "invalid_payload", 5, "invalid_payload"}
+	if !reflect.DeepEqual(failures, expected) {
+		t.Errorf("expected %v, got %v", expected, failures)
+	}
+	if len(documents) != 2 || documents[1]["n"] != int64(2) {
+		t.Errorf("expected the valid documents, got %v", documents)
+	}
+
+	// an error of fn stops the reading
+	stop := errors.New("stop")
+	calls := 0
+	err = ValidateNDJSON(strings.NewReader(input), schema, Options{Usage: INIT}, func(map[string]interface{}, DataErrors) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("expected the reading to stop at the first document, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function converts a value decoded from another format than JSON into the representation the JSON path uses
+// the maps become map[string]interface{}, the arrays []interface{}, every number a json.Number, the binary strings base64 strings and the times RFC 3339 strings
+// an error is returned for a map key that is not a string or a number, or a number JSON cannot represent (NaN, infinities)
+func Normalize(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case nil, bool, string, json.Number:
+		return value, nil
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			item, err := Normalize(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			normalized[key] = item
+		}
+		return normalized, nil
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			name, err := normalizeKey(key)
+			if err != nil {
+				return nil, err
+			}
+			item, err := Normalize(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			normalized[name] = item
+		}
+		return normalized, nil
+	case []interface{}:
+		normalized := make([]interface{}, len(value))
+		for i, item := range value {
+			item, err := Normalize(item)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", i, err)
+			}
+			normalized[i] = item
+		}
+		return normalized, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(value), nil
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano), nil
+	case int:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int8:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int16:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int32:
+		return json.Number(strconv.FormatInt(int64(value), 10)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(value, 10)), nil
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(value), 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(value, 10)), nil
+	case float32:
+		return normalizeFloat(float64(value), 32)
+	case float64:
+		return normalizeFloat(value, 64)
+	case big.Int:
+		return json.Number(value.String()), nil
+	case *big.Int:
+		return json.Number(value.String()), nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %T", value)
+}
+
+// this private function returns the string form of a decoded map key
+func normalizeKey(key interface{}) (string, error) {
+	switch key := key.(type) {
+	case string:
+		return key, nil
+	case []byte:
+		return string(key), nil
+	}
+	number, err := Normalize(key)
+	if n, ok := number.(json.Number); err == nil && ok {
+		return string(n), nil
+	}
+	return "", fmt.Errorf("unsupported map key of type %T", key)
+}
+
+// this private function writes a float the way a JSON document would: without exponent unless the number is huge or tiny
+func normalizeFloat(value float64, bits int) (interface{}, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("%v cannot be represented in JSON", value)
+	}
+	if abs := math.Abs(value); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return json.Number(strconv.FormatFloat(value, 'g', -1, bits)), nil
+	}
+	return json.Number(strconv.FormatFloat(value, 'f', -1, bits)), nil
+}
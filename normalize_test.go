@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	decoded := map[interface{}]interface{}{
+		"name":    "Ada",
+		uint64(7): []interface{}{int8(-1), uint32(2), float32(1.5), 0.1, 1e300, big.NewInt(1 << 62)},
+		"avatar":  []byte("png"),
+		"born":    time.Date(1815, 12, 10, 0, 0, 0, 0, time.FixedZone("GMT", 0)),
+		"nested":  map[string]interface{}{"ok": true, "none": nil},
+	}
+	expected := map[string]interface{}{
+		"name":   "Ada",
+		"7":      []interface{}{json.Number("-1"), json.Number("2"), json.Number("1.5"), json.Number("0.1"), json.Number("1e+300"), json.Number("4611686018427387904")},
+		"avatar": "cG5n",
+		"born":   "1815-12-10T00:00:00Z",
+		"nested": map[string]interface{}{"ok": true, "none": nil},
+	}
+	normalized, err := Normalize(decoded)
+	if err != nil || !reflect.DeepEqual(normalized, expected) {
+		t.Errorf("expected %v, got %v, %v", expected, normalized, err)
+	}
+
+	for _, invalid := range []interface{}{
+		map[interface{}]interface{}{true: "key"},
+		[]interface{}{math.NaN()},
+		map[string]interface{}{"inf": math.Inf(1)},
+		struct{}{},
+	} {
+		if _, err := Normalize(invalid); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+// Package openapi validates the HTTP requests against an OpenAPI 3 spec with the validation engine
+// a schema is built for the path, query and header parameters and for the JSON body of each operation, then a middleware validates the requests in one pass
+// the validators are the ones the engine knows: type, pattern, enum, bounds, lengths, required and default – the arrays items and the oneOf/anyOf schemas are not checked
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/grebett/validation"
+	"gopkg.in/yaml.v3"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct holds the operations of a spec, built by Load
+type Spec struct {
+	Operations []*Operation // sorted so that the most specific paths are matched first
+}
+
+// This struct holds the schemas of an operation
+type Operation struct {
+	ID           string                                // the operationId
+	Method       string                                // in upper case
+	Path         string                                // the path template, e.g. "/users/{id}"
+	Params       map[string]*validation.CompiledSchema // the parameters schemas, by location: "path", "query" or "header"
+	Body         *validation.CompiledSchema            // the schema of the JSON body, nil if the operation has none
+	BodyRequired bool
+
+	segments []string
+}
+
+// This struct is the HTTP middleware validating the requests of the spec operations
+type Middleware struct {
+	Spec        *Spec
+	Prefix      string                                                                     // the prefix of the paths in front of the spec ones, e.g. "/api/v1"
+	MaxBodySize int64                                                                      // the maximal size of the bodies in bytes, 1 MiB if 0
+	Options     func(r *http.Request) validation.Options                                   // the options of the validations, the zero ones if nil – see Operation.Validate
+	OnError     func(w http.ResponseWriter, r *http.Request, errors validation.DataErrors) // writes the response of an invalid request, a JSON:API error document if nil
+}
+
+// the key of the validated request in the request context
+type contextKey struct{}
+
+// the methods an OpenAPI path item may hold
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the operation matching the request method and path, and the values of the path parameters
+// returns nil if no operation matches
+func (s *Spec) Find(method string, path string) (*Operation, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, operation := range s.Operations {
+		if operation.Method != strings.ToUpper(method) || len(operation.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		for i, segment := range operation.segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && segments[i] != "" {
+				value, err := url.PathUnescape(segments[i])
+				if err != nil {
+					value = segments[i]
+				}
+				params[segment[1:len(segment)-1]] = value
+			} else if segment != segments[i] {
+				params = nil
+				break
+			}
+		}
+		if params != nil {
+			return operation, params
+		}
+	}
+	return nil, nil
+}
+
+// This method validates the parameters and the body of the request
+// the validated document is {"path": {...}, "query": {...}, "header": {...}, "body": {...}}, and the errors are located under these keys
+// the parameters are validated on INIT, the body on SET for PATCH and on INIT otherwise; the body is read but left readable for the handler
+func (o *Operation) Validate(r *http.Request, params map[string]string, opt validation.Options) (map[string]interface{}, validation.DataErrors) {
+	doc := make(map[string]interface{})
+	var errors validation.DataErrors
+
+	paramsOpt := opt
+	paramsOpt.Usage, paramsOpt.Existing = validation.INIT, nil
+	for _, location := range []string{"path", "query", "header"} {
+		schema, ok := o.Params[location]
+		if !ok {
+			continue
+		}
+		var validated map[string]interface{}
+		var locationErrors validation.DataErrors
+		switch location {
+		case "path":
+			values := make(url.Values, len(params))
+			for name, value := range params {
+				values.Set(name, value)
+			}
+			validated, locationErrors = validation.ValidateQuery(values, schema, paramsOpt)
+		case "query":
+			validated, locationErrors = validation.ValidateQuery(r.URL.Query(), schema, paramsOpt)
+		case "header":
+			validated, locationErrors = validation.ValidateHeaders(r.Header, schema, paramsOpt)
+		}
+		doc[location] = validated
+		errors = append(errors, locate(location, locationErrors)...)
+	}
+
+	if o.Body == nil {
+		return doc, errors
+	}
+	body, bodyErrors := readBody(r)
+	if len(bodyErrors) == 0 && body == nil && o.BodyRequired {
+		bodyErrors = validation.DataErrors{validation.NewError("required", "", nil, nil)}
+	}
+	if len(bodyErrors) == 0 && body != nil {
+		bodyOpt := opt
+		bodyOpt.Usage = validation.INIT
+		if r.Method == http.MethodPatch {
+			bodyOpt.Usage = validation.SET
+		}
+		doc["body"], bodyErrors = o.Body.Validate(body, bodyOpt)
+	}
+	return doc, append(errors, locate("body", bodyErrors)...)
+}
+
+// This method returns the handler validating the requests before next handles them – see Validated
+// the requests matching no operation of the spec are handed to next as they are
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation, params := m.Spec.Find(r.Method, strings.TrimPrefix(r.URL.Path, m.Prefix))
+		if operation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := m.MaxBodySize
+		if limit == 0 {
+			limit = 1 << 20
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		var opt validation.Options
+		if m.Options != nil {
+			opt = m.Options(r)
+		}
+
+		doc, errors := operation.Validate(r, params, opt)
+		if len(errors) > 0 {
+			if m.OnError != nil {
+				m.OnError(w, r, errors)
+			} else {
+				writeErrors(w, errors)
+			}
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, doc)))
+	})
+}
+
+// This method builds the schemas of an operation, the operation parameters overriding the path item ones
+func (c *converter) operation(path string, method string, node map[string]interface{}, itemParams []interface{}) (*Operation, error) {
+	operation := &Operation{
+		Method:   strings.ToUpper(method),
+		Path:     path,
+		Params:   make(map[string]*validation.CompiledSchema),
+		segments: strings.Split(strings.Trim(path, "/"), "/"),
+	}
+	operation.ID, _ = node["operationId"].(string)
+
+	opParams, _ := node["parameters"].([]interface{})
+	params := make(map[[2]string]map[string]interface{})
+	for _, item := range append(append([]interface{}{}, itemParams...), opParams...) {
+		param, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter")
+		}
+		param, _, err := c.resolve(param, nil)
+		if err != nil {
+			return nil, err
+		}
+		in, _ := param["in"].(string)
+		name, _ := param["name"].(string)
+		params[[2]string{in, name}] = param
+	}
+
+	validators := make(map[string]map[string]*validation.Validator)
+	for key, param := range params {
+		in, name := key[0], key[1]
+		if in != "path" && in != "query" && in != "header" {
+			// the cookies are not validated
+			continue
+		}
+		schema, _ := param["schema"].(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		required, _ := param["required"].(bool)
+		if validators[in] == nil {
+			validators[in] = make(map[string]*validation.Validator)
+		}
+		if err := c.validators(schema, name, required || in == "path", false, validators[in], nil); err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", name, err)
+		}
+	}
+	for in, locationValidators := range validators {
+		compiled, err := (&validation.Schema{Validators: locationValidators}).Compile()
+		if err != nil {
+			return nil, err
+		}
+		operation.Params[in] = compiled
+	}
+
+	if body, ok := node["requestBody"].(map[string]interface{}); ok {
+		body, _, err := c.resolve(body, nil)
+		if err != nil {
+			return nil, err
+		}
+		operation.BodyRequired, _ = body["required"].(bool)
+		if schema := jsonSchema(body); schema != nil {
+			bodyValidators := make(map[string]*validation.Validator)
+			if err := c.validators(schema, "", true, false, bodyValidators, nil); err != nil {
+				return nil, fmt.Errorf("body: %v", err)
+			}
+			if operation.Body, err = (&validation.Schema{Validators: bodyValidators}).Compile(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return operation, nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function loads an OpenAPI 3 spec, written in JSON or in YAML, and builds the schemas of its operations
+// the references must be local ("#/components/..."); an operation whose schemas cannot be built fails the loading
+func Load(data []byte) (*Spec, error) {
+	var decoded interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, fmt.Errorf("openapi: %v", err)
+		}
+	} else if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("openapi: %v", err)
+	}
+	normalized, err := validation.Normalize(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %v", err)
+	}
+	spec, ok := normalized.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("openapi: the spec is not a document")
+	}
+
+	c := &converter{spec: spec}
+	paths, _ := spec["paths"].(map[string]interface{})
+	result := &Spec{}
+	for path, item := range paths {
+		item, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("openapi: %s: invalid path item", path)
+		}
+		itemParams, _ := item["parameters"].([]interface{})
+		for _, method := range methods {
+			node, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operation, err := c.operation(path, method, node, itemParams)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %v", strings.ToUpper(method), path, err)
+			}
+			result.Operations = append(result.Operations, operation)
+		}
+	}
+
+	// the literal segments take precedence over the templated ones: /users/me before /users/{id}
+	sort.SliceStable(result.Operations, func(i, j int) bool {
+		a, b := result.Operations[i], result.Operations[j]
+		if a.Path != b.Path {
+			return literalSegments(a.segments) > literalSegments(b.segments) || (literalSegments(a.segments) == literalSegments(b.segments) && a.Path < b.Path)
+		}
+		return a.Method < b.Method
+	})
+	return result, nil
+}
+
+// This function returns the document validated by the middleware, nil if the request was not validated
+func Validated(r *http.Request) map[string]interface{} {
+	doc, _ := r.Context().Value(contextKey{}).(map[string]interface{})
+	return doc
+}
+
+// this private function returns the schema of the JSON content of a request body, nil if it has none
+func jsonSchema(body map[string]interface{}) map[string]interface{} {
+	content, _ := body["content"].(map[string]interface{})
+	for mediaType, media := range content {
+		parsed, _, err := mime.ParseMediaType(mediaType)
+		if err != nil || (parsed != "application/json" && !strings.HasSuffix(parsed, "+json")) {
+			continue
+		}
+		if media, ok := media.(map[string]interface{}); ok {
+			schema, _ := media["schema"].(map[string]interface{})
+			return schema
+		}
+	}
+	return nil
+}
+
+// this private function returns the number of segments of a path template that are not parameters
+func literalSegments(segments []string) int {
+	count := 0
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment, "{") {
+			count++
+		}
+	}
+	return count
+}
+
+// this private function reads and decodes the JSON body of the request, then makes it readable again
+// returns a nil document for an empty body
+func readBody(r *http.Request) (map[string]interface{}, validation.DataErrors) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, validation.DataErrors{validation.NewError("too_large", "", nil, map[string]interface{}{"max": tooLarge.Limit})}
+	} else if err != nil {
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"error": err.Error()})}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil || doc == nil {
+		if err == nil {
+			err = fmt.Errorf("the body is not a document")
+		}
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"error": err.Error()})}
+	}
+	return doc, nil
+}
+
+// this private function returns copies of the errors located under the key: their field and path begin with it
+func locate(key string, errors validation.DataErrors) validation.DataErrors {
+	located := make(validation.DataErrors, len(errors))
+	for i, err := range errors {
+		copied := *err
+		copied.Field = key
+		if err.Field != "" {
+			copied.Field = key + "." + err.Field
+		}
+		copied.Path = append([]validation.PathSegment{{Key: key}}, err.Path...)
+		located[i] = &copied
+	}
+	return located
+}
+
+// this private function writes the errors as a JSON:API error document
+// 413 for a body too large, 400 for a body that is not a document, 422 otherwise
+func writeErrors(w http.ResponseWriter, errors validation.DataErrors) {
+	status := http.StatusUnprocessableEntity
+	for _, err := range errors {
+		if err.Code == "too_large" && len(err.Path) == 1 {
+			status = http.StatusRequestEntityTooLarge
+		} else if err.Code == "invalid_payload" {
+			status = http.StatusBadRequest
+		}
+	}
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(validation.JSONAPIErrors(errors, ""))
+}
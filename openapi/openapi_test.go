@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const petSpec = `{
+	"openapi": "3.0.3",
+	"paths": {
+		"/pets/{id}": {
+			"parameters": [{"name": "id", "in": "path", "schema": {"type": "integer", "minimum": 1}}],
+			"get": {
+				"operationId": "getPet",
+				"parameters": [
+					{"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+					{"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+				]
+			},
+			"patch": {
+				"operationId": "updatePet",
+				"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+			}
+		},
+		"/pets/mine": {
+			"get": {"operationId": "myPets"}
+		},
+		"/pets": {
+			"post": {
+				"operationId": "createPet",
+				"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"kind": {"type": "string", "enum": ["cat", "dog"], "default": "cat"},
+					"weight": {"type": "number", "minimum": 0},
+					"owner": {"type": "object", "required": ["email"], "properties": {"email": {"type": "string"}}}
+				}
+			}
+		}
+	}
+}`
+
+func TestFind(t *testing.T) {
+	spec, err := Load([]byte(petSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		path   string
+		id     string
+		params map[string]string
+	}{
+		{"GET", "/pets/mine", "myPets", map[string]string{}},
+		{"get", "/pets/a%20b", "getPet", map[string]string{"id": "a b"}},
+		{"PATCH", "/pets/12/", "updatePet", map[string]string{"id": "12"}},
+		{"POST", "/pets", "createPet", map[string]string{}},
+		{"DELETE", "/pets/12", "", nil},
+		{"GET", "/pets/12/toys", "", nil},
+	}
+	for _, test := range tests {
+		operation, params := spec.Find(test.method, test.path)
+		id := ""
+		if operation != nil {
+			id = operation.ID
+		}
+		if id != test.id || !reflect.DeepEqual(params, test.params) {
+			t.Errorf("%s %s: expected %q %v, got %q %v", test.method, test.path, test.id, test.params, id, params)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	specs := []string{
+		`{"paths": {"/a": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}`,
+		`{"paths": {"/a": {"get": {"parameters": [{"name": "n", "in": "query", "schema": {"$ref": "common.json#/Page"}}]}}}}`,
+		`{"paths": {"/a": "invalid"}}`,
+		`[1, 2]`,
+	}
+	for _, spec := range specs {
+		if _, err := Load([]byte(spec)); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	spec, err := Load([]byte(petSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validated map[string]interface{}
+	handler := (&Middleware{Spec: spec, Prefix: "/api", MaxBodySize: 128}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validated = Validated(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method string
+		target string
+		tenant string
+		body   string
+		status int
+		codes  []string
+	}{
+		{"GET", "/api/pets/12?fields=name,kind", "123e4567-e89b-12d3-a456-426614174000", "", http.StatusNoContent, nil},
+		{"GET", "/api/pets/0", "tenant", "", http.StatusUnprocessableEntity, []string{"out_of_boundaries", "regex_not_match"}},
+		{"GET", "/api/unknown", "", "", http.StatusNoContent, nil},
+		{"POST", "/api/pets", "", `{"name": "Rex", "weight": 12.5}`, http.StatusNoContent, nil},
+		{"POST", "/api/pets", "", ``, http.StatusUnprocessableEntity, []string{"required"}},
+		{"POST", "/api/pets", "", `{"name": "Rex", "kind": "bird"}`, http.StatusUnprocessableEntity, []string{"not_in_enum"}},
+		{"POST", "/api/pets", "", `{"name": `, http.StatusBadRequest, []string{"invalid_payload"}},
+		{"POST", "/api/pets", "", `{"name": "` + strings.Repeat("x", 200) + `"}`, http.StatusRequestEntityTooLarge, []string{"too_large"}},
+		{"PATCH", "/api/pets/3", "", `{"weight": 2}`, http.StatusNoContent, nil},
+	}
+	for _, test := range tests {
+		validated = nil
+		r := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		if test.tenant != "" {
+			r.Header.Set("X-Tenant", test.tenant)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d %s", test.method, test.target, test.status, w.Code, w.Body)
+			continue
+		}
+		if test.codes == nil {
+			continue
+		}
+		var document struct {
+			Errors []struct {
+				Code string `json:"code"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &document)
+		codes := make([]string, len(document.Errors))
+		for i, err := range document.Errors {
+			codes[i] = err.Code
+		}
+		if !reflect.DeepEqual(codes, test.codes) {
+			t.Errorf("%s %s: expected %v, got %v", test.method, test.target, test.codes, codes)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/api/pets", strings.NewReader(`{"name": "Rex"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if body, _ := validated["body"].(map[string]interface{}); body["kind"] != "cat" {
+		t.Errorf("expected the default kind, got %v", validated)
+	}
+}
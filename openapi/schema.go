@@ -0,0 +1,408 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct converts the OpenAPI schemas into validators, resolving the local references of the spec
+type converter struct {
+	spec map[string]interface{}
+}
+
+// the regexp of the uuid format
+const uuidRegexp = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the node a local reference ("#/components/schemas/User") points to
+func (c *converter) pointer(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only the local references are supported: %s", ref)
+	}
+	var node interface{} = c.spec
+	for _, token := range strings.Split(ref[2:], "/") {
+		parent, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %s", ref)
+		}
+		node = parent[strings.NewReplacer("~1", "/", "~0", "~").Replace(token)]
+	}
+	target, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", ref)
+	}
+	return target, nil
+}
+
+// This method returns the node, its reference resolved if it is one
+// refs holds the references resolved along the current branch: a reference found again is a recursive schema, returned as nil
+func (c *converter) resolve(node map[string]interface{}, refs map[string]bool) (map[string]interface{}, map[string]bool, error) {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node, refs, nil
+	}
+	if refs[ref] {
+		return nil, refs, nil
+	}
+	target, err := c.pointer(ref)
+	if err != nil {
+		return nil, refs, err
+	}
+	branch := make(map[string]bool, len(refs)+1)
+	for r := range refs {
+		branch[r] = true
+	}
+	branch[ref] = true
+	return c.resolve(target, branch)
+}
+
+// This method returns the schema, its reference resolved and its allOf subschemas merged into it
+func (c *converter) flatten(schema map[string]interface{}, refs map[string]bool) (map[string]interface{}, map[string]bool, error) {
+	schema, refs, err := c.resolve(schema, refs)
+	if err != nil || schema == nil {
+		return schema, refs, err
+	}
+	all, ok := schema["allOf"].([]interface{})
+	if !ok {
+		return schema, refs, nil
+	}
+
+	merged := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if key != "allOf" {
+			merged[key] = value
+		}
+	}
+	properties := make(map[string]interface{})
+	var required []interface{}
+	for _, item := range append([]interface{}{merged}, all...) {
+		sub, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, refs, fmt.Errorf("invalid allOf item")
+		}
+		if sub, _, err = c.flatten(sub, refs); err != nil {
+			return nil, refs, err
+		}
+		if sub == nil {
+			continue
+		}
+		for key, value := range sub {
+			if _, ok := merged[key]; !ok {
+				merged[key] = value
+			}
+		}
+		if props, ok := sub["properties"].(map[string]interface{}); ok {
+			for name, prop := range props {
+				properties[name] = prop
+			}
+		}
+		if names, ok := sub["required"].([]interface{}); ok {
+			required = append(required, names...)
+		}
+	}
+	merged["properties"], merged["required"] = properties, required
+	return merged, refs, nil
+}
+
+// This method writes the validator of the schema under the path, and the ones of its properties if it is an object
+// the properties of an optional object are neither required nor defaulted, since the object itself may be missing
+// the root schema of a body has no validator of its own: path is then empty
+func (c *converter) validators(schema map[string]interface{}, path string, required bool, optional bool, validators map[string]*validation.Validator, refs map[string]bool) error {
+	schema, refs, err := c.flatten(schema, refs)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		// a recursive schema is validated down to its first repetition only
+		validators[path] = &validation.Validator{Type: "map[string]interface {}", IsRequired: required && !optional}
+		return nil
+	}
+
+	if path != "" {
+		validator, err := c.validator(schema)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		validator.IsRequired = required && !optional && validator.Default == nil
+		if optional {
+			validator.Default = nil
+		}
+		validators[path] = validator
+		optional = optional || !required
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	requiredNames := make(map[string]bool)
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			requiredNames[fmt.Sprint(name)] = true
+		}
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: invalid property schema", name)
+		}
+		childPath := name
+		if path != "" {
+			childPath = path + "." + name
+		}
+		if err := c.validators(property, childPath, requiredNames[name], optional, validators, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This method returns the validator of a flattened schema, without its requirement
+func (c *converter) validator(schema map[string]interface{}) (*validation.Validator, error) {
+	validator := &validation.Validator{}
+	var tests []func(value interface{}) *validation.DataError
+
+	switch schemaType(schema) {
+	case "string":
+		validator.Type = "string"
+		if pattern, ok := schema["pattern"].(string); ok {
+			validator.Regexp = pattern
+		}
+		switch schema["format"] {
+		case "uuid":
+			if validator.Regexp == "" {
+				validator.Regexp = uuidRegexp
+			} else {
+				tests = append(tests, matchTest(regexp.MustCompile(uuidRegexp)))
+			}
+		case "date-time":
+			tests = append(tests, timeTest(time.RFC3339))
+		case "date":
+			tests = append(tests, timeTest("2006-01-02"))
+		}
+		if min, ok := number(schema["minLength"]); ok {
+			tests = append(tests, lengthTest("minLength", func(n int) bool { return float64(n) >= min }))
+		}
+		if max, ok := number(schema["maxLength"]); ok {
+			tests = append(tests, lengthTest("maxLength", func(n int) bool { return float64(n) <= max }))
+		}
+	case "integer":
+		validator.Type = "int64"
+		bounds := validation.IntBoundaries{Min: math.MinInt64, Max: math.MaxInt64}
+		if schema["format"] == "int32" {
+			validator.Type = "int32"
+			bounds = validation.IntBoundaries{Min: math.MinInt32, Max: math.MaxInt32}
+		}
+		if min, ok := schema["minimum"]; ok {
+			if n, err := strconv.ParseInt(fmt.Sprint(min), 10, 64); err == nil {
+				bounds.Min = n
+			}
+		}
+		if max, ok := schema["maximum"]; ok {
+			if n, err := strconv.ParseInt(fmt.Sprint(max), 10, 64); err == nil {
+				bounds.Max = n
+			}
+		}
+		validator.IntBoundaries = &bounds
+	case "number":
+		validator.Type = "json.Number"
+		validator.Boundaries = validation.Boundaries{Min: -math.MaxFloat64, Max: math.MaxFloat64}
+		if min, ok := number(schema["minimum"]); ok {
+			validator.Boundaries.Min = min
+		}
+		if max, ok := number(schema["maximum"]); ok {
+			validator.Boundaries.Max = max
+		}
+	case "boolean":
+		validator.Type = "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		itemType, err := c.itemType(items)
+		if err != nil {
+			return nil, err
+		}
+		validator.Type = "[]" + itemType
+	case "object":
+		validator.Type = "map[string]interface {}"
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok && schema["properties"] == nil {
+			itemType, err := c.itemType(additional)
+			if err != nil {
+				return nil, err
+			}
+			validator.Type = "map[string]" + itemType
+		}
+	default:
+		// oneOf, anyOf and the untyped schemas accept any value
+		validator.Type = "interface {}"
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		tests = append(tests, enumTest(enum))
+	}
+	if value, ok := schema["default"]; ok && value != nil {
+		if _, err := defaultValue(validator.Type, value); err != nil {
+			return nil, fmt.Errorf("invalid default: %v", err)
+		}
+		_type := validator.Type
+		validator.Default = func(interface{}) interface{} {
+			defaulted, _ := defaultValue(_type, value)
+			return defaulted
+		}
+	}
+	if len(tests) > 0 {
+		validator.CustomTest = func(value interface{}) (bool, *validation.DataError) {
+			for _, test := range tests {
+				if err := test(value); err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+	}
+	return validator, nil
+}
+
+// This method returns the type of the items of an array or the values of a map
+// inside the arrays and maps, the numbers are json.Number as decoded from the body
+func (c *converter) itemType(schema map[string]interface{}) (string, error) {
+	if schema == nil {
+		return "interface {}", nil
+	}
+	schema, _, err := c.flatten(schema, nil)
+	if err != nil || schema == nil {
+		return "map[string]interface {}", err
+	}
+	switch schemaType(schema) {
+	case "string":
+		return "string", nil
+	case "integer", "number":
+		return "json.Number", nil
+	case "boolean":
+		return "bool", nil
+	case "object":
+		return "map[string]interface {}", nil
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		itemType, err := c.itemType(items)
+		return "[]" + itemType, err
+	}
+	return "interface {}", nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the type of the schema, the OpenAPI 3.1 lists such as ["string", "null"] read as their non-null type
+// a nullable value is a missing one for the engine, so the null type needs no validator of its own
+func schemaType(schema map[string]interface{}) string {
+	switch _type := schema["type"].(type) {
+	case string:
+		return _type
+	case []interface{}:
+		var types []string
+		for _, item := range _type {
+			if item != "null" {
+				types = append(types, fmt.Sprint(item))
+			}
+		}
+		if len(types) == 1 {
+			return types[0]
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// this private function returns a numeric keyword of the spec as a float64
+func number(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	return n, err == nil
+}
+
+// this private function returns the default value of a validator, in the representation of the decoded bodies
+func defaultValue(_type string, value interface{}) (interface{}, error) {
+	value, err := validation.Normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := value.(json.Number); ok && (_type == "int64" || _type == "int32") {
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if _type == "int32" {
+			return int32(i), err
+		}
+		return i, err
+	}
+	return value, nil
+}
+
+// this private function returns the test of a regexp added to the one of the validator
+func matchTest(re *regexp.Regexp) func(interface{}) *validation.DataError {
+	return func(value interface{}) *validation.DataError {
+		if str, ok := value.(string); ok && !re.MatchString(str) {
+			return validation.NewError("regex_not_match", "", value, map[string]interface{}{"pattern": re.String()})
+		}
+		return nil
+	}
+}
+
+// this private function returns the test of a date or date-time format
+func timeTest(layout string) func(interface{}) *validation.DataError {
+	return func(value interface{}) *validation.DataError {
+		if str, ok := value.(string); ok {
+			if _, err := time.Parse(layout, str); err != nil {
+				return validation.NewError("invalid_format", "", value, map[string]interface{}{"layout": layout})
+			}
+		}
+		return nil
+	}
+}
+
+// this private function returns the test of a string length, counted in characters
+func lengthTest(keyword string, ok func(n int) bool) func(interface{}) *validation.DataError {
+	return func(value interface{}) *validation.DataError {
+		if str, isString := value.(string); isString && !ok(utf8.RuneCountInString(str)) {
+			return validation.NewError("out_of_boundaries", "", value, map[string]interface{}{"keyword": keyword})
+		}
+		return nil
+	}
+}
+
+// this private function returns the test of an enum, the values being compared as written in JSON
+func enumTest(enum []interface{}) func(interface{}) *validation.DataError {
+	allowed := make(map[string]bool, len(enum))
+	for _, item := range enum {
+		if encoded, err := json.Marshal(item); err == nil {
+			allowed[string(encoded)] = true
+		}
+	}
+	return func(value interface{}) *validation.DataError {
+		if encoded, err := json.Marshal(value); err != nil || !allowed[string(encoded)] {
+			return validation.NewError("not_in_enum", "", value, map[string]interface{}{"enum": enum})
+		}
+		return nil
+	}
+}
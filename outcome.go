@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct validates the documents against a schema, and keeps the outcomes for the identical validations to come, e.g. the GET of the same hot documents
+// the outcomes are stored by schema hash, document and options, in an LRUCache of the size and time to live – see NewOutcomeCache
+// it is a DocumentValidator, to use in place of the schema where the validations are idempotent: the custom tests, defaults and factories
+// must not depend on anything but the document and the options, since their outcome is served again as is
+type OutcomeCache struct {
+	Schema DocumentValidator // the schema the documents are validated against
+
+	lru    *LRUCache
+	mu     sync.Mutex
+	hashed *Schema // the schema the hash is the one of, the swappable schemas changing it
+	hash   string
+}
+
+// This private struct is a validation stored by an OutcomeCache
+type outcome struct {
+	dest   map[string]interface{}
+	errors DataErrors
+}
+
+// This private struct holds the options an outcome depends on, as part of its key
+// the functions, the context and the collectors are not part of it, and the validations with an override, a trace or a coverage are never cached
+type outcomeOptions struct {
+	Usage          int
+	UserRights     int
+	Existing       map[string]interface{}   `json:",omitempty"`
+	OwnerField     string                   `json:",omitempty"`
+	UserID         interface{}              `json:",omitempty"`
+	Profile        string                   `json:",omitempty"`
+	Strict         bool                     `json:",omitempty"`
+	MaxDepth       int                      `json:",omitempty"`
+	MaxFields      int                      `json:",omitempty"`
+	ArrayFilters   []map[string]interface{} `json:",omitempty"`
+	ErrorOnMissing []string                 `json:",omitempty"`
+	Partial        bool                     `json:",omitempty"`
+	HideDeleted    bool                     `json:",omitempty"`
+	Locale         string                   `json:",omitempty"`
+	DocBaseURL     string                   `json:",omitempty"`
+	MaxRefDepth    int                      `json:",omitempty"`
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the outcome of a former identical validation if it is stored, or validates the document and stores the outcome
+// the validations failing on an internal error are never stored; the Meta of the options is attached to the errors of every call
+func (c *OutcomeCache) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	key, ok := c.key(_map, opt)
+	if !ok {
+		return c.Schema.Validate(_map, opt)
+	}
+	if stored, ok := c.lru.get(key); ok {
+		return stored.(*outcome).copy(opt.Meta)
+	}
+
+	// the outcome is stored without the metadata of the request
+	meta := opt.Meta
+	opt.Meta = nil
+	dest, errors := c.Schema.Validate(_map, opt)
+	for _, err := range errors {
+		if err.Type == "Internal error" {
+			return (&outcome{dest: dest, errors: errors}).copy(meta)
+		}
+	}
+	stored := &outcome{dest: copyDeep(dest), errors: errors}
+	c.lru.set(key, stored)
+	return stored.copy(meta)
+}
+
+// This method returns the number of outcomes stored, the expired ones included until they are looked up or evicted
+func (c *OutcomeCache) Len() int {
+	return c.lru.Len()
+}
+
+// this private method returns the key of the validation, false if it cannot be cached
+func (c *OutcomeCache) key(_map map[string]interface{}, opt Options) (string, bool) {
+	if opt.Override != nil || opt.Trace != nil || opt.Coverage != nil || opt.ShadowSchema != nil {
+		return "", false
+	}
+	document, err := json.Marshal(_map)
+	if err != nil {
+		return "", false
+	}
+	options, err := json.Marshal(outcomeOptions{
+		Usage: opt.Usage, UserRights: opt.UserRights, Existing: opt.Existing, OwnerField: opt.OwnerField, UserID: opt.UserID,
+		Profile: opt.Profile, Strict: opt.Strict, MaxDepth: opt.MaxDepth, MaxFields: opt.MaxFields,
+		ArrayFilters: opt.ArrayFilters, ErrorOnMissing: opt.ErrorOnMissing, Partial: opt.Partial, HideDeleted: opt.HideDeleted,
+		Locale: opt.Locale, DocBaseURL: opt.DocBaseURL, MaxRefDepth: opt.MaxRefDepth,
+	})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(append(append(document, 0), options...))
+	return c.schemaHash() + "\x00" + hex.EncodeToString(sum[:]), true
+}
+
+// this private method returns the hash of the schema, computed again only when a swappable schema changed it
+func (c *OutcomeCache) schemaHash() string {
+	schema := documentSchema(c.Schema)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hashed != schema {
+		c.hashed, c.hash = schema, SchemaHash(schema)
+	}
+	return c.hash
+}
+
+// this private method returns a copy of the outcome the caller may modify, its errors getting the metadata
+func (o *outcome) copy(meta map[string]interface{}) (map[string]interface{}, DataErrors) {
+	var dest map[string]interface{}
+	if o.dest != nil {
+		dest = copyDeep(o.dest)
+	}
+	if o.errors == nil {
+		return dest, nil
+	}
+	errors := make(DataErrors, len(o.errors))
+	for i, err := range o.errors {
+		copied := *err
+		if copied.Meta == nil {
+			copied.Meta = meta
+		}
+		errors[i] = &copied
+	}
+	return dest, errors
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the cache of the outcomes of the schema validations, of the size and whose outcomes expire after the time to live – never if 0
+func NewOutcomeCache(schema DocumentValidator, size int, ttl time.Duration) *OutcomeCache {
+	return &OutcomeCache{Schema: schema, lru: NewLRUCache(size, ttl)}
+}
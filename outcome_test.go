@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutcomeCache(t *testing.T) {
+	runs := 0
+	schema := &Schema{Validators: map[string]*Validator{
+		"name": {Type: "string", IsRequired: true, CustomTest: func(interface{}) (bool, *DataError) {
+			runs++
+			return true, nil
+		}},
+		"age": {Type: "int", Boundaries: Boundaries{Min: 0, Max: 10}},
+	}}
+	cache := NewOutcomeCache(schema, 2, 0)
+	document := map[string]interface{}{"name": "a", "age": float64(3)}
+	for i := 0; i < 3; i++ {
+		dest, errors := cache.Validate(document, Options{Usage: INIT})
+		if len(errors) != 0 || dest["name"] != "a" {
+			t.Fatalf("expected the stored outcome to be unchanged, got %v %v", dest, errors)
+		}
+		dest["name"] = "changed"
+	}
+	if runs != 1 || cache.Len() != 1 {
+		t.Errorf("expected the document to be validated once, got %d runs and %d outcomes", runs, cache.Len())
+	}
+
+	cache.Validate(document, Options{Usage: SET})
+	if runs != 2 {
+		t.Errorf("expected other options to miss, got %d runs", runs)
+	}
+	cache.Validate(document, Options{Usage: INIT, Override: map[string]*Validator{}})
+	if runs != 3 {
+		t.Errorf("expected an override to bypass the cache, got %d runs", runs)
+	}
+
+	invalid := map[string]interface{}{"age": float64(30)}
+	_, first := cache.Validate(invalid, Options{Usage: INIT, Meta: map[string]interface{}{"request": 1}})
+	_, second := cache.Validate(invalid, Options{Usage: INIT, Meta: map[string]interface{}{"request": 2}})
+	if len(first) != 2 || len(second) != 2 || first[0].Meta["request"] != 1 || second[0].Meta["request"] != 2 {
+		t.Errorf("expected the metadata of each call, got %v %v", first, second)
+	}
+
+	expiring := NewOutcomeCache(schema, 2, time.Millisecond)
+	expiring.Validate(document, Options{Usage: INIT})
+	time.Sleep(5 * time.Millisecond)
+	expiring.Validate(document, Options{Usage: INIT})
+	if runs != 5 {
+		t.Errorf("expected the outcome to expire, got %d runs", runs)
+	}
+}
+
+func TestOutcomeCacheInternalError(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name": {Type: "string", CustomTest: func(interface{}) (bool, *DataError) { panic("boom") }},
+	}}
+	cache := NewOutcomeCache(schema, 2, 0)
+	_, errors := cache.Validate(map[string]interface{}{"name": "a"}, Options{Usage: INIT})
+	if len(errors) != 1 || errors[0].Type != errorReasons["internal_error"][0] || cache.Len() != 0 {
+		t.Errorf("expected the internal error not to be stored, got %v and %d outcomes", errors, cache.Len())
+	}
+}
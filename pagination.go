@@ -0,0 +1,209 @@
+package validation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is the paging policy of a list endpoint – see ValidatePage
+type PageRules struct {
+	MaxLimit     int      // the max page size, 100 if 0
+	DefaultLimit int      // the page size when the client gives none, 20 if 0 – at most MaxLimit
+	Sortable     []string // the paths the clients may sort on, provided they have the GET rights on them – all the readable fields if empty
+	DefaultSort  string   // the sort when the client gives none, written as the sort parameter, e.g. "-createdAt"
+}
+
+// This struct is the validated paging of a query, ready for the driver: Sort holds the stored paths
+type Page struct {
+	Sort  []SortField            // the fields to sort on, in order
+	Limit int                    // the page size
+	Skip  int                    // the number of documents to skip, from the offset parameter
+	After map[string]interface{} // the sort values the page starts after, by stored path, from the cursor parameter – nil if none
+}
+
+// This struct is a field to sort on
+type SortField struct {
+	Field string // the path the clients know the field by
+	Path  string // the stored path
+	Order int    // 1 for an ascending order, -1 for a descending one
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the sort in the mgo Query.Sort format, e.g. ["-createdAt", "name"]
+func (p *Page) MgoSort() []string {
+	keys := make([]string, len(p.Sort))
+	for i, field := range p.Sort {
+		keys[i] = field.Path
+		if field.Order < 0 {
+			keys[i] = "-" + field.Path
+		}
+	}
+	return keys
+}
+
+// This method returns the cursor parameter of the next page, from the last document of the current one as stored
+// the cursor is the base64url JSON of the sort values by client path: the sort should end with a unique field (e.g. the id), for no document to be skipped
+func (p *Page) Cursor(last map[string]interface{}) string {
+	values := make(map[string]interface{}, len(p.Sort))
+	for _, field := range p.Sort {
+		values[field.Field] = serializeValue(readExisting(last, field.Path))
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates the paging parameters of a list request: sort (comma-separated or repeated, "-" for a descending order), limit, and offset or cursor
+// the sortable fields are the ones of the rules the user has the GET rights for; the cursor values are validated against their field validators, as filter bounds
+func ValidatePage(values url.Values, schema DocumentValidator, rules PageRules, opt Options) (*Page, DataErrors) {
+	page, errors := validatePage(values, documentSchema(schema), rules, opt)
+	finishErrors(errors, opt)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	return page, errors
+}
+
+// this private function validates the paging parameters – see ValidatePage
+func validatePage(values url.Values, root *Schema, rules PageRules, opt Options) (*Page, []*DataError) {
+	errors := make([]*DataError, 0)
+	opt.root = root
+	profiled, opt, _, err := applyProfile(root, opt)
+	if err != nil {
+		return nil, append(errors, err)
+	}
+	profiled = applyOverride(profiled, opt.Override)
+	rights := resolveRights(opt)
+
+	maxLimit, limit := rules.MaxLimit, rules.DefaultLimit
+	if maxLimit <= 0 {
+		maxLimit = 100
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	page := &Page{Limit: limit}
+
+	// the sort
+	keys := values["sort"]
+	if len(keys) == 0 && rules.DefaultSort != "" {
+		keys = []string{rules.DefaultSort}
+	}
+	sortable := sortableFields(profiled, rules, rights, opt)
+	for _, list := range keys {
+		for _, key := range strings.Split(list, ",") {
+			field := SortField{Field: strings.TrimSpace(key), Order: 1}
+			if strings.HasPrefix(field.Field, "-") {
+				field.Field, field.Order = field.Field[1:], -1
+			} else {
+				field.Field = strings.TrimPrefix(field.Field, "+")
+			}
+			path, ok := sortable[field.Field]
+			if !ok {
+				errors = append(errors, NewError("not_sortable", "sort", key, nil))
+				continue
+			}
+			field.Path = path
+			page.Sort = append(page.Sort, field)
+		}
+	}
+
+	// the size and the start of the page
+	var ok bool
+	if page.Limit, ok = pageNumber(values, "limit", 1, maxLimit, limit, &errors); !ok {
+		return nil, errors
+	}
+	offset, cursor := values.Get("offset"), values.Get("cursor")
+	if offset != "" && cursor != "" {
+		errors = append(errors, NewError("exclusive_fields", "", nil, map[string]interface{}{"fields": []string{"offset", "cursor"}}))
+		return nil, errors
+	}
+	page.Skip, _ = pageNumber(values, "offset", 0, math.MaxInt32, 0, &errors)
+	if cursor != "" {
+		page.After = decodeCursor(profiled, cursor, page.Sort, rights, opt, &errors)
+	}
+	return page, errors
+}
+
+// this private function returns the stored paths of the fields the user may sort on, by client path
+func sortableFields(schema *Schema, rules PageRules, rights int, opt Options) map[string]string {
+	checker := collaboratorsOf(opt).Rights
+	sortable := make(map[string]string)
+	for path, validator := range schema.Validators {
+		if len(rules.Sortable) > 0 && !containsString(rules.Sortable, path) {
+			continue
+		}
+		if checker.CheckRights(rights, validator.Rights[GET]) {
+			in, stored := validator.Paths(path, INIT)
+			sortable[in] = stored
+		}
+	}
+	return sortable
+}
+
+// this private function reads an integer parameter within the boundaries, the default one if absent
+func pageNumber(values url.Values, name string, min int, max int, byDefault int, errors *[]*DataError) (int, bool) {
+	param := values.Get(name)
+	if param == "" {
+		return byDefault, true
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		*errors = append(*errors, NewError("type_mismatch", name, param, map[string]interface{}{"expected": "int"}))
+		return 0, false
+	}
+	if n < min || n > max {
+		*errors = append(*errors, NewError("out_of_boundaries", name, n, map[string]interface{}{"min": min, "max": max}))
+		return 0, false
+	}
+	return n, true
+}
+
+// this private function decodes the cursor into the sort values the page starts after, by stored path
+// the cursor must hold a value for every sort field, and only for them
+func decodeCursor(schema *Schema, cursor string, sort []SortField, rights int, opt Options, errors *[]*DataError) map[string]interface{} {
+	invalid := NewError("invalid_format", "cursor", cursor, map[string]interface{}{"expected": "cursor"})
+	var values map[string]interface{}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		decoder := json.NewDecoder(strings.NewReader(string(decoded)))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	}
+	if err != nil || len(values) != len(sort) {
+		*errors = append(*errors, invalid)
+		return nil
+	}
+
+	fields := submittedFields(schema)
+	after := make(map[string]interface{}, len(sort))
+	for _, field := range sort {
+		value, ok := values[field.Field]
+		if !ok {
+			*errors = append(*errors, invalid)
+			return nil
+		}
+		after[field.Path] = filterOperand(schema.Validators[fields[field.Field]], "cursor."+field.Field, value, false, rights, opt, errors)
+	}
+	return after
+}
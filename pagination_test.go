@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidatePage(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"createdAt": {Type: "time.Time"},
+		"name":      {Type: "string", Target: "n"},
+		"id":        {Type: "string"},
+		"secret":    {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+	}}
+	rules := PageRules{MaxLimit: 50, DefaultSort: "-createdAt,id"}
+
+	page, errors := ValidatePage(url.Values{}, schema, rules, Options{})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if page.Limit != 20 || !reflect.DeepEqual(page.MgoSort(), []string{"-createdAt", "id"}) {
+		t.Errorf("expected the default paging, got %+v", page)
+	}
+	page, _ = ValidatePage(url.Values{"sort": {"name"}}, schema, rules, Options{})
+	if !reflect.DeepEqual(page.MgoSort(), []string{"n"}) {
+		t.Errorf("expected the stored path, got %v", page.MgoSort())
+	}
+
+	// the cursor of the next page holds the sort values of the last document
+	cursor := page.Cursor(map[string]interface{}{"n": "bob", "id": "x"})
+	page, errors = ValidatePage(url.Values{"sort": {"name"}, "cursor": {cursor}, "limit": {"5"}}, schema, rules, Options{})
+	if len(errors) > 0 || page.Limit != 5 || page.After["n"] != "bob" {
+		t.Errorf("expected the page after bob, got %+v and %v", page, errors)
+	}
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor = (&Page{Sort: []SortField{{Field: "createdAt", Path: "createdAt", Order: -1}, {Field: "id", Path: "id", Order: 1}}}).Cursor(map[string]interface{}{"createdAt": created, "id": "x"})
+	page, errors = ValidatePage(url.Values{"cursor": {cursor}}, schema, rules, Options{})
+	if len(errors) > 0 || !reflect.DeepEqual(page.After, map[string]interface{}{"createdAt": created, "id": "x"}) {
+		t.Errorf("expected the time to be parsed back, got %+v and %v", page, errors)
+	}
+
+	refused := []struct {
+		values url.Values
+		codes  []string
+	}{
+		{url.Values{"sort": {"secret"}, "limit": {"500"}}, []string{"not_sortable", "out_of_boundaries"}},
+		{url.Values{"limit": {"ten"}}, []string{"type_mismatch"}},
+		{url.Values{"offset": {"1"}, "cursor": {cursor}}, []string{"exclusive_fields"}},
+		{url.Values{"sort": {"name"}, "cursor": {cursor}}, []string{"invalid_format"}},
+	}
+	for _, test := range refused {
+		page, errors := ValidatePage(test.values, schema, rules, Options{})
+		if page != nil || len(errors) != len(test.codes) {
+			t.Errorf("%v: expected %v, got %v", test.values, test.codes, errors)
+			continue
+		}
+		for i, err := range errors {
+			if err.Code != test.codes[i] {
+				t.Errorf("%v: expected %v, got %v", test.values, test.codes, errors)
+			}
+		}
+	}
+}
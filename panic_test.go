@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateNeverPanics(t *testing.T) {
+	validators := map[string]*Validator{
+		"short":    {Type: "x", Field: "short"},
+		"strings":  {Type: "[]string"},
+		"city":     {Type: "string", Source: "address.city"},
+		"custom":   {Type: "string", Field: "custom", CustomTest: func(interface{}) (bool, *DataError) { return false, nil }},
+		"panicked": {Type: "string", CustomTest: func(interface{}) (bool, *DataError) { panic("boom") }},
+	}
+	document := map[string]interface{}{
+		"short":    []interface{}{"a"},
+		"strings":  []string{"a", "b"},
+		"address":  "Paris",
+		"custom":   "a",
+		"panicked": "a",
+	}
+	_, errors := Validate(validators, document, Options{Usage: INIT})
+
+	reasons := make(map[string]string)
+	for _, err := range errors {
+		reasons[err.Field] = err.Reason
+	}
+	expected := map[string]string{
+		"short":        "Type mismatch",
+		"address.city": "Type mismatch",
+		"custom":       "Custom test failed",
+		"panicked":     "Unexpected failure",
+	}
+	if len(reasons) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, errors)
+	}
+	for field, reason := range expected {
+		if reasons[field] != reason {
+			t.Errorf("%s: expected %q, got %q", field, reason, reasons[field])
+		}
+	}
+}
+
+func TestCallbackPanics(t *testing.T) {
+	schema := &Schema{
+		Validators: map[string]*Validator{
+			"custom":    {Type: "string", CustomTest: func(interface{}) (bool, *DataError) { panic("custom") }},
+			"default":   {Type: "string", Default: func(interface{}) interface{} { panic("default") }},
+			"transform": {Type: "string", Transform: func(interface{}) (interface{}, error) { panic("transform") }},
+			"safe":      {Type: "string"},
+			"at":        {Type: "time.Time"},
+		},
+	}
+	document := map[string]interface{}{"custom": "a", "transform": "b", "safe": "c", "at": "2020-01-01T00:00:00Z"}
+	options := Options{
+		Usage:        INIT,
+		TimeFactory:  func(time.Time) interface{} { panic("time") },
+		ErrorFactory: func(string, string, interface{}, map[string]interface{}) *DataError { panic("factory") },
+	}
+	dest, errors := schema.Validate(document, options)
+
+	// each panic only fails its own field, naming the callback, and the failing factory keeps the default errors
+	callbacks := make(map[string]interface{})
+	for _, err := range errors {
+		if err.Code != "internal_error" {
+			t.Errorf("%s: expected an internal error, got %v", err.Field, err)
+		}
+		callbacks[err.Field] = err.Params["callback"]
+	}
+	expected := map[string]interface{}{"custom": "CustomTest", "default": "Default", "transform": "Transform", "at": "TimeFactory"}
+	if !reflect.DeepEqual(callbacks, expected) {
+		t.Errorf("expected %v, got %v", expected, callbacks)
+	}
+	if dest["safe"] != "c" {
+		t.Errorf("expected the safe field to be validated, got %v", dest)
+	}
+
+	computed := &Schema{
+		Validators: map[string]*Validator{"safe": {Type: "string"}},
+		Computed:   map[string]func(map[string]interface{}) interface{}{"total": func(map[string]interface{}) interface{} { panic("computed") }},
+	}
+	if _, errors := computed.Validate(map[string]interface{}{"safe": "c"}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Field != "total" || errors[0].Params["callback"] != "Computed" {
+		t.Errorf("expected the computed panic on total, got %v", errors)
+	}
+}
+
+func TestInternalErrorFinished(t *testing.T) {
+	// two fields written at the same stored path, the second one under the string of the first one, fail the validation
+	schema := &Schema{Validators: map[string]*Validator{
+		"a": {Type: "string", Target: "x"},
+		"b": {Type: "string", Target: "x.y"},
+	}}
+	factory := func(code, field string, value interface{}, params map[string]interface{}) *DataError {
+		err := NewError(code, field, value, params)
+		err.Message = "built"
+		return err
+	}
+	opt := Options{Usage: INIT, Meta: map[string]interface{}{"request": "r1"}, ErrorFactory: factory, DocBaseURL: "https://docs.example.com/errors/"}
+	dest, errors := schema.Validate(map[string]interface{}{"a": "1", "b": "2"}, opt)
+	if len(dest) != 0 || len(errors) != 1 || errors[0].Code != "internal_error" {
+		t.Fatalf("expected an internal error, got %v %v", dest, errors)
+	}
+	if err := errors[0]; err.Meta["request"] != "r1" || err.Message != "built" || err.Docs == "" {
+		t.Errorf("expected the internal error to be finished, got %+v", err)
+	}
+}
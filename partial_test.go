@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestPartial(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":    {Type: "string", IsRequired: true},
+		"tags.of": {Type: "[]string", IsRequired: true},
+		"note":    {Type: "string"},
+	}}
+	tests := []struct {
+		doc    map[string]interface{}
+		opt    Options
+		fields []string
+	}{
+		{map[string]interface{}{}, Options{Usage: SET, Partial: true}, nil},
+		{map[string]interface{}{"note": nil}, Options{Usage: SET, Partial: true}, nil},
+		{map[string]interface{}{"name": nil, "tags": map[string]interface{}{"of": []interface{}{}}}, Options{Usage: SET, Partial: true}, []string{"name", "tags.of"}},
+		{map[string]interface{}{"tags": "flat"}, Options{Usage: SET, Partial: true}, []string{"tags.of"}},
+		{map[string]interface{}{"name": nil}, Options{Usage: SET}, nil},
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: INIT, Partial: true}, []string{"tags.of"}},
+	}
+	for _, test := range tests {
+		_, errors := schema.Validate(test.doc, test.opt)
+		fields := []string{}
+		for _, err := range errors {
+			fields = append(fields, err.Field)
+		}
+		if len(fields) != len(test.fields) || (len(fields) > 0 && fields[0] != test.fields[0]) {
+			t.Errorf("%v %+v: expected errors on %v, got %v", test.doc, test.opt, test.fields, errors)
+		}
+	}
+}
+
+func TestIsSubmitted(t *testing.T) {
+	doc := map[string]interface{}{"a": nil, "b": map[string]interface{}{"c": nil}, "d": "x"}
+	for path, submitted := range map[string]bool{"a": true, "b.c": true, "b.e": false, "d.e": false, "f": false, "f.g": false} {
+		if isSubmitted(doc, path) != submitted {
+			t.Errorf("%s: expected %v", path, submitted)
+		}
+	}
+}
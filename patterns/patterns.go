@@ -0,0 +1,53 @@
+// Package patterns holds vetted and precompiled regexps of common formats, registered when the package is imported
+// for the validators to reference them by name rather than copying them: a Validator.Regexp of "@slug" matches Slug
+//
+//	import _ "github.com/grebett/validation/patterns"
+//
+// the patterns only use the syntax RE2 and the JavaScript regexps share, so that the code generators can write them as is
+package patterns
+
+import (
+	"regexp"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+var (
+	// a calendar date as ISO 8601 writes it, e.g. "2024-02-29" – the days are not checked against the month
+	ISODate = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`)
+	// the hex string of a MongoDB ObjectId, e.g. "507f1f77bcf86cd799439011"
+	ObjectIdHex = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	// lower case words of letters and digits joined by single hyphens, e.g. "my-first-post"
+	Slug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// an international phone number in the E.164 format, e.g. "+33612345678"
+	E164 = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+	// a host name of RFC 1123 labels, e.g. "api.example.com" – the 253 characters limit of the whole name is not checked
+	Hostname = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+	// the standard base64 encoding of RFC 4648, padded, e.g. "aGVsbG8="
+	Base64 = regexp.MustCompile(`^([A-Za-z0-9+/]{4})*([A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$`)
+)
+
+// the names the patterns are registered under
+var names = map[string]*regexp.Regexp{
+	"isodate":     ISODate,
+	"objectidhex": ObjectIdHex,
+	"slug":        Slug,
+	"e164":        E164,
+	"hostname":    Hostname,
+	"base64":      Base64,
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function registers the patterns in the validation package
+func init() {
+	for name, pattern := range names {
+		validation.RegisterPattern(name, pattern)
+	}
+}
@@ -0,0 +1,38 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/grebett/validation"
+)
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		valid   []string
+		invalid []string
+	}{
+		{"isodate", []string{"2024-02-29", "1999-12-31"}, []string{"2024-13-01", "2024-1-01", "24-01-01"}},
+		{"objectidhex", []string{"507f1f77bcf86cd799439011"}, []string{"507f1f77bcf86cd79943901", "507f1f77bcf86cd79943901z"}},
+		{"slug", []string{"my-first-post", "a1"}, []string{"My-post", "a--b", "-a", ""}},
+		{"e164", []string{"+33612345678"}, []string{"0612345678", "+0612", "+1234567890123456"}},
+		{"hostname", []string{"api.example.com", "localhost"}, []string{"-a.com", "a..com", "a_b.com"}},
+		{"base64", []string{"aGVsbG8=", "aGVsbG8h", ""}, []string{"aGVsbG8", "a===", "a b="}},
+	}
+	for _, test := range tests {
+		schema := &validation.Schema{Validators: map[string]*validation.Validator{"value": {Type: "string", Regexp: "@" + test.name}}}
+		if errs := validation.CheckSchema(schema); len(errs) != 0 {
+			t.Fatalf("%s: %v", test.name, errs)
+		}
+		for _, value := range test.valid {
+			if _, errors := schema.Validate(map[string]interface{}{"value": value}, validation.Options{}); len(errors) != 0 {
+				t.Errorf("%s: expected %q to match, got %v", test.name, value, errors)
+			}
+		}
+		for _, value := range test.invalid {
+			if _, errors := schema.Validate(map[string]interface{}{"value": value}, validation.Options{}); len(errors) != 1 {
+				t.Errorf("%s: expected %q not to match, got %v", test.name, value, errors)
+			}
+		}
+	}
+}
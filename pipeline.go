@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function checks the client-influenced stages of an aggregation pipeline and returns them rewritten for the stored documents, e.g. for a reporting endpoint
+// only the $match stages – validated as ValidateFilter does –, the $project stages including or excluding fields, the $sort stages, and the $skip and $limit stages are accepted,
+// every field they reference being one of the schema the user has the GET rights for: the expressions, $lookup, $out... are refused
+func ValidatePipeline(schema DocumentValidator, pipeline []map[string]interface{}, opt Options) (validated []map[string]interface{}, errors DataErrors) {
+	errors = make([]*DataError, 0)
+	defer func() {
+		if r := recover(); r != nil {
+			validated, errors = nil, append(errors, internalError("", r))
+		}
+		finishErrors(errors, opt)
+	}()
+
+	root := documentSchema(schema)
+	opt.root = root
+	profiled, opt, minRights, err := applyProfile(root, opt)
+	if err != nil {
+		errors = append(errors, err)
+		return nil, errors
+	}
+	profiled = applyOverride(profiled, opt.Override)
+	rights := resolveRights(opt)
+	if rights < minRights {
+		errors = append(errors, NewError("insufficient_rights", "", nil, map[string]interface{}{"rights": minRights}))
+		return nil, errors
+	}
+
+	fields := submittedFields(profiled)
+	var stageErrors []*DataError
+	validated = make([]map[string]interface{}, len(pipeline))
+	for i, stage := range pipeline {
+		location := strconv.Itoa(i)
+		if len(stage) != 1 {
+			stageErrors = append(stageErrors, NewError("invalid_stage", location, nil, map[string]interface{}{"expected": "a single stage operator"}))
+			continue
+		}
+		for operator, spec := range stage {
+			location += "." + operator
+			switch operator {
+			case "$match":
+				filter, ok := spec.(map[string]interface{})
+				if !ok {
+					stageErrors = append(stageErrors, NewError("type_mismatch", location, typeName(spec), map[string]interface{}{"expected": "map[string]interface {}"}))
+					continue
+				}
+				if err := checkSize(filter, opt.MaxDepth, opt.MaxFields); err != nil {
+					err.Field = location
+					stageErrors = append(stageErrors, err)
+					continue
+				}
+				validated[i] = map[string]interface{}{operator: validateFilter(profiled, fields, filter, location+".", rights, opt, &stageErrors)}
+			case "$project", "$sort":
+				validated[i] = map[string]interface{}{operator: stageFields(profiled, fields, operator, spec, location, rights, opt, &stageErrors)}
+			case "$skip", "$limit":
+				if n, ok := spec.(json.Number); ok {
+					spec, _ = n.Int64()
+				}
+				if n, _, _, ok := integerValue(spec); !ok || n < 0 || (operator == "$limit" && n == 0) {
+					stageErrors = append(stageErrors, NewError("invalid_stage", location, spec, map[string]interface{}{"expected": "a positive integer"}))
+					continue
+				}
+				validated[i] = map[string]interface{}{operator: spec}
+			default:
+				stageErrors = append(stageErrors, NewError("invalid_stage", location, nil, map[string]interface{}{"operator": operator}))
+			}
+		}
+	}
+	if len(stageErrors) > 0 {
+		errors = append(errors, stageErrors...)
+		return nil, errors
+	}
+	return validated, errors
+}
+
+// this private function validates the fields of a $project or $sort stage, and returns the stage for the stored paths
+// a $project field is included or excluded with 0, 1 or a bool, a $sort one ordered with 1 or -1: the expressions could reveal the protected fields
+func stageFields(schema *Schema, fields map[string]string, operator string, spec interface{}, location string, rights int, opt Options, errors *[]*DataError) map[string]interface{} {
+	document, ok := spec.(map[string]interface{})
+	if !ok || len(document) == 0 {
+		*errors = append(*errors, NewError("invalid_stage", location, nil, map[string]interface{}{"expected": "a non-empty document"}))
+		return nil
+	}
+	checker := collaboratorsOf(opt).Rights
+	validated := make(map[string]interface{}, len(document))
+	for _, key := range sortedKeys(document) {
+		field := location + "." + key
+		path, ok := fields[key]
+		if !ok && !(operator == "$project" && key == "_id") {
+			*errors = append(*errors, NewError("unknown_field", field, nil, nil))
+			continue
+		}
+		value := document[key]
+		if n, ok := value.(json.Number); ok {
+			value, _ = n.Int64()
+		}
+		n, _, _, isInteger := integerValue(value)
+		flag, isBool := value.(bool)
+		included := isBool && flag || isInteger && n == 1
+		switch {
+		case operator == "$sort" && isInteger && (n == 1 || n == -1):
+		case operator == "$project" && (isBool || isInteger && (n == 0 || n == 1)):
+		default:
+			*errors = append(*errors, NewError("invalid_stage", field, value, map[string]interface{}{"operator": operator}))
+			continue
+		}
+
+		stored := key
+		if validator, ok := schema.Validators[path]; ok {
+			// an excluded field reveals nothing
+			if (operator == "$sort" || included) && !checker.CheckRights(rights, validator.Rights[GET]) {
+				*errors = append(*errors, NewError("insufficient_rights", field, nil, map[string]interface{}{"rights": validator.Rights[GET]}))
+				continue
+			}
+			_, stored = validator.Paths(path, INIT)
+		}
+		validated[stored] = value
+	}
+	return validated
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidatePipeline(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"age":    {Type: "int64", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"name":   {Type: "string", Target: "n"},
+		"secret": {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+	}}
+
+	// an excluded field reveals nothing, so its rights are not required
+	pipeline := []map[string]interface{}{
+		{"$match": map[string]interface{}{"name": "bob", "age": map[string]interface{}{"$gte": json.Number("18")}}},
+		{"$project": map[string]interface{}{"name": 1, "age": true, "_id": 0, "secret": 0}},
+		{"$sort": map[string]interface{}{"age": json.Number("-1")}},
+		{"$skip": json.Number("10")},
+		{"$limit": 5},
+	}
+	validated, errors := ValidatePipeline(schema, pipeline, Options{UserRights: USER})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := []map[string]interface{}{
+		{"$match": map[string]interface{}{"n": "bob", "age": map[string]interface{}{"$gte": int64(18)}}},
+		{"$project": map[string]interface{}{"n": 1, "age": true, "_id": 0, "secret": 0}},
+		{"$sort": map[string]interface{}{"age": int64(-1)}},
+		{"$skip": int64(10)},
+		{"$limit": 5},
+	}
+	if !reflect.DeepEqual(validated, expected) {
+		t.Errorf("expected %v, got %v", expected, validated)
+	}
+
+	refused := []map[string]interface{}{
+		{"$match": map[string]interface{}{"secret": "x"}},
+		{"$project": map[string]interface{}{"secret": 1, "age": "$secret"}},
+		{"$sort": map[string]interface{}{"secret": 1}},
+		{"$lookup": map[string]interface{}{}},
+		{"$limit": 0},
+		{"$sort": 1, "$skip": 1},
+	}
+	validated, errors = ValidatePipeline(schema, refused, Options{UserRights: USER})
+	fields := []string{"0.$match.secret", "1.$project.age", "1.$project.secret", "2.$sort.secret", "3.$lookup", "4.$limit", "5"}
+	if validated != nil || len(errors) != len(fields) {
+		t.Fatalf("expected errors on %v, got %v", fields, errors)
+	}
+	for i, err := range errors {
+		if err.Field != fields[i] {
+			t.Errorf("error %d: expected %q, got %q", i, fields[i], err.Field)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownProfile(t *testing.T) {
+	schema := &Schema{
+		Validators: map[string]*Validator{"name": {Type: "string"}},
+		Profiles:   map[string]*Profile{"signup": {Usage: INIT, Fields: []string{"name"}}},
+	}
+	opt := Options{Usage: INIT, Profile: "admin"}
+	unknown := func(name string, errors DataErrors) {
+		if len(errors) != 1 || errors[0].Code != "unknown_profile" || errors[0].Value != "admin" {
+			t.Errorf("%s: expected the unknown profile, got %v", name, errors)
+		}
+	}
+
+	dest, errors := schema.Validate(map[string]interface{}{"name": "Ada"}, opt)
+	if len(dest) != 0 {
+		t.Errorf("expected nothing validated, got %v", dest)
+	}
+	unknown("Validate", errors)
+	_, errors = ValidateField(schema, "name", "Ada", opt)
+	unknown("ValidateField", errors)
+	_, errors = ValidateFilter(schema, map[string]interface{}{"name": "Ada"}, opt)
+	unknown("ValidateFilter", errors)
+	_, errors = ValidatePipeline(schema, []map[string]interface{}{{"$limit": 10}}, opt)
+	unknown("ValidatePipeline", errors)
+	_, errors = ValidatePage(url.Values{"sort": {"name"}}, schema, PageRules{}, opt)
+	unknown("ValidatePage", errors)
+	_, errors = ApplyDefaultsDeep(schema, map[string]interface{}{"name": "Ada"}, nil, opt)
+	unknown("ApplyDefaultsDeep", errors)
+	if projection := Projection(schema, opt); !reflect.DeepEqual(projection, map[string]interface{}{"_id": 1}) {
+		t.Errorf("expected only _id to be projected, got %v", projection)
+	}
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"sort"
+	"strings"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the MongoDB projection of the stored fields the user may read, {path: 1}, so the queries never fetch the other ones
+// it complements the redaction of the responses (Serialize, GET validation), which still runs on the fetched documents:
+// the fields readable by the OWNER are projected for a USER when the options have an OwnerField, since the ownership is only known once fetched,
+// the masked fields are projected to be masked, and the owner, version and soft-delete fields are projected for the rules reading them
+// _id is excluded unless a validator grants it; an inclusion projection cannot be empty, so only _id is projected if no field is readable, e.g. with an unknown profile
+func Projection(schema DocumentValidator, opt Options) map[string]interface{} {
+	s, opt, _, err := applyProfile(documentSchema(schema), opt)
+	if err != nil {
+		// nothing is readable through an unknown profile
+		return map[string]interface{}{"_id": 1}
+	}
+	s = applyOverride(s, opt.Override)
+	rights := opt.UserRights
+	if rights == USER && opt.OwnerField != "" && opt.UserID != nil {
+		rights = OWNER
+	}
+
+	var paths []string
+	for _, path := range s.sortedPaths() {
+		validator := s.Validators[path]
+		if validator.CheckRights(rights, validator.Rights[GET]) || validator.Mask != nil {
+			stored, _ := validator.Paths(path, GET)
+			paths = append(paths, stored)
+		}
+	}
+	if len(paths) > 0 {
+		for _, path := range []string{opt.OwnerField, s.VersionField, s.IdempotencyField} {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+		if s.SoftDelete != nil {
+			paths = append(paths, s.SoftDelete.field())
+		}
+	}
+
+	// MongoDB refuses a path along with one of its parents
+	sort.Strings(paths)
+	projection := make(map[string]interface{}, len(paths)+1)
+	var parent string
+	for _, path := range paths {
+		if parent != "" && (path == parent || strings.HasPrefix(path, parent+".")) {
+			continue
+		}
+		projection[path] = 1
+		parent = path
+	}
+	if len(projection) == 0 {
+		projection["_id"] = 1
+	} else if _, ok := projection["_id"]; !ok {
+		projection["_id"] = 0
+	}
+	return projection
+}
@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProjection(t *testing.T) {
+	schema := &Schema{VersionField: "version", Validators: map[string]*Validator{
+		"name":      {Type: "string"},
+		"email":     {Type: "string", Rights: [3]int{0, OWNER, 0}},
+		"secret":    {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+		"card":      {Type: "string", Rights: [3]int{0, ADMIN, 0}, Mask: func(interface{}) interface{} { return "****" }},
+		"address":   {Type: "map[string]interface {}"},
+		"address.a": {Type: "string"},
+		"displayed": {Type: "string", Source: "shown", Target: "stored"},
+	}}
+
+	// the masked fields are fetched to be masked, the children of a projected field are not repeated
+	expected := map[string]interface{}{"name": 1, "card": 1, "address": 1, "stored": 1, "version": 1, "_id": 0}
+	if projection := Projection(schema, Options{UserRights: USER}); !reflect.DeepEqual(projection, expected) {
+		t.Errorf("expected %v, got %v", expected, projection)
+	}
+	// the ownership is only known once fetched
+	projection := Projection(schema, Options{UserRights: USER, OwnerField: "owner", UserID: "u"})
+	if projection["email"] != 1 || projection["owner"] != 1 {
+		t.Errorf("expected the owner fields to be projected, got %v", projection)
+	}
+
+	hidden := &Schema{Validators: map[string]*Validator{"secret": {Type: "string", Rights: [3]int{0, ADMIN, 0}}}}
+	if projection := Projection(hidden, Options{UserRights: USER}); !reflect.DeepEqual(projection, map[string]interface{}{"_id": 1}) {
+		t.Errorf("expected only _id, got %v", projection)
+	}
+}
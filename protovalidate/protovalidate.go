@@ -0,0 +1,127 @@
+// Package protovalidate runs the schemas of the validation package against Protocol Buffers messages, for the gRPC services
+// the messages are walked through protoreflect and turned into documents whose keys are the proto field names, so the schemas are keyed by paths like "address.zip_code"
+package protovalidate
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grebett/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the document form of the message – see validation.Normalize for the representation of the values
+// only the populated fields are written: a proto3 scalar holding its zero value is missing, as on the wire
+// the enums are written as their value name (their number if unknown), the google.protobuf.Timestamp messages as RFC 3339 strings
+func Decode(msg proto.Message) (map[string]interface{}, error) {
+	normalized, err := validation.Normalize(message(msg.ProtoReflect()))
+	if err != nil {
+		return nil, err
+	}
+	return normalized.(map[string]interface{}), nil
+}
+
+// This function runs the schema against the document form of the message – see Decode and validation.Validate
+// a message that cannot be decoded is reported as a single "invalid_payload" error
+func Validate(msg proto.Message, schema validation.DocumentValidator, opt validation.Options) (map[string]interface{}, validation.DataErrors) {
+	doc, err := Decode(msg)
+	if err != nil {
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"format": "protobuf", "error": err.Error()})}
+	}
+	return schema.Validate(doc, opt)
+}
+
+// This function returns the errors as a google.rpc.BadRequest detail, to attach to an INVALID_ARGUMENT status
+// returns nil if there are no errors
+func BadRequest(errors []*validation.DataError) *errdetails.BadRequest {
+	if len(errors) == 0 {
+		return nil
+	}
+	request := &errdetails.BadRequest{FieldViolations: make([]*errdetails.BadRequest_FieldViolation, 0, len(errors))}
+	for _, err := range errors {
+		request.FieldViolations = append(request.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldPath(err),
+			Description: err.Reason,
+		})
+	}
+	return request
+}
+
+// this private function returns the path of the error in the google.rpc.BadRequest syntax: "items[2].name"
+func fieldPath(err *validation.DataError) string {
+	if len(err.Path) == 0 {
+		return err.Field
+	}
+	var path strings.Builder
+	for i, segment := range err.Path {
+		if segment.IsIndex {
+			path.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+			continue
+		}
+		if i > 0 {
+			path.WriteByte('.')
+		}
+		path.WriteString(segment.Key)
+	}
+	return path.String()
+}
+
+// this private function returns the populated fields of the message, by proto name
+func message(msg protoreflect.Message) interface{} {
+	if msg.Descriptor().FullName() == "google.protobuf.Timestamp" {
+		fields := msg.Descriptor().Fields()
+		seconds := msg.Get(fields.ByName("seconds")).Int()
+		nanos := msg.Get(fields.ByName("nanos")).Int()
+		return time.Unix(seconds, nanos)
+	}
+
+	doc := make(map[string]interface{})
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		doc[string(field.Name())] = fieldValue(field, value)
+		return true
+	})
+	return doc
+}
+
+// this private function returns the Go value of a field: a slice for a repeated field, a map for a map field
+func fieldValue(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch {
+	case field.IsList():
+		list := value.List()
+		items := make([]interface{}, list.Len())
+		for i := range items {
+			items[i] = singular(field, list.Get(i))
+		}
+		return items
+	case field.IsMap():
+		entries := make(map[string]interface{})
+		value.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			entries[key.String()] = singular(field.MapValue(), value)
+			return true
+		})
+		return entries
+	}
+	return singular(field, value)
+}
+
+// this private function returns the Go value of a single field value
+func singular(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return message(value.Message())
+	case protoreflect.EnumKind:
+		number := value.Enum()
+		if name := field.Enum().Values().ByNumber(number); name != nil {
+			return string(name.Name())
+		}
+		return int32(number)
+	}
+	return value.Interface()
+}
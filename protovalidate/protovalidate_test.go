@@ -0,0 +1,29 @@
+package protovalidate
+
+import (
+	"testing"
+
+	"github.com/grebett/validation"
+)
+
+func TestBadRequest(t *testing.T) {
+	if BadRequest(nil) != nil {
+		t.Error("expected no detail without errors")
+	}
+
+	errors := validation.DataErrors{
+		{Reason: "Type mismatch", Field: "items", Path: append(validation.ParsePath("items"), validation.PathSegment{Index: 2, IsIndex: true}, validation.PathSegment{Key: "name"})},
+		{Reason: "Required", Field: "address.zip_code", Path: validation.ParsePath("address.zip_code")},
+		{Reason: "Insufficient rights"},
+	}
+	expected := []string{"items[2].name", "address.zip_code", ""}
+	request := BadRequest(errors)
+	if len(request.FieldViolations) != len(expected) {
+		t.Fatalf("expected %d violations, got %v", len(expected), request.FieldViolations)
+	}
+	for i, violation := range request.FieldViolations {
+		if violation.Field != expected[i] || violation.Description != errors[i].Reason {
+			t.Errorf("violation %d: expected %q, got %q: %q", i, expected[i], violation.Field, violation.Description)
+		}
+	}
+}
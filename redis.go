@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates a document stored as a Redis hash, e.g. the reply of HGETALL, the fields being named by the validator paths
+// the strings are converted into the validator types as for ValidateEnv, the arrays and the maps being read as JSON
+// the outcome is returned as the fields of an HSET, the sub-documents flattened into dotted fields and the arrays and maps of the validators encoded as JSON,
+// along with the fields to HDEL, the ones Unset on SET – the version field of a SET holds the next version, to be written under WATCH
+func ValidateHash(hash map[string]string, schema DocumentValidator, opt Options) (map[string]string, []string, DataErrors) {
+	validators := schemaValidators(schema)
+	doc := make(map[string]interface{})
+	for path, validator := range validators {
+		in, _ := validator.Paths(path, opt.Usage)
+		str, ok := hash[in]
+		if !ok {
+			continue
+		}
+		if err := tools.WriteDeep(doc, in, parseHashField(validator.Type, str)); err != nil {
+			// the path goes through another field, which is reported by the validation
+			continue
+		}
+	}
+	dest, errors := schema.Validate(doc, opt)
+	if len(errors) > 0 {
+		return nil, nil, errors
+	}
+
+	// the documents of the validators are values, the other ones the nesting of the paths
+	values := make(map[string]bool, len(validators))
+	for path, validator := range validators {
+		_, out := validator.Paths(path, opt.Usage)
+		values[out] = true
+	}
+	fields := make(map[string]string, len(dest))
+	var removed []string
+	flattenHash(dest, "", values, fields, &removed)
+	sort.Strings(removed)
+	return fields, removed, errors
+}
+
+// this private function converts a hash field into the validator type: the arrays and the maps are JSON, the other values as ValidateEnv reads them
+func parseHashField(_type string, str string) interface{} {
+	if strings.HasPrefix(_type, "[]") && strings.HasPrefix(strings.TrimSpace(str), "[") {
+		decoder := json.NewDecoder(strings.NewReader(str))
+		decoder.UseNumber()
+		var value []interface{}
+		if decoder.Decode(&value) == nil {
+			return value
+		}
+	}
+	return parseString(_type, str)
+}
+
+// this private function writes the values of the document as hash fields, the sub-documents which are not values of a validator being flattened
+func flattenHash(document map[string]interface{}, prefix string, values map[string]bool, fields map[string]string, removed *[]string) {
+	for key, value := range document {
+		path := prefix + key
+		if sub, ok := value.(map[string]interface{}); ok && !values[path] {
+			flattenHash(sub, path+".", values, fields, removed)
+			continue
+		}
+		if value == Unset {
+			*removed = append(*removed, path)
+			continue
+		}
+		fields[path] = hashField(value)
+	}
+}
+
+// this private function returns the string a value is stored as in a hash field: the strings and the times as they are, any other value as JSON
+func hashField(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case VersionCheck:
+		return strconv.FormatInt(value.Expected+1, 10)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	// the types encoded as JSON strings, such as the ObjectIds, are stored unquoted
+	var str string
+	if json.Unmarshal(encoded, &str) == nil {
+		return str
+	}
+	return string(encoded)
+}
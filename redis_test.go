@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateHash(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":         {Type: "string", IsRequired: true},
+		"age":          {Type: "int64", Boundaries: Boundaries{Min: 0, Max: 150}},
+		"active":       {Type: "bool"},
+		"tags":         {Type: "[]string"},
+		"address.city": {Type: "string"},
+		"meta":         {Type: "map[string]interface {}"},
+		"born":         {Type: "time.Time"},
+	}}
+	hash := map[string]string{
+		"name": "bob", "age": "42", "active": "true", "tags": `["a","b,c"]`, "address.city": "Paris", "meta": `{"x":1}`, "born": "2000-01-02T03:04:05Z",
+	}
+
+	// the stored hash reads back as it was written
+	fields, removed, errors := ValidateHash(hash, schema, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if !reflect.DeepEqual(fields, hash) || len(removed) > 0 {
+		t.Errorf("expected %v, got %v and %v", hash, fields, removed)
+	}
+
+	if _, _, errors := ValidateHash(map[string]string{"name": "bob", "age": "x"}, schema, Options{Usage: INIT}); len(errors) != 1 || errors[0].Field != "age" {
+		t.Errorf("expected a type mismatch on age, got %v", errors)
+	}
+	// the arrays which are not JSON are read as ValidateEnv reads them
+	fields, _, errors = ValidateHash(map[string]string{"tags": "a,b"}, schema, Options{Usage: SET})
+	if len(errors) > 0 || fields["tags"] != `["a","b"]` {
+		t.Errorf("expected the comma-separated tags, got %v and %v", fields, errors)
+	}
+}
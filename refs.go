@@ -0,0 +1,44 @@
+package validation
+
+import "fmt"
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function validates the sub-document, or the array of sub-documents, against the fields validators of the referenced schema
+// the nesting of the references is limited by Options.MaxRefDepth; the sub-documents being validated as a whole, on SET the INIT rules apply
+func checkRef(validator *Validator, in string, value interface{}, rights int, opt Options, errors *[]*DataError) (interface{}, bool) {
+	if opt.MaxRefDepth > 0 && opt.refDepth >= opt.MaxRefDepth {
+		*errors = append(*errors, NewError("max_depth_exceeded", in, opt.MaxRefDepth, map[string]interface{}{"max": opt.MaxRefDepth}))
+		return value, false
+	}
+	referenced := resolveRef(opt.root, validator.Ref)
+	if referenced == nil {
+		panic(fmt.Errorf("validation: unknown schema reference %q", validator.Ref))
+	}
+	schema := &Schema{Validators: referenced.Validators}
+	opt.refDepth++
+
+	document, ok := value.(map[string]interface{})
+	if !ok {
+		return checkElements(schema, in, value, rights, opt, errors)
+	}
+	validated, documentErrors := validate(schema, document, itemOptions(opt, rights, true))
+	if len(documentErrors) > 0 {
+		*errors = append(*errors, relocateErrors(documentErrors, in, ParsePath(in))...)
+		return value, false
+	}
+	return validated, true
+}
+
+// this private function returns the schema of the reference: "#" is the root schema of the validation, any other name one of its Definitions
+func resolveRef(root *Schema, ref string) *Schema {
+	if root == nil {
+		return nil
+	}
+	if ref == "#" {
+		return root
+	}
+	return root.Definitions[ref]
+}
@@ -0,0 +1,63 @@
+package validation
+
+import "testing"
+
+func TestRef(t *testing.T) {
+	schema := &Schema{
+		Validators: map[string]*Validator{
+			"text":     {Type: "string", IsRequired: true},
+			"replies":  {Type: "[]map[string]interface {}", Ref: "#"},
+			"category": {Type: "map[string]interface {}", Ref: "category"},
+		},
+		Definitions: map[string]*Schema{"category": {Validators: map[string]*Validator{
+			"name":   {Type: "string", IsRequired: true},
+			"parent": {Type: "map[string]interface {}", Ref: "category"},
+		}}},
+	}
+	if errors := CheckSchema(schema); len(errors) > 0 {
+		t.Fatalf("expected no schema errors, got %v", errors)
+	}
+	doc := map[string]interface{}{
+		"text":     "a",
+		"replies":  []interface{}{map[string]interface{}{"text": "b", "replies": []interface{}{map[string]interface{}{}}}},
+		"category": map[string]interface{}{"name": "x", "parent": map[string]interface{}{"name": "y", "parent": map[string]interface{}{}}},
+	}
+	_, errors := schema.Validate(doc, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Field != "category.parent.parent.name" || errors[1].Field != "replies.0.replies.0.text" {
+		t.Fatalf("expected the nested required fields, got %v", errors)
+	}
+	for _, err := range errors {
+		if err.Code != "required" {
+			t.Errorf("expected a required error, got %v", err)
+		}
+	}
+
+	// the nesting of the references is limited
+	_, errors = schema.Validate(doc, Options{Usage: INIT, MaxRefDepth: 1})
+	if len(errors) != 2 || errors[0].Field != "category.parent" || errors[1].Field != "replies.0.replies" || errors[0].Code != "max_depth_exceeded" {
+		t.Errorf("expected the depth to be exceeded, got %v", errors)
+	}
+
+	// the references resolve against the root schema in ValidateField too
+	if _, errors := ValidateField(schema, "replies", []interface{}{map[string]interface{}{}}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Field != "replies.0.text" {
+		t.Errorf("expected the reply text to be required, got %v", errors)
+	}
+
+	if clone := schema.Clone(); clone.Definitions["category"] == schema.Definitions["category"] {
+		t.Errorf("expected the definitions to be cloned")
+	}
+}
+
+func TestRefCheck(t *testing.T) {
+	schema := &Schema{
+		Validators:  map[string]*Validator{"a": {Type: "string", Ref: "unknown"}},
+		Definitions: map[string]*Schema{"broken": {Validators: map[string]*Validator{"b": {Type: "x y"}}}},
+	}
+	errors := CheckSchema(schema)
+	if len(errors) != 3 {
+		t.Fatalf("expected the unknown reference, the non-document type and the broken definition, got %v", errors)
+	}
+	if field := errors[2].(*DataError).Field; field != "#broken.b" {
+		t.Errorf("expected the definition error under its name, got %q", field)
+	}
+}
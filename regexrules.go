@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"log"
+	"regexp"
+)
+
+//***********************************************************************************
+//                                   CONSTANTS
+//***********************************************************************************
+
+// the modes of the regexp rules – see RegexRule
+const (
+	MustMatch = iota
+	MustNotMatch
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is one of the patterns a string is checked against, along with the Regexp of the validator – see Validator.Regexps
+// e.g. a password must match `[0-9]` and must not match `(?i)password`, each rule telling the user what is wrong
+type RegexRule struct {
+	Pattern string // the pattern, or the name of a registered one, e.g. "@slug" – see RegisterPattern
+	Mode    int    // MustMatch (the default) or MustNotMatch
+	Message string `json:",omitempty"` // the message of the error, in place of the one of Options.Messages
+
+	compiled *regexp.Regexp // the compiled Pattern, shared by the clones of the validator
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method tells whether the string passes the rule: it matches the pattern in the MustMatch mode, or does not in the MustNotMatch one
+func (r *RegexRule) Check(str string) (bool, error) {
+	compiled := r.compiled
+	// the compiled pattern is only used if the pattern has not been changed since
+	if compiled == nil || compiled.String() != ResolvePattern(r.Pattern) {
+		var err error
+		if compiled, err = compilePattern(r.Pattern); err != nil {
+			return false, err
+		}
+	}
+	return compiled.MatchString(str) == (r.Mode != MustNotMatch), nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns a copy of the rules, their patterns compiled
+func cloneRegexRules(rules []RegexRule) []RegexRule {
+	cloned := append([]RegexRule(nil), rules...)
+	for i := range cloned {
+		if cloned[i].compiled == nil {
+			// an invalid pattern is reported when checked, as the Regexp one
+			cloned[i].compiled, _ = compilePattern(cloned[i].Pattern)
+		}
+	}
+	return cloned
+}
+
+// this private function checks the string against the regexp rules of the validator, every failing one being reported
+func checkRegexRules(validator *Validator, value string, errors *[]*DataError) bool {
+	valid := true
+	for i := range validator.Regexps {
+		rule := &validator.Regexps[i]
+		ok, err := rule.Check(value)
+		if err != nil {
+			log.Panic(err) // if the regexp is false, panic!
+		}
+		if ok {
+			continue
+		}
+		code := "regex_not_match"
+		if rule.Mode == MustNotMatch {
+			code = "regex_match"
+		}
+		dataErr := NewError(code, validator.Field, value, map[string]interface{}{"pattern": rule.Pattern})
+		dataErr.Message = rule.Message
+		*errors = append(*errors, dataErr)
+		valid = false
+	}
+	return valid
+}
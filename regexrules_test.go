@@ -0,0 +1,39 @@
+package validation
+
+import "testing"
+
+func TestRegexRules(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"password": {Type: "string", Regexp: "^.{4,}$", Regexps: []RegexRule{
+		{Pattern: "[0-9]", Message: "needs a digit"},
+		{Pattern: "(?i)password", Mode: MustNotMatch, Message: "must not contain password"},
+	}}}}
+	if _, errors := schema.Validate(map[string]interface{}{"password": "abcd1"}, Options{}); len(errors) != 0 {
+		t.Errorf("expected every rule to pass, got %v", errors)
+	}
+	_, errors := schema.Validate(map[string]interface{}{"password": "myPassword"}, Options{})
+	if len(errors) != 2 || errors[0].Code != "regex_not_match" || errors[0].Message != "needs a digit" || errors[1].Code != "regex_match" || errors[1].Message != "must not contain password" {
+		t.Errorf("expected both failing rules with their messages, got %v", errors)
+	}
+	_, errors = schema.Clone().Validate(map[string]interface{}{"password": "password1"}, Options{})
+	if len(errors) != 1 || errors[0].Params["pattern"] != "(?i)password" {
+		t.Errorf("expected the clone to check the rules, got %v", errors)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{"password": "ab"}, Options{}); len(errors) != 1 || errors[0].Params["pattern"] != "^.{4,}$" {
+		t.Errorf("expected the Regexp to be checked first, got %v", errors)
+	}
+
+	invalid := &Schema{Validators: map[string]*Validator{"password": {Type: "string", Regexps: []RegexRule{{Pattern: "("}, {Pattern: "a", Mode: 3}}}}}
+	if errs := CheckSchema(invalid); len(errs) != 2 {
+		t.Errorf("expected the invalid pattern and mode to be reported, got %v", errs)
+	}
+
+	changed := schema.Clone()
+	changed.Validators["password"].Regexps[0].Message = "a digit is missing"
+	if changes := DiffSchemas(schema, changed); len(changes) != 0 {
+		t.Errorf("expected a message change not to be reported, got %v", changes)
+	}
+	changed.Validators["password"].Regexps[0].Pattern = "[0-9]{2}"
+	if changes := DiffSchemas(schema, changed); len(changes) != 1 || changes[0].Kind != "regexps_changed" {
+		t.Errorf("expected the pattern change to be reported, got %v", changes)
+	}
+}
@@ -0,0 +1,289 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct holds the named schemas of a service, each of them versioned: registering a name again publishes a new version
+// it is safe for concurrent use, and can be exposed to the other services and front-ends with SchemaHandler
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string][]*registryEntry // the versions of the schemas, by name, the current one last
+}
+
+// This private struct is a registered version of a schema
+type registryEntry struct {
+	version  int
+	compiled *CompiledSchema
+	document []byte // the JSON description of the schema, as served by SchemaHandler
+	etag     string
+}
+
+// This private struct is the JSON description of a validator: its rules that are not functions
+// its keys are the Validator ones, so the description unmarshals into a Validator
+type validatorDescription struct {
+	Type            string
+	Field           string `json:",omitempty"`
+	Source          string `json:",omitempty"`
+	Target          string `json:",omitempty"`
+	Regexp          string `json:",omitempty"`
+	Rights          [3]int
+	Boundaries      Boundaries
+	IntBoundaries   *IntBoundaries `json:",omitempty"`
+	Decimal         *Decimal       `json:",omitempty"`
+	Money           *Money         `json:",omitempty"`
+	Boolish         bool           `json:",omitempty"`
+	RawJSON         bool           `json:",omitempty"`
+	MaxRawSize      int            `json:",omitempty"`
+	IsRequired      bool
+	RequiredOnSet   bool                             `json:",omitempty"`
+	Immutable       bool                             `json:",omitempty"`
+	Nullable        bool                             `json:",omitempty"`
+	Items           map[string]*validatorDescription `json:",omitempty"`
+	Tuple           []*validatorDescription          `json:",omitempty"`
+	UniqueBy        string                           `json:",omitempty"`
+	Ordered         *Ordered                         `json:",omitempty"`
+	Ref             string                           `json:",omitempty"`
+	Label           string                           `json:",omitempty"`
+	Labels          map[string]string                `json:",omitempty"`
+	DocSlugs        map[string]string                `json:",omitempty"`
+	AdditionalItems *validatorDescription            `json:",omitempty"`
+	SearchQuery     *SearchQuery                     `json:",omitempty"`
+	Format          string                           `json:",omitempty"`
+	HashOf          string                           `json:",omitempty"`
+	LocaleNumbers   bool                             `json:",omitempty"`
+	Schedule        *Schedule                        `json:",omitempty"`
+	Birthdate       *Birthdate                       `json:",omitempty"`
+	TimeWindow      *TimeWindow                      `json:",omitempty"`
+	DefaultNow      bool                             `json:",omitempty"`
+	Regexps         []RegexRule                      `json:",omitempty"`
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method compiles the schema and publishes it as the new current version of the name, whose number it returns
+// the functions of the validators (defaults, custom tests, transforms...) are not part of the published description
+func (r *Registry) Register(name string, schema *Schema) (int, error) {
+	compiled, err := schema.Compile()
+	if err != nil {
+		return 0, err
+	}
+	document, err := describeSchema(compiled.schema)
+	if err != nil {
+		return 0, fmt.Errorf("validation: cannot describe schema %q: %s", name, err)
+	}
+	sum := sha256.Sum256(document)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[string][]*registryEntry)
+	}
+	versions := r.entries[name]
+	entry := &registryEntry{version: len(versions) + 1, compiled: compiled, document: document}
+	entry.etag = fmt.Sprintf(`"%s-%d-%s"`, name, entry.version, hex.EncodeToString(sum[:8]))
+	r.entries[name] = append(versions, entry)
+	return entry.version, nil
+}
+
+// This method returns the current version of the named schema, and its number
+func (r *Registry) Get(name string) (*CompiledSchema, int, bool) {
+	entry := r.entry(name, 0)
+	if entry == nil {
+		return nil, 0, false
+	}
+	return entry.compiled, entry.version, true
+}
+
+// This method returns the names of the registered schemas, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// This method returns the given version of the named schema, the current one if 0, nil if there is none
+func (r *Registry) entry(name string, version int) *registryEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.entries[name]
+	if len(versions) == 0 || version < 0 || version > len(versions) {
+		return nil
+	}
+	if version == 0 {
+		return versions[len(versions)-1]
+	}
+	return versions[version-1]
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns a new empty registry
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string][]*registryEntry)}
+}
+
+// This function returns the HTTP handler serving the registered schemas as JSON, to mount with http.StripPrefix
+//   - GET / returns the current version number of every schema, by name
+//   - GET /{name} returns the current version of the schema, GET /{name}?version=N a previous one
+//
+// a schema is served as a validation.Schema value, so it unmarshals into one; its version is sent in the Schema-Version header
+// the responses carry an ETag, and the requests whose If-None-Match matches it are answered 304 Not Modified
+func SchemaHandler(registry *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.Trim(r.URL.Path, "/")
+		if name == "" {
+			index := make(map[string]int)
+			for _, name := range registry.Names() {
+				if _, version, ok := registry.Get(name); ok {
+					index[name] = version
+				}
+			}
+			body, _ := json.Marshal(index)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Write(body)
+			return
+		}
+
+		version := 0
+		if query := r.URL.Query().Get("version"); query != "" {
+			var err error
+			if version, err = strconv.Atoi(query); err != nil || version < 1 {
+				http.Error(w, "invalid version", http.StatusBadRequest)
+				return
+			}
+		}
+		entry := registry.entry(name, version)
+		if entry == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("ETag", entry.etag)
+		w.Header().Set("Schema-Version", strconv.Itoa(entry.version))
+		w.Header().Set("Cache-Control", "no-cache")
+		if matchETag(r.Header.Get("If-None-Match"), entry.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(entry.document)))
+		if r.Method == http.MethodGet {
+			w.Write(entry.document)
+		}
+	})
+}
+
+// this private function returns the JSON description of the schema validators and profiles – see validatorDescription
+func describeSchema(schema *Schema) ([]byte, error) {
+	description := map[string]interface{}{"Validators": describeValidators(schema.Validators)}
+	if len(schema.Profiles) > 0 {
+		description["Profiles"] = schema.Profiles
+	}
+	if len(schema.ExclusiveGroups) > 0 {
+		description["ExclusiveGroups"] = schema.ExclusiveGroups
+	}
+	if len(schema.AnyOfGroups) > 0 {
+		description["AnyOfGroups"] = schema.AnyOfGroups
+	}
+	if len(schema.Definitions) > 0 {
+		definitions := make(map[string]interface{}, len(schema.Definitions))
+		for name, definition := range schema.Definitions {
+			definitions[name] = map[string]interface{}{"Validators": describeValidators(definition.Validators)}
+		}
+		description["Definitions"] = definitions
+	}
+	if len(schema.DependentRules) > 0 {
+		description["DependentRules"] = schema.DependentRules
+	}
+	if len(schema.DependentValidators) > 0 {
+		dependents := make(map[string]map[string]*validatorDescription, len(schema.DependentValidators))
+		for path, validators := range schema.DependentValidators {
+			dependents[path] = describeValidators(validators)
+		}
+		description["DependentValidators"] = dependents
+	}
+	if schema.MaxFields > 0 {
+		description["MaxFields"] = schema.MaxFields
+	}
+	if schema.SoftDelete != nil {
+		description["SoftDelete"] = schema.SoftDelete
+	}
+	if schema.VersionField != "" {
+		description["VersionField"] = schema.VersionField
+	}
+	if schema.IdempotencyField != "" {
+		description["IdempotencyField"] = schema.IdempotencyField
+	}
+	return json.Marshal(description)
+}
+
+// this private function returns the descriptions of the validators, the ones of the array items included
+func describeValidators(validators map[string]*Validator) map[string]*validatorDescription {
+	descriptions := make(map[string]*validatorDescription, len(validators))
+	for path, v := range validators {
+		descriptions[path] = describeValidator(v)
+	}
+	return descriptions
+}
+
+// this private function returns the description of a validator, the ones of the array items included
+func describeValidator(v *Validator) *validatorDescription {
+	description := &validatorDescription{
+		Type: v.Type, Field: v.Field, Source: v.Source, Target: v.Target, Label: v.Label, Labels: v.Labels, DocSlugs: v.DocSlugs, Regexp: v.Regexp,
+		Rights: v.Rights, Boundaries: v.Boundaries, IntBoundaries: v.IntBoundaries, Decimal: v.Decimal, Money: v.Money,
+		Boolish: v.Boolish, RawJSON: v.RawJSON, MaxRawSize: v.MaxRawSize, IsRequired: v.IsRequired, RequiredOnSet: v.RequiredOnSet, Immutable: v.Immutable, Nullable: v.Nullable,
+		UniqueBy: v.UniqueBy, Ordered: v.Ordered, Ref: v.Ref, SearchQuery: v.SearchQuery,
+		Format: v.Format, HashOf: v.HashOf, LocaleNumbers: v.LocaleNumbers, Schedule: v.Schedule, Birthdate: v.Birthdate,
+		TimeWindow: v.TimeWindow, DefaultNow: v.DefaultNow, Regexps: v.Regexps,
+	}
+	if v.Items != nil {
+		description.Items = describeValidators(v.Items)
+	}
+	for _, item := range v.Tuple {
+		description.Tuple = append(description.Tuple, describeValidator(item))
+	}
+	if v.AdditionalItems != nil {
+		description.AdditionalItems = describeValidator(v.AdditionalItems)
+	}
+	return description
+}
+
+// this private function tells whether the If-None-Match header lists the etag, or is "*"
+func matchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	first := &Schema{Validators: map[string]*Validator{"name": {Type: "string", IsRequired: true, Default: func(interface{}) interface{} { return "x" }}}}
+	if version, err := registry.Register("user", first); err != nil || version != 1 {
+		t.Fatalf("expected version 1, got %d %v", version, err)
+	}
+	if version, err := registry.Register("user", &Schema{Validators: map[string]*Validator{"name": {Type: "string"}}}); err != nil || version != 2 {
+		t.Fatalf("expected version 2, got %d %v", version, err)
+	}
+	if _, err := registry.Register("broken", &Schema{Validators: map[string]*Validator{"name": {Type: "string", Regexp: "("}}}); err == nil {
+		t.Errorf("expected the invalid schema to be refused")
+	}
+	registry.Register("address", &Schema{Validators: map[string]*Validator{"city": {Type: "string"}}})
+
+	if names := registry.Names(); len(names) != 2 || names[0] != "address" || names[1] != "user" {
+		t.Errorf("expected [address user], got %v", names)
+	}
+	compiled, version, ok := registry.Get("user")
+	if !ok || version != 2 || compiled.Schema().Validators["name"].IsRequired {
+		t.Errorf("expected the second version, got %d %v", version, ok)
+	}
+	if _, _, ok := registry.Get("unknown"); ok {
+		t.Errorf("expected no unknown schema")
+	}
+	if registry.entry("user", 3) != nil || registry.entry("user", -1) != nil || registry.entry("user", 1).version != 1 {
+		t.Errorf("unexpected versions lookup")
+	}
+
+	// the zero registry is usable too
+	var zero Registry
+	if version, err := zero.Register("user", first); err != nil || version != 1 {
+		t.Errorf("expected version 1, got %d %v", version, err)
+	}
+}
+
+func TestSchemaHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("user", &Schema{Validators: map[string]*Validator{"name": {Type: "string", IsRequired: true, Regexp: "^a"}}})
+	registry.Register("user", &Schema{Validators: map[string]*Validator{"name": {Type: "string"}}})
+	handler := SchemaHandler(registry)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != `{"user":2}` {
+		t.Errorf("expected the index, got %s", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/user?version=1", nil))
+	var schema Schema
+	if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil || !schema.Validators["name"].IsRequired || schema.Validators["name"].Regexp != "^a" {
+		t.Errorf("expected the first version, got %s %v", w.Body, err)
+	}
+	if w.Header().Get("Schema-Version") != "1" {
+		t.Errorf("expected the version header 1, got %q", w.Header().Get("Schema-Version"))
+	}
+
+	r := httptest.NewRequest("GET", "/user", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	etag := w.Header().Get("ETag")
+	r.Header.Set("If-None-Match", `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("expected 304 Not Modified, got %d", w.Code)
+	}
+
+	for target, code := range map[string]int{"/unknown": 404, "/user?version=3": 404, "/user?version=0": 400, "/user?version=x": 400} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != code {
+			t.Errorf("%s: expected %d, got %d", target, code, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/user", nil))
+	if w.Code != 405 || w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct fetches a schema served as JSON by a remote service – typically a SchemaHandler – and keeps it up to date
+// the refreshes are conditional requests (If-None-Match), and a failed refresh keeps the last good schema
+type SchemaLoader struct {
+	URL       string               // the URL of the schema
+	Client    *http.Client         // the client of the requests, http.DefaultClient if nil
+	Interval  time.Duration        // the delay between two refreshes, a minute if 0 – no refresh if negative
+	CacheFile string               // the file the last good schema is saved to, and loaded from when the service cannot be reached at startup
+	OnError   func(err error)      // this function is called with the errors of the refreshes, which are otherwise only kept for Err
+	OnUpdate  func(version string) // this function is called when a new version of the schema is loaded, with its Schema-Version header
+}
+
+// This struct is a schema loaded by a SchemaLoader: it validates the documents against the last good version of the remote schema
+// it is safe for concurrent use, the refreshes swapping the compiled schema atomically
+type RemoteSchema struct {
+	mu       sync.RWMutex
+	compiled *CompiledSchema
+	etag     string
+	version  string
+	err      error
+}
+
+// This private struct is the content of the cache file of a SchemaLoader
+type remoteCache struct {
+	ETag    string
+	Version string
+	Schema  json.RawMessage
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method loads the schema, from the service or else from the cache file, and refreshes it in the background until the context is done
+// an error is returned if neither the service nor the cache file provide a valid schema
+func (l *SchemaLoader) Load(ctx context.Context) (*RemoteSchema, error) {
+	remote := &RemoteSchema{}
+	if err := l.refresh(ctx, remote); err != nil {
+		if l.CacheFile == "" {
+			return nil, err
+		}
+		if cacheErr := l.loadCache(remote); cacheErr != nil {
+			return nil, fmt.Errorf("%s (cache: %s)", err, cacheErr)
+		}
+		remote.setErr(err)
+	}
+
+	interval := l.Interval
+	if interval == 0 {
+		interval = time.Minute
+	}
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := l.refresh(ctx, remote); err != nil && l.OnError != nil {
+						l.OnError(err)
+					}
+				}
+			}
+		}()
+	}
+	return remote, nil
+}
+
+// This method fetches the schema if it changed since the last request, and swaps it in if it is valid
+func (l *SchemaLoader) refresh(ctx context.Context, remote *RemoteSchema) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.URL, nil)
+	if err != nil {
+		return remote.setErr(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	remote.mu.RLock()
+	if remote.etag != "" {
+		req.Header.Set("If-None-Match", remote.etag)
+	}
+	remote.mu.RUnlock()
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return remote.setErr(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified {
+		return remote.setErr(nil)
+	}
+	if res.StatusCode != http.StatusOK {
+		return remote.setErr(fmt.Errorf("validation: cannot load schema %s: %s", l.URL, res.Status))
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return remote.setErr(err)
+	}
+
+	version := res.Header.Get("Schema-Version")
+	if err := remote.update(body, res.Header.Get("ETag"), version); err != nil {
+		return remote.setErr(fmt.Errorf("validation: cannot load schema %s: %s", l.URL, err))
+	}
+	if l.CacheFile != "" {
+		cache, _ := json.Marshal(&remoteCache{ETag: res.Header.Get("ETag"), Version: version, Schema: body})
+		if err := os.WriteFile(l.CacheFile, cache, 0o644); err != nil && l.OnError != nil {
+			l.OnError(err)
+		}
+	}
+	if l.OnUpdate != nil {
+		l.OnUpdate(version)
+	}
+	return remote.setErr(nil)
+}
+
+// This method loads the schema saved in the cache file
+func (l *SchemaLoader) loadCache(remote *RemoteSchema) error {
+	data, err := os.ReadFile(l.CacheFile)
+	if err != nil {
+		return err
+	}
+	var cache remoteCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return err
+	}
+	return remote.update(cache.Schema, cache.ETag, cache.Version)
+}
+
+// This method compiles the JSON schema and swaps it in; the current schema is kept if it is invalid
+func (r *RemoteSchema) update(data []byte, etag, version string) error {
+	schema := new(Schema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return err
+	}
+	compiled, err := schema.Compile()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.compiled, r.etag, r.version = compiled, etag, version
+	r.mu.Unlock()
+	return nil
+}
+
+// This method records the outcome of the last refresh and returns it
+func (r *RemoteSchema) setErr(err error) error {
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+	return err
+}
+
+// This method runs the current version of the schema against the provided data – see Validate
+func (r *RemoteSchema) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	return r.Compiled().Validate(_map, opt)
+}
+
+// This method returns the current version of the schema
+func (r *RemoteSchema) Compiled() *CompiledSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.compiled
+}
+
+// This method returns the Schema-Version header of the current version of the schema, if the service sent one
+func (r *RemoteSchema) Version() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// This method returns the error of the last refresh, nil if it succeeded: the schema in use is then the last good one
+func (r *RemoteSchema) Err() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function loads the schema served at the URL and refreshes it every minute until the context is done – see SchemaLoader
+func LoadSchemaURL(ctx context.Context, url string) (*RemoteSchema, error) {
+	return (&SchemaLoader{URL: url}).Load(ctx)
+}
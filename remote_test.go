@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaLoader(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("user", &Schema{Validators: map[string]*Validator{"name": {Type: "string", IsRequired: true}}})
+	handler := SchemaHandler(registry)
+	statuses := []int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		statuses = append(statuses, recorder.Code)
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorder.Code)
+		w.Write(recorder.Body.Bytes())
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := []string{}
+	loader := &SchemaLoader{URL: server.URL + "/user", Interval: -1, CacheFile: filepath.Join(t.TempDir(), "user.json"), OnUpdate: func(version string) { updates = append(updates, version) }}
+	remote, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, errors := remote.Validate(map[string]interface{}{}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "required" {
+		t.Errorf("expected the name to be required, got %v", errors)
+	}
+
+	// an unchanged schema is answered 304, a new version is swapped in
+	if err := loader.refresh(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	registry.Register("user", &Schema{Validators: map[string]*Validator{"name": {Type: "string"}}})
+	if err := loader.refresh(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	if _, errors := remote.Validate(map[string]interface{}{}, Options{Usage: INIT}); len(errors) != 0 || remote.Version() != "2" {
+		t.Errorf("expected the second version, got %v %q", errors, remote.Version())
+	}
+	if len(statuses) != 3 || statuses[1] != http.StatusNotModified || strings.Join(updates, ",") != "1,2" {
+		t.Errorf("expected 200, 304 and 200 with the updates 1 and 2, got %v %v", statuses, updates)
+	}
+
+	// the last good schema is kept when the service fails, and loaded from the cache file at startup
+	loader.URL = server.URL + "/unknown"
+	if err := loader.refresh(ctx, remote); err == nil || remote.Err() == nil || remote.Compiled() == nil {
+		t.Errorf("expected the refresh to fail and the schema to be kept, got %v", err)
+	}
+	cached, err := (&SchemaLoader{URL: server.URL + "/unknown", Interval: -1, CacheFile: loader.CacheFile}).Load(ctx)
+	if err != nil || cached.Version() != "2" || cached.Err() == nil {
+		t.Errorf("expected the cached second version, got %v", err)
+	}
+	if _, err := (&SchemaLoader{URL: server.URL + "/unknown", Interval: -1}).Load(ctx); err == nil {
+		t.Errorf("expected an error without a cache file")
+	}
+}
+
+func TestRemoteSchemaUpdate(t *testing.T) {
+	remote := &RemoteSchema{}
+	if err := remote.update([]byte(`{"Validators": {"name": {"Type": "string"}}}`), `"a"`, "1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{`{"Validators": `, `{"Validators": {"name": {"Type": "string", "Regexp": "("}}}`} {
+		if err := remote.update([]byte(data), `"b"`, "2"); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+	if remote.Version() != "1" || remote.etag != `"a"` {
+		t.Errorf("expected the first version to be kept, got %q", remote.Version())
+	}
+}
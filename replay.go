@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a recorded validation: the submitted document, the options, the hash of the schema and the outcome
+// the options that cannot be written in JSON (functions, context, overrides) are not recorded
+type Fixture struct {
+	Time       time.Time
+	SchemaHash string          // the hash of the schema the document was validated against – see SchemaHash
+	Input      json.RawMessage // the submitted document
+	Options    FixtureOptions
+	Result     json.RawMessage // the validated document
+	Errors     json.RawMessage // the errors
+}
+
+// This struct holds the recorded options of a fixture
+type FixtureOptions struct {
+	Usage      int
+	UserRights int
+	Existing   map[string]interface{} `json:",omitempty"`
+	OwnerField string                 `json:",omitempty"`
+	UserID     interface{}            `json:",omitempty"`
+	Profile    string                 `json:",omitempty"`
+	Strict     bool                   `json:",omitempty"`
+	MaxDepth   int                    `json:",omitempty"`
+	MaxFields  int                    `json:",omitempty"`
+}
+
+// This struct validates the documents against a schema, and records a sample of the validations as fixture files, to regression-test the schema changes with Replay
+// it is a DocumentValidator, to use in place of the schema where the real traffic is validated
+type Recorder struct {
+	Schema  DocumentValidator // the schema the documents are validated against
+	Dir     string            // the directory the fixtures are written to, one file per validation
+	Every   int               // one validation out of Every is recorded, all of them below 2
+	OnError func(err error)   // this function is called when a fixture cannot be written; the validation is not affected
+
+	count uint64
+}
+
+// This struct is the outcome of the replay of a fixture
+type ReplayResult struct {
+	File          string
+	Fixture       *Fixture
+	Result        map[string]interface{} // the document validated by the current schema
+	Errors        DataErrors             // the errors reported by the current schema
+	Changed       bool                   // whether the outcome differs from the recorded one
+	SchemaChanged bool                   // whether the current schema differs from the recorded one
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method validates the document against the schema, and records the validation if it is sampled
+func (r *Recorder) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	// the document is encoded first, since the validation may modify nested values through the custom tests
+	input, inputErr := json.Marshal(_map)
+	result, errors := r.Schema.Validate(_map, opt)
+
+	n := atomic.AddUint64(&r.count, 1)
+	if r.Every > 1 && n%uint64(r.Every) != 1 {
+		return result, errors
+	}
+	err := inputErr
+	if err == nil {
+		err = r.write(n, input, opt, result, errors)
+	}
+	if err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+	return result, errors
+}
+
+// This method writes the fixture of a validation
+func (r *Recorder) write(n uint64, input []byte, opt Options, result map[string]interface{}, errors DataErrors) error {
+	fixture := &Fixture{
+		Time:       time.Now().UTC(),
+		SchemaHash: SchemaHash(documentSchema(r.Schema)),
+		Input:      input,
+		Options: FixtureOptions{
+			Usage: opt.Usage, UserRights: opt.UserRights, Existing: opt.Existing, OwnerField: opt.OwnerField, UserID: opt.UserID,
+			Profile: opt.Profile, Strict: opt.Strict, MaxDepth: opt.MaxDepth, MaxFields: opt.MaxFields,
+		},
+	}
+	var err error
+	if fixture.Result, err = json.Marshal(result); err != nil {
+		return err
+	}
+	if fixture.Errors, err = json.Marshal(errorsOrEmpty(errors)); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%d.json", fixture.Time.Format("20060102T150405.000000000"), n)
+	return os.WriteFile(filepath.Join(r.Dir, name), data, 0o644)
+}
+
+// This method returns the options of the fixture
+func (o FixtureOptions) options() Options {
+	return Options{
+		Usage: o.Usage, UserRights: o.UserRights, Existing: o.Existing, OwnerField: o.OwnerField, UserID: o.UserID,
+		Profile: o.Profile, Strict: o.Strict, MaxDepth: o.MaxDepth, MaxFields: o.MaxFields,
+	}
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the hash of the schema rules that can be written in JSON – the functions of the validators are not part of it
+func SchemaHash(schema *Schema) string {
+	description, err := describeSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(description)
+	return hex.EncodeToString(sum[:])
+}
+
+// This function validates again the documents of the fixtures written by a Recorder in the directory, against the current schema
+// the results are sorted by file name; the ones whose outcome changed are the regressions, or the expected effects of the schema change
+func Replay(dir string, schema DocumentValidator) ([]*ReplayResult, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	hash := SchemaHash(documentSchema(schema))
+
+	results := make([]*ReplayResult, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return results, err
+		}
+		// the numbers of the existing document are kept as json.Number, like the submitted ones
+		fixture := new(Fixture)
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(fixture); err != nil {
+			return results, fmt.Errorf("%s: %s", file, err)
+		}
+		input, err := decodeJSON(fixture.Input)
+		if err != nil {
+			return results, fmt.Errorf("%s: %s", file, err)
+		}
+
+		replayed := &ReplayResult{File: file, Fixture: fixture, SchemaChanged: fixture.SchemaHash != hash}
+		replayed.Result, replayed.Errors = schema.Validate(input, fixture.Options.options())
+		result, _ := json.Marshal(replayed.Result)
+		errors, _ := json.Marshal(errorsOrEmpty(replayed.Errors))
+		replayed.Changed = !sameJSON(result, fixture.Result) || !sameJSON(errors, fixture.Errors)
+		results = append(results, replayed)
+	}
+	return results, nil
+}
+
+// this private function decodes a JSON document, the numbers as json.Number
+func decodeJSON(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	err := decoder.Decode(&document)
+	return document, err
+}
+
+// this private function returns the errors, an empty list rather than nil
+func errorsOrEmpty(errors DataErrors) DataErrors {
+	if errors == nil {
+		return DataErrors{}
+	}
+	return errors
+}
+
+// this private function tells whether two JSON documents are the same, regardless of the indentation
+func sameJSON(a, b []byte) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return false
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	schema := &Schema{Validators: map[string]*Validator{
+		"age":  {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 100}},
+		"name": {Type: "string"},
+	}}
+	recorder := &Recorder{Schema: schema, Dir: dir, OnError: func(err error) { t.Error(err) }}
+	recorder.Validate(map[string]interface{}{"age": json.Number("50"), "name": "Ada"}, Options{Usage: INIT})
+	recorder.Validate(map[string]interface{}{"age": json.Number("5")}, Options{Usage: SET, Existing: map[string]interface{}{"age": json.Number("1")}})
+	recorder.Validate(map[string]interface{}{"age": json.Number("500")}, Options{Usage: INIT})
+
+	results, err := Replay(dir, schema)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("expected 3 fixtures, got %d %v", len(results), err)
+	}
+	for _, result := range results {
+		if result.Changed || result.SchemaChanged {
+			t.Errorf("%s: expected the same outcome with the same schema", result.File)
+		}
+	}
+	if results[1].Fixture.Options.Usage != SET || results[1].Fixture.Options.Existing["age"] != json.Number("1") {
+		t.Errorf("expected the recorded options, got %+v", results[1].Fixture.Options)
+	}
+	if len(results[2].Errors) != 1 || results[2].Errors[0].Code != "out_of_boundaries" {
+		t.Errorf("expected the recorded error, got %v", results[2].Errors)
+	}
+
+	tightened := &Schema{Validators: map[string]*Validator{
+		"age":  {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 10}},
+		"name": {Type: "string"},
+	}}
+	results, err = Replay(dir, MustCompile(tightened))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the error of the third fixture changes too, its parameters holding the new boundaries
+	if !results[0].Changed || results[1].Changed || !results[2].Changed || !results[0].SchemaChanged {
+		t.Errorf("expected the first and third outcomes to change, got %v %v %v", results[0].Changed, results[1].Changed, results[2].Changed)
+	}
+
+	os.WriteFile(filepath.Join(dir, "zz.json"), []byte(`{"Input": `), 0644)
+	if _, err := Replay(dir, schema); err == nil {
+		t.Errorf("expected the invalid fixture to be reported")
+	}
+}
+
+func TestRecorderSampling(t *testing.T) {
+	dir := t.TempDir()
+	recorder := &Recorder{Schema: &Schema{Validators: map[string]*Validator{"name": {Type: "string"}}}, Dir: dir, Every: 3}
+	for i := 0; i < 7; i++ {
+		recorder.Validate(map[string]interface{}{"name": "Ada"}, Options{Usage: INIT})
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 3 {
+		t.Errorf("expected the 1st, 4th and 7th validations to be recorded, got %d files", len(files))
+	}
+
+	failed := 0
+	recorder = &Recorder{Schema: recorder.Schema, Dir: filepath.Join(dir, "missing"), OnError: func(error) { failed++ }}
+	if _, errors := recorder.Validate(map[string]interface{}{"name": 1}, Options{Usage: INIT}); len(errors) != 1 || failed != 1 {
+		t.Errorf("expected the validation to be unaffected by the write error, got %v %d", errors, failed)
+	}
+}
@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredOnSet(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"id":   {Type: "string", RequiredOnSet: true},
+		"name": {Type: "string"},
+		"note": {Type: "string"},
+	}}
+	tests := []struct {
+		doc    map[string]interface{}
+		opt    Options
+		fields []string
+	}{
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: SET}, []string{"id"}},
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: SET, ErrorOnMissing: []string{"note"}}, []string{"id", "note"}},
+		{map[string]interface{}{"id": "1"}, Options{Usage: SET}, []string{}},
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: INIT, ErrorOnMissing: []string{"note"}}, []string{}},
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: GET, ErrorOnMissing: []string{"id"}}, []string{"id"}},
+		{map[string]interface{}{"name": "Ada"}, Options{Usage: GET}, []string{}},
+	}
+	for _, test := range tests {
+		_, errors := schema.Validate(test.doc, test.opt)
+		fields := []string{}
+		for _, err := range errors {
+			if err.Code != "required" {
+				t.Errorf("%v: expected a required error, got %v", test.doc, err)
+			}
+			fields = append(fields, err.Field)
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%v %+v: expected %v, got %v", test.doc, test.opt, test.fields, fields)
+		}
+	}
+
+	// a field required on SET cannot be removed
+	if _, errors := schema.Validate(map[string]interface{}{"id": Unset}, Options{Usage: SET}); len(errors) != 1 || errors[0].Code != "required" {
+		t.Errorf("expected the removal to be refused, got %v", errors)
+	}
+}
@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is the outcome of a validation along with what the caller acts upon besides the document – see Evaluate
+type Result struct {
+	Dest   map[string]interface{} // the validated document, as returned by Validate
+	Errors DataErrors             // the errors of the validation, as returned by Validate
+	// the idempotency key of the request, to look up the outcome of a former try with – see Schema.IdempotencyField
+	IdempotencyKey string
+	// the submitted values the validation changed (coercion, sanitization, normalization, transform) or added (defaults), if Options.ReportModifications is set
+	Modifications []Modification
+}
+
+// This struct is a value the validation stored differently from the submitted one, e.g. a phone number normalized to "+33…"
+type Modification struct {
+	Field    string      `json:"field"`              // the path of the field, as submitted
+	Original interface{} `json:"original,omitempty"` // the submitted value, nil for a default
+	Stored   interface{} `json:"stored"`             // the value written in the validated document
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method tells whether the validation succeeded
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function validates the document as Validate does, and returns the outcome as a Result
+// the idempotency key and the modifications are only returned if the validation succeeded, an invalid one being no key to look up
+func Evaluate(schema DocumentValidator, _map map[string]interface{}, opt Options) *Result {
+	dest, errors := schema.Validate(_map, opt)
+	result := &Result{Dest: dest, Errors: errors}
+	if result.Valid() {
+		result.IdempotencyKey = IdempotencyKey(schema, dest, opt)
+		if opt.ReportModifications && opt.Usage != GET {
+			result.Modifications = modifications(documentSchema(schema), _map, dest, opt.Usage)
+		}
+	}
+	return result
+}
+
+// this private function returns the values of the fields the validation changed or added, in the order of the paths
+// the submitted values only converted to their type, e.g. the 3.0 of a JSON int, or cleared are not reported
+func modifications(schema *Schema, _map map[string]interface{}, dest map[string]interface{}, usage int) []Modification {
+	var modified []Modification
+	for _, path := range schema.sortedPaths() {
+		in, out := schema.Validators[path].Paths(path, usage)
+		if !isSubmitted(dest, out) {
+			continue
+		}
+		stored := readExisting(dest, out)
+		if !isSubmitted(_map, in) {
+			if stored != nil && stored != Unset {
+				modified = append(modified, Modification{Field: in, Stored: stored})
+			}
+			continue
+		}
+		original := readExisting(_map, in)
+		if original != nil && !sameStored(original, stored) {
+			modified = append(modified, Modification{Field: in, Original: original, Stored: stored})
+		}
+	}
+	return modified
+}
+
+// this private function tells whether the stored value is the submitted one, or has the same JSON representation, e.g. a float64 and an int of the same value
+func sameStored(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && sameJSON(aJSON, bJSON)
+}
@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestModifications(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"phone": {Type: "string", Transform: func(v interface{}) (interface{}, error) { return strings.Replace(v.(string), " ", "", -1), nil }},
+		"age":   {Type: "int", Boundaries: Boundaries{Min: 0, Max: 10}},
+		"lang":  {Type: "string", Default: func(interface{}) interface{} { return "fr" }},
+		"name":  {Type: "string"},
+	}}
+	// the float of a JSON int is only converted to its type
+	document := map[string]interface{}{"phone": "+33 6 12", "age": float64(3), "name": "a"}
+	result := Evaluate(schema, document, Options{Usage: INIT, ReportModifications: true})
+	expected := []Modification{
+		{Field: "lang", Stored: "fr"},
+		{Field: "phone", Original: "+33 6 12", Stored: "+33612"},
+	}
+	if !result.Valid() || !reflect.DeepEqual(result.Modifications, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	if result := Evaluate(schema, document, Options{Usage: INIT}); result.Modifications != nil {
+		t.Errorf("expected no modifications unless asked, got %+v", result.Modifications)
+	}
+	document["age"] = 11
+	if result := Evaluate(schema, document, Options{Usage: INIT, ReportModifications: true}); result.Valid() || result.Modifications != nil {
+		t.Errorf("expected no modifications of an invalid document, got %+v", result)
+	}
+}
@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct is the rule of a weekly schedule sub-document, such as the opening hours of a shop:
+// {"monday": [{"open": "09:00", "close": "12:30"}, {"open": "14:00", "close": "19:00"}], "sunday": []}
+// the days are the lower-case English ones, each one optional, and the times "HH:MM" on 24 hours, "24:00" closing at midnight
+// the ranges of a day must open before they close, and not overlap – a range may close when the next one opens
+type Schedule struct {
+	OpenKey   string // the key of the opening time, "open" if empty
+	CloseKey  string // the key of the closing time, "close" if empty
+	MaxRanges int    // the max number of ranges a day, unlimited if 0
+}
+
+// the days of a schedule, in order
+var scheduleDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the keys of the opening and closing times in the ranges
+func (s *Schedule) Keys() (string, string) {
+	openKey, closeKey := s.OpenKey, s.CloseKey
+	if openKey == "" {
+		openKey = "open"
+	}
+	if closeKey == "" {
+		closeKey = "close"
+	}
+	return openKey, closeKey
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function runs the Schedule rule of the validator against the sub-document, reporting the problems of every day
+func checkSchedule(validator *Validator, path string, document map[string]interface{}, errors *[]*DataError) bool {
+	rule := validator.Schedule
+	openKey, closeKey := rule.Keys()
+	valid := true
+	report := func(err *DataError, segments ...PathSegment) {
+		err.Path = append(ParsePath(path), segments...)
+		*errors = append(*errors, err)
+		valid = false
+	}
+
+	for _, day := range sortedKeys(document) {
+		daySegment := PathSegment{Key: day}
+		if !containsString(scheduleDays, day) {
+			report(NewError("unknown_field", validator.Field, nil, map[string]interface{}{"expected": scheduleDays}), daySegment)
+			continue
+		}
+		ranges := reflect.ValueOf(document[day])
+		if ranges.Kind() != reflect.Slice {
+			report(NewError("type_mismatch", validator.Field, typeName(document[day]), map[string]interface{}{"expected": "[]interface {}"}), daySegment)
+			continue
+		}
+		if rule.MaxRanges > 0 && ranges.Len() > rule.MaxRanges {
+			report(NewError("too_many_items", validator.Field, ranges.Len(), map[string]interface{}{"max": rule.MaxRanges}), daySegment)
+			continue
+		}
+
+		// the ranges in minutes since midnight, by position
+		type minutesRange struct{ index, open, close int }
+		var parsed []minutesRange
+		for i := 0; i < ranges.Len(); i++ {
+			rangeSegment := PathSegment{Index: i, IsIndex: true}
+			item := ranges.Index(i).Interface()
+			hours, ok := item.(map[string]interface{})
+			if !ok {
+				report(NewError("type_mismatch", validator.Field, typeName(item), map[string]interface{}{"expected": "map[string]interface {}"}), daySegment, rangeSegment)
+				continue
+			}
+			open, openOk := scheduleMinutes(hours[openKey], false)
+			if !openOk {
+				report(NewError("invalid_format", validator.Field, hours[openKey], map[string]interface{}{"expected": "HH:MM"}), daySegment, rangeSegment, PathSegment{Key: openKey})
+			}
+			close, closeOk := scheduleMinutes(hours[closeKey], true)
+			if !closeOk {
+				report(NewError("invalid_format", validator.Field, hours[closeKey], map[string]interface{}{"expected": "HH:MM"}), daySegment, rangeSegment, PathSegment{Key: closeKey})
+			}
+			if !openOk || !closeOk {
+				continue
+			}
+			if open >= close {
+				report(NewError("invalid_range", validator.Field, item, map[string]interface{}{"open": hours[openKey], "close": hours[closeKey]}), daySegment, rangeSegment)
+				continue
+			}
+			parsed = append(parsed, minutesRange{i, open, close})
+		}
+
+		sort.SliceStable(parsed, func(a, b int) bool { return parsed[a].open < parsed[b].open })
+		for j := 1; j < len(parsed); j++ {
+			if parsed[j].open < parsed[j-1].close {
+				report(NewError("overlapping_ranges", validator.Field, nil, map[string]interface{}{"range": parsed[j-1].index}), daySegment, PathSegment{Index: parsed[j].index, IsIndex: true})
+			}
+		}
+	}
+	return valid
+}
+
+// this private function returns the minutes since midnight of a "HH:MM" time, "24:00" being accepted as a closing time
+func scheduleMinutes(value interface{}, closing bool) (int, bool) {
+	str, ok := value.(string)
+	if !ok || len(str) != 5 || str[2] != ':' {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(str[:2])
+	if err != nil || str[0] == '+' || str[0] == '-' {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(str[3:])
+	if err != nil || str[3] == '+' || str[3] == '-' || minutes > 59 {
+		return 0, false
+	}
+	if hours > 23 && !(closing && hours == 24 && minutes == 0) {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
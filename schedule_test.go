@@ -0,0 +1,57 @@
+package validation
+
+import "testing"
+
+// this private function returns an opening hours range
+func openingHours(open string, close string) interface{} {
+	return map[string]interface{}{"open": open, "close": close}
+}
+
+func TestSchedule(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"hours": {Type: "map[string]interface {}", Schedule: &Schedule{MaxRanges: 2}}}}
+
+	// a range may close when the next one opens, and at midnight
+	valid := map[string]interface{}{
+		"monday": []interface{}{openingHours("09:00", "12:30"), openingHours("12:30", "24:00")},
+		"sunday": []interface{}{},
+	}
+	if _, errors := schema.Validate(map[string]interface{}{"hours": valid}, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	invalid := map[string]interface{}{
+		"monday":    []interface{}{openingHours("09:00", "13:00"), openingHours("12:30", "18:00")},
+		"tuesday":   []interface{}{openingHours("9:00", "25:00"), openingHours("18:00", "10:00")},
+		"wednesday": []interface{}{openingHours("08:00", "09:00"), openingHours("10:00", "11:00"), openingHours("12:00", "13:00")},
+		"funday":    []interface{}{},
+	}
+	_, errors := schema.Validate(map[string]interface{}{"hours": invalid}, Options{Usage: INIT})
+	expected := map[string]string{
+		"/hours/funday":          "unknown_field",
+		"/hours/monday/1":        "overlapping_ranges",
+		"/hours/tuesday/0/open":  "invalid_format",
+		"/hours/tuesday/0/close": "invalid_format",
+		"/hours/tuesday/1":       "invalid_range",
+		"/hours/wednesday":       "too_many_items",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, errors)
+	}
+	for _, err := range errors {
+		if expected[err.Pointer()] != err.Code {
+			t.Errorf("%s: expected %q, got %q", err.Pointer(), expected[err.Pointer()], err.Code)
+		}
+	}
+
+	if errs := CheckSchema(&Schema{Validators: map[string]*Validator{"hours": {Type: "string", Schedule: &Schedule{}}}}); len(errs) != 1 {
+		t.Errorf("expected the non-document type to be reported, got %v", errs)
+	}
+}
+
+func TestScheduleKeys(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"hours": {Type: "map[string]interface {}", Schedule: &Schedule{OpenKey: "from", CloseKey: "to"}}}}
+	hours := map[string]interface{}{"friday": []interface{}{map[string]interface{}{"from": "10:00", "to": "11:00"}}}
+	if _, errors := schema.Validate(map[string]interface{}{"hours": hours}, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+}
@@ -0,0 +1,296 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct gathers the validators of a document and the rules applying to the document as a whole
+type Schema struct {
+	Validators map[string]*Validator                                   // the fields validators, by path
+	Computed   map[string]func(doc map[string]interface{}) interface{} // the derived fields, by path, computed from the validated document
+	Profiles   map[string]*Profile                                     // the named options sets of the endpoints using the schema
+	// the schemas the validators can reference by name, e.g. a category of a category tree – see Validator.Ref
+	Definitions map[string]*Schema
+	// the path of the optimistic concurrency version field, handled by the schema rather than by a validator – see VersionCheck
+	// it is required on SET and written as a VersionCheck, set to 1 on INIT, and kept as stored on GET
+	VersionField string
+	SoftDelete   *SoftDelete // the soft-delete convention of the documents, if any
+	// the path of the idempotency key of the APIs retrying safely, e.g. "requestId": a string of the default pattern unless the validator has one, required on INIT and immutable
+	// no validator needs to be written for it – see IdempotencyKey
+	IdempotencyField string
+
+	// the rules applying to the document as a whole, reported with an empty field
+	MaxFields       int                                                 // the maximal number of submitted values, as Options.MaxFields, for every call
+	ExclusiveGroups [][]string                                          // the groups of paths of which one field at most may hold a value
+	AnyOfGroups     [][]string                                          // the groups of paths of which one field at least must hold a value
+	DocumentTest    func(doc map[string]interface{}) (bool, *DataError) // this function tests the validated document, once its fields are all valid
+	// the fields required when another one holds a value, by path of the latter, as JSON Schema dependentRequired
+	DependentRules map[string][]string
+	// the validators run when another field holds a value, by path of the latter, as JSON Schema dependentSchemas – they replace the schema ones of the same paths
+	DependentValidators map[string]map[string]*Validator
+
+	paths       []string              // the sorted validators paths, computed once by Compile
+	middlewares []ValidatorMiddleware // the wrappers of the fields validation – see Use
+}
+
+// This struct is the immutable form of a Schema, built by Schema.Compile
+// it owns a private copy of the schema that nothing can modify: it can be used from many goroutines simultaneously
+// Validate never modifies the schema, the validators (Options.Override ones included) nor the submitted data
+type CompiledSchema struct {
+	schema        *Schema
+	collaborators *Collaborators // the fakes of the unit tests, the default collaborators if nil – see WithCollaborators
+}
+
+// This interface is implemented by Schema, CompiledSchema and RemoteSchema: the entry points decoding other formats than JSON accept any of them
+type DocumentValidator interface {
+	Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors)
+}
+
+// This struct bundles the options an endpoint validates its data with – see Options.Profile
+// when a profile is selected, its Usage replaces the Options one and its Strict flag adds up to the Options one
+type Profile struct {
+	Usage     int               // INIT, SET, GET
+	MinRights int               // the minimal user rights to use the profile at all
+	Rights    map[string][3]int // the rights replacing the validators ones, by path
+	Fields    []string          // the paths of the validators to run, all of them if empty
+	Strict    bool              // reject the submitted fields no validator is written for
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns a deep copy of the schema, whose validators can be customized without altering the original ones
+// the regexps of the copied validators are compiled once and shared by the next clones
+func (s *Schema) Clone() *Schema {
+	clone := &Schema{VersionField: s.VersionField, IdempotencyField: s.IdempotencyField, MaxFields: s.MaxFields, DocumentTest: s.DocumentTest}
+	clone.middlewares = append([]ValidatorMiddleware(nil), s.middlewares...)
+	clone.ExclusiveGroups = cloneGroups(s.ExclusiveGroups)
+	clone.AnyOfGroups = cloneGroups(s.AnyOfGroups)
+	if s.DependentRules != nil {
+		clone.DependentRules = make(map[string][]string, len(s.DependentRules))
+		for path, required := range s.DependentRules {
+			clone.DependentRules[path] = append([]string(nil), required...)
+		}
+	}
+	if s.DependentValidators != nil {
+		clone.DependentValidators = make(map[string]map[string]*Validator, len(s.DependentValidators))
+		for path, validators := range s.DependentValidators {
+			clone.DependentValidators[path] = cloneValidators(validators)
+		}
+	}
+	if s.Validators != nil {
+		clone.Validators = cloneValidators(s.Validators)
+	}
+	if s.Computed != nil {
+		clone.Computed = make(map[string]func(doc map[string]interface{}) interface{}, len(s.Computed))
+		for path, fn := range s.Computed {
+			clone.Computed[path] = fn
+		}
+	}
+	if s.Definitions != nil {
+		clone.Definitions = make(map[string]*Schema, len(s.Definitions))
+		for name, definition := range s.Definitions {
+			clone.Definitions[name] = definition.Clone()
+		}
+	}
+	if s.SoftDelete != nil {
+		clone.SoftDelete = s.SoftDelete.Clone()
+	}
+	if s.Profiles != nil {
+		clone.Profiles = make(map[string]*Profile, len(s.Profiles))
+		for name, profile := range s.Profiles {
+			clone.Profiles[name] = profile.Clone()
+		}
+	}
+	return clone
+}
+
+// This method returns a deep copy of the profile
+func (p *Profile) Clone() *Profile {
+	clone := *p
+	if p.Rights != nil {
+		clone.Rights = make(map[string][3]int, len(p.Rights))
+		for path, rights := range p.Rights {
+			clone.Rights[path] = rights
+		}
+	}
+	if p.Fields != nil {
+		clone.Fields = append([]string(nil), p.Fields...)
+	}
+	return &clone
+}
+
+// This method returns the immutable form of the schema, to be shared between goroutines
+// the schema is checked first, and the first problem CheckSchema reports is returned as an error
+// the schema is copied, so modifying it afterwards has no effect on the compiled one
+func (s *Schema) Compile() (*CompiledSchema, error) {
+	if errors := CheckSchema(s); len(errors) > 0 {
+		return nil, fmt.Errorf("validation: invalid schema: %v", errors[0])
+	}
+	clone := s.Clone()
+	clone.paths = clone.sortedPaths()
+	return &CompiledSchema{schema: clone}, nil
+}
+
+// This method runs the compiled schema against the provided data – see Validate
+func (c *CompiledSchema) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	if c.collaborators != nil {
+		opt.collaborators = c.collaborators
+	}
+	return validate(c.schema, _map, opt)
+}
+
+// This method returns a copy of the compiled schema, to inspect it or to derive a new one from it
+func (c *CompiledSchema) Schema() *Schema {
+	return c.schema.Clone()
+}
+
+// This method returns the paths of the validators, sorted
+func (s *Schema) sortedPaths() []string {
+	if s.paths != nil {
+		return s.paths
+	}
+	paths := make([]string, 0, len(s.Validators))
+	for path := range s.Validators {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// This method runs the schema against the provided data – see Validate
+func (s *Schema) Validate(_map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	return validate(s, _map, opt)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function compiles the schema and panics if it is invalid – handy for globally shared schemas
+func MustCompile(s *Schema) *CompiledSchema {
+	compiled, err := s.Compile()
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// this private function returns the schema and options to run once the selected profile is applied, and the minimal rights it requires
+// the schema is left untouched: the profile restrictions are applied on a copy; an unknown profile is reported as an "unknown_profile" error
+func applyProfile(schema *Schema, opt Options) (*Schema, Options, int, *DataError) {
+	if opt.Profile == "" {
+		return schema, opt, UNAUTHENTICATED, nil
+	}
+	profile, ok := schema.Profiles[opt.Profile]
+	if !ok {
+		return schema, opt, UNAUTHENTICATED, NewError("unknown_profile", "", opt.Profile, nil)
+	}
+
+	validators := schema.Validators
+	if len(profile.Fields) > 0 {
+		validators = make(map[string]*Validator, len(profile.Fields))
+		for _, path := range profile.Fields {
+			if validator, ok := schema.Validators[path]; ok {
+				validators[path] = validator
+			}
+		}
+	}
+	if len(profile.Rights) > 0 {
+		overridden := make(map[string]*Validator, len(validators))
+		for path, validator := range validators {
+			if rights, ok := profile.Rights[path]; ok {
+				copied := *validator
+				copied.Rights = rights
+				validator = &copied
+			}
+			overridden[path] = validator
+		}
+		validators = overridden
+	}
+
+	profiled := *schema
+	profiled.Validators = validators
+	profiled.paths = nil
+	opt.Usage = profile.Usage
+	opt.Strict = opt.Strict || profile.Strict
+	return &profiled, opt, profile.MinRights, nil
+}
+
+// this private function returns a copy of the schema whose validators are replaced by the overriding ones
+// the schema is left untouched, so it can be shared between calls
+func applyOverride(schema *Schema, override map[string]*Validator) *Schema {
+	if len(override) == 0 {
+		return schema
+	}
+	validators := make(map[string]*Validator, len(schema.Validators)+len(override))
+	for path, validator := range schema.Validators {
+		validators[path] = validator
+	}
+	for path, validator := range override {
+		validators[path] = validator
+	}
+
+	overridden := *schema
+	overridden.Validators = validators
+	overridden.paths = nil
+	return &overridden
+}
+
+// this private function writes the computed fields into dest
+// the functions receive the validated document: on SET, this is the existing document overlaid with the validated changes
+func compute(schema *Schema, dest map[string]interface{}, opt Options, errors *[]*DataError) {
+	if len(schema.Computed) == 0 {
+		return
+	}
+
+	doc := resultDocument(dest, opt)
+
+	// computed in a stable order, since a function may read a field computed before it
+	paths := make([]string, 0, len(schema.Computed))
+	for path := range schema.Computed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		var value interface{}
+		if err := callSafely(path, "Computed", func() { value = schema.Computed[path](doc) }); err != nil {
+			*errors = append(*errors, err)
+			continue
+		}
+		if opt.Usage == SET {
+			dest[path] = value
+			if err := tools.WriteDeep(doc, path, value); err != nil {
+				panic(err)
+			}
+		} else if err := tools.WriteDeep(dest, path, value); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// this private function returns the schema behind a DocumentValidator
+func documentSchema(schema DocumentValidator) *Schema {
+	switch schema := schema.(type) {
+	case *Schema:
+		return schema
+	case *CompiledSchema:
+		return schema.schema
+	case *Recorder:
+		return documentSchema(schema.Schema)
+	case *OutcomeCache:
+		return documentSchema(schema.Schema)
+	case interface{ Compiled() *CompiledSchema }:
+		return schema.Compiled().schema
+	}
+	panic("validation: the schema must be a *Schema, a *CompiledSchema or provide a Compiled method")
+}
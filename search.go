@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This inner struct is the rule of a free-text search input, e.g. the query of a $text search
+// the query is sanitized before being bounded: the operators of MongoDB and Lucene are stripped, the whitespace collapsed and the case lowered
+type SearchQuery struct {
+	MinLength     int  // the min number of characters of the sanitized query, 1 if 0
+	MaxLength     int  // the max number of characters of the sanitized query, 200 if 0
+	CaseSensitive bool // keep the case of the query
+}
+
+// the characters the search engines give a meaning to: the negation, the phrases, the grouping, the wildcards, the fuzziness...
+const searchOperators = `+-&|!(){}[]^"~*?:\/$<>=;`
+
+// the boolean keywords of Lucene, which are operators in upper case only
+var searchKeywords = map[string]bool{"AND": true, "OR": true, "NOT": true, "TO": true}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the query stripped of its operators and control characters, its words separated by single spaces, and lowered unless CaseSensitive
+// e.g. `  "Go" AND -java*` becomes "go java"
+func (s *SearchQuery) Sanitize(query string) string {
+	stripped := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(searchOperators, r) || unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, query)
+
+	words := strings.Fields(stripped)
+	kept := words[:0]
+	for _, word := range words {
+		if !searchKeywords[word] {
+			kept = append(kept, word)
+		}
+	}
+	sanitized := strings.Join(kept, " ")
+	if !s.CaseSensitive {
+		sanitized = strings.ToLower(sanitized)
+	}
+	return sanitized
+}
+
+// This method returns the min and max lengths of the sanitized query, the defaults applied
+func (s *SearchQuery) lengths() (int, int) {
+	min, max := s.MinLength, s.MaxLength
+	if min <= 0 {
+		min = 1
+	}
+	if max <= 0 {
+		max = 200
+	}
+	return min, max
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function sanitizes a search query and checks the length of the outcome, which replaces the submitted query
+// a query made of operators only is as invalid as a too short one
+func checkSearchQuery(validator *Validator, in string, value interface{}, errors *[]*DataError) (interface{}, bool) {
+	query, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	sanitized := validator.SearchQuery.Sanitize(query)
+	min, max := validator.SearchQuery.lengths()
+	if length := utf8.RuneCountInString(sanitized); length < min || length > max {
+		*errors = append(*errors, NewError("invalid_search_query", in, value, map[string]interface{}{"min": min, "max": max}))
+		return value, false
+	}
+	return sanitized, true
+}
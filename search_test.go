@@ -0,0 +1,41 @@
+package validation
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		query     string
+		sensitive bool
+		expected  string
+	}{
+		{`  "Go" AND -java*	`, false, "go java"},
+		{`title:(Go OR Rust)^2`, false, "title go rust 2"},
+		{`{"$where": "x"}`, false, "where x"},
+		{`Go and Rust`, true, "Go and Rust"},
+		{`( - * ) AND`, false, ""},
+	}
+	for _, test := range tests {
+		if sanitized := (&SearchQuery{CaseSensitive: test.sensitive}).Sanitize(test.query); sanitized != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.query, test.expected, sanitized)
+		}
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{"q": {Type: "string", SearchQuery: &SearchQuery{MaxLength: 10}}}}
+	dest, errors := schema.Validate(map[string]interface{}{"q": `"Go" AND -java*`}, Options{Usage: INIT})
+	if len(errors) > 0 || dest["q"] != "go java" {
+		t.Errorf("expected the sanitized query, got %v and %v", dest, errors)
+	}
+	// a query made of operators only is too short
+	for _, query := range []string{"( - * ) AND", "abcdefghijkl"} {
+		if _, errors := schema.Validate(map[string]interface{}{"q": query}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "invalid_search_query" {
+			t.Errorf("%q: expected an invalid search query, got %v", query, errors)
+		}
+	}
+
+	invalid := &Schema{Validators: map[string]*Validator{"q": {Type: "int", SearchQuery: &SearchQuery{MinLength: 5, MaxLength: 3}}}}
+	if errors := CheckSchema(invalid); len(errors) != 2 {
+		t.Errorf("expected the type and the lengths to be reported, got %v", errors)
+	}
+}
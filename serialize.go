@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function builds an API response from a stored document, without validating it: only the fields of the schema the user has the GET rights for are output,
+// the other ones being stripped, or masked if their validator has a Mask fn
+// the values are written for JSON: a bson.ObjectId as its hex string, a time.Time as RFC3339, at any depth; the array sub-documents follow the Items validators
+func Serialize(schema DocumentValidator, doc map[string]interface{}, rights int) (map[string]interface{}, error) {
+	return serialize(documentSchema(schema), doc, rights)
+}
+
+// this private function serializes a document against the schema – see Serialize
+func serialize(schema *Schema, doc map[string]interface{}, rights int) (map[string]interface{}, error) {
+	dest := make(map[string]interface{})
+	for _, path := range schema.sortedPaths() {
+		validator := schema.Validators[path]
+		in, out := validator.Paths(path, GET)
+		value := readExisting(doc, in)
+		if value == nil {
+			continue
+		}
+
+		if !validator.CheckRights(rights, validator.Rights[GET]) {
+			if validator.Mask == nil {
+				continue
+			}
+			if value = validator.Mask(value); value == nil {
+				continue
+			}
+		} else if validator.Items != nil {
+			items, err := serializeItems(validator, value, rights)
+			if err != nil {
+				return nil, err
+			}
+			value = items
+		}
+
+		if err := tools.WriteDeep(dest, out, serializeValue(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	// the version is part of the response, to be sent back on SET
+	if schema.VersionField != "" {
+		if version := readExisting(doc, schema.VersionField); version != nil {
+			if err := tools.WriteDeep(dest, schema.VersionField, version); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return dest, nil
+}
+
+// this private function serializes the sub-documents of an array against the items validators
+func serializeItems(validator *Validator, value interface{}, rights int) (interface{}, error) {
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return value, nil
+	}
+	element := &Schema{Validators: validator.Items}
+	items := make([]interface{}, slice.Len())
+	for i := range items {
+		item := slice.Index(i).Interface()
+		if document, ok := item.(map[string]interface{}); ok {
+			serialized, err := serialize(element, document, rights)
+			if err != nil {
+				return nil, err
+			}
+			item = serialized
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// this private function returns the value written for JSON: the ObjectIds as hex strings and the times as RFC3339, in the sub-documents and arrays too
+func serializeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case map[string]interface{}:
+		serialized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			serialized[key] = serializeValue(item)
+		}
+		return serialized
+	case []byte, json.RawMessage:
+		return v
+	}
+	if slice := reflect.ValueOf(value); slice.Kind() == reflect.Slice {
+		serialized := make([]interface{}, slice.Len())
+		for i := range serialized {
+			serialized[i] = serializeValue(slice.Index(i).Interface())
+		}
+		return serialized
+	}
+	return objectIdString(value)
+}
@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSerialize(t *testing.T) {
+	last4 := func(value interface{}) interface{} { return "****" + value.(string)[len(value.(string))-4:] }
+	schema := &Schema{VersionField: "version", Validators: map[string]*Validator{
+		"name": {Type: "string", Source: "label"},
+		"card": {Type: "string", Rights: [3]int{0, ADMIN, 0}, Mask: last4},
+		"hash": {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+		"at":   {Type: "time.Time"},
+		"lines": {Type: "[]interface {}", Items: map[string]*Validator{
+			"sku":    {Type: "string"},
+			"secret": {Type: "string", Rights: [3]int{0, ADMIN, 0}},
+		}},
+	}}
+	doc := map[string]interface{}{
+		"name": "Ada", "card": "1234567812345678", "hash": "x", "at": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "version": int64(3),
+		"lines": []interface{}{map[string]interface{}{"sku": "a", "secret": "s"}}, "other": 1,
+	}
+	dest, err := Serialize(schema, doc, USER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"label": "Ada", "card": "****5678", "at": "2020-01-02T03:04:05Z", "version": int64(3),
+		"lines": []interface{}{map[string]interface{}{"sku": "a"}},
+	}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("expected %v, got %v", expected, dest)
+	}
+
+	dest, err = Serialize(schema, doc, ADMIN)
+	if err != nil || dest["card"] != "1234567812345678" || dest["hash"] != "x" || len(dest["lines"].([]interface{})[0].(map[string]interface{})) != 2 {
+		t.Errorf("expected every field for an admin, got %v %v", dest, err)
+	}
+}
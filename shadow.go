@@ -0,0 +1,63 @@
+package validation
+
+import "reflect"
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct reports how the shadow schema validated a document differently from the current one – see Options.ShadowSchema
+type ShadowDivergence struct {
+	Input         map[string]interface{} // the submitted document
+	Current       map[string]interface{} // the dest of the current schema, the one returned
+	Shadow        map[string]interface{} // the dest of the shadow schema
+	CurrentErrors DataErrors             // the errors of the current schema, the ones returned
+	ShadowErrors  DataErrors             // the errors of the shadow schema
+	Added         DataErrors             // the errors only the shadow schema reports, by field and code: the documents it would refuse
+	Removed       DataErrors             // the errors only the current schema reports: the documents it would accept
+	DestDiffers   bool                   // the dests differ, e.g. a new default or transform
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function validates the document against the current schema then the shadow one, reporting their divergence, and returns the current outcome
+// the shadow validation never alters the outcome: its divergences are only reported to Options.OnShadowDivergence
+func validateShadowed(schema *Schema, _map map[string]interface{}, opt Options) (map[string]interface{}, []*DataError) {
+	shadow := opt.ShadowSchema
+	opt.ShadowSchema = nil
+	dest, errors := validate(schema, _map, opt)
+	if opt.OnShadowDivergence == nil {
+		return dest, errors
+	}
+
+	// the shadow validation is neither traced nor covered, not to mix its rules up with the current ones
+	shadowOpt := opt
+	shadowOpt.Coverage, shadowOpt.Trace = nil, nil
+	shadowDest, shadowErrors := validate(shadow, _map, shadowOpt)
+
+	divergence := &ShadowDivergence{Input: _map, Current: dest, Shadow: shadowDest, CurrentErrors: errors, ShadowErrors: shadowErrors}
+	divergence.Added = missingErrors(shadowErrors, errors)
+	divergence.Removed = missingErrors(errors, shadowErrors)
+	divergence.DestDiffers = !reflect.DeepEqual(dest, shadowDest)
+	if len(divergence.Added) > 0 || len(divergence.Removed) > 0 || divergence.DestDiffers {
+		callSafely("", "OnShadowDivergence", func() { opt.OnShadowDivergence(divergence) })
+	}
+	return dest, errors
+}
+
+// this private function returns the errors of the list whose field and code the other list has not
+func missingErrors(errors []*DataError, others []*DataError) DataErrors {
+	reported := make(map[[2]string]bool, len(others))
+	for _, err := range others {
+		reported[[2]string{err.Field, err.Code}] = true
+	}
+	var missing DataErrors
+	for _, err := range errors {
+		if !reported[[2]string{err.Field, err.Code}] {
+			missing = append(missing, err)
+		}
+	}
+	return missing
+}
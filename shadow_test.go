@@ -0,0 +1,27 @@
+package validation
+
+import "testing"
+
+func TestShadowSchema(t *testing.T) {
+	current := &Schema{Validators: map[string]*Validator{"code": {Type: "string"}, "name": {Type: "string", IsRequired: true}}}
+	shadow := &Schema{Validators: map[string]*Validator{"code": {Type: "string", Regexp: "^x$"}, "name": {Type: "string"}}}
+	var divergence *ShadowDivergence
+	options := Options{Usage: INIT, ShadowSchema: shadow, OnShadowDivergence: func(d *ShadowDivergence) { divergence = d }}
+
+	// the current outcome is returned, the shadow one only reported
+	_, errors := current.Validate(map[string]interface{}{"code": "y"}, options)
+	if len(errors) != 1 || errors[0].Field != "name" {
+		t.Errorf("expected the errors of the current schema, got %v", errors)
+	}
+	if divergence == nil {
+		t.Fatalf("expected a divergence")
+	}
+	if len(divergence.Added) != 1 || divergence.Added[0].Field != "code" || len(divergence.Removed) != 1 || divergence.Removed[0].Field != "name" {
+		t.Errorf("expected the regexp added and the required removed, got %v and %v", divergence.Added, divergence.Removed)
+	}
+
+	divergence = nil
+	if _, errors := current.Validate(map[string]interface{}{"code": "x", "name": "z"}, options); len(errors) > 0 || divergence != nil {
+		t.Errorf("expected no divergence, got %v and %v", errors, divergence)
+	}
+}
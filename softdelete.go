@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"time"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is the soft-delete convention of a schema: a document is deleted once its Field holds a value, e.g. the deletion date
+// the Field must have its validator, whose SET rights are the ones to delete a document – see MarkDeleted
+type SoftDelete struct {
+	Field     string   // the path of the deletion date, "deletedAt" if empty
+	Protected []string // the paths that cannot be SET anymore once the document is deleted, all of them but Field if empty
+	Visible   []string // the paths still returned on GET with Options.HideDeleted once the document is deleted, besides Field
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns a deep copy of the convention
+func (d *SoftDelete) Clone() *SoftDelete {
+	clone := *d
+	if d.Protected != nil {
+		clone.Protected = append([]string(nil), d.Protected...)
+	}
+	if d.Visible != nil {
+		clone.Visible = append([]string(nil), d.Visible...)
+	}
+	return &clone
+}
+
+// This method returns the path of the deletion date
+func (d *SoftDelete) field() string {
+	if d.Field == "" {
+		return "deletedAt"
+	}
+	return d.Field
+}
+
+// This method tells whether the path cannot be SET once the document is deleted
+func (d *SoftDelete) protects(path string) bool {
+	if path == d.field() {
+		return false
+	}
+	if len(d.Protected) == 0 {
+		return true
+	}
+	return containsString(d.Protected, path)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the validated SET dest marking the existing document deleted at the date – see SoftDelete
+// opt.Existing should be the stored document: a document already deleted is refused, and its version is the one checked if the schema has a VersionField
+func MarkDeleted(schema DocumentValidator, at time.Time, opt Options) (map[string]interface{}, DataErrors) {
+	s := documentSchema(schema)
+	if s.SoftDelete == nil {
+		panic("validation: MarkDeleted on a schema without SoftDelete")
+	}
+	field := s.SoftDelete.field()
+	if readExisting(opt.Existing, field) != nil {
+		errors := DataErrors{NewError("deleted_document", field, nil, nil)}
+		finishErrors(errors, opt)
+		return make(map[string]interface{}), errors
+	}
+
+	_map := make(map[string]interface{})
+	if err := tools.WriteDeep(_map, field, at); err != nil {
+		panic(err)
+	}
+	if s.VersionField != "" {
+		if version := readExisting(opt.Existing, s.VersionField); version != nil {
+			if err := tools.WriteDeep(_map, s.VersionField, version); err != nil {
+				panic(err)
+			}
+		}
+	}
+	opt.Usage = SET
+	return schema.Validate(_map, opt)
+}
+
+// this private function tells whether the existing document is soft-deleted
+func isDeleted(schema *Schema, opt Options) bool {
+	return schema.SoftDelete != nil && readExisting(opt.Existing, schema.SoftDelete.field()) != nil
+}
+
+// this private function reports the protected fields submitted on SET for a deleted document
+func checkDeleted(schema *Schema, _map map[string]interface{}, errors *[]*DataError) {
+	for _, path := range schema.sortedPaths() {
+		in, _ := schema.Validators[path].Paths(path, SET)
+		if schema.SoftDelete.protects(path) && isSubmitted(_map, in) {
+			*errors = append(*errors, NewError("deleted_document", in, nil, nil))
+		}
+	}
+}
+
+// this private function returns a copy of the schema restricted to the fields visible once the document is deleted
+func hideDeleted(schema *Schema) *Schema {
+	field := schema.SoftDelete.field()
+	validators := make(map[string]*Validator, len(schema.SoftDelete.Visible)+1)
+	for path, validator := range schema.Validators {
+		if path == field || containsString(schema.SoftDelete.Visible, path) {
+			validators[path] = validator
+		}
+	}
+
+	restricted := *schema
+	restricted.Validators = validators
+	restricted.paths = nil
+	return &restricted
+}
+
+// this private function tells whether the list holds the string
+func containsString(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoftDelete(t *testing.T) {
+	schema := &Schema{VersionField: "version", SoftDelete: &SoftDelete{Visible: []string{"name"}}, Validators: map[string]*Validator{
+		"name":      {Type: "string"},
+		"secret":    {Type: "string"},
+		"deletedAt": {Type: "time.Time", Nullable: true, Rights: [3]int{0, 0, ADMIN}},
+	}}
+	if errors := CheckSchema(schema); len(errors) > 0 {
+		t.Fatalf("expected no schema errors, got %v", errors)
+	}
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := map[string]interface{}{"name": "Ada", "secret": "s", "version": int64(2)}
+	dest, errors := MarkDeleted(schema, at, Options{Existing: existing, UserRights: ADMIN})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if update := Update(dest); !reflect.DeepEqual(update["$set"], map[string]interface{}{"deletedAt": at}) {
+		t.Errorf("expected the deletion date to be set, got %v", update)
+	}
+	if filter := VersionFilter(dest); filter["version"] != int64(2) {
+		t.Errorf("expected the existing version to be checked, got %v", filter)
+	}
+	if _, errors := MarkDeleted(schema, at, Options{Existing: existing, UserRights: USER}); len(errors) != 1 || errors[0].Code != "insufficient_rights" {
+		t.Errorf("expected the deletion rights, got %v", errors)
+	}
+
+	// once deleted, the protected fields are refused but the document can be restored
+	existing["deletedAt"] = at
+	if _, errors := MarkDeleted(schema, at, Options{Existing: existing, UserRights: ADMIN}); len(errors) != 1 || errors[0].Code != "deleted_document" {
+		t.Errorf("expected a deleted document to be refused, got %v", errors)
+	}
+	_, errors = schema.Validate(map[string]interface{}{"name": "Bob", "version": int64(2)}, Options{Usage: SET, Existing: existing})
+	if len(errors) != 1 || errors[0].Code != "deleted_document" || errors[0].Field != "name" {
+		t.Errorf("expected the name to be protected, got %v", errors)
+	}
+	dest, errors = schema.Validate(map[string]interface{}{"deletedAt": nil, "version": int64(2)}, Options{Usage: SET, Existing: existing, UserRights: ADMIN})
+	if len(errors) > 0 || dest["deletedAt"] != Unset {
+		t.Errorf("expected the document to be restored, got %v %v", dest, errors)
+	}
+
+	// on GET, only the visible fields are returned
+	dest, errors = schema.Validate(existing, Options{Usage: GET, HideDeleted: true})
+	if _, ok := dest["secret"]; len(errors) > 0 || ok || dest["name"] != "Ada" || dest["version"] != int64(2) {
+		t.Errorf("expected the secret to be hidden, got %v %v", dest, errors)
+	}
+}
+
+func TestSoftDeleteCheck(t *testing.T) {
+	schema := &Schema{SoftDelete: &SoftDelete{Protected: []string{"unknown"}}, Validators: map[string]*Validator{"name": {Type: "string"}}}
+	errors := CheckSchema(schema)
+	if len(errors) != 2 || !strings.Contains(errors[0].Error(), "no validator for the soft-delete field") || !strings.Contains(errors[1].Error(), "unknown field") {
+		t.Errorf("expected the missing deletedAt and the unknown protected field, got %v", errors)
+	}
+	clone := schema.Clone()
+	clone.SoftDelete.Protected[0] = "name"
+	if schema.SoftDelete.Protected[0] != "unknown" {
+		t.Errorf("expected the clone to be deep")
+	}
+}
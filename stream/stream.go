@@ -0,0 +1,132 @@
+// Package stream validates the messages of a broker (Kafka, NATS, ...) before handing them to the application
+// it does not depend on any client library: the consumer loop converts its messages into Message values and calls Consumer.Handle
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is a message as received from the broker
+type Message struct {
+	Topic   string            // the Kafka topic or the NATS subject
+	Key     []byte            // the message key, if any
+	Headers map[string]string // the message headers, if any
+	Payload []byte
+}
+
+// This struct validates the messages against the schema of their topic before handing them to Handler
+// the invalid messages go to DeadLetter instead, along with the errors
+type Consumer struct {
+	// the schemas by topic or subject – a key may use the NATS wildcards: "*" for one dot-separated token, ">" for the remaining ones
+	Schemas map[string]validation.DocumentValidator
+	// this function decodes the payloads, JSON documents whose numbers are json.Number if nil – codec.DecodeCBOR can be used for instance
+	Decode func(payload []byte) (map[string]interface{}, error)
+	// this function returns the options of the validation of a message, Options{Usage: INIT} if nil
+	Options func(msg *Message) validation.Options
+	// this function receives the clean documents: its error is returned by Handle
+	Handler func(msg *Message, doc map[string]interface{}) error
+	// this function receives the messages that cannot be decoded or are invalid: its error is returned by Handle
+	// if nil, Handle returns an error for such messages
+	DeadLetter func(msg *Message, errors validation.DataErrors) error
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method decodes and validates the message, then passes it either to Handler or to DeadLetter
+// a message whose topic has no schema is dead-lettered with an "unknown_schema" error
+func (c *Consumer) Handle(msg *Message) error {
+	doc, errors := c.Validate(msg)
+	if len(errors) == 0 {
+		return c.Handler(msg, doc)
+	}
+	if c.DeadLetter == nil {
+		return fmt.Errorf("stream: invalid message on %s: %s", msg.Topic, errors.Summary())
+	}
+	return c.DeadLetter(msg, errors)
+}
+
+// This method decodes the message and runs the schema of its topic against it – see validation.Validate
+func (c *Consumer) Validate(msg *Message) (map[string]interface{}, validation.DataErrors) {
+	schema := c.schema(msg.Topic)
+	if schema == nil {
+		return nil, validation.DataErrors{validation.NewError("unknown_schema", "", nil, map[string]interface{}{"topic": msg.Topic})}
+	}
+
+	decode := c.Decode
+	if decode == nil {
+		decode = decodeJSON
+	}
+	doc, err := decode(msg.Payload)
+	if err != nil {
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"topic": msg.Topic, "error": err.Error()})}
+	}
+
+	opt := validation.Options{Usage: validation.INIT}
+	if c.Options != nil {
+		opt = c.Options(msg)
+	}
+	return schema.Validate(doc, opt)
+}
+
+// This method returns the schema of the topic: the one registered under the topic itself, else the one of the first matching wildcard key in lexical order
+func (c *Consumer) schema(topic string) validation.DocumentValidator {
+	if schema, ok := c.Schemas[topic]; ok {
+		return schema
+	}
+	patterns := make([]string, 0, len(c.Schemas))
+	for pattern := range c.Schemas {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if matchSubject(pattern, topic) {
+			return c.Schemas[pattern]
+		}
+	}
+	return nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function tells if the subject matches the pattern, NATS-style
+func matchSubject(pattern string, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" && i == len(patternTokens)-1 {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
+
+// this private function decodes a JSON document, its numbers as json.Number
+func decodeJSON(payload []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("the payload is not a document")
+	}
+	return doc, nil
+}
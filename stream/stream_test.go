@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/grebett/validation"
+)
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		match            bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{"*.created", "users.created", true},
+		{"orders.created", "orders", false},
+	}
+	for _, test := range tests {
+		if match := matchSubject(test.pattern, test.subject); match != test.match {
+			t.Errorf("%s %s: expected %v", test.pattern, test.subject, test.match)
+		}
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	order := &validation.Schema{Validators: map[string]*validation.Validator{
+		"qty": {Type: "json.Number", Boundaries: validation.Boundaries{Min: 1, Max: 10}, IsRequired: true},
+	}}
+	var handled map[string]interface{}
+	var dead validation.DataErrors
+	consumer := &Consumer{
+		Schemas: map[string]validation.DocumentValidator{"orders.>": order, "orders.created": order},
+		Handler: func(msg *Message, doc map[string]interface{}) error {
+			handled = doc
+			return nil
+		},
+		DeadLetter: func(msg *Message, errors validation.DataErrors) error {
+			dead = errors
+			return nil
+		},
+	}
+
+	if err := consumer.Handle(&Message{Topic: "orders.created.eu", Payload: []byte(`{"qty": 2}`)}); err != nil || handled["qty"] != json.Number("2") {
+		t.Errorf("expected the clean document, got %v %v", handled, err)
+	}
+	for payload, code := range map[string]string{`{"qty": 20}`: "out_of_boundaries", `[1]`: "invalid_payload", `null`: "invalid_payload"} {
+		dead = nil
+		consumer.Handle(&Message{Topic: "orders.created", Payload: []byte(payload)})
+		if len(dead) != 1 || dead[0].Code != code {
+			t.Errorf("%s: expected %s, got %v", payload, code, dead)
+		}
+	}
+	dead = nil
+	consumer.Handle(&Message{Topic: "users.created", Payload: []byte(`{}`)})
+	if len(dead) != 1 || dead[0].Code != "unknown_schema" || dead[0].Params["topic"] != "users.created" {
+		t.Errorf("expected unknown_schema, got %v", dead)
+	}
+
+	// without DeadLetter, the invalid messages are errors; the handler errors are returned as they are
+	consumer.DeadLetter = nil
+	if err := consumer.Handle(&Message{Topic: "orders.created", Payload: []byte(`{}`)}); err == nil {
+		t.Error("expected an error for the invalid message")
+	}
+	failure := errors.New("down")
+	consumer.Handler = func(*Message, map[string]interface{}) error { return failure }
+	if err := consumer.Handle(&Message{Topic: "orders.created", Payload: []byte(`{"qty": 1}`)}); err != failure {
+		t.Errorf("expected the handler error, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the candidate the closest to the submitted string, if close enough to be a typo, e.g. "userName" for "usrName"
+// the comparison ignores the case; a tie is broken by the candidates order
+func closest(submitted string, candidates []string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		if candidate == submitted {
+			continue
+		}
+		distance := levenshtein(strings.ToLower(submitted), strings.ToLower(candidate))
+		// a third of the characters may be wrong, and at least one
+		max := utf8.RuneCountInString(candidate) / 3
+		if max < 1 {
+			max = 1
+		}
+		if distance <= max && (bestDistance < 0 || distance < bestDistance) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// this private function returns the number of single-character insertions, deletions and substitutions turning a into b
+func levenshtein(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+// this private function returns the smallest of the integers
+func minInt(first int, others ...int) int {
+	for _, other := range others {
+		if other < first {
+			first = other
+		}
+	}
+	return first
+}
+
+// this private function completes the error with the suggested value: in its params, for the message templates, and as a sentence
+// the error is copied, since custom tests may return shared ones
+func suggestError(err *DataError, suggestion string) *DataError {
+	suggested := *err
+	suggested.Params = make(map[string]interface{}, len(err.Params)+1)
+	for key, value := range err.Params {
+		suggested.Params[key] = value
+	}
+	suggested.Params["suggestion"] = suggestion
+	suggested.Suggestion = fmt.Sprintf("Did you mean %q?", suggestion)
+	return &suggested
+}
+
+// this private function suggests the known paths closest to the fields of the unknown_field errors
+func suggestFields(schema *Schema, usage int, errors []*DataError) {
+	if len(errors) == 0 {
+		return
+	}
+	// the sub-documents holding known fields are candidates too
+	candidates := make([]string, 0, len(schema.Validators))
+	for _, path := range schema.sortedPaths() {
+		in, _ := schema.Validators[path].Paths(path, usage)
+		segments := strings.Split(in, ".")
+		for i := 1; i < len(segments); i++ {
+			if parent := strings.Join(segments[:i], "."); !containsString(candidates, parent) {
+				candidates = append(candidates, parent)
+			}
+		}
+		candidates = append(candidates, in)
+	}
+	for i, err := range errors {
+		if suggestion, ok := closest(err.Field, candidates); ok {
+			errors[i] = suggestError(err, suggestion)
+		}
+	}
+}
+
+// this private function suggests the allowed value closest to the submitted one of a not_in_enum error, whose "enum" param lists the allowed values
+func suggestEnum(err *DataError) *DataError {
+	submitted, ok := err.Value.(string)
+	if !ok || err.Params["suggestion"] != nil {
+		return err
+	}
+	var candidates []string
+	switch enum := err.Params["enum"].(type) {
+	case []string:
+		candidates = append([]string(nil), enum...)
+	case []interface{}:
+		for _, value := range enum {
+			if str, ok := value.(string); ok {
+				candidates = append(candidates, str)
+			}
+		}
+	}
+	sort.Strings(candidates)
+	if suggestion, ok := closest(submitted, candidates); ok {
+		return suggestError(err, suggestion)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	colors := []string{"red", "green"}
+	schema := &Schema{Validators: map[string]*Validator{
+		"userName":     {Type: "string"},
+		"address.city": {Type: "string"},
+		"color": {Type: "string", CustomTest: func(value interface{}) (bool, *DataError) {
+			if value != "red" && value != "green" {
+				return false, NewError("not_in_enum", "", value, map[string]interface{}{"enum": colors})
+			}
+			return true, nil
+		}},
+	}}
+	document := map[string]interface{}{
+		"username": "a", "adress": map[string]interface{}{}, "address": map[string]interface{}{"ctiy": "x"}, "zzz": 1, "color": "gren",
+	}
+	_, errors := schema.Validate(document, Options{Usage: INIT, Strict: true})
+	suggestions := make(map[string]string)
+	for _, err := range errors {
+		suggestions[err.Field] = err.Suggestion
+	}
+	expected := map[string]string{
+		"username":     `Did you mean "userName"?`,
+		"adress":       `Did you mean "address"?`,
+		"address.ctiy": `Did you mean "address.city"?`,
+		"zzz":          "",
+		"color":        `Did you mean "green"?`,
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for field, suggestion := range expected {
+		if suggestions[field] != suggestion {
+			t.Errorf("%s: expected %q, got %q", field, suggestion, suggestions[field])
+		}
+	}
+	for _, err := range errors {
+		if err.Field == "color" && (err.Params["suggestion"] != "green" || !strings.HasSuffix(err.Error(), `. Did you mean "green"?`)) {
+			t.Errorf("expected the suggestion in the params and the message, got %v %v", err.Params, err)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	for pair, distance := range map[[2]string]int{{"kitten", "sitting"}: 3, {"", "abc"}: 3, {"abc", "abc"}: 0, {"été", "ete"}: 2} {
+		if got := levenshtein(pair[0], pair[1]); got != distance {
+			t.Errorf("%v: expected %d, got %d", pair, distance, got)
+		}
+	}
+	if _, ok := closest("abcdef", []string{"uvwxyz"}); ok {
+		t.Errorf("expected no suggestion for a distant candidate")
+	}
+}
@@ -0,0 +1,48 @@
+package validation
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This public function creates a document from a server-side base (a template) and the submitted input, e.g. for a template-based resource creation
+// the input is merged over the base – the sub-documents both have are merged, any other input value replaces the base one –, then the result is validated on INIT:
+// the defaults only apply to the fields the merged document misses, and the required ones may be provided by the base alone
+// the base is trusted: the INIT rights are only checked on the fields the input provides, and neither base nor input is modified
+func ApplyDefaultsDeep(schema DocumentValidator, base map[string]interface{}, input map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	merged := mergeDeep(copyDeep(base), input)
+	opt.Usage = INIT
+	opt.Override = trustBase(documentSchema(schema), base, input, opt)
+	return schema.Validate(merged, opt)
+}
+
+// this private function returns the overriding validators granting the INIT rights on the fields only the base provides
+// the ones of the options are kept, and the fields out of the profile are not added up
+func trustBase(schema *Schema, base map[string]interface{}, input map[string]interface{}, opt Options) map[string]*Validator {
+	profiled, opt, _, err := applyProfile(schema, opt)
+	if err != nil {
+		// the validation reports the unknown profile
+		return opt.Override
+	}
+	profiled = applyOverride(profiled, opt.Override)
+
+	var override map[string]*Validator
+	for path, validator := range profiled.Validators {
+		in, _ := validator.Paths(path, INIT)
+		if validator.Rights[INIT] == UNAUTHENTICATED || !isSubmitted(base, in) || isSubmitted(input, in) {
+			continue
+		}
+		if override == nil {
+			override = make(map[string]*Validator, len(opt.Override)+1)
+			for overridden, validator := range opt.Override {
+				override[overridden] = validator
+			}
+		}
+		trusted := validator.Clone()
+		trusted.Rights[INIT] = UNAUTHENTICATED
+		override[path] = trusted
+	}
+	if override == nil {
+		return opt.Override
+	}
+	return override
+}
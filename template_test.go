@@ -0,0 +1,37 @@
+package validation
+
+import "testing"
+
+func TestApplyDefaultsDeep(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":   {Type: "string", IsRequired: true},
+		"plan":   {Type: "string", Rights: [3]int{ADMIN, 0, ADMIN}},
+		"conf.a": {Type: "string", Default: func(interface{}) interface{} { return "default-a" }},
+		"conf.b": {Type: "string", Default: func(interface{}) interface{} { return "default-b" }},
+		"conf.c": {Type: "string"},
+	}}
+	base := map[string]interface{}{"plan": "gold", "conf": map[string]interface{}{"b": "base-b"}}
+	input := map[string]interface{}{"name": "Ada", "conf": map[string]interface{}{"c": "input-c"}}
+	dest, errors := ApplyDefaultsDeep(schema, base, input, Options{UserRights: USER})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	conf := dest["conf"].(map[string]interface{})
+	if dest["plan"] != "gold" || conf["a"] != "default-a" || conf["b"] != "base-b" || conf["c"] != "input-c" {
+		t.Errorf("expected the input merged over the base and the defaults, got %v", dest)
+	}
+	if _, ok := base["conf"].(map[string]interface{})["c"]; ok {
+		t.Errorf("expected the base to be left untouched, got %v", base)
+	}
+
+	// the rights are checked on the fields the input provides
+	_, errors = ApplyDefaultsDeep(schema, base, map[string]interface{}{"name": "Ada", "plan": "free"}, Options{UserRights: USER})
+	if len(errors) != 1 || errors[0].Code != "insufficient_rights" || errors[0].Field != "plan" {
+		t.Errorf("expected the plan rights, got %v", errors)
+	}
+
+	// the required fields may come from the base alone
+	if _, errors := ApplyDefaultsDeep(schema, map[string]interface{}{"name": "Ada"}, nil, Options{}); len(errors) > 0 {
+		t.Errorf("expected the base name to be enough, got %v", errors)
+	}
+}
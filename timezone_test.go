@@ -0,0 +1,21 @@
+package validation
+
+import "testing"
+
+func TestTimezone(t *testing.T) {
+	for _, name := range []string{"Europe/Paris", "America/Argentina/Buenos_Aires", "UTC", "Etc/GMT+5", "US/Pacific"} {
+		if _, ok := Timezone(name); !ok {
+			t.Errorf("%q: expected a time zone", name)
+		}
+	}
+	for _, name := range []string{"", "Local", "Europe/Atlantis", "europe/paris", "Europe/ Paris", "../etc/passwd", `Europe\Paris`} {
+		if _, ok := Timezone(name); ok {
+			t.Errorf("%q: expected the name to be refused", name)
+		}
+	}
+
+	schema := &Schema{Validators: map[string]*Validator{"zone": {Type: "string", Format: "timezone"}}}
+	if _, errors := schema.Validate(map[string]interface{}{"zone": "Mars/Olympus"}, Options{Usage: INIT}); len(errors) != 1 || errors[0].Code != "invalid_format" {
+		t.Errorf("expected an invalid format, got %v", errors)
+	}
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct follows the validation of a field: it accumulates the rules run for Options.Coverage, and the steps written to Options.Trace
+type fieldTrace struct {
+	on     bool
+	path   string
+	rules  []string
+	writer io.Writer
+	steps  strings.Builder
+}
+
+// the lock of the trace writers: the steps of a field are written at once, even when the fields are validated concurrently
+var traceLock sync.Mutex
+
+// the names of the usages, as written in the traces
+var usageNames = [...]string{INIT: "INIT", GET: "GET", SET: "SET"}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method records a rule run
+func (t *fieldTrace) add(rule string) {
+	if !t.on {
+		return
+	}
+	if t.writer != nil {
+		t.step("run %s", rule)
+	}
+	t.rules = append(t.rules, rule)
+}
+
+// This method writes a step of the validation of the field
+func (t *fieldTrace) step(format string, args ...interface{}) {
+	if t.writer == nil {
+		return
+	}
+	fmt.Fprintf(&t.steps, "validation: %s: ", t.path)
+	fmt.Fprintf(&t.steps, format, args...)
+	t.steps.WriteByte('\n')
+}
+
+// This method records the rules run while coercing the value
+func (t *fieldTrace) addCoerce(validator *Validator) {
+	if !t.on {
+		return
+	}
+	if validator.RawJSON {
+		t.add("rawjson")
+	} else if validator.Decimal != nil {
+		t.add("decimal")
+	} else if validator.Boolish && validator.Type == "bool" {
+		t.add("boolish")
+	}
+}
+
+// This method records the rules checkValue runs on the value
+func (t *fieldTrace) addValue(validator *Validator, value interface{}) {
+	if !t.on {
+		return
+	}
+	switch value := value.(type) {
+	case string:
+		if validator.Regexp != "" || len(validator.Regexps) > 0 {
+			t.add("regexp")
+		}
+	case json.Number:
+		if validator.Decimal == nil {
+			t.add("boundaries")
+		}
+	case float64:
+		if validator.Decimal == nil && validator.Boundaries != (Boundaries{}) {
+			t.add("boundaries")
+		}
+	case map[string]interface{}:
+		if validator.Money != nil {
+			t.add("money")
+		}
+		if validator.Schedule != nil {
+			t.add("schedule")
+		}
+	default:
+		if _, _, _, ok := integerValue(value); ok {
+			t.add("boundaries")
+		}
+	}
+}
+
+// This method writes the outcome of the validation of the field, then all its steps at once
+func (t *fieldTrace) finish(result *fieldResult) {
+	if t.writer == nil {
+		return
+	}
+	if len(result.errors) > 0 {
+		codes := make([]string, len(result.errors))
+		for i, err := range result.errors {
+			codes[i] = err.Code
+			if codes[i] == "" {
+				codes[i] = "custom_test_failed"
+			}
+		}
+		t.step("invalid: %s", strings.Join(codes, ", "))
+	} else if result.write {
+		t.step("valid: %s = %#v", result.out, result.value)
+	} else {
+		t.step("valid: nothing written")
+	}
+
+	traceLock.Lock()
+	defer traceLock.Unlock()
+	io.WriteString(t.writer, t.steps.String())
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function returns the trace of the validation of a field, which only records anything when Options.Coverage or Options.Trace is set
+func newFieldTrace(path string, opt Options) fieldTrace {
+	return fieldTrace{on: opt.Coverage != nil || opt.Trace != nil, path: path, writer: opt.Trace}
+}
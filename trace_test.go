@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":  {Type: "string", Regexp: "^a", IsRequired: true, Rights: [3]int{USER, 0, 0}},
+		"age":   {Type: "int", IntBoundaries: &IntBoundaries{Min: 0, Max: 10}},
+		"ratio": {Type: "float64", Boundaries: Boundaries{Min: 0, Max: 1}},
+		"role":  {Type: "string", Default: func(interface{}) interface{} { return "user" }},
+	}}
+	var trace bytes.Buffer
+	schema.Validate(map[string]interface{}{"name": "b", "age": json.Number("3"), "ratio": 0.5}, Options{Usage: INIT, Trace: &trace, Concurrency: 4, UserRights: ADMIN})
+
+	// the fields are validated concurrently, but the steps of a field are written at once
+	expected := map[string]string{
+		"name": `validation: name: read string "b" at "name"
+validation: name: run required
+validation: name: run type
+validation: name: run regexp
+validation: name: invalid: regex_not_match
+`,
+		"age": `validation: age: read json.Number "3" at "age"
+validation: age: run type
+validation: age: run boundaries
+validation: age: valid: age = 3
+`,
+		"ratio": `validation: ratio: read float64 0.5 at "ratio"
+validation: ratio: run type
+validation: ratio: run boundaries
+validation: ratio: valid: ratio = 0.5
+`,
+		"role": `validation: role: not submitted at "role"
+validation: role: run default
+validation: role: valid: role = "user"
+`,
+	}
+	output := trace.String()
+	for path, steps := range expected {
+		if !strings.Contains(output, steps) {
+			t.Errorf("%s: expected the steps\n%s\ngot\n%s", path, steps, output)
+		}
+	}
+	if strings.Count(output, "\n") != 16 {
+		t.Errorf("expected 16 steps, got\n%s", output)
+	}
+}
+
+func TestTraceRights(t *testing.T) {
+	var trace bytes.Buffer
+	validators := map[string]*Validator{"name": {Type: "string", Rights: [3]int{ADMIN, 0, 0}}}
+	Validate(validators, map[string]interface{}{"name": "a"}, Options{Usage: INIT, Trace: &trace, UserRights: USER})
+	if !strings.Contains(trace.String(), "validation: name: rights: 3 required on INIT, user has 1\n") || !strings.HasSuffix(trace.String(), "validation: name: invalid: insufficient_rights\n") {
+		t.Errorf("unexpected trace\n%s", trace.String())
+	}
+}
+
+func TestTraceRegexRules(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"password": {Type: "string", Regexps: []RegexRule{{Pattern: "[0-9]"}}},
+	}}
+	var trace bytes.Buffer
+	schema.Validate(map[string]interface{}{"password": "secret"}, Options{Usage: INIT, Trace: &trace})
+	if output := trace.String(); !strings.Contains(output, "validation: password: run regexp\n") {
+		t.Errorf("expected the regexp rules to be traced, got\n%s", output)
+	}
+}
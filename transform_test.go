@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	validators := map[string]*Validator{
+		"email": {Type: "string", Transform: func(value interface{}) (interface{}, error) {
+			if value == "" {
+				return nil, errors.New("empty email")
+			}
+			return strings.ToLower(value.(string)), nil
+		}},
+	}
+	for _, usage := range []int{INIT, SET} {
+		if dest, errs := Validate(validators, map[string]interface{}{"email": "A@B.fr"}, Options{Usage: usage}); len(errs) != 0 || dest["email"] != "a@b.fr" {
+			t.Errorf("%d: expected the value to be transformed, got %v %v", usage, dest, errs)
+		}
+	}
+	if dest, errs := Validate(validators, map[string]interface{}{"email": "A@B.fr"}, Options{Usage: GET}); len(errs) != 0 || dest["email"] != "A@B.fr" {
+		t.Errorf("expected the stored value not to be transformed on GET, got %v %v", dest, errs)
+	}
+	if _, errs := Validate(validators, map[string]interface{}{"email": ""}, Options{Usage: INIT}); len(errs) != 1 || errs[0].Code != "transform_failed" || errs[0].Reason != "empty email" {
+		t.Errorf("expected the transform error, got %v", errs)
+	}
+}
@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"reflect"
+	"strconv"
+)
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function validates the items of an array against the validators of their position, e.g. [longitude, latitude]
+// the items past the tuple are validated against AdditionalItems, and refused without it; the array being validated as a whole, on SET the INIT rules apply
+func checkTuple(validator *Validator, in string, value interface{}, rights int, opt Options, errors *[]*DataError) (interface{}, bool) {
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return value, true
+	}
+	if validator.AdditionalItems == nil && slice.Len() > len(validator.Tuple) {
+		*errors = append(*errors, NewError("too_many_items", in, slice.Len(), map[string]interface{}{"max": len(validator.Tuple)}))
+		return value, false
+	}
+
+	itemOpt := itemOptions(opt, rights, true)
+	items := make([]interface{}, 0, slice.Len())
+	valid := true
+	for i := 0; i < slice.Len() || i < len(validator.Tuple); i++ {
+		itemValidator := validator.AdditionalItems
+		if i < len(validator.Tuple) {
+			itemValidator = validator.Tuple[i]
+		}
+		var item interface{}
+		if i < slice.Len() {
+			item = slice.Index(i).Interface()
+		}
+
+		key := strconv.Itoa(i)
+		document := map[string]interface{}{key: item}
+		result := validateField(key, itemValidator, document, document, rights, itemOpt)
+		if len(result.errors) > 0 {
+			located := append(ParsePath(in), PathSegment{Index: i, IsIndex: true})
+			for _, err := range result.errors {
+				relocated := *err
+				relocated.Field = joinPath(in, err.Field)
+				relocated.Path = append(append(make([]PathSegment, 0, len(located)+len(err.Path)), located...), err.Path[1:]...)
+				*errors = append(*errors, &relocated)
+			}
+			valid = false
+			continue
+		}
+
+		// the positions are kept: a null item stays null, and only the missing items past the array may be defaulted
+		if result.write {
+			items = append(items, result.value)
+		} else if i < slice.Len() {
+			items = append(items, item)
+		}
+	}
+	return items, valid
+}
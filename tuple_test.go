@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTuple(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"loc": {Type: "[]interface {}", Tuple: []*Validator{
+			{Type: "json.Number", Boundaries: Boundaries{Min: -180, Max: 180}, IsRequired: true},
+			{Type: "json.Number", Boundaries: Boundaries{Min: -90, Max: 90}, IsRequired: true},
+		}},
+		"tags": {Type: "[]interface {}", Tuple: []*Validator{{Type: "string"}}, AdditionalItems: &Validator{Type: "int64", IntBoundaries: &IntBoundaries{Max: 5}}},
+	}}
+	if errors := CheckSchema(schema); len(errors) > 0 {
+		t.Fatalf("expected no schema errors, got %v", errors)
+	}
+	dest, errors := schema.Validate(map[string]interface{}{"loc": []interface{}{json.Number("2.5"), json.Number("48.1")}, "tags": []interface{}{"a", json.Number("3")}}, Options{Usage: INIT})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if tags := dest["tags"]; !reflect.DeepEqual(tags, []interface{}{"a", int64(3)}) {
+		t.Errorf("expected the coerced additional item, got %#v", tags)
+	}
+
+	// the array is validated as a whole on SET
+	_, errors = schema.Validate(map[string]interface{}{"loc": []interface{}{json.Number("200")}, "tags": []interface{}{"a", json.Number("9")}}, Options{Usage: SET})
+	expected := [][2]string{{"out_of_boundaries", "loc.0"}, {"required", "loc.1"}, {"out_of_boundaries", "tags.1"}}
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for i, err := range errors {
+		if err.Code != expected[i][0] || err.Field != expected[i][1] {
+			t.Errorf("error %d: expected %v, got %s on %s", i, expected[i], err.Code, err.Field)
+		}
+	}
+	if path := errors[2].Path; len(path) != 2 || !path[1].IsIndex || path[1].Index != 1 {
+		t.Errorf("expected the tags[1] path, got %v", path)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{"loc": []interface{}{json.Number("1"), json.Number("2"), json.Number("3")}}, Options{Usage: SET})
+	if len(errors) != 1 || errors[0].Code != "too_many_items" || errors[0].Params["max"] != 2 {
+		t.Errorf("expected too many items, got %v", errors)
+	}
+}
+
+func TestTupleCheck(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"a": {Type: "string", Tuple: []*Validator{{Type: "x y"}}},
+		"b": {Type: "[]interface {}", AdditionalItems: &Validator{Type: "string"}},
+	}}
+	expected := []string{"non-array type", "a.0", "additional items validator without tuple"}
+	errors := CheckSchema(schema)
+	if len(errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errors)
+	}
+	for i, err := range errors {
+		if !strings.Contains(err.Error(), expected[i]) {
+			t.Errorf("error %d: expected %q, got %v", i, expected[i], err)
+		}
+	}
+
+	description, err := describeSchema(&Schema{Validators: map[string]*Validator{"loc": {Type: "[]interface {}", Tuple: []*Validator{{Type: "float64"}}, AdditionalItems: &Validator{Type: "string"}}}})
+	if err != nil || !strings.Contains(string(description), `"Tuple":[{"Type":"float64"`) || !strings.Contains(string(description), `"AdditionalItems":{"Type":"string"`) {
+		t.Errorf("expected the tuple to be published, got %s %v", description, err)
+	}
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct is the parsed form of a Validator.Type, built once by Compile rather than for every value checked – see parseType
+type typeDescriptor struct {
+	source string          // the type representation it was parsed from
+	kind   typeKind        // what the type is made of
+	elem   *typeDescriptor // the element type of a slice, the value type of a map, the pointed type of a pointer
+	key    *typeDescriptor // the key type of a map
+}
+
+// This private type is the kind of a typeDescriptor
+type typeKind int
+
+// the kinds of the types: interface {} accepts any value, a named type (string, json.Number, bson.ObjectId...) is compared to the value type name
+const (
+	anyType typeKind = iota
+	namedType
+	sliceType
+	mapType
+	pointerType
+)
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method returns the descriptor of the validator type, parsed on the fly if the type has been changed since the validator was compiled
+// an invalid type, which CheckSchema – and thus Compile – reports beforehand, is a named type no value matches
+func (v *Validator) typeDescriptor() *typeDescriptor {
+	if v.descriptor != nil && v.descriptor.source == v.Type {
+		return v.descriptor
+	}
+	descriptor, err := parseType(v.Type)
+	if err != nil {
+		return &typeDescriptor{source: v.Type, kind: namedType}
+	}
+	return descriptor
+}
+
+// This method tells whether a value of the type name matches the descriptor; a hex string matches a bson.ObjectId
+func (d *typeDescriptor) matches(name string, value interface{}) bool {
+	if d.kind == anyType || name == d.source {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && d.source == "bson.ObjectId" && isObjectIdHex(str)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// this private function parses a type representation written as reflect would write it
+// e.g. string, json.Number, bson.ObjectId, []string, map[string]interface {}, *big.Rat
+func parseType(_type string) (*typeDescriptor, error) {
+	descriptor := &typeDescriptor{source: _type}
+	var err error
+	switch {
+	case _type == "":
+		return nil, fmt.Errorf("empty type")
+	case _type == "interface {}":
+		descriptor.kind = anyType
+	case strings.HasPrefix(_type, "[]"):
+		descriptor.kind = sliceType
+		descriptor.elem, err = parseType(_type[2:])
+	case strings.HasPrefix(_type, "*"):
+		descriptor.kind = pointerType
+		descriptor.elem, err = parseType(_type[1:])
+	case strings.HasPrefix(_type, "map["):
+		end := strings.Index(_type, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed map key")
+		}
+		descriptor.kind = mapType
+		if descriptor.key, err = parseType(_type[4:end]); err == nil {
+			descriptor.elem, err = parseType(_type[end+1:])
+		}
+	default:
+		// a predeclared or a qualified type name
+		for _, part := range strings.SplitN(_type, ".", 2) {
+			if !isIdentifier(part) {
+				return nil, fmt.Errorf("invalid name %q", part)
+			}
+		}
+		descriptor.kind = namedType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	for _type, valid := range map[string]bool{
+		"string": true, "json.Number": true, "[]bson.ObjectId": true, "map[string]interface {}": true,
+		"map[bson.ObjectId]string": true, "*big.Rat": true, "[]map[string]interface {}": true,
+		"": false, "[]": false, "map[string": false, "map[]int": false, "a b": false, "1x": false,
+	} {
+		if _, err := parseType(_type); (err == nil) != valid {
+			t.Errorf("%q: expected valid %v, got %v", _type, valid, err)
+		}
+	}
+
+	descriptor, _ := parseType("map[bson.ObjectId][]interface {}")
+	if descriptor.kind != mapType || descriptor.key.source != "bson.ObjectId" || descriptor.elem.kind != sliceType || descriptor.elem.elem.kind != anyType {
+		t.Errorf("expected a map of ObjectIds to slices of any value, got %+v", descriptor)
+	}
+}
+
+func TestTypeDescriptor(t *testing.T) {
+	hex := "5f1b2c3d4e5f60718293a4b5"
+	schema := MustCompile(&Schema{Validators: map[string]*Validator{
+		"ids":    {Type: "[]bson.ObjectId"},
+		"prices": {Type: "map[bson.ObjectId]json.Number"},
+		"tags":   {Type: "[]string"},
+	}})
+	document := map[string]interface{}{"ids": []interface{}{hex}, "prices": map[string]interface{}{hex: json.Number("1")}, "tags": []interface{}{"a"}}
+	if _, errors := schema.Validate(document, Options{Usage: INIT}); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+	document = map[string]interface{}{"ids": []interface{}{hex, "zz"}, "prices": map[string]interface{}{"x": json.Number("1")}, "tags": []interface{}{"a", 1.0}}
+	_, errors := schema.Validate(document, Options{Usage: INIT})
+	if len(errors) != 3 {
+		t.Fatalf("expected 3 type mismatches, got %v", errors)
+	}
+	for _, err := range errors {
+		if err.Code != "type_mismatch" {
+			t.Errorf("expected a type mismatch, got %v", err)
+		}
+	}
+
+	// the type changed after the compilation is parsed again
+	validator := &Validator{Type: "string"}
+	clone := validator.Clone()
+	clone.Type = "int"
+	if descriptor := clone.typeDescriptor(); descriptor.source != "int" {
+		t.Errorf("expected the changed type, got %q", descriptor.source)
+	}
+}
+
+func TestInvalidType(t *testing.T) {
+	validators := map[string]*Validator{"a": {Type: "["}, "b": {Type: "map[string"}}
+	_, errors := Validate(validators, map[string]interface{}{"a": "x", "b": map[string]interface{}{}}, Options{Usage: INIT})
+	if len(errors) != 2 || errors[0].Code != "type_mismatch" || errors[1].Code != "type_mismatch" {
+		t.Errorf("expected the invalid types to match no value, got %v", errors)
+	}
+}
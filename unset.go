@@ -0,0 +1,71 @@
+package validation
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This private struct is the type of the Unset sentinel
+type unset struct{}
+
+// The value meaning "remove this field" on SET: submitted in place of a value, it is written in dest when the removal is permitted – see Update
+// a field whose validator is Nullable can also be removed by submitting null
+var Unset = unset{}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method marshals the sentinel as null
+func (unset) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function splits the dest of a SET validation into a MongoDB update document: the values under "$set", the removed fields under "$unset",
+// and the version field under "$inc" – see VersionFilter
+// the operators absent from the update are omitted
+func Update(dest map[string]interface{}) map[string]interface{} {
+	set := make(map[string]interface{})
+	unsetFields := make(map[string]interface{})
+	inc := make(map[string]interface{})
+	for path, value := range dest {
+		if value == Unset {
+			unsetFields[path] = ""
+		} else if _, ok := value.(VersionCheck); ok {
+			inc[path] = 1
+		} else {
+			set[path] = value
+		}
+	}
+
+	update := make(map[string]interface{})
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unsetFields) > 0 {
+		update["$unset"] = unsetFields
+	}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+	return update
+}
+
+// this private function checks the removal of a field is permitted: it is not required (on SET either), nor immutable, and the user has the SET rights
+func checkUnset(validator *Validator, in string, out string, rights int, opt Options, errors *[]*DataError) bool {
+	if validator.IsRequired || validator.RequiredOnSet {
+		*errors = append(*errors, NewError("required", in, nil, nil))
+		return false
+	}
+	if !checkRights(validator, SET, rights, collaboratorsOf(opt).Rights, errors) {
+		return false
+	}
+	if validator.Immutable && (opt.Existing == nil || readExisting(opt.Existing, out) != nil) {
+		*errors = append(*errors, NewError("immutable", in, nil, nil))
+		return false
+	}
+	return true
+}
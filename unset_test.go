@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnset(t *testing.T) {
+	schema := &Schema{Validators: map[string]*Validator{
+		"name":    {Type: "string", IsRequired: true, Nullable: true},
+		"nick":    {Type: "string", Nullable: true},
+		"a.b":     {Type: "string"},
+		"admin":   {Type: "string", Rights: [3]int{0, 0, ADMIN}},
+		"id":      {Type: "string", Immutable: true},
+		"comment": {Type: "string"},
+	}}
+	dest, errors := schema.Validate(map[string]interface{}{"nick": nil, "a": map[string]interface{}{"b": Unset}, "comment": nil}, Options{Usage: SET, UserRights: USER})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	expected := map[string]interface{}{"$unset": map[string]interface{}{"nick": "", "a.b": ""}}
+	if update := Update(dest); !reflect.DeepEqual(update, expected) {
+		t.Errorf("expected %v, got %v", expected, update)
+	}
+
+	_, errors = schema.Validate(map[string]interface{}{"name": nil, "admin": Unset, "id": Unset}, Options{Usage: SET, UserRights: USER, Existing: map[string]interface{}{"id": "x"}})
+	if len(errors) != 3 || errors[0].Code != "insufficient_rights" || errors[1].Code != "immutable" || errors[2].Code != "required" {
+		t.Errorf("expected the admin rights, the immutable id and the required name, got %v", errors)
+	}
+
+	// on INIT, the sentinel is an absent value
+	dest, errors = schema.Validate(map[string]interface{}{"name": "Ada", "nick": Unset}, Options{Usage: INIT})
+	if _, ok := dest["nick"]; len(errors) > 0 || ok {
+		t.Errorf("expected nick to be absent, got %v %v", dest, errors)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	update := Update(map[string]interface{}{"name": "Ada", "nick": Unset})
+	expected := map[string]interface{}{"$set": map[string]interface{}{"name": "Ada"}, "$unset": map[string]interface{}{"nick": ""}}
+	if !reflect.DeepEqual(update, expected) {
+		t.Errorf("expected %v, got %v", expected, update)
+	}
+	if update := Update(map[string]interface{}{}); len(update) != 0 {
+		t.Errorf("expected an empty update, got %v", update)
+	}
+	if data, _ := json.Marshal(map[string]interface{}{"nick": Unset}); string(data) != `{"nick":null}` {
+		t.Errorf("expected the sentinel to marshal as null, got %s", data)
+	}
+}
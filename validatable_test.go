@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"context"
+	"testing"
+)
+
+type ctxKey struct{}
+
+// This struct is a domain type owning its invariants
+type period struct {
+	start, end int
+}
+
+// This method reports an end before the start, and the context value if any as the error value
+func (p period) ValidateSelf(ctx context.Context) []*DataError {
+	if p.end >= p.start {
+		return nil
+	}
+	return []*DataError{nil, {Type: "Validation error", Reason: "End before start", Code: "end_before_start", Field: "end", Value: ctx.Value(ctxKey{})}}
+}
+
+func TestValidatable(t *testing.T) {
+	validators := map[string]*Validator{"booking.period": {Type: "validation.period"}}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+
+	document := map[string]interface{}{"booking": map[string]interface{}{"period": period{start: 2, end: 1}}}
+	_, errors := Validate(validators, document, Options{Usage: INIT, Context: ctx})
+	if len(errors) != 1 || errors[0].Field != "booking.period.end" || errors[0].Pointer() != "/booking/period/end" || errors[0].Value != "request-1" {
+		t.Fatalf("expected the error of the value under the field path, got %v", errors)
+	}
+
+	document = map[string]interface{}{"booking": map[string]interface{}{"period": period{start: 1, end: 2}}}
+	if dest, errors := Validate(validators, document, Options{Usage: INIT}); len(errors) > 0 || dest["booking"] == nil {
+		t.Errorf("expected the valid period to be written, got %v %v", dest, errors)
+	}
+}
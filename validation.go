@@ -2,15 +2,20 @@
 package validation
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"reflect"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/grebett/tools"
-	"gopkg.in/mgo.v2/bson"
 )
 
 //***********************************************************************************
@@ -37,34 +42,184 @@ const (
 
 // DataErrors are detailed errors when receiving or manipulating data
 type DataError struct {
-	Type   string      `json:"type"`
-	Reason string      `json:"reason"`
-	Field  string      `json:"field,omitempty"`
-	Value  interface{} `json:"value,omitempty"`
+	Type   string                 `json:"type"`
+	Reason string                 `json:"reason"`
+	Field  string                 `json:"field,omitempty"`
+	Value  interface{}            `json:"value,omitempty"`
+	Path   []PathSegment          `json:"path,omitempty"`   // the unambiguous path to the field, even if its keys contain dots – see Pointer
+	Code   string                 `json:"code,omitempty"`   // the machine-readable reason, e.g. "type_mismatch"
+	Params map[string]interface{} `json:"params,omitempty"` // the parameters of the rule at fault, e.g. the expected type
+
+	// the error as presented to a person, in the locale of the validation
+	Label      string `json:"label,omitempty"`      // the human-friendly name of the field – see Validator.LabelFor
+	Message    string `json:"message,omitempty"`    // the human-readable reason – see Options.Messages
+	Docs       string `json:"docs,omitempty"`       // the URL of the documentation of the rule at fault – see Options.DocBaseURL
+	Suggestion string `json:"suggestion,omitempty"` // the likely intended input, e.g. `Did you mean "userName"?` for an unknown field or a value out of an enum
+
+	Meta map[string]interface{} `json:"meta,omitempty"` // the correlation metadata of the request the error is about, e.g. its request id – see Options.Meta
 }
 
 // This struct hosts the Validate fn secondary parameters
 type Options struct {
-	Usage      int         // INIT, SET, GET
-	UserRights int         // UNAUTHENTICATED to ADMIN
-	Args       interface{} // custom args to be used with Default fn
+	Usage          int                      // INIT, SET, GET
+	UserRights     int                      // UNAUTHENTICATED to ADMIN
+	Args           interface{}              // custom args to be used with Default fn
+	Existing       map[string]interface{}   // the document as currently stored, if any (SET or INIT on upsert)
+	OwnerField     string                   // the path of the owner id in Existing, used to grant OWNER rights
+	UserID         interface{}              // the id of the user, compared to the OwnerField value of Existing
+	Profile        string                   // the name of the schema profile to apply – see Profile
+	Strict         bool                     // reject the submitted fields no validator is written for
+	Override       map[string]*Validator    // the validators replacing (or adding up to) the schema ones for this call only, by path
+	Concurrency    int                      // the number of fields validated in parallel, sequential below 2 – the custom tests must then be safe for concurrent use
+	MaxDepth       int                      // the maximal nesting of the submitted documents and arrays, unlimited if 0
+	MaxFields      int                      // the maximal number of submitted values (fields and array items, at any depth), unlimited if 0
+	Context        context.Context          // the context passed to the Validatable values, context.Background() if nil
+	Coverage       *Coverage                // the collector of the rules run, to find the dead schema entries – see Coverage
+	ArrayFilters   []map[string]interface{} // on SET, the MongoDB arrayFilters of the update, declaring the identifiers of the "items.$[elem].qty" keys
+	ErrorOnMissing []string                 // the paths of the fields required on GET and SET for this call, whereas the missing fields are ignored outside INIT
+	Partial        bool                     // on SET, the absent fields are left untouched but the required ones submitted empty (null, []) are refused
+	HideDeleted    bool                     // on GET, only the SoftDelete.Visible fields of a soft-deleted document are returned
+	Locale         string                   // the locale the fields are named in by the errors, e.g. "fr-FR" – see Validator.Labels
+	Messages       *Messages                // the templates of the messages of the errors, rendered in the Locale
+	DocBaseURL     string                   // the URL of the API documentation the errors link to, followed by the slug of the rule at fault – see Validator.DocSlugs
+	ShadowSchema   *Schema                  // the schema being migrated to, run after the current one whose outcome is returned – see OnShadowDivergence
+	MaxRefDepth    int                      // the maximal nesting of the sub-documents following a schema reference – see Validator.Ref –, unlimited if 0
+	Trace          io.Writer                // the writer of the step-by-step validation of every field (value read, rules run, rights, outcome), for debugging
+
+	// the correlation metadata of the request, e.g. its request and trace ids, attached to every error so the failures can be found in the logs
+	// the map is shared by the errors and must not be modified once the validation started
+	Meta map[string]interface{}
+	// report the submitted values the validation changed or added in the Result, for the clients to show them – see Evaluate
+	ReportModifications bool
+
+	// this function converts the hex strings validated against a "bson.ObjectId" type into the ObjectIds written in dest on INIT and SET, e.g. BsonObjectId or the one of another driver
+	ObjectIdFactory func(hex string) interface{}
+	// this function converts the times validated against a "time.Time" type into the datetimes written in dest on INIT and SET, e.g. the one of a driver
+	TimeFactory func(t time.Time) interface{}
+	// this function returns the current time of the time-based rules (Validator.DefaultNow, TimeWindow, Birthdate), time.Now if nil – e.g. a fixed time in the tests
+	Now func() time.Time
+
+	// this function builds every error of the validation from its code, field, value and rule parameters, in place of the default one – a nil result keeps the default
+	// the built errors get the Meta of the options unless they have their own
+	ErrorFactory func(code, field string, value interface{}, params map[string]interface{}) *DataError
+	// this function receives the divergences of the ShadowSchema outcome from the current one, the shadow schema being only run if it is set
+	OnShadowDivergence func(divergence *ShadowDivergence)
+
+	root          *Schema        // the schema the validation started with, the references resolve against
+	refDepth      int            // the number of references followed to reach the validated document
+	collaborators *Collaborators // the ones of the compiled schema – see CompiledSchema.WithCollaborators
 }
 
 // Error stringer for DataErrors
 func (e *DataError) Error() string {
-	return fmt.Sprintf("%s for %s = %v: %s", e.Type, e.Field, e.Value, e.Reason)
+	field, reason := e.Field, e.Reason
+	if e.Label != "" {
+		field = e.Label
+	}
+	if e.Message != "" {
+		reason = e.Message
+	}
+	if e.Suggestion != "" {
+		reason += ". " + e.Suggestion
+	}
+	return fmt.Sprintf("%s for %s = %v: %s", e.Type, field, e.Value, reason)
+}
+
+// This interface is implemented by the domain types owning their invariants
+// when a submitted value implements it, the errors it returns are reported under the field path, their own Field and Path being relative to it
+type Validatable interface {
+	ValidateSelf(ctx context.Context) []*DataError
 }
 
 // This struct contains information about a specifical fields – could be a separated package later
 type Validator struct {
-	Type       string                               // the string representation of the expected type
-	Field      string                               // the key the validator is about
-	Regexp     string                               // if a string, the pattern the valus has to match
-	Rights     [3]int                               // INIT, GET, SET minimal value to equal to act on the field value
-	Boundaries Boundaries                           // if a number, the min and max boundaries for the value
-	IsRequired bool                                 // is the field required
-	Default    func(interface{}) interface{}        // this function is called to replace the optional nil value with default one - the arg interface{} value is usually a map[string]interface{} -- should I change it?
-	CustomTest func(interface{}) (bool, *DataError) // this function enables user custom testing
+	Type          string                                 // the string representation of the expected type
+	Field         string                                 // the key the validator is about
+	Source        string                                 // the key read in the submitted data, when it differs from the schema path
+	Target        string                                 // the key written in the stored document, when it differs from the schema path
+	Label         string                                 // the human-friendly name of the field the errors refer to, e.g. "Email address"
+	Labels        map[string]string                      // the translations of the Label, by locale or language, e.g. {"fr": "Adresse e-mail"}
+	Regexp        string                                 // if a string, the pattern the valus has to match, or the name of a registered one, e.g. "@slug" – see RegisterPattern
+	Regexps       []RegexRule                            // if a string, the patterns the value has to match or not, along with Regexp, e.g. "must not contain the user name"
+	Rights        [3]int                                 // INIT, GET, SET minimal value to equal to act on the field value
+	Boundaries    Boundaries                             // if a number, the min and max boundaries for the value – the float64 values are bounded only when they are set
+	IntBoundaries *IntBoundaries                         // if an integer, the exact min and max boundaries for the value, in place of Boundaries
+	Decimal       *Decimal                               // if a decimal (e.g. an amount of money), its exact scale and boundaries
+	Money         *Money                                 // if a {amount, currency} sub-document, its currency and amount rules
+	Boolish       bool                                   // if a bool, the strings "true", "1", "yes" and "false", "0", "no" are accepted too, e.g. from a query string or a CSV file
+	RawJSON       bool                                   // the value is any well-formed JSON, stored as a json.RawMessage without type check
+	MaxRawSize    int                                    // if RawJSON, the max size of the encoded value in bytes, unlimited if 0
+	IsRequired    bool                                   // is the field required
+	RequiredOnSet bool                                   // the field is required on SET too, e.g. an id, whereas the missing fields are ignored on update
+	Immutable     bool                                   // once stored, the field value cannot be changed anymore
+	Nullable      bool                                   // on SET, null removes the field, as Unset does
+	Location      *time.Location                         // if a time.Time, the location the time is converted into (e.g. time.UTC), the submitted offset being kept if nil
+	Items         map[string]*Validator                  // if an array of sub-documents, the validators of its elements, by path relative to the element
+	Tuple         []*Validator                           // if an array of fixed positions, the validators of its items by position, e.g. [longitude, latitude]
+	UniqueBy      string                                 // if an array of sub-documents, the path in the elements whose value no two of them may share, e.g. a label
+	Ordered       *Ordered                               // if an array, the order its items must follow, e.g. the points of a time series
+	Ref           string                                 // if a sub-document or an array of them, the schema they follow: "#" for the root one (e.g. a comment tree), or the name of one of its Definitions
+	Default       func(interface{}) interface{}          // this function is called to replace the optional nil value with default one - the arg interface{} value is usually a map[string]interface{} -- should I change it?
+	CustomTest    func(interface{}) (bool, *DataError)   // this function enables user custom testing
+	Transform     func(interface{}) (interface{}, error) // this function turns the validated value into the one written in dest on INIT and SET (hashing, normalization...)
+
+	// if a Tuple, the validator of the items past it, which are refused if nil
+	AdditionalItems *Validator
+	// if a number or a decimal, the strings are parsed with the separators of Options.Locale, e.g. "1.234,56" in "de-DE" – see ParseLocaleNumber
+	// opt-in, for the imports of spreadsheets: the value is written in dest in the validator type, as a JSON number would be
+	LocaleNumbers bool
+	// if a string, the free-text search rule sanitizing the query and bounding its length, e.g. the input of a $text query
+	SearchQuery *SearchQuery
+	// if a string, its named format: "md5hex", "sha1hex", "sha256hex", "hexlen:<n>" for n hexadecimal digits, or "filename" and "filepath" for the names of files – see Filename, "domain" for a domain name – see Domain, or "timezone" for an IANA time zone name
+	Format string
+	// if a date string or a time.Time, the birth date rule bounding the age
+	Birthdate *Birthdate
+	// if a time.Time, the bounds of the time relative to the current one of Options.Now
+	TimeWindow *TimeWindow
+	// if a time.Time, the default of the field is the current time of Options.Now, in the Location if any, e.g. a creation date – the other defaults prevail
+	DefaultNow bool
+	// if a sub-document, the weekly schedule rule of its days and their opening hours ranges, e.g. the opening hours of a shop
+	Schedule *Schedule
+	// if a "domain" format, the domains refused along with their subdomains, e.g. the disposable email providers
+	BlockedDomains DomainList
+	// if a hash format, the stored path of the field whose content the value is the hash of, e.g. the checksum of an uploaded file
+	HashOf string
+	// the feature flags of the rules, by rule name as in the traces ("regexp", "boundaries", "custom"...): a rule is not run while its function returns true for the Options.Context,
+	// so a stricter rule can be rolled out gradually, or killed without a deploy – the type and rights checks cannot be disabled
+	DisabledWhen map[string]func(ctx context.Context) bool
+	// the slugs of the documentation of the field rules appended to Options.DocBaseURL, by error code or "*" for all of them, in place of the code, e.g. {"invalid_format": "formats/email"}
+	DocSlugs map[string]string
+	// the candidate defaults, tried in order before the static ones – see DefaultValue
+	Defaults []ConditionalDefault
+	// this function is called like Default, but also receives the merged document to derive the default value from the other fields – it prevails over Default
+	DefaultFromDoc func(doc map[string]interface{}, args interface{}) interface{}
+	// this function returns the value Serialize outputs in place of the one the user has no GET rights for (e.g. the last digits of a card number), the field being stripped if nil
+	Mask func(value interface{}) interface{}
+	// this function tests the value against the merged document (existing values overlaid with the submitted ones) and the existing one
+	CrossTest func(value interface{}, doc map[string]interface{}, existing map[string]interface{}) (bool, *DataError)
+
+	compiled   *regexp.Regexp  // the compiled Regexp, shared by the clones of the validator
+	descriptor *typeDescriptor // the parsed Type, shared by the clones of the validator
+}
+
+// This private struct hosts the outcome of the validation of a single field
+type fieldResult struct {
+	errors []*DataError
+	out    string      // the path to write the value to in dest
+	value  interface{} // the value to write in dest
+	write  bool        // whether the value has to be written
+}
+
+// the buffers of field results are reused between Validate calls
+var resultsPool = sync.Pool{
+	New: func() interface{} { return new([]fieldResult) },
+}
+
+// This inner struct is a default value applying only if its condition is met - see above
+// both functions receive the same arguments as Validator.DefaultFromDoc; a nil When always applies
+type ConditionalDefault struct {
+	When  func(doc map[string]interface{}, args interface{}) bool
+	Value func(doc map[string]interface{}, args interface{}) interface{}
 }
 
 // This inner struct sets the boundaries for an int value - see above
@@ -79,18 +234,145 @@ type Boundaries struct {
 
 // This method create a regexp from the pattern defined in the Validation struct and test it for the provided string
 func (v *Validator) ExecRegexp(str string) (bool, error) {
-	validate, err := regexp.Compile(v.Regexp)
+	// the compiled regexp is only used if the pattern has not been changed since
+	if v.compiled != nil && v.compiled.String() == v.Regexp {
+		return v.compiled.MatchString(str), nil
+	}
+	validate, err := compilePattern(v.Regexp)
 	if err != nil {
 		return false, err
 	}
 	return validate.MatchString(str), nil
 }
 
+// This method returns a deep copy of the validator, which can be modified without altering the original one
+// the functions, the compiled regexp and the parsed type are shared, since they are never modified
+func (v *Validator) Clone() *Validator {
+	clone := *v
+	if v.Defaults != nil {
+		clone.Defaults = append([]ConditionalDefault(nil), v.Defaults...)
+	}
+	clone.Labels = copyStrings(v.Labels)
+	clone.DocSlugs = copyStrings(v.DocSlugs)
+	if v.DisabledWhen != nil {
+		clone.DisabledWhen = make(map[string]func(ctx context.Context) bool, len(v.DisabledWhen))
+		for rule, disabled := range v.DisabledWhen {
+			clone.DisabledWhen[rule] = disabled
+		}
+	}
+	// the in-memory domain sets are copied, the other lists (e.g. a shared store) being the caller's
+	if domains, ok := v.BlockedDomains.(DomainSet); ok && domains != nil {
+		copied := make(DomainSet, len(domains))
+		for domain, blocked := range domains {
+			copied[domain] = blocked
+		}
+		clone.BlockedDomains = copied
+	}
+	if v.IntBoundaries != nil {
+		boundaries := *v.IntBoundaries
+		clone.IntBoundaries = &boundaries
+	}
+	if v.Decimal != nil {
+		decimal := *v.Decimal
+		clone.Decimal = &decimal
+	}
+	if v.Money != nil {
+		clone.Money = v.Money.Clone()
+	}
+	if v.Ordered != nil {
+		ordered := *v.Ordered
+		clone.Ordered = &ordered
+	}
+	if v.TimeWindow != nil {
+		window := *v.TimeWindow
+		clone.TimeWindow = &window
+	}
+	if v.Birthdate != nil {
+		birthdate := *v.Birthdate
+		clone.Birthdate = &birthdate
+	}
+	if v.Schedule != nil {
+		schedule := *v.Schedule
+		clone.Schedule = &schedule
+	}
+	if v.SearchQuery != nil {
+		searchQuery := *v.SearchQuery
+		clone.SearchQuery = &searchQuery
+	}
+	if v.Regexps != nil {
+		clone.Regexps = cloneRegexRules(v.Regexps)
+	}
+	if v.Items != nil {
+		clone.Items = cloneValidators(v.Items)
+	}
+	if v.Tuple != nil {
+		clone.Tuple = make([]*Validator, len(v.Tuple))
+		for i, item := range v.Tuple {
+			clone.Tuple[i] = item.Clone()
+		}
+	}
+	if v.AdditionalItems != nil {
+		clone.AdditionalItems = v.AdditionalItems.Clone()
+	}
+	if clone.compiled == nil && clone.Regexp != "" {
+		// an invalid pattern is reported when executed, as usual
+		clone.compiled, _ = compilePattern(clone.Regexp)
+	}
+	if clone.descriptor == nil || clone.descriptor.source != clone.Type {
+		// an invalid type is reported by CheckSchema, or when checked
+		clone.descriptor, _ = parseType(clone.Type)
+	}
+	return &clone
+}
+
+// this private function returns a deep copy of the validators, by path
+func cloneValidators(validators map[string]*Validator) map[string]*Validator {
+	clone := make(map[string]*Validator, len(validators))
+	for path, validator := range validators {
+		clone[path] = validator.Clone()
+	}
+	return clone
+}
+
 // This method test if the provided int fits in the validator boundaries
 func (v *Validator) CheckBoundaries(value float64) bool {
 	return value >= v.Boundaries.Min && value <= v.Boundaries.Max
 }
 
+// This method returns the path to read the value from and the path to write it to
+// Source is the submitted key and Target the stored one: on GET, the stored document is read, so they are swapped
+func (v *Validator) Paths(path string, usage int) (string, string) {
+	in, out := path, path
+	if v.Source != "" {
+		in = v.Source
+	}
+	if v.Target != "" {
+		out = v.Target
+	}
+	if usage == GET {
+		return out, in
+	}
+	return in, out
+}
+
+// This method returns the default value of the field, if it has one
+// doc is the submitted document, overlaid on the existing one if any
+// the precedence is: the submitted value (the method is not called then) > the first matching Defaults > DefaultFromDoc > Default
+func (v *Validator) DefaultValue(doc map[string]interface{}, args interface{}) (interface{}, bool) {
+	for _, candidate := range v.Defaults {
+		if candidate.Value != nil && (candidate.When == nil || candidate.When(doc, args)) {
+			return candidate.Value(doc, args), true
+		}
+	}
+	if v.DefaultFromDoc != nil {
+		return v.DefaultFromDoc(doc, args), true
+	}
+	if v.Default != nil {
+		return v.Default(args), true
+	}
+	return nil, false
+}
+
 // This method checks if the user has the rights for the specified usage
 func (v *Validator) CheckRights(userRights int, usage int) bool {
 	return userRights >= usage
@@ -111,61 +393,872 @@ func (v *Validator) CheckRights(userRights int, usage int) bool {
 // This public function runs the provided validators against the provided data
 // The usage int is an enum for INIT, GET or SET value
 // the checkValue flag enables a more complex validation -- is it still needed?
-func Validate(validators map[string]*Validator, _map map[string]interface{}, opt Options) (map[string]interface{}, []*DataError) {
-	errors := make([]*DataError, 0)
-	dest := make(map[string]interface{})
+// When opt.Existing is provided, it is used to resolve OWNER rights, to enforce Immutable fields and to feed the CrossTest fns.
+// On INIT, the returned map is then the existing document merged with the validated values (upsert).
+func Validate(validators map[string]*Validator, _map map[string]interface{}, opt Options) (map[string]interface{}, DataErrors) {
+	return validate(&Schema{Validators: validators}, _map, opt)
+}
 
-	// browse the validators and get the path they are written for
-	for path, validator := range validators {
-		// get the value
-		value, err := tools.ReadDeep(_map, path)
-		if err != nil {
+// This public function validates a single field, e.g. for a live per-field validation endpoint, with the same rules and rights checks as Validate
+// the path is the schema one; the value is validated as if it were submitted alone, the other fields being read from opt.Existing if any
+// it returns the value to store, nil if nothing is to be written (an absent optional field), and the errors – unknown_field if no validator is written for the path
+func ValidateField(schema DocumentValidator, path string, value interface{}, opt Options) (written interface{}, errors DataErrors) {
+	errors = make([]*DataError, 0)
+	defer func() {
+		if r := recover(); r != nil {
+			written, errors = nil, append(errors, internalError(path, r))
+		}
+		finishErrors(errors, opt)
+	}()
+
+	root := documentSchema(schema)
+	opt.root = root
+	if compiled, ok := schema.(*CompiledSchema); ok && compiled.collaborators != nil {
+		opt.collaborators = compiled.collaborators
+	}
+	profiled, opt, minRights, err := applyProfile(root, opt)
+	if err != nil {
+		errors = append(errors, err)
+		return nil, errors
+	}
+	profiled = applyOverride(profiled, opt.Override)
+	rights := resolveRights(opt)
+	if rights < minRights {
+		errors = append(errors, NewError("insufficient_rights", "", nil, map[string]interface{}{"rights": minRights}))
+		return nil, errors
+	}
+	validator, ok := profiled.Validators[path]
+	if !ok {
+		errors = append(errors, NewError("unknown_field", path, nil, nil))
+		return nil, errors
+	}
+
+	in, _ := validator.Paths(path, opt.Usage)
+	_map := make(map[string]interface{})
+	if value != nil {
+		if err := tools.WriteDeep(_map, in, value); err != nil {
 			panic(err)
-		} else {
-			// if the value is nil or is a slice with len == 0
-			if value == nil || (reflect.ValueOf(value).Kind() == reflect.Slice && len(value.([]interface{})) == 0) {
-				// for INIT only, if value does not exist, check in the validators if it is required and apply defaults accordingly
-				if opt.Usage == INIT {
-					// does not check for now if the slice is not nil but has nil values in it...
-					if validator.IsRequired {
-						errors = append(errors, &DataError{Type: "Validation error", Reason: "Required", Field: path})
-					} else if validator.Default != nil {
-						err := tools.WriteDeep(dest, path, validator.Default(opt.Args))
-						if err != nil {
-							panic(err)
-						}
-					}
+		}
+	}
+	merged := rename(profiled, _map, opt.Usage)
+	if opt.Existing != nil {
+		merged = mergeDeep(copyDeep(opt.Existing), merged)
+	}
+
+	result := runField(path, validator, _map, merged, rights, opt)
+	errors = append(errors, result.errors...)
+	if !result.write {
+		return nil, errors
+	}
+	return result.value, errors
+}
+
+// this private function runs the schema against the provided data – see Validate
+// whatever the submitted data, it never panics: an unexpected failure is reported as an "Internal error"
+func validate(schema *Schema, _map map[string]interface{}, opt Options) (dest map[string]interface{}, errors []*DataError) {
+	// the schema being migrated to is run alongside the current one, for the documents of the call only
+	if opt.ShadowSchema != nil && opt.root == nil {
+		return validateShadowed(schema, _map, opt)
+	}
+
+	errors = make([]*DataError, 0)
+	dest = make(map[string]interface{})
+	// the deferred calls run in reverse order: a recovered failure is finished as the other errors are
+	defer func() {
+		finishErrors(errors, opt)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			dest, errors = make(map[string]interface{}), append(errors, internalError("", r))
+		}
+	}()
+
+	// the references resolve against the schema the validation started with
+	if opt.root == nil {
+		opt.root = schema
+	}
+
+	// hostile payloads are rejected before anything else is done
+	if err := checkSize(_map, opt.MaxDepth, stricterLimit(opt.MaxFields, schema.MaxFields)); err != nil {
+		errors = append(errors, err)
+		return dest, errors
+	}
+
+	schema, opt, minRights, err := applyProfile(schema, opt)
+	if err != nil {
+		errors = append(errors, err)
+		return dest, errors
+	}
+	schema = applyOverride(schema, opt.Override)
+	rights := resolveRights(opt)
+
+	// the idempotency key has its rules whatever its validator
+	if schema.IdempotencyField != "" {
+		schema = withIdempotencyKey(schema)
+	}
+
+	// the profile may be restricted to some users
+	if rights < minRights {
+		errors = append(errors, NewError("insufficient_rights", "", nil, map[string]interface{}{"rights": minRights}))
+		return dest, errors
+	}
+
+	// the validators depending on the presence of other fields are added up
+	if len(schema.DependentValidators) > 0 {
+		schema = applyDependents(schema, _map, opt)
+	}
+
+	// on SET, the keys targeting array elements are validated against the items validators, once the other fields are
+	submitted := _map
+	var positional []string
+	if opt.Usage == SET {
+		_map, positional = splitPositional(schema, _map)
+	}
+
+	// the version field is handled apart from the validators
+	var version interface{}
+	var versionSubmitted bool
+	if schema.VersionField != "" {
+		_map, version, versionSubmitted = splitVersion(schema, _map)
+	}
+
+	// the fields of a soft-deleted document are hidden on GET, and protected on SET
+	if schema.SoftDelete != nil {
+		if opt.Usage == GET && opt.HideDeleted && readExisting(_map, schema.SoftDelete.field()) != nil {
+			schema = hideDeleted(schema)
+		} else if opt.Usage == SET && isDeleted(schema, opt) {
+			checkDeleted(schema, _map, &errors)
+		}
+	}
+
+	// the submitted fields must all be known in strict mode
+	if opt.Strict {
+		checkUnknown(schema, _map, opt.Usage, &errors)
+	}
+
+	// the merged document is the existing one overlaid with the submitted values
+	merged := rename(schema, _map, opt.Usage)
+	if opt.Existing != nil {
+		merged = mergeDeep(copyDeep(opt.Existing), merged)
+		if opt.Usage == INIT {
+			dest = copyDeep(opt.Existing)
+		}
+	}
+
+	// browse the validators and get the path they are written for, in a stable order
+	paths := schema.sortedPaths()
+
+	// the fields are validated independently, and their results merged in order
+	buffer := resultsPool.Get().(*[]fieldResult)
+	if cap(*buffer) < len(paths) {
+		*buffer = make([]fieldResult, len(paths))
+	}
+	results := (*buffer)[:len(paths)]
+	defer func() {
+		for i := range results {
+			results[i] = fieldResult{}
+		}
+		resultsPool.Put(buffer)
+	}()
+
+	runFields(len(paths), opt.Concurrency, func(i int) {
+		// a failure is confined to its field, and never reaches the workers goroutines
+		defer func() {
+			if r := recover(); r != nil {
+				results[i] = fieldResult{errors: []*DataError{internalError(paths[i], r)}}
+			}
+		}()
+		results[i] = runField(paths[i], schema.Validators[paths[i]], _map, merged, rights, opt)
+	})
+
+	count := len(errors)
+	for _, result := range results {
+		count += len(result.errors)
+	}
+	if count > cap(errors) {
+		errors = append(make([]*DataError, 0, count), errors...)
+	}
+	for _, result := range results {
+		errors = append(errors, result.errors...)
+		if result.write {
+			// copy value to dest (differs based on usage: mongoDB need dot notation for update --> https://docs.mongodb.org/manual/reference/glossary/#term-dot-notation)
+			if opt.Usage == SET {
+				dest[result.out] = result.value
+			} else {
+				err := tools.WriteDeep(dest, result.out, result.value)
+				if err != nil {
+					panic(err)
 				}
-				// else the field is simply ignored
-				continue
+			}
+		}
+	}
+
+	if opt.Usage == SET && (len(positional) > 0 || len(opt.ArrayFilters) > 0) {
+		checkArrayFilters(positional, opt.ArrayFilters, &errors)
+		validatePositional(schema, submitted, positional, dest, rights, opt, &errors)
+	}
+
+	var versionWrite bool
+	if schema.VersionField != "" {
+		version, versionWrite = checkVersion(schema, version, versionSubmitted, opt, &errors)
+	}
+
+	// the document as a whole
+	if opt.Usage != GET {
+		from := len(errors)
+		checkGroups(schema, merged, opt, &errors)
+		checkDependents(schema, merged, opt, &errors)
+		labelDocumentErrors(schema, errors[from:], opt)
+	}
+
+	// derived fields are only computed from a valid document, then tested with it
+	if len(errors) == 0 {
+		compute(schema, dest, opt, &errors)
+		if schema.DocumentTest != nil && opt.Usage != GET {
+			checkDocument(schema, dest, opt, &errors)
+		}
+	}
+	if versionWrite {
+		if opt.Usage == SET {
+			dest[schema.VersionField] = version
+		} else if err := tools.WriteDeep(dest, schema.VersionField, version); err != nil {
+			panic(err)
+		}
+	}
+	return dest, errors
+}
+
+// this private function validates the value of one field, without modifying anything but its result
+// it may run concurrently with the other fields validation – see Options.Concurrency
+func validateField(path string, validator *Validator, _map map[string]interface{}, merged map[string]interface{}, rights int, opt Options) (result fieldResult) {
+	// the rules disabled by their feature flag are not run
+	if len(validator.DisabledWhen) > 0 {
+		validator = enabledRules(validator, opt)
+	}
+
+	// get the value
+	in, out := validator.Paths(path, opt.Usage)
+	result.out = out
+	collaborators := collaboratorsOf(opt)
+	value, err := collaborators.Lookup.ReadDeep(_map, in)
+
+	// the rules run are recorded once the field is validated – see Options.Coverage and Options.Trace
+	trace := newFieldTrace(path, opt)
+	if trace.on {
+		submitted := err == nil && !isEmpty(value)
+		if submitted {
+			trace.step("read %s %#v at %q", typeName(value), value, in)
+		} else {
+			trace.step("not submitted at %q", in)
+		}
+		defer func() {
+			if opt.Coverage != nil {
+				opt.Coverage.record(path, submitted, trace.rules, result.errors)
+			}
+			trace.finish(&result)
+		}()
+	}
+
+	// the errors are about the submitted field, named in the locale of the validation
+	defer func() {
+		locateErrors(result.errors, in, ParsePath(in))
+		labelErrors(result.errors, in, validator.LabelFor(opt.Locale))
+		linkFieldErrors(result.errors, in, validator.DocSlugs, opt.DocBaseURL)
+	}()
+
+	if err != nil {
+		// the path goes through a value which is not a document
+		result.errors = append(result.errors, NewError("type_mismatch", in, nil, map[string]interface{}{"expected": validator.Type}))
+		return result
+	}
+
+	// the removal of the field: the Unset sentinel, or null for a Nullable field – the sentinel is an absent value but on SET
+	if value == Unset || (value == nil && validator.Nullable && opt.Usage == SET && isSubmitted(_map, in)) {
+		if opt.Usage == SET {
+			trace.step("removal requested")
+			if checkUnset(validator, in, out, rights, opt, &result.errors) {
+				result.value, result.write = Unset, true
+			}
+			return result
+		}
+		value = nil
+	}
+
+	// if the value is nil or is a slice with len == 0
+	if isEmpty(value) {
+		// for INIT only, if value does not exist, check in the validators if it is required and apply defaults accordingly
+		if opt.Usage == INIT {
+			// on upsert, the existing value is kept and already copied to dest
+			if readExisting(opt.Existing, out) != nil {
+				trace.step("existing value kept")
+				return result
+			}
+			// does not check for now if the slice is not nil but has nil values in it...
+			if validator.IsRequired {
+				trace.add("required")
+				result.errors = append(result.errors, NewError("required", in, nil, nil))
 			} else {
-				// copy value to dest (differs based on usage: mongoDB need dot notation for update --> https://docs.mongodb.org/manual/reference/glossary/#term-dot-notation)
-				if opt.Usage == SET {
-					dest[path] = value
-				} else {
-					err := tools.WriteDeep(dest, path, value)
-					if err != nil {
-						panic(err)
+				var value interface{}
+				var ok bool
+				if err := callSafely(in, "Default", func() { value, ok = validator.DefaultValue(merged, opt.Args) }); err != nil {
+					result.errors = append(result.errors, err)
+				} else if ok {
+					trace.add("default")
+					result.value, result.write = value, true
+				} else if validator.DefaultNow {
+					trace.add("default")
+					now := currentTime(opt)
+					if validator.Location != nil {
+						now = now.In(validator.Location)
 					}
+					result.value, result.write = now, true
 				}
+			}
+		}
+		// some fields are demanded outside INIT too, e.g. an id on update
+		if opt.Usage != INIT && (opt.Usage == SET && validator.RequiredOnSet || containsString(opt.ErrorOnMissing, path)) {
+			trace.add("required")
+			result.errors = append(result.errors, NewError("required", in, nil, nil))
+		} else if opt.Usage == SET && opt.Partial && validator.IsRequired && isSubmitted(_map, in) {
+			// a partial update only requires the fields it provides: a required field explicitly emptied is refused
+			trace.add("required")
+			result.errors = append(result.errors, NewError("required", in, nil, nil))
+		}
+		// else the field is simply ignored
+		return result
+	}
 
-				// check type
-				if checkType(validator, value, &errors) == false {
-					continue
-				}
+	if validator.IsRequired && (opt.Usage == INIT || opt.Usage == SET && opt.Partial) || validator.RequiredOnSet && opt.Usage == SET {
+		trace.add("required")
+	}
+	trace.addCoerce(validator)
 
-				// check requirements
-				if checkValue(validator, value, &errors) == false {
-					continue
-				}
-				// check rights
-				if checkRights(validator, opt.Usage, opt.UserRights, &errors) == false {
-					continue // not useful, but for consistancy
-				}
+	// the numbers written by a person are read in the locale first
+	if validator.LocaleNumbers {
+		trace.add("localenumbers")
+		var localeError *DataError
+		if value, localeError = parseLocaleNumber(value, opt.Locale); localeError != nil {
+			result.errors = append(result.errors, localeError)
+			return result
+		}
+	}
+
+	// convert the value into the expected type when it is lossless
+	value, coerceError := coerce(validator, value)
+	if coerceError != nil {
+		result.errors = append(result.errors, coerceError)
+		return result
+	}
+
+	// check type – a raw JSON value can be of any type
+	if !validator.RawJSON && validator.Type != "interface {}" {
+		trace.add("type")
+	}
+	if !validator.RawJSON {
+		if err := collaborators.Types.CheckType(validator, in, value); err != nil {
+			result.errors = append(result.errors, err)
+			return result
+		}
+	}
+
+	// the sub-documents of an array are validated against the items validators
+	if validator.Items != nil {
+		trace.add("items")
+		var ok bool
+		if value, ok = checkItems(validator, in, value, rights, opt, &result.errors); !ok {
+			return result
+		}
+	}
+	if validator.Ref != "" {
+		trace.add("ref")
+		var ok bool
+		if value, ok = checkRef(validator, in, value, rights, opt, &result.errors); !ok {
+			return result
+		}
+	}
+	if validator.Tuple != nil {
+		trace.add("tuple")
+		var ok bool
+		if value, ok = checkTuple(validator, in, value, rights, opt, &result.errors); !ok {
+			return result
+		}
+	}
+	if validator.UniqueBy != "" {
+		trace.add("unique")
+		if checkUnique(validator, in, value, &result.errors) == false {
+			return result
+		}
+	}
+	if validator.Ordered != nil {
+		trace.add("ordered")
+		if checkOrdered(validator, in, value, &result.errors) == false {
+			return result
+		}
+	}
+
+	if validator.SearchQuery != nil {
+		trace.add("searchquery")
+		var ok bool
+		if value, ok = checkSearchQuery(validator, in, value, &result.errors); !ok {
+			return result
+		}
+	}
+
+	// check requirements
+	trace.addValue(validator, value)
+	if checkValue(validator, in, value, collaborators.Regexps, &result.errors) == false {
+		return result
+	}
+	if validator.Format != "" {
+		trace.add("format")
+		var ok bool
+		if value, ok = checkFormat(validator, in, value, &result.errors); !ok {
+			return result
+		}
+	}
+	if validator.TimeWindow != nil {
+		trace.add("timewindow")
+		if checkTimeWindow(validator, in, value, opt, &result.errors) == false {
+			return result
+		}
+	}
+	if validator.Birthdate != nil {
+		trace.add("birthdate")
+		if checkBirthdate(validator, in, value, opt, &result.errors) == false {
+			return result
+		}
+	}
+	if validator.CustomTest != nil {
+		trace.add("custom")
+		if checkCustom(validator, in, value, &result.errors) == false {
+			return result
+		}
+	}
+	// the value may check its own invariants
+	if checkSelf(in, value, opt, &result.errors) == false {
+		return result
+	}
+	// check rights
+	if validator.Rights != [3]int{} {
+		trace.add("rights")
+		trace.step("rights: %d required on %s, user has %d", validator.Rights[opt.Usage], usageNames[opt.Usage], rights)
+	}
+	if checkRights(validator, opt.Usage, rights, collaborators.Rights, &result.errors) == false {
+		return result
+	}
+	// check the value against the existing document
+	if validator.Immutable && opt.Usage != GET {
+		trace.add("immutable")
+	}
+	if checkExisting(validator, in, out, value, opt, &result.errors) == false {
+		return result
+	}
+	if validator.HashOf != "" {
+		trace.add("hash")
+		if checkHash(validator, in, value, merged, &result.errors) == false {
+			return result
+		}
+	}
+	if validator.CrossTest != nil {
+		trace.add("cross")
+		if checkCross(validator, in, value, merged, opt, &result.errors) == false {
+			return result
+		}
+	}
+
+	// the ObjectIds submitted as hex strings and the times are stored as the driver types
+	if opt.ObjectIdFactory != nil && opt.Usage != GET {
+		if err := callSafely(in, "ObjectIdFactory", func() { value = convertObjectIds(validator, value, opt.ObjectIdFactory) }); err != nil {
+			result.errors = append(result.errors, err)
+			return result
+		}
+	}
+	if parsed, ok := value.(time.Time); ok && opt.TimeFactory != nil && opt.Usage != GET {
+		if err := callSafely(in, "TimeFactory", func() { value = opt.TimeFactory(parsed) }); err != nil {
+			result.errors = append(result.errors, err)
+			return result
+		}
+	}
+
+	// transform the validated value into its stored form – not on GET, the stored values being transformed already
+	if validator.Transform != nil && opt.Usage != GET {
+		trace.add("transform")
+		var transformed interface{}
+		var err error
+		if panicked := callSafely(in, "Transform", func() { transformed, err = validator.Transform(value) }); panicked != nil {
+			result.errors = append(result.errors, panicked)
+			return result
+		}
+		if err != nil {
+			transformError := NewError("transform_failed", in, value, map[string]interface{}{"error": err.Error()})
+			transformError.Reason = err.Error()
+			result.errors = append(result.errors, transformError)
+			return result
+		}
+		value = transformed
+	}
+
+	// dest never shares its documents with the submitted data, since it can be written into afterwards
+	if sub, ok := value.(map[string]interface{}); ok {
+		value = copyDeep(sub)
+	}
+	result.value, result.write = value, true
+	return result
+}
+
+// this private function turns a recovered panic into an error, and logs it with its stack trace since it reveals a bug
+// it must be called by the deferred function recovering the panic, for the stack to be the one of the panic
+func internalError(field string, r interface{}) *DataError {
+	log.Printf("validation: recovered from a panic validating %q: %v\n%s", field, r, debug.Stack())
+	return NewError("internal_error", field, nil, nil)
+}
+
+// this private function runs a callback of the schema or of the options (Default, CustomTest, TimeFactory...), confining its panic:
+// the panic is turned into an internal error about the field naming the callback, so a buggy callback only fails its own field
+func callSafely(field string, callback string, fn func()) (err *DataError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = internalError(field, r)
+			err.Params = map[string]interface{}{"callback": callback}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// this private function tells whether the key at path is present in the submitted document, even with a nil value
+func isSubmitted(_map map[string]interface{}, path string) bool {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		sub, ok := _map[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		_map = sub
+	}
+	_, ok := _map[keys[len(keys)-1]]
+	return ok
+}
+
+// this private function tells whether the value is nil or an empty slice
+func isEmpty(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(value) == 0
+	case string, json.Number, bool, float64, map[string]interface{}:
+		return false
+	}
+	reflected := reflect.ValueOf(value)
+	return reflected.Kind() == reflect.Slice && reflected.Len() == 0
+}
+
+// this private function returns the string representation of the value type
+// the usual JSON types are resolved without reflection, which is way cheaper
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return "string"
+	case json.Number:
+		return "json.Number"
+	case bool:
+		return "bool"
+	case float64:
+		return "float64"
+	case map[string]interface{}:
+		return "map[string]interface {}"
+	case []interface{}:
+		return "[]interface {}"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// this private function calls fn for each index up to n, using at most concurrency goroutines
+// with a concurrency below 2, the calls are made in order from the calling goroutine
+func runFields(n int, concurrency int, fn func(i int)) {
+	if concurrency < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
 			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// this private function returns the rights the user has on the document
+// a USER whose id matches the owner id of the existing document is granted the OWNER rights
+func resolveRights(opt Options) int {
+	if opt.UserRights != USER || opt.OwnerField == "" || opt.UserID == nil {
+		return opt.UserRights
+	}
+	if owner := readExisting(opt.Existing, opt.OwnerField); owner != nil && sameValue(owner, opt.UserID) {
+		return OWNER
+	}
+	return opt.UserRights
+}
+
+// this private function checks the value against the existing document
+// immutable fields can be set once: afterwards, only the stored value is accepted
+// returns true if everything is ok, false otherelse
+func checkExisting(validator *Validator, in string, out string, value interface{}, opt Options, errors *[]*DataError) bool {
+	if validator.Immutable && opt.Usage != GET {
+		old := readExisting(opt.Existing, out)
+		// without the existing document, an update cannot be told apart from a change
+		if (opt.Usage == SET && opt.Existing == nil) || (old != nil && !sameValue(old, value)) {
+			*errors = append(*errors, NewError("immutable", in, value, nil))
+			return false
 		}
 	}
-	return dest, errors
+	return true
+}
+
+// this private function runs the user's cross test against the merged document and the existing one
+func checkCross(validator *Validator, in string, value interface{}, merged map[string]interface{}, opt Options, errors *[]*DataError) bool {
+	var ok bool
+	var err *DataError
+	if panicked := callSafely(in, "CrossTest", func() { ok, err = validator.CrossTest(value, merged, opt.Existing) }); panicked != nil {
+		*errors = append(*errors, panicked)
+		return false
+	}
+	if !ok {
+		if err == nil {
+			err = NewError("cross_test_failed", in, value, nil)
+		}
+		*errors = append(*errors, err)
+		return false
+	}
+	return true
+}
+
+// this private function reads the value at path in the existing document, if any
+func readExisting(existing map[string]interface{}, path string) interface{} {
+	if existing == nil {
+		return nil
+	}
+	value, err := tools.ReadDeep(existing, path)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// this private function compares two values; a bson.ObjectId equals its hex string representation
+func sameValue(a, b interface{}) bool {
+	return reflect.DeepEqual(objectIdString(a), objectIdString(b))
+}
+
+// this private function returns a copy of the submitted document where the values are moved from their Source to their Target key
+func rename(schema *Schema, _map map[string]interface{}, usage int) map[string]interface{} {
+	var renamed map[string]interface{}
+	for path, validator := range schema.Validators {
+		in, out := validator.Paths(path, usage)
+		if in == out {
+			continue
+		}
+		value, err := tools.ReadDeep(_map, in)
+		if err != nil || value == nil {
+			continue
+		}
+		// the submitted document is never modified
+		if renamed == nil {
+			renamed = copyDeep(_map)
+		}
+		deleteDeep(renamed, in)
+		if err := tools.WriteDeep(renamed, out, value); err != nil {
+			panic(err)
+		}
+	}
+	if renamed == nil {
+		return _map
+	}
+	return renamed
+}
+
+// this private function removes the value at path from the document
+func deleteDeep(_map map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		sub, ok := _map[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		_map = sub
+	}
+	delete(_map, keys[len(keys)-1])
+}
+
+// this private function deeply copies the maps of a document – other values are shared
+func copyDeep(_map map[string]interface{}) map[string]interface{} {
+	dest := make(map[string]interface{}, len(_map))
+	for key, value := range _map {
+		if sub, ok := value.(map[string]interface{}); ok {
+			value = copyDeep(sub)
+		}
+		dest[key] = value
+	}
+	return dest
+}
+
+// this private function writes src values over dest, merging the sub-documents both have
+func mergeDeep(dest map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		if sub, ok := value.(map[string]interface{}); ok {
+			if target, ok := dest[key].(map[string]interface{}); ok {
+				mergeDeep(target, sub)
+				continue
+			}
+			value = copyDeep(sub)
+		}
+		dest[key] = value
+	}
+	return dest
+}
+
+// this private function checks the depth and the number of values of the submitted document
+// the document is browsed without recursion, and no further than the limits
+func checkSize(_map map[string]interface{}, maxDepth int, maxFields int) *DataError {
+	if maxDepth <= 0 && maxFields <= 0 {
+		return nil
+	}
+
+	type level struct {
+		value interface{}
+		depth int
+	}
+	stack := []level{{_map, 1}}
+	fields := 0
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// only documents and arrays have children
+		size := 0
+		switch value := current.value.(type) {
+		case map[string]interface{}:
+			size = len(value)
+		case []interface{}:
+			size = len(value)
+		default:
+			continue
+		}
+
+		if maxDepth > 0 && current.depth > maxDepth {
+			return NewError("max_depth_exceeded", "", maxDepth, map[string]interface{}{"max": maxDepth})
+		}
+		fields += size
+		if maxFields > 0 && fields > maxFields {
+			return NewError("max_fields_exceeded", "", maxFields, map[string]interface{}{"max": maxFields})
+		}
+
+		switch value := current.value.(type) {
+		case map[string]interface{}:
+			for _, child := range value {
+				stack = append(stack, level{child, current.depth + 1})
+			}
+		case []interface{}:
+			for _, child := range value {
+				stack = append(stack, level{child, current.depth + 1})
+			}
+		}
+	}
+	return nil
+}
+
+// this private function reports the submitted fields that are neither validated nor a document containing validated fields
+func checkUnknown(schema *Schema, _map map[string]interface{}, usage int, errors *[]*DataError) {
+	known := make(map[string]bool, len(schema.Validators))
+	parents := make(map[string]bool)
+	for path, validator := range schema.Validators {
+		in, _ := validator.Paths(path, usage)
+		known[in] = true
+		for i := strings.LastIndex(in, "."); i > 0; i = strings.LastIndex(in[:i], ".") {
+			parents[in[:i]] = true
+		}
+	}
+	from := len(*errors)
+	walkUnknown(_map, "", nil, known, parents, errors)
+	suggestFields(schema, usage, (*errors)[from:])
+}
+
+// this private function browses the submitted document for checkUnknown, in a stable order
+func walkUnknown(_map map[string]interface{}, prefix string, segments []PathSegment, known map[string]bool, parents map[string]bool, errors *[]*DataError) {
+	keys := make([]string, 0, len(_map))
+	for key := range _map {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		// the segments are copied, since the slice is shared between the siblings
+		located := append(segments[:len(segments):len(segments)], PathSegment{Key: key})
+		if known[path] {
+			continue
+		}
+		if parents[path] {
+			// if not a document, the type mismatch is reported by the validators of the children
+			if sub, ok := _map[key].(map[string]interface{}); ok {
+				walkUnknown(sub, path+".", located, known, parents, errors)
+			}
+			continue
+		}
+		err := NewError("unknown_field", path, nil, nil)
+		err.Path = located
+		*errors = append(*errors, err)
+	}
+}
+
+// this private function runs the ValidateSelf method of the value if it is Validatable
+// returns true if everything is ok, false otherelse
+func checkSelf(path string, value interface{}, opt Options, errors *[]*DataError) bool {
+	validatable, ok := value.(Validatable)
+	if !ok {
+		return true
+	}
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var selfErrors []*DataError
+	if err := callSafely(path, "ValidateSelf", func() { selfErrors = validatable.ValidateSelf(ctx) }); err != nil {
+		*errors = append(*errors, err)
+		return false
+	}
+	failed := false
+	for _, err := range selfErrors {
+		if err == nil {
+			continue
+		}
+		nested := *err
+		nested.Field = joinPath(path, err.Field)
+		nested.Path = append(ParsePath(path), err.Path...)
+		if err.Path == nil {
+			nested.Path = ParsePath(nested.Field)
+		}
+		*errors = append(*errors, &nested)
+		failed = true
+	}
+	return !failed
 }
 
 // this private function runs the rights validator
@@ -177,9 +1270,9 @@ func Validate(validators map[string]*Validator, _map map[string]interface{}, opt
 // 2 => set rights: can the user update the property value?
 // rights values are, in order: UNAUTHENTICATED, USER, OWNER, ADMIN, NONE
 // returns true if everything is ok, false otherelse (could be the contrary)
-func checkRights(validator *Validator, usage int, userRights int, errors *[]*DataError) bool {
-	if ok := validator.CheckRights(userRights, validator.Rights[usage]); !ok {
-		*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Insufficient rights", Field: validator.Field})
+func checkRights(validator *Validator, usage int, userRights int, checker RightsChecker, errors *[]*DataError) bool {
+	if ok := checker.CheckRights(userRights, validator.Rights[usage]); !ok {
+		*errors = append(*errors, NewError("insufficient_rights", validator.Field, nil, map[string]interface{}{"rights": validator.Rights[usage]}))
 		return false
 	}
 	return true
@@ -188,34 +1281,51 @@ func checkRights(validator *Validator, usage int, userRights int, errors *[]*Dat
 // this private function runs the validator according to the provided field if existing
 // the validator checks different conditions, some based on value type
 // returns true if everything is ok, false otherelse (could be the contrary)
-func checkValue(validator *Validator, valueToTest interface{}, errors *[]*DataError) bool {
+func checkValue(validator *Validator, path string, valueToTest interface{}, matcher RegexpMatcher, errors *[]*DataError) bool {
 	// test based on value's type
 	switch value := valueToTest.(type) {
 	case string:
 		if validator.Regexp != "" {
-			ok, err := validator.ExecRegexp(value)
+			ok, err := matcher.MatchRegexp(validator, value)
 			if err != nil {
 				log.Panic(err) // if the regexp is false, panic!
 			} else {
 				if !ok {
-					*errors = append(*errors, &DataError{"Validation error", "Regex not match", validator.Field, value})
+					*errors = append(*errors, NewError("regex_not_match", validator.Field, value, map[string]interface{}{"pattern": validator.Regexp}))
 					return false
 				}
 			}
 		}
+		if len(validator.Regexps) > 0 && !checkRegexRules(validator, value, errors) {
+			return false
+		}
 	case json.Number:
 		n, _ := value.Float64()
-		if ok := validator.CheckBoundaries(n); !ok {
-			*errors = append(*errors, &DataError{"Validation error", "Out of boundaries", validator.Field, value})
+		// the decimals are bounded exactly by their own boundaries
+		if ok := validator.Decimal != nil || validator.CheckBoundaries(n); !ok {
+			*errors = append(*errors, NewError("out_of_boundaries", validator.Field, value, map[string]interface{}{"min": validator.Boundaries.Min, "max": validator.Boundaries.Max}))
 			return false
 		}
-	}
-
-	// user's custom test
-	if validator.CustomTest != nil {
-		ok, err := validator.CustomTest(valueToTest)
-		if !ok {
-			*errors = append(*errors, err)
+	case float64:
+		// the documents decoded without UseNumber hold float64 values, which were never bounded: only the Boundaries set apply to them
+		if ok := validator.Decimal != nil || validator.Boundaries == (Boundaries{}) || validator.CheckBoundaries(value); !ok {
+			*errors = append(*errors, NewError("out_of_boundaries", validator.Field, value, map[string]interface{}{"min": validator.Boundaries.Min, "max": validator.Boundaries.Max}))
+			return false
+		}
+	case map[string]interface{}:
+		if validator.Money != nil && !checkMoney(validator, path, value, errors) {
+			return false
+		}
+		if validator.Schedule != nil && !checkSchedule(validator, path, value, errors) {
+			return false
+		}
+	default:
+		if signedValue, unsignedValue, unsigned, ok := integerValue(value); ok && !checkIntegerBoundaries(validator, signedValue, unsignedValue, unsigned) {
+			params := map[string]interface{}{"min": validator.Boundaries.Min, "max": validator.Boundaries.Max}
+			if validator.IntBoundaries != nil {
+				params = map[string]interface{}{"min": validator.IntBoundaries.Min, "max": validator.IntBoundaries.Max}
+			}
+			*errors = append(*errors, NewError("out_of_boundaries", validator.Field, value, params))
 			return false
 		}
 	}
@@ -223,60 +1333,83 @@ func checkValue(validator *Validator, valueToTest interface{}, errors *[]*DataEr
 	return true
 }
 
+// this private function runs the user's custom test
+func checkCustom(validator *Validator, in string, valueToTest interface{}, errors *[]*DataError) bool {
+	var ok bool
+	var err *DataError
+	if panicked := callSafely(in, "CustomTest", func() { ok, err = validator.CustomTest(valueToTest) }); panicked != nil {
+		*errors = append(*errors, panicked)
+		return false
+	}
+	if !ok {
+		if err == nil {
+			err = NewError("custom_test_failed", validator.Field, valueToTest, nil)
+		}
+		*errors = append(*errors, err)
+		return false
+	}
+	return true
+}
+
+// this private function returns a type mismatch error for the validator, the path being the one of the element at fault if any
+func typeMismatch(validator *Validator, value interface{}, path []PathSegment) *DataError {
+	err := NewError("type_mismatch", validator.Field, value, map[string]interface{}{"expected": validator.Type})
+	err.Path = path
+	return err
+}
+
 // This function check if the real type behind the interface value is the one wished by the validators
-func checkType(validator *Validator, valueToTest interface{}, errors *[]*DataError) bool {
+func checkType(validator *Validator, path string, valueToTest interface{}, errors *[]*DataError) bool {
+	// as for the slices and maps items, "interface {}" accepts any value
+	descriptor := validator.typeDescriptor()
+	if descriptor.kind == anyType {
+		return true
+	}
 	kind := reflect.ValueOf(valueToTest).Kind()
 	switch kind {
 	case reflect.Slice:
-		array := validator.Type[0:2] // indeed, the type representation string begins with []
-		_type := validator.Type[2:]  // here we have the type after []
-		if array != "[]" {
-			*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Type mismatch", Field: validator.Field, Value: reflect.TypeOf(valueToTest).String()})
+		if descriptor.kind != sliceType {
+			*errors = append(*errors, typeMismatch(validator, typeName(valueToTest), nil))
 			return false
-		} else {
-			for _, value := range valueToTest.([]interface{}) {
-				vtype := reflect.TypeOf(value).String()
-				if vtype != _type {
-					// _type can be bson.ObjectId... which is basicly a string. So the condition above may fail but the type is in fact correct. Let's check:
-					if stringValue, ok := value.(string); ok && _type == "bson.ObjectId" && bson.IsObjectIdHex(stringValue) {
-						return true
-					} else {
-						*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Type mismatch", Field: validator.Field, Value: "[] contains " + reflect.TypeOf(value).String()})
-						return false
-					}
-				}
+		}
+		slice := reflect.ValueOf(valueToTest)
+		for i := 0; i < slice.Len(); i++ {
+			value := slice.Index(i).Interface()
+			// the element type can be bson.ObjectId... which is basicly a string: matches accepts the hex strings
+			if vtype := typeName(value); !descriptor.elem.matches(vtype, value) {
+				*errors = append(*errors, typeMismatch(validator, "[] contains "+vtype, append(ParsePath(path), PathSegment{Index: i, IsIndex: true})))
+				return false
 			}
 		}
 	case reflect.Map:
 		// json maps are map[string]interface{}, but we could test for more...
-		parts := strings.SplitAfter(validator.Type, "]")
-		for key, value := range valueToTest.(map[string]interface{}) {
-			vtype := reflect.TypeOf(value)
-
+		_map, ok := valueToTest.(map[string]interface{})
+		if !ok || descriptor.kind != mapType {
+			// not a json map: the whole type has to match
+			if _type := typeName(valueToTest); _type != validator.Type {
+				*errors = append(*errors, typeMismatch(validator, _type, nil))
+				return false
+			}
+			return true
+		}
+		for key, value := range _map {
 			// such as is the string key a correct ObjectId ?
-			if parts[0] == "map[bson.ObjectId]" {
-				if !bson.IsObjectIdHex(key) {
-					*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Type mismatch", Field: validator.Field, Value: "one of the indexes at least is not valid ObjectId: " + key})
-					return false
-				}
+			if !descriptor.key.matches("string", key) {
+				*errors = append(*errors, typeMismatch(validator, "one of the indexes at least is not valid ObjectId: "+key, append(ParsePath(path), PathSegment{Key: key})))
+				return false
 			}
 
 			// or test the real value behind interface{}
-			if vtype.String() != parts[1] {
-				*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Type mismatch", Field: validator.Field, Value: "one of the map values is of type: " + vtype.String()})
+			if vtype := typeName(value); !descriptor.elem.matches(vtype, nil) {
+				*errors = append(*errors, typeMismatch(validator, "one of the map values is of type: "+vtype, append(ParsePath(path), PathSegment{Key: key})))
 				return false
 			}
 		}
 	default:
-		if _type := reflect.TypeOf(valueToTest); _type != nil && _type.String() != validator.Type {
-			// bson.ObjectId is match as a string, let's try to save them off the error pireflect.TypeOf(valueToTest)reflect.TypeOf(valueToTest)t
-			if _type.String() == "string" && bson.IsObjectIdHex(valueToTest.(string)) && validator.Type == "bson.ObjectId" {
-				return true
-			} else {
-				// ok, let'em fall
-				*errors = append(*errors, &DataError{Type: "Validation error", Reason: "Type mismatch", Field: validator.Field, Value: _type.String()})
-				return false
-			}
+		// bson.ObjectId is match as a string, let's try to save them off the error
+		if _type := typeName(valueToTest); valueToTest != nil && !descriptor.matches(_type, valueToTest) {
+			*errors = append(*errors, typeMismatch(validator, _type, nil))
+			return false
 		}
 	}
 	return true
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/grebett/tools"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct is written in the dest of a SET validation in place of the version field – see Schema.VersionField
+// it holds the version the client read the document at: Update turns it into an increment of the field, and VersionFilter into the condition of a compare-and-swap
+type VersionCheck struct {
+	Expected int64
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the condition the update of a SET dest must be filtered with, {path: expected version}, for a compare-and-swap
+// e.g. collection.Update(bson.M{"_id": id, ...VersionFilter(dest)}, Update(dest)): no document is updated if it was modified in the meantime
+// it returns nil if dest holds no version check
+func VersionFilter(dest map[string]interface{}) map[string]interface{} {
+	for path, value := range dest {
+		if check, ok := value.(VersionCheck); ok {
+			return map[string]interface{}{path: check.Expected}
+		}
+	}
+	return nil
+}
+
+// this private function returns the submitted document without the version field, which no validator is run for
+func splitVersion(schema *Schema, _map map[string]interface{}) (map[string]interface{}, interface{}, bool) {
+	value, err := tools.ReadDeep(_map, schema.VersionField)
+	if err != nil || !isSubmitted(_map, schema.VersionField) {
+		return _map, nil, false
+	}
+	remaining := copyDeep(_map)
+	deleteDeep(remaining, schema.VersionField)
+	// the sub-documents left empty are removed too, not to be reported as unknown
+	for path := schema.VersionField; strings.Contains(path, "."); {
+		path = path[:strings.LastIndex(path, ".")]
+		if parent, _ := tools.ReadDeep(remaining, path); parent == nil || len(parent.(map[string]interface{})) > 0 {
+			break
+		}
+		deleteDeep(remaining, path)
+	}
+	return remaining, value, true
+}
+
+// this private function validates the version field and returns the value to write in dest:
+// on SET, the submitted version is required and checked against the existing one if any; on INIT, the version starts at 1, or follows the existing one; on GET, the stored one is kept
+func checkVersion(schema *Schema, value interface{}, submitted bool, opt Options, errors *[]*DataError) (interface{}, bool) {
+	path := schema.VersionField
+	switch opt.Usage {
+	case INIT:
+		existing, ok := versionNumber(readExisting(opt.Existing, path))
+		if !ok {
+			return int64(1), true
+		}
+		return existing + 1, true
+
+	case GET:
+		return value, submitted
+
+	default:
+		if !submitted || value == nil {
+			*errors = append(*errors, NewError("required", path, nil, nil))
+			return nil, false
+		}
+		version, ok := versionNumber(value)
+		if !ok {
+			*errors = append(*errors, NewError("type_mismatch", path, typeName(value), map[string]interface{}{"expected": "int64"}))
+			return nil, false
+		}
+		if existing := readExisting(opt.Existing, path); existing != nil {
+			if current, ok := versionNumber(existing); !ok || current != version {
+				*errors = append(*errors, NewError("version_conflict", path, version, map[string]interface{}{"current": existing}))
+				return nil, false
+			}
+		}
+		return VersionCheck{Expected: version}, true
+	}
+}
+
+// this private function returns the version number of a value: a positive or zero integer, or a json.Number holding one
+func versionNumber(value interface{}) (int64, bool) {
+	if number, ok := value.(json.Number); ok {
+		version, err := number.Int64()
+		return version, err == nil && version >= 0
+	}
+	signed, unsigned, isUnsigned, ok := integerValue(value)
+	if !ok {
+		return 0, false
+	}
+	if isUnsigned {
+		return int64(unsigned), unsigned <= 1<<63-1
+	}
+	return signed, signed >= 0
+}
@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVersionField(t *testing.T) {
+	schema := &Schema{VersionField: "meta.version", Validators: map[string]*Validator{"name": {Type: "string"}}}
+	dest, errors := schema.Validate(map[string]interface{}{"name": "Ada"}, Options{Usage: INIT, Strict: true})
+	if len(errors) > 0 || dest["meta"].(map[string]interface{})["version"] != int64(1) {
+		t.Errorf("expected the version to start at 1, got %v %v", dest, errors)
+	}
+
+	dest, errors = schema.Validate(map[string]interface{}{"name": "Ada", "meta": map[string]interface{}{"version": json.Number("3")}}, Options{Usage: SET, Strict: true})
+	if len(errors) > 0 {
+		t.Fatalf("expected no errors, got %v", errors)
+	}
+	if filter := VersionFilter(dest); !reflect.DeepEqual(filter, map[string]interface{}{"meta.version": int64(3)}) {
+		t.Errorf("expected the compare-and-swap filter, got %v", filter)
+	}
+	expected := map[string]interface{}{"$set": map[string]interface{}{"name": "Ada"}, "$inc": map[string]interface{}{"meta.version": 1}}
+	if update := Update(dest); !reflect.DeepEqual(update, expected) {
+		t.Errorf("expected %v, got %v", expected, update)
+	}
+
+	// on SET, the version is required, and must match the existing one
+	existing := map[string]interface{}{"meta": map[string]interface{}{"version": int64(4)}}
+	for _, test := range []struct {
+		document map[string]interface{}
+		code     string
+	}{
+		{map[string]interface{}{"name": "Ada"}, "required"},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": "x"}}, "type_mismatch"},
+		{map[string]interface{}{"meta": map[string]interface{}{"version": json.Number("3")}}, "version_conflict"},
+	} {
+		_, errors := schema.Validate(test.document, Options{Usage: SET, Existing: existing})
+		if len(errors) != 1 || errors[0].Code != test.code || errors[0].Field != "meta.version" {
+			t.Errorf("%v: expected %s, got %v", test.document, test.code, errors)
+		}
+	}
+
+	// on GET, the stored version is kept
+	dest, errors = schema.Validate(existing, Options{Usage: GET})
+	if len(errors) > 0 || dest["meta"].(map[string]interface{})["version"] != int64(4) {
+		t.Errorf("expected the stored version, got %v %v", dest, errors)
+	}
+
+	if errors := CheckSchema(&Schema{VersionField: "name", Validators: schema.Validators}); len(errors) != 1 {
+		t.Errorf("expected a validator on the version field to be reported, got %v", errors)
+	}
+	if VersionFilter(map[string]interface{}{"name": "Ada"}) != nil {
+		t.Errorf("expected no filter without a version check")
+	}
+}
@@ -0,0 +1,171 @@
+// Package watch reloads the schemas written in files whenever the files change, for the deployments tweaking their rules without redeploying
+// a schema file holds a single validation.Schema written as JSON: {"Validators": {"name": {"Type": "string", "IsRequired": true}}}
+// the running handlers keep validating against the last good version of the file: a file that cannot be read or is invalid is reported and ignored
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This struct watches a schema file and reloads it on change
+// the directory of the file is watched rather than the file itself, so the editors and tools replacing the file (rename, symlink swap) are handled
+type Watcher struct {
+	Path     string          // the path of the schema file
+	Delay    time.Duration   // the quiet period after a change before the file is reloaded, so a file being written is read once complete – 100ms if 0
+	OnError  func(err error) // this function is called with the errors of the reloads, which are otherwise only kept for Err
+	OnReload func()          // this function is called once a new version of the file is in use
+}
+
+// This struct is a schema loaded from a file by a Watcher: it validates the documents against the last good version of the file
+// it is safe for concurrent use, the reloads swapping the compiled schema atomically
+type Schema struct {
+	mu       sync.RWMutex
+	compiled *validation.CompiledSchema
+	err      error
+}
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method loads the schema file and reloads it on change until the context is done
+// an error is returned if the file cannot be loaded at first or cannot be watched
+func (w *Watcher) Watch(ctx context.Context) (*Schema, error) {
+	schema := &Schema{}
+	if err := schema.load(w.Path); err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(w.Path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	delay := w.Delay
+	if delay == 0 {
+		delay = 100 * time.Millisecond
+	}
+	go func() {
+		defer watcher.Close()
+		var timer *time.Timer
+		reload := make(chan struct{}, 1)
+		for {
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(w.Path) || event.Op.Has(fsnotify.Chmod) && !event.Op.Has(fsnotify.Write) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(delay, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			case <-reload:
+				w.reload(schema)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				w.fail(schema, err)
+			}
+		}
+	}()
+	return schema, nil
+}
+
+// This method reloads the schema file and reports the outcome
+func (w *Watcher) reload(schema *Schema) {
+	if err := schema.load(w.Path); err != nil {
+		w.fail(schema, err)
+		return
+	}
+	if w.OnReload != nil {
+		w.OnReload()
+	}
+}
+
+// This method records the error and passes it to OnError
+func (w *Watcher) fail(schema *Schema, err error) {
+	schema.mu.Lock()
+	schema.err = err
+	schema.mu.Unlock()
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// This method reads and compiles the schema file and swaps it in; the current schema is kept if the file is invalid
+func (s *Schema) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	schema := new(validation.Schema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return fmt.Errorf("watch: %s: %s", path, err)
+	}
+	compiled, err := schema.Compile()
+	if err != nil {
+		return fmt.Errorf("watch: %s: %s", path, err)
+	}
+	s.mu.Lock()
+	s.compiled, s.err = compiled, nil
+	s.mu.Unlock()
+	return nil
+}
+
+// This method runs the current version of the schema against the provided data – see validation.Validate
+func (s *Schema) Validate(_map map[string]interface{}, opt validation.Options) (map[string]interface{}, validation.DataErrors) {
+	return s.Compiled().Validate(_map, opt)
+}
+
+// This method returns the current version of the schema
+func (s *Schema) Compiled() *validation.CompiledSchema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.compiled
+}
+
+// This method returns the error of the last reload, nil if it succeeded: the schema in use is then the last good one
+func (s *Schema) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function loads the schema file and reloads it on change until the context is done – see Watcher
+func File(ctx context.Context, path string) (*Schema, error) {
+	return (&Watcher{Path: path}).Watch(ctx)
+}
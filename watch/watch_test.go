@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grebett/validation"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.json")
+	if err := os.WriteFile(path, []byte(`{"Validators": {"name": {"Type": "string", "IsRequired": true}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan struct{}, 1)
+	failed := make(chan error, 1)
+	schema, err := (&Watcher{
+		Path:     path,
+		Delay:    20 * time.Millisecond,
+		OnReload: func() { reloaded <- struct{}{} },
+		OnError:  func(err error) { failed <- err },
+	}).Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, errors := schema.Validate(map[string]interface{}{}, validation.Options{Usage: validation.INIT}); len(errors) != 1 || errors[0].Code != "required" {
+		t.Errorf("expected the name to be required, got %v", errors)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"Validators": {"name": {"Type": "string"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reloaded:
+	case err := <-failed:
+		t.Fatalf("expected a reload, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a reload")
+	}
+	if _, errors := schema.Validate(map[string]interface{}{}, validation.Options{Usage: validation.INIT}); len(errors) != 0 {
+		t.Errorf("expected the new version, got %v", errors)
+	}
+
+	// an invalid file is reported, and the last good version kept
+	if err := os.WriteFile(path, []byte(`{"Validators": {"name": {"Type": "string", "Regexp": "("}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-failed:
+		if schema.Err() != err {
+			t.Errorf("expected Err to be %v, got %v", err, schema.Err())
+		}
+	case <-reloaded:
+		t.Fatal("expected the invalid file to be refused")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an error")
+	}
+	if schema.Compiled() == nil {
+		t.Errorf("expected the last good version to be kept")
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte(`{"Validators": `), 0644)
+	for _, path := range []string{filepath.Join(dir, "missing.json"), invalid} {
+		if _, err := File(context.Background(), path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package webhook ingests the webhooks sent by third-party services: the signature of the request is verified, then its body is validated against a schema
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grebett/validation"
+)
+
+//***********************************************************************************
+//                                 STRUCTURES
+//***********************************************************************************
+
+// This interface is implemented by the signature schemes – HMAC and Stripe are provided
+type Verifier interface {
+	Verify(header http.Header, body []byte) error
+}
+
+// This type lets a function be used as a Verifier
+type VerifierFunc func(header http.Header, body []byte) error
+
+// This struct verifies a hex-encoded HMAC of the body sent in a header, GitHub-style: "X-Hub-Signature-256: sha256=<hex>"
+type HMAC struct {
+	Secret []byte
+	Header string           // the header holding the signature
+	Prefix string           // the prefix of the signature in the header, e.g. "sha256="
+	Hash   func() hash.Hash // sha256.New if nil
+}
+
+// This struct verifies the Stripe-Signature header: "t=<timestamp>,v1=<hex HMAC-SHA256 of timestamp.body>"
+type Stripe struct {
+	Secret    []byte
+	Tolerance time.Duration    // the maximal age of the signature, 5 minutes if 0
+	Now       func() time.Time // time.Now if nil
+}
+
+// This struct receives the webhooks: the signature is verified first, then the JSON body is validated
+type Receiver struct {
+	Verifier    Verifier                     // the request is not verified if nil
+	Schema      validation.DocumentValidator // the schema of the body
+	Options     validation.Options           // the options of the body validation
+	MaxBodySize int64                        // the maximal size of the body in bytes, 1 MiB if 0
+}
+
+// the error of a signature that does not match
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+//***********************************************************************************
+//                                   METHODS
+//***********************************************************************************
+
+// This method calls the function
+func (f VerifierFunc) Verify(header http.Header, body []byte) error {
+	return f(header, body)
+}
+
+// This method verifies the HMAC of the body
+func (h HMAC) Verify(header http.Header, body []byte) error {
+	signature := header.Get(h.Header)
+	if !strings.HasPrefix(signature, h.Prefix) {
+		return ErrInvalidSignature
+	}
+	newHash := h.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	return checkMAC(newHash, h.Secret, body, signature[len(h.Prefix):])
+}
+
+// This method verifies the Stripe signature of the body, and that it is recent enough
+func (s Stripe) Verify(header http.Header, body []byte) error {
+	var timestamp string
+	var signatures []string
+	for _, item := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	tolerance, now := s.Tolerance, time.Now
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if s.Now != nil {
+		now = s.Now
+	}
+	if age := now().Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("webhook: signature too old")
+	}
+
+	signed := append([]byte(timestamp+"."), body...)
+	for _, signature := range signatures {
+		if checkMAC(sha256.New, s.Secret, signed, signature) == nil {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// This method reads the body of the request, verifies its signature and validates it – see validation.Validate
+// the errors are an "invalid_signature", "too_large" or "invalid_payload" error alone, or the validation ones – see Status
+func (r *Receiver) Receive(req *http.Request) (map[string]interface{}, validation.DataErrors) {
+	limit := r.MaxBodySize
+	if limit == 0 {
+		limit = 1 << 20
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"error": err.Error()})}
+	}
+	if int64(len(body)) > limit {
+		return nil, validation.DataErrors{validation.NewError("too_large", "", nil, map[string]interface{}{"max": limit})}
+	}
+
+	if r.Verifier != nil {
+		if err := r.Verifier.Verify(req.Header, body); err != nil {
+			return nil, validation.DataErrors{validation.NewError("invalid_signature", "", nil, map[string]interface{}{"error": err.Error()})}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil || doc == nil {
+		if err == nil {
+			err = fmt.Errorf("the body is not a document")
+		}
+		return nil, validation.DataErrors{validation.NewError("invalid_payload", "", nil, map[string]interface{}{"error": err.Error()})}
+	}
+	return r.Schema.Validate(doc, r.Options)
+}
+
+//***********************************************************************************
+//                                  FUNCTIONS
+//***********************************************************************************
+
+// This function returns the verifier of the GitHub webhooks
+func GitHub(secret []byte) HMAC {
+	return HMAC{Secret: secret, Header: "X-Hub-Signature-256", Prefix: "sha256="}
+}
+
+// This function returns the HTTP status answering a webhook received with these errors
+// 401 for a bad signature, 413 for a body too large, 400 for a body that is not a document, 500 for an internal error, else 422
+func Status(errors validation.DataErrors) int {
+	if len(errors) == 0 {
+		return http.StatusOK
+	}
+	switch errors[0].Code {
+	case "invalid_signature":
+		return http.StatusUnauthorized
+	case "too_large":
+		if errors[0].Field == "" {
+			return http.StatusRequestEntityTooLarge
+		}
+	case "invalid_payload":
+		return http.StatusBadRequest
+	case "internal_error":
+		return http.StatusInternalServerError
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// this private function compares in constant time the hex MAC with the one of the message
+func checkMAC(newHash func() hash.Hash, secret []byte, message []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(newHash, secret)
+	mac.Write(message)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
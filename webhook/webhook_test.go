@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grebett/validation"
+)
+
+// this private function returns the hex HMAC-SHA256 of the message
+func sign(secret string, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestReceiveGitHub(t *testing.T) {
+	receiver := &Receiver{
+		Verifier:    GitHub([]byte("secret")),
+		Schema:      &validation.Schema{Validators: map[string]*validation.Validator{"action": {Type: "string", IsRequired: true}}},
+		Options:     validation.Options{Usage: validation.INIT},
+		MaxBodySize: 64,
+	}
+	tests := []struct {
+		body, signature string
+		status          int
+	}{
+		{`{"action": "opened"}`, "sha256=" + sign("secret", `{"action": "opened"}`), http.StatusOK},
+		{`{"action": "opened"}`, "sha256=" + sign("other", `{"action": "opened"}`), http.StatusUnauthorized},
+		{`{"action": "opened"}`, sign("secret", `{"action": "opened"}`), http.StatusUnauthorized},
+		{`{}`, "sha256=" + sign("secret", `{}`), http.StatusUnprocessableEntity},
+		{`[1]`, "sha256=" + sign("secret", `[1]`), http.StatusBadRequest},
+		{strings.Repeat(" ", 65), "", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/hooks", strings.NewReader(test.body))
+		req.Header.Set("X-Hub-Signature-256", test.signature)
+		dest, errors := receiver.Receive(req)
+		if status := Status(errors); status != test.status {
+			t.Errorf("%s: expected %d, got %d %v", test.body, test.status, status, errors)
+		}
+		if test.status == http.StatusOK && dest["action"] != "opened" {
+			t.Errorf("expected the validated document, got %v", dest)
+		}
+	}
+}
+
+func TestStripe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	verifier := Stripe{Secret: []byte("whsec"), Now: func() time.Time { return now }}
+	body := []byte(`{"id": "evt_1"}`)
+	header := func(timestamp string, signature string) http.Header {
+		return http.Header{"Stripe-Signature": []string{"t=" + timestamp + ",v1=bad,v1=" + signature}}
+	}
+
+	if err := verifier.Verify(header("1700000000", sign("whsec", "1700000000."+string(body))), body); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	if err := verifier.Verify(header("1699999000", sign("whsec", "1699999000."+string(body))), body); err == nil {
+		t.Error("expected an error for an old signature")
+	}
+	if err := verifier.Verify(header("1700000000", sign("whsec", "1700000000.{}")), body); err != ErrInvalidSignature {
+		t.Errorf("expected an invalid signature, got %v", err)
+	}
+	if err := verifier.Verify(http.Header{}, body); err != ErrInvalidSignature {
+		t.Errorf("expected an invalid signature without header, got %v", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if status := Status(nil); status != http.StatusOK {
+		t.Errorf("expected 200, got %d", status)
+	}
+	if status := Status(validation.DataErrors{validation.NewError("too_large", "raw", nil, nil)}); status != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a field too large, got %d", status)
+	}
+	if status := Status(validation.DataErrors{validation.NewError("internal_error", "", nil, nil)}); status != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", status)
+	}
+}